@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"codemap/scanner"
+)
+
+// RunAge implements "codemap age": a quick read on how mature a codebase is -
+// first/latest commit, author count, and the most and least recently changed
+// source files.
+func RunAge(args []string) error {
+	fs := flag.NewFlagSet("age", flag.ContinueOnError)
+	limit := fs.Int("limit", 5, "Number of files to list in each direction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	age, err := scanner.GitAge(absRoot)
+	if err != nil || age.Commits == 0 {
+		fmt.Println("No git history here - nothing to date")
+		return nil
+	}
+
+	now := time.Now()
+	fmt.Printf("📅 %s\n", filepath.Base(absRoot))
+	fmt.Printf("   First commit:  %s (%s)\n", age.FirstCommit.Format("2006-01-02"), formatAge(now.Sub(age.FirstCommit)))
+	fmt.Printf("   Latest commit: %s (%s)\n", age.LastCommit.Format("2006-01-02"), formatAge(now.Sub(age.LastCommit)))
+	fmt.Printf("   Commits: %d | Authors: %d\n", age.Commits, age.Authors)
+
+	if len(age.Files) == 0 {
+		return nil
+	}
+	n := min(*limit, len(age.Files))
+
+	fmt.Println()
+	fmt.Println("   Most recently changed:")
+	for _, f := range age.Files[:n] {
+		fmt.Printf("     • %s (%s)\n", f.Path, formatAge(now.Sub(f.LastChanged)))
+	}
+
+	fmt.Println()
+	fmt.Println("   Least recently changed:")
+	for i := len(age.Files) - 1; i >= len(age.Files)-n; i-- {
+		f := age.Files[i]
+		fmt.Printf("     • %s (%s)\n", f.Path, formatAge(now.Sub(f.LastChanged)))
+	}
+	return nil
+}
+
+// formatAge renders a duration as a coarse "3 days ago" / "2 years ago"
+func formatAge(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d < time.Hour:
+		return "just now"
+	case d < day:
+		return plural(int(d/time.Hour), "hour") + " ago"
+	case d < 60*day:
+		return plural(int(d/day), "day") + " ago"
+	case d < 730*day:
+		return plural(int(d/(30*day)), "month") + " ago"
+	default:
+		return plural(int(d/(365*day)), "year") + " ago"
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"codemap/scanner"
+)
+
+// RunBrokenImports implements "codemap broken-imports": reports imports that
+// point inside the project but no longer resolve to a file, usually left
+// behind by a delete or rename.
+func RunBrokenImports(args []string) error {
+	fs := flag.NewFlagSet("broken-imports", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	focus, err := scanner.ConfigFocus(absRoot)
+	if err != nil {
+		return err
+	}
+
+	fg, analyses, err := scanner.BuildFileGraphWithAnalyses(absRoot)
+	if err != nil {
+		return fmt.Errorf("building file graph: %w", err)
+	}
+
+	broken := scanner.FindBrokenImports(fg, scanner.FilterAnalysisToFocus(analyses, focus))
+	if len(broken) == 0 {
+		fmt.Println("✅ No broken imports")
+		return nil
+	}
+
+	fmt.Printf("💔 %d import(s) with no file on disk:\n", len(broken))
+	for _, b := range broken {
+		fmt.Printf("   • %s imports %s\n", b.File, b.Import)
+	}
+	return fmt.Errorf("%d broken import(s)", len(broken))
+}
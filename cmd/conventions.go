@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"codemap/scanner"
+)
+
+// RunConventions implements "codemap conventions": flags files whose names
+// break the dominant naming style of their directory and language.
+func RunConventions(args []string) error {
+	fs := flag.NewFlagSet("conventions", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	focus, err := scanner.ConfigFocus(absRoot)
+	if err != nil {
+		return err
+	}
+
+	files, err := scanner.ScanFiles(absRoot, scanner.NewGitIgnoreCache(absRoot), nil, nil)
+	if err != nil {
+		return err
+	}
+	files = scanner.FilterToFocus(files, focus)
+
+	outliers := scanner.FindConventionOutliers(absRoot, files)
+	if len(outliers) == 0 {
+		fmt.Println("✅ File names follow each directory's dominant convention")
+		return nil
+	}
+
+	fmt.Printf("📛 %d file(s) break their directory's naming convention:\n", len(outliers))
+	for _, o := range outliers {
+		fmt.Printf("   • %s (%s, siblings use %s)\n", o.Path, o.Style, o.Dominant)
+	}
+	return nil
+}
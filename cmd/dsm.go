@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"codemap/render"
+	"codemap/scanner"
+)
+
+// RunDSM implements "codemap dsm": prints the import graph as a Design
+// Structure Matrix, partitioned so cycles cluster together.
+func RunDSM(args []string) error {
+	fs := flag.NewFlagSet("dsm", flag.ContinueOnError)
+	level := fs.String("dsm-level", "", fmt.Sprintf("Matrix entries: file or dir (default file, or dir above %d files)", scanner.DSMFileLimit))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	if _, err := scanner.ResolveDSMLevel(*level, 0); err != nil {
+		return err
+	}
+	fg, err := scanner.BuildFileGraph(absRoot)
+	if err != nil {
+		return fmt.Errorf("building file graph: %w", err)
+	}
+	m, err := scanner.BuildDSM(fg, *level)
+	if err != nil {
+		return err
+	}
+	render.DSM(m)
+	return nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"codemap/scanner"
+)
+
+// RunExtHubs implements "codemap ext-hubs": ranks third-party packages by how
+// many project files import them, to size up what an upgrade touches.
+func RunExtHubs(args []string) error {
+	fs := flag.NewFlagSet("ext-hubs", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "Number of packages to list (0 = all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	fg, analyses, err := scanner.BuildFileGraphWithAnalyses(absRoot)
+	if err != nil {
+		return fmt.Errorf("building file graph: %w", err)
+	}
+
+	hubs := scanner.FindExternalHubs(fg, analyses)
+	if len(hubs) == 0 {
+		fmt.Println("No external dependencies imported")
+		return nil
+	}
+
+	shown := hubs
+	if *limit > 0 && len(shown) > *limit {
+		shown = shown[:*limit]
+	}
+	fmt.Printf("📦 %d external package(s), most imported first:\n", len(hubs))
+	for _, h := range shown {
+		fmt.Printf("   %4d  %s (%s)\n", len(h.Files), h.Package, h.Language)
+	}
+	if len(shown) < len(hubs) {
+		fmt.Printf("   ... and %d more (--limit 0 for all)\n", len(hubs)-len(shown))
+	}
+	return nil
+}
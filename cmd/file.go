@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codemap/scanner"
+)
+
+// RunFile implements "codemap file": everything codemap knows about one file
+// (language, size, functions, imports, importers, hub status, connected
+// files), the command-line counterpart of the get_file_context MCP tool.
+func RunFile(args []string) error {
+	fs := flag.NewFlagSet("file", flag.ContinueOnError)
+	rootFlag := fs.String("root", ".", "Project root the file belongs to")
+	importPaths := fs.Bool("import-paths", false, "Show imports and importers as Go package / Python module paths")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.Arg(0) == "" {
+		return fmt.Errorf("usage: codemap file [--root dir] [--import-paths] <path>")
+	}
+
+	absRoot, err := filepath.Abs(*rootFlag)
+	if err != nil {
+		return err
+	}
+	absFile, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(absRoot, absFile)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is outside %s (set --root)", fs.Arg(0), absRoot)
+	}
+	info, err := os.Stat(absFile)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", fs.Arg(0))
+	}
+
+	ctx := fileContext{Path: filepath.ToSlash(rel), Language: scanner.DetectLanguageAt(absRoot, rel), Size: info.Size(), ImportPaths: *importPaths}
+	fg, err := scanner.BuildFileGraph(absRoot)
+	if err != nil {
+		ctx.GraphErr = err
+	} else {
+		ctx.Graph = fg
+		if sg, err := scanner.NewAstGrepScanner(); err == nil {
+			if a, err := sg.AnalyzeFile(absFile); err == nil && a != nil {
+				ctx.Functions = a.Functions
+			}
+			sg.Close()
+		}
+	}
+	writeFileContext(os.Stdout, ctx)
+	return nil
+}
+
+// fileContext is what "codemap file" reports on
+type fileContext struct {
+	Path      string // relative to the root, slash-separated
+	Language  string
+	Size      int64
+	Functions []string
+	Graph     *scanner.FileGraph // nil if the graph couldn't be built
+	GraphErr  error
+
+	ImportPaths bool // list edges as import paths rather than file paths
+}
+
+// writeFileContext prints a file's details and its place in the graph
+func writeFileContext(w io.Writer, c fileContext) {
+	fmt.Fprintf(w, "📄 %s\n", c.Path)
+	lang := scanner.LangDisplay[c.Language]
+	if lang == "" {
+		lang = "unknown language"
+	}
+	fmt.Fprintf(w, "   %s · %d bytes\n", lang, c.Size)
+	if len(c.Functions) > 0 {
+		fmt.Fprintf(w, "   Functions (%d): %s\n", len(c.Functions), strings.Join(c.Functions, ", "))
+	}
+
+	fg := c.Graph
+	if fg == nil {
+		fmt.Fprintf(w, "\n   Dependency graph unavailable: %v\n", c.GraphErr)
+		return
+	}
+	inGraph := false
+	for _, f := range fg.Files {
+		if filepath.ToSlash(f) == c.Path {
+			inGraph = true
+			break
+		}
+	}
+	if !inGraph {
+		fmt.Fprintln(w, "\n   Not in the dependency graph (ignored, or not a source file)")
+		return
+	}
+
+	file := filepath.FromSlash(c.Path)
+	imports, importers := fg.Imports[file], fg.ImportersOf(file)
+	shownImports, shownImporters := imports, importers
+	if c.ImportPaths {
+		shownImports, shownImporters = fg.ImportPaths(imports), fg.ImportPaths(importers)
+	}
+	fmt.Fprintln(w)
+	if fg.IsHub(file) {
+		fmt.Fprintf(w, "   ⚠️  HUB FILE - %d files depend on this\n\n", len(importers))
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(w, "   Imports (%d):\n", len(imports))
+		for _, f := range shownImports {
+			fmt.Fprintf(w, "     -> %s\n", f)
+		}
+	} else {
+		fmt.Fprintln(w, "   Imports: none (leaf file)")
+	}
+	if len(importers) > 0 {
+		fmt.Fprintf(w, "   Imported by (%d):\n", len(importers))
+		for _, f := range shownImporters {
+			fmt.Fprintf(w, "     <- %s\n", f)
+		}
+	} else {
+		fmt.Fprintln(w, "   Imported by: none (entry point or unused)")
+	}
+	fmt.Fprintf(w, "   Connected: %d files in dependency graph\n", len(fg.ConnectedFiles(file)))
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"codemap/scanner"
+)
+
+func TestWriteFileContext(t *testing.T) {
+	fg := &scanner.FileGraph{
+		Files: []string{"main.go", "api/server.go", "db/conn.go", "api/routes.go", "cli/run.go"},
+		Imports: map[string][]string{
+			"main.go":       {"api/server.go"},
+			"api/server.go": {"db/conn.go"},
+			"api/routes.go": {"api/server.go"},
+			"cli/run.go":    {"api/server.go"},
+		},
+		Importers: map[string][]string{
+			"api/server.go": {"api/routes.go", "cli/run.go", "main.go"},
+			"db/conn.go":    {"api/server.go"},
+		},
+	}
+
+	var buf bytes.Buffer
+	writeFileContext(&buf, fileContext{Path: "api/server.go", Language: "go", Size: 1200, Functions: []string{"Serve", "route"}, Graph: fg})
+	out := buf.String()
+	for _, want := range []string{
+		"📄 api/server.go",
+		"Go · 1200 bytes",
+		"Functions (2): Serve, route",
+		"HUB FILE - 3 files depend on this",
+		"Imports (1):\n     -> db/conn.go",
+		"Imported by (3):\n     <- api/routes.go\n     <- cli/run.go\n     <- main.go",
+		"Connected: 4 files",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	// With import paths, Go edges show as packages and same-package files merge
+	fg.Module = "example.com/app"
+	buf.Reset()
+	writeFileContext(&buf, fileContext{Path: "api/server.go", Language: "go", Graph: fg, ImportPaths: true})
+	out = buf.String()
+	for _, want := range []string{
+		"Imports (1):\n     -> example.com/app/db\n",
+		"Imported by (3):\n     <- example.com/app/api\n     <- example.com/app/cli\n     <- example.com/app\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	writeFileContext(&buf, fileContext{Path: "README.md", Size: 10, Graph: fg})
+	if !strings.Contains(buf.String(), "Not in the dependency graph") {
+		t.Errorf("Expected a not-in-graph note, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	writeFileContext(&buf, fileContext{Path: "main.go", Language: "go", GraphErr: errors.New("ast-grep not found")})
+	if !strings.Contains(buf.String(), "Dependency graph unavailable: ast-grep not found") {
+		t.Errorf("Expected the graph error, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"codemap/scanner"
+)
+
+// RunFingerprint implements "codemap fingerprint": prints a stable identity
+// for the project's current file set, for caching and multi-repo dashboards.
+func RunFingerprint(args []string) error {
+	fs := flag.NewFlagSet("fingerprint", flag.ContinueOnError)
+	content := fs.Bool("content", false, "Hash file contents too, so same-size edits change the fingerprint")
+	jsonOut := fs.Bool("json", false, "Output JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	fingerprint := scanner.Fingerprint
+	if *content {
+		fingerprint = scanner.ContentFingerprint
+	}
+	fp, err := fingerprint(absRoot)
+	if err != nil {
+		return err
+	}
+
+	if *jsonOut {
+		return json.NewEncoder(os.Stdout).Encode(fp)
+	}
+	lang := fp.PrimaryLanguage
+	if lang == "" {
+		lang = "none"
+	}
+	fmt.Printf("%s  %d files  %s\n", fp.Hash, fp.Files, lang)
+	return nil
+}
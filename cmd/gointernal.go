@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"codemap/scanner"
+)
+
+// RunGoInternal implements "codemap go-internal": lists internal/ packages and
+// reports imports that violate Go's internal visibility rule.
+func RunGoInternal(args []string) error {
+	fs := flag.NewFlagSet("go-internal", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	fg, err := scanner.BuildFileGraph(absRoot)
+	if err != nil {
+		return fmt.Errorf("building file graph: %w", err)
+	}
+	if fg.Module == "" {
+		fmt.Println("No go.mod found - not a Go module")
+		return nil
+	}
+
+	files, err := scanner.ScanFiles(absRoot, scanner.NewGitIgnoreCache(absRoot), []string{"go"}, nil)
+	if err != nil {
+		return err
+	}
+	pkgs := scanner.GoInternalPackages(files)
+	if len(pkgs) == 0 {
+		fmt.Printf("No internal/ packages in %s\n", fg.Module)
+		return nil
+	}
+
+	fmt.Printf("🔒 Internal packages in %s:\n", fg.Module)
+	for _, p := range pkgs {
+		fmt.Printf("   • %s\n", p)
+	}
+
+	analyses, err := scanner.ScanForDeps(absRoot)
+	if err != nil {
+		return err
+	}
+	violations := scanner.FindInternalViolations(fg.Module, analyses)
+	fmt.Println()
+	if len(violations) == 0 {
+		fmt.Println("✅ No internal visibility violations")
+		return nil
+	}
+
+	fmt.Printf("⚠️  %d internal visibility violation(s):\n", len(violations))
+	for _, v := range violations {
+		fmt.Printf("   • %s imports %s (only importable within %s)\n", v.File, v.Import, v.Allowed)
+	}
+	return fmt.Errorf("%d internal import violation(s)", len(violations))
+}
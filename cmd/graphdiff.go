@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"codemap/scanner"
+)
+
+// RunGraphDiff implements "codemap graph-diff": compares two projects'
+// dependency graphs, e.g. a fork against its upstream, edge by edge.
+func RunGraphDiff(args []string) error {
+	fs := flag.NewFlagSet("graph-diff", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "Output JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: codemap graph-diff [--json] <dir-a> <dir-b>")
+	}
+
+	var graphs [2]*scanner.FileGraph
+	for i := range graphs {
+		absRoot, err := filepath.Abs(fs.Arg(i))
+		if err != nil {
+			return err
+		}
+		if graphs[i], err = scanner.BuildFileGraph(absRoot); err != nil {
+			return fmt.Errorf("building file graph for %s: %w", fs.Arg(i), err)
+		}
+	}
+
+	d := scanner.DiffGraphs(graphs[0], graphs[1])
+	if *jsonOut {
+		return json.NewEncoder(os.Stdout).Encode(d)
+	}
+	writeGraphDiff(os.Stdout, d, fs.Arg(0), fs.Arg(1))
+	return nil
+}
+
+// writeGraphDiff prints a GraphDiff with a and b naming the two projects
+func writeGraphDiff(w io.Writer, d scanner.GraphDiff, a, b string) {
+	fmt.Fprintf(w, "Graph diff: %s vs %s\n", a, b)
+	fmt.Fprintf(w, "  %d shared files, %d shared edges, %.0f%% of edges diverge\n", d.SharedFiles, d.SharedEdges, d.Divergence*100)
+	if d.Empty() {
+		fmt.Fprintln(w, "  Same files, edges, and hubs")
+		return
+	}
+
+	if len(d.Renamed) > 0 {
+		fmt.Fprintf(w, "\nMoved (%d, aligned by name):\n", len(d.Renamed))
+		for _, r := range d.Renamed {
+			fmt.Fprintf(w, "  %s -> %s\n", r.A, r.B)
+		}
+	}
+	for _, s := range []struct {
+		title string
+		edges []scanner.GraphEdge
+	}{{"Edges only in " + a, d.OnlyA}, {"Edges only in " + b, d.OnlyB}} {
+		if len(s.edges) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d):\n", s.title, len(s.edges))
+		for _, e := range s.edges {
+			fmt.Fprintf(w, "  %s -> %s\n", e.From, e.To)
+		}
+	}
+	for _, s := range []struct {
+		title string
+		paths []string
+	}{
+		{"Hubs only in " + a, d.HubsOnlyA}, {"Hubs only in " + b, d.HubsOnlyB},
+		{"Files only in " + a, d.FilesOnlyA}, {"Files only in " + b, d.FilesOnlyB},
+	} {
+		if len(s.paths) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d):\n", s.title, len(s.paths))
+		for _, p := range s.paths {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ type hubInfo struct {
 	Hubs      []string
 	Importers map[string][]string
 	Imports   map[string][]string
+	graph     *scanner.FileGraph // the graph the above came from; nil uses Importers as is
 }
 
 // getHubInfo returns hub info from daemon state (fast) or fresh scan (slow)
@@ -31,11 +33,12 @@ func getHubInfo(root string) *hubInfo {
 			Hubs:      state.Hubs,
 			Importers: state.Importers,
 			Imports:   state.Imports,
+			graph:     state.Graph(root),
 		}
 	}
 
-	// Fall back to fresh scan (slower)
-	fg, err := scanner.BuildFileGraph(root)
+	// Fall back to a scan (slower), reused while the project is unchanged
+	fg, err := scanner.BuildFileGraphCached(root)
 	if err != nil {
 		return nil
 	}
@@ -44,31 +47,75 @@ func getHubInfo(root string) *hubInfo {
 		Hubs:      fg.HubFiles(),
 		Importers: fg.Importers,
 		Imports:   fg.Imports,
+		graph:     fg,
 	}
 }
 
+// HookOptions tweaks hook output
+type HookOptions struct {
+	ByImpact bool // session-stop: order edits by impact instead of chronologically
+}
+
+// hookLimits caps how many entries hook output lists
+type hookLimits struct {
+	Importers int // dependents listed for an edited hub (pre-edit, post-edit)
+	Hubs      int // hubs listed at session start
+}
+
+// defaultHookLimits applies when neither config nor environment sets a cap
+var defaultHookLimits = hookLimits{Importers: 5, Hubs: 10}
+
+// loadHookLimits reads hook_max_importers and hook_max_hubs from root's
+// .codemap/config.toml, overridden by CODEMAP_HOOK_MAX_IMPORTERS and
+// CODEMAP_HOOK_MAX_HUBS. Unset or invalid values keep the defaults, so a
+// bad setting never breaks a hook.
+func loadHookLimits(root string) hookLimits {
+	limits := defaultHookLimits
+	cfg, _ := scanner.LoadConfig(root)
+	for _, l := range []struct {
+		env   string
+		cfg   int
+		limit *int
+	}{
+		{"CODEMAP_HOOK_MAX_IMPORTERS", cfg.HookMaxImporters, &limits.Importers},
+		{"CODEMAP_HOOK_MAX_HUBS", cfg.HookMaxHubs, &limits.Hubs},
+	} {
+		if n, err := strconv.Atoi(os.Getenv(l.env)); err == nil && n > 0 {
+			*l.limit = n
+		} else if l.cfg > 0 {
+			*l.limit = l.cfg
+		}
+	}
+	return limits
+}
+
 // RunHook executes the named hook with the given project root
 func RunHook(hookName, root string) error {
+	return RunHookWithOptions(hookName, root, HookOptions{})
+}
+
+// RunHookWithOptions executes the named hook with the given project root and options
+func RunHookWithOptions(hookName, root string, opts HookOptions) error {
 	switch hookName {
 	case "session-start":
-		return hookSessionStart(root)
+		return hookSessionStart(root, loadHookLimits(root))
 	case "pre-edit":
-		return hookPreEdit(root)
+		return hookPreEdit(root, loadHookLimits(root))
 	case "post-edit":
-		return hookPostEdit(root)
+		return hookPostEdit(root, loadHookLimits(root))
 	case "prompt-submit":
 		return hookPromptSubmit(root)
 	case "pre-compact":
 		return hookPreCompact(root)
 	case "session-stop":
-		return hookSessionStop(root)
+		return hookSessionStop(root, opts.ByImpact)
 	default:
 		return fmt.Errorf("unknown hook: %s\nAvailable: session-start, pre-edit, post-edit, prompt-submit, pre-compact, session-stop", hookName)
 	}
 }
 
 // hookSessionStart shows project structure, starts daemon, and shows hub warnings
-func hookSessionStart(root string) error {
+func hookSessionStart(root string, limits hookLimits) error {
 	// Guard: require git repo
 	gitDir := filepath.Join(root, ".git")
 	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
@@ -86,6 +133,12 @@ func hookSessionStart(root string) error {
 	// Check for previous session context before starting new daemon
 	lastSessionEvents := getLastSessionEvents(root)
 
+	// The state a stopped daemon left behind, before a new one overwrites it
+	var lastState *watch.State
+	if !watch.IsRunning(root) {
+		lastState = watch.ReadLastState(root)
+	}
+
 	// Start the watch daemon in background (if not already running)
 	if !watch.IsRunning(root) {
 		startDaemon(root)
@@ -109,11 +162,11 @@ func hookSessionStart(root string) error {
 	if info != nil && len(info.Hubs) > 0 {
 		fmt.Println("⚠️  High-impact files (hubs):")
 		for i, hub := range info.Hubs {
-			if i >= 10 {
-				fmt.Printf("   ... and %d more\n", len(info.Hubs)-10)
+			if i >= limits.Hubs {
+				fmt.Printf("   ... and %d more\n", len(info.Hubs)-limits.Hubs)
 				break
 			}
-			importers := len(info.Importers[hub])
+			importers := len(info.importersOf(hub))
 			fmt.Printf("   ⚠️  HUB FILE: %s (imported by %d files)\n", hub, importers)
 		}
 	}
@@ -126,9 +179,61 @@ func hookSessionStart(root string) error {
 		showLastSessionContext(root, lastSessionEvents)
 	}
 
+	// Show what changed on disk in between (a pull, a branch switch)
+	if diff := sessionChanges(root, lastState); diff != nil {
+		tf, _ := watch.LoadTimeFormat(root)
+		showSessionChanges(*diff, lastState.UpdatedAt, tf)
+	}
+
 	return nil
 }
 
+// sessionChanges compares the files on disk with the sizes the last daemon
+// recorded, returning nil when nothing was recorded or nothing changed
+func sessionChanges(root string, last *watch.State) *watch.SnapshotDiff {
+	if last == nil || len(last.Sizes) == 0 {
+		return nil
+	}
+	if fp, err := scanner.Fingerprint(root); err == nil && fp.Hash == scanner.SizesFingerprint(last.Sizes) {
+		return nil
+	}
+	files, err := scanner.ScanFiles(root, scanner.NewGitIgnoreCache(root), nil, nil)
+	if err != nil {
+		return nil
+	}
+	diff := watch.DiffSizes(last.Sizes, files)
+	if diff.Empty() {
+		return nil
+	}
+	return &diff
+}
+
+// showSessionChanges lists files added, removed, and resized since the last
+// session's state was written (at most 10)
+func showSessionChanges(diff watch.SnapshotDiff, since time.Time, tf watch.TimeFormat) {
+	fmt.Println()
+	fmt.Printf("🔀 Changed on disk since last session (%s): %d added, %d removed, %d resized\n",
+		tf.FormatLog(since), len(diff.Added), len(diff.Removed), len(diff.Resized))
+
+	var lines []string
+	for _, path := range diff.Added {
+		lines = append(lines, "+ "+path)
+	}
+	for _, path := range diff.Removed {
+		lines = append(lines, "- "+path)
+	}
+	for _, r := range diff.Resized {
+		lines = append(lines, fmt.Sprintf("~ %s (%+d bytes)", r.Path, r.NewSize-r.OldSize))
+	}
+	for i, line := range lines {
+		if i >= 10 {
+			fmt.Printf("   ... and %d more\n", len(lines)-10)
+			break
+		}
+		fmt.Printf("   %s\n", line)
+	}
+}
+
 // showDiffVsMain shows files changed on this branch vs main
 func showDiffVsMain(root string) {
 	// Check if we're on a branch other than main
@@ -238,23 +343,23 @@ func startDaemon(root string) {
 }
 
 // hookPreEdit warns before editing hub files (reads JSON from stdin)
-func hookPreEdit(root string) error {
+func hookPreEdit(root string, limits hookLimits) error {
 	filePath, err := extractFilePathFromStdin()
 	if err != nil || filePath == "" {
 		return nil // silently skip if no file path
 	}
 
-	return checkFileImporters(root, filePath)
+	return checkFileImporters(root, filePath, limits)
 }
 
 // hookPostEdit shows impact after editing (reads JSON from stdin)
-func hookPostEdit(root string) error {
+func hookPostEdit(root string, limits hookLimits) error {
 	filePath, err := extractFilePathFromStdin()
 	if err != nil || filePath == "" {
 		return nil
 	}
 
-	return checkFileImporters(root, filePath)
+	return checkFileImporters(root, filePath, limits)
 }
 
 // hookPromptSubmit detects file mentions in user prompt and shows session context
@@ -292,8 +397,8 @@ func hookPromptSubmit(root string) error {
 	var output []string
 	if info != nil {
 		for _, file := range filesMentioned {
-			if importers := info.Importers[file]; len(importers) > 0 {
-				if len(importers) >= 3 {
+			if importers := info.importersOf(file); len(importers) > 0 {
+				if info.isHub(file) {
 					output = append(output, fmt.Sprintf("   ⚠️  %s is a HUB (imported by %d files)", file, len(importers)))
 				} else {
 					output = append(output, fmt.Sprintf("   📍 %s (imported by %d files)", file, len(importers)))
@@ -378,10 +483,35 @@ func hookPreCompact(root string) error {
 	return nil
 }
 
-// hookSessionStop summarizes what changed in the session and stops the daemon
-func hookSessionStop(root string) error {
+// printSessionEvent prints one event line of the session-stop summary
+func printSessionEvent(e watch.Event, tf watch.TimeFormat) {
+	deltaStr := ""
+	if e.Delta > 0 {
+		deltaStr = fmt.Sprintf(" +%d", e.Delta)
+	} else if e.Delta < 0 {
+		deltaStr = fmt.Sprintf(" %d", e.Delta)
+	}
+
+	hubStr := ""
+	if e.IsHub {
+		hubStr = " ⚠️HUB"
+	}
+
+	fmt.Printf("  %s %-6s %s%s%s\n",
+		tf.FormatClock(e.Time),
+		e.Op,
+		e.Path,
+		deltaStr,
+		hubStr,
+	)
+}
+
+// hookSessionStop summarizes what changed in the session and stops the daemon.
+// With byImpact, edits are listed hub-first then by line delta instead of by time.
+func hookSessionStop(root string, byImpact bool) error {
 	// Read state BEFORE stopping daemon (includes timeline)
 	state := watch.ReadState(root)
+	tf, _ := watch.LoadTimeFormat(root) // a bad setting falls back to defaults
 
 	// Stop the watch daemon
 	stopDaemon(root)
@@ -392,9 +522,6 @@ func hookSessionStop(root string) error {
 
 	// Show timeline from daemon events (if available)
 	if state != nil && len(state.RecentEvents) > 0 {
-		fmt.Println()
-		fmt.Println("Edit Timeline:")
-
 		// Calculate stats
 		totalDelta := 0
 		fileEdits := make(map[string]int) // file -> edit count
@@ -408,34 +535,39 @@ func hookSessionStop(root string) error {
 			}
 		}
 
-		// Show last 10 events
-		events := state.RecentEvents
-		start := 0
-		if len(events) > 10 {
-			start = len(events) - 10
-			fmt.Printf("  ... %d earlier events\n", start)
-		}
-
-		for _, e := range events[start:] {
-			deltaStr := ""
-			if e.Delta > 0 {
-				deltaStr = fmt.Sprintf(" +%d", e.Delta)
-			} else if e.Delta < 0 {
-				deltaStr = fmt.Sprintf(" %d", e.Delta)
+		fmt.Println()
+		if byImpact {
+			fmt.Println("Edits by Impact:")
+			events := watch.SortByImpact(state.RecentEvents)
+			if len(events) > 10 {
+				fmt.Printf("  ... %d lower-impact events not shown\n", len(events)-10)
+				events = events[:10]
 			}
-
-			hubStr := ""
-			if e.IsHub {
-				hubStr = " ⚠️HUB"
+			for _, e := range events {
+				printSessionEvent(e, tf)
+			}
+		} else {
+			fmt.Println("Edit Timeline:")
+
+			// Show last 10 events
+			events := state.RecentEvents
+			start := 0
+			if len(events) > 10 {
+				start = len(events) - 10
+				fmt.Printf("  ... %d earlier events\n", start)
+			}
+			for _, e := range events[start:] {
+				printSessionEvent(e, tf)
 			}
 
-			fmt.Printf("  %s %-6s %s%s%s\n",
-				e.Time.Format("15:04:05"),
-				e.Op,
-				e.Path,
-				deltaStr,
-				hubStr,
-			)
+			// Surface hub edits even when they scrolled out of the timeline
+			if hubEdits > 0 {
+				fmt.Println()
+				fmt.Println("Highest Impact:")
+				for _, e := range watch.HighestImpact(state.RecentEvents, 3) {
+					printSessionEvent(e, tf)
+				}
+			}
 		}
 
 		// Show stats
@@ -477,7 +609,7 @@ func hookSessionStop(root string) error {
 			}
 
 			if info != nil && info.isHub(file) {
-				importers := len(info.Importers[file])
+				importers := len(info.importersOf(file))
 				fmt.Printf("  ⚠️  %s (HUB - imported by %d files)\n", file, importers)
 			} else {
 				fmt.Printf("  • %s\n", file)
@@ -529,7 +661,7 @@ func extractFilePathFromStdin() (string, error) {
 }
 
 // checkFileImporters checks if a file is a hub and shows its importers
-func checkFileImporters(root, filePath string) error {
+func checkFileImporters(root, filePath string, limits hookLimits) error {
 	info := getHubInfo(root)
 	if info == nil {
 		return nil // silently skip if deps unavailable
@@ -542,26 +674,33 @@ func checkFileImporters(root, filePath string) error {
 		}
 	}
 
-	importers := info.Importers[filePath]
-	if len(importers) >= 3 {
-		fmt.Println()
-		fmt.Printf("⚠️  HUB FILE: %s\n", filePath)
-		fmt.Printf("   Imported by %d files - changes have wide impact!\n", len(importers))
-		fmt.Println()
-		fmt.Println("   Dependents:")
+	writeFileImporters(os.Stdout, info, filePath, limits.Importers)
+	return nil
+}
+
+// writeFileImporters prints filePath's importers, listing at most
+// maxImporters dependents of a hub, and any hubs it imports
+func writeFileImporters(w io.Writer, info *hubInfo, filePath string, maxImporters int) {
+	importers := info.importersOf(filePath)
+	if info.isHub(filePath) {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "⚠️  HUB FILE: %s\n", filePath)
+		fmt.Fprintf(w, "   Imported by %d files - changes have wide impact!\n", len(importers))
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "   Dependents:")
 		for i, imp := range importers {
-			if i >= 5 {
-				fmt.Printf("   ... and %d more\n", len(importers)-5)
+			if i >= maxImporters {
+				fmt.Fprintf(w, "   ... and %d more\n", len(importers)-maxImporters)
 				break
 			}
-			fmt.Printf("   • %s\n", imp)
+			fmt.Fprintf(w, "   • %s\n", imp)
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	} else if len(importers) > 0 {
-		fmt.Println()
-		fmt.Printf("📍 File: %s\n", filePath)
-		fmt.Printf("   Imported by %d file(s): %s\n", len(importers), strings.Join(importers, ", "))
-		fmt.Println()
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "📍 File: %s\n", filePath)
+		fmt.Fprintf(w, "   Imported by %d file(s): %s\n", len(importers), strings.Join(importers, ", "))
+		fmt.Fprintln(w)
 	}
 
 	// Also check if this file imports any hubs
@@ -573,16 +712,27 @@ func checkFileImporters(root, filePath string) error {
 		}
 	}
 	if len(hubImports) > 0 {
-		fmt.Printf("   Imports %d hub(s): %s\n", len(hubImports), strings.Join(hubImports, ", "))
-		fmt.Println()
+		fmt.Fprintf(w, "   Imports %d hub(s): %s\n", len(hubImports), strings.Join(hubImports, ", "))
+		fmt.Fprintln(w)
 	}
+}
 
-	return nil
+// importersOf returns the files importing path, see scanner.FileGraph.ImportersOf
+func (h *hubInfo) importersOf(path string) []string {
+	if h.graph != nil {
+		return h.graph.ImportersOf(path)
+	}
+	return h.Importers[path]
 }
 
-// isHub checks if a file is a hub (has 3+ importers)
+// isHub checks if a file is a hub by the graph's rules (hub_threshold,
+// ignored importers, minified files); without a graph, DefaultHubThreshold
+// importers make a hub
 func (h *hubInfo) isHub(path string) bool {
-	return len(h.Importers[path]) >= 3
+	if h.graph != nil {
+		return h.graph.IsHub(path)
+	}
+	return len(h.importersOf(path)) >= scanner.DefaultHubThreshold
 }
 
 // findChildRepos returns subdirectories that are git repositories
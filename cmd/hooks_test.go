@@ -3,12 +3,16 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
+
+	"codemap/scanner"
+	"codemap/watch"
 )
 
 // TestHubInfoIsHub tests the hub detection threshold (3+ importers)
@@ -80,6 +84,30 @@ func TestHubInfoIsHub(t *testing.T) {
 	}
 }
 
+// TestHubInfoGraphRules tests that hub checks from daemon state follow the
+// project's hub_threshold and skip minified files
+func TestHubInfoGraphRules(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, ".codemap"), 0755)
+	if err := os.WriteFile(filepath.Join(root, scanner.ConfigFile), []byte("hub_threshold = 4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	state := &watch.State{
+		Importers: map[string][]string{
+			"three.go":   {"a.go", "b.go", "c.go"},
+			"four.go":    {"a.go", "b.go", "c.go", "d.go"},
+			"app.min.js": {"a.js", "b.js", "c.js", "d.js"},
+		},
+		Minified: []string{"app.min.js"},
+	}
+	info := &hubInfo{Importers: state.Importers, graph: state.Graph(root)}
+	for file, want := range map[string]bool{"three.go": false, "four.go": true, "app.min.js": false} {
+		if got := info.isHub(file); got != want {
+			t.Errorf("isHub(%s) = %v, want %v", file, got, want)
+		}
+	}
+}
+
 // TestRunHookRouting tests that RunHook routes to correct handlers
 func TestRunHookRouting(t *testing.T) {
 	// Test unknown hook returns error
@@ -285,46 +313,54 @@ func TestCheckFileImportersOutput(t *testing.T) {
 	}
 }
 
-// formatFileImportersOutput is the core output logic extracted for testing
-// This mirrors checkFileImporters but takes hubInfo directly instead of calling getHubInfo
+// formatFileImportersOutput prints info's view of filePath with the default caps
 func formatFileImportersOutput(info *hubInfo, filePath string) {
 	if info == nil {
 		return
 	}
+	writeFileImporters(os.Stdout, info, filePath, defaultHookLimits.Importers)
+}
 
-	importers := info.Importers[filePath]
-	if len(importers) >= 3 {
-		fmt.Println()
-		fmt.Printf("⚠️  HUB FILE: %s\n", filePath)
-		fmt.Printf("   Imported by %d files - changes have wide impact!\n", len(importers))
-		fmt.Println()
-		fmt.Println("   Dependents:")
-		for i, imp := range importers {
-			if i >= 5 {
-				fmt.Printf("   ... and %d more\n", len(importers)-5)
-				break
-			}
-			fmt.Printf("   • %s\n", imp)
-		}
-		fmt.Println()
-	} else if len(importers) > 0 {
-		fmt.Println()
-		fmt.Printf("📍 File: %s\n", filePath)
-		fmt.Printf("   Imported by %d file(s): %s\n", len(importers), strings.Join(importers, ", "))
-		fmt.Println()
+func TestHookImporterLimit(t *testing.T) {
+	info := &hubInfo{Importers: map[string][]string{
+		"types.go": {"a.go", "b.go", "c.go", "d.go", "e.go", "f.go", "g.go"},
+	}}
+	dependents := func(max int) int {
+		var buf bytes.Buffer
+		writeFileImporters(&buf, info, "types.go", max)
+		return strings.Count(buf.String(), "   • ")
+	}
+	if got := dependents(defaultHookLimits.Importers); got != 5 {
+		t.Errorf("Expected 5 dependents by default, got %d", got)
+	}
+	if got := dependents(2); got != 2 {
+		t.Errorf("Expected 2 dependents with a cap of 2, got %d", got)
+	}
+	if got := dependents(20); got != 7 {
+		t.Errorf("Expected all 7 dependents with a cap of 20, got %d", got)
 	}
+}
 
-	// Also check if this file imports any hubs
-	imports := info.Imports[filePath]
-	var hubImports []string
-	for _, imp := range imports {
-		if info.isHub(imp) {
-			hubImports = append(hubImports, imp)
-		}
+func TestLoadHookLimits(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".codemap"), 0755); err != nil {
+		t.Fatal(err)
 	}
-	if len(hubImports) > 0 {
-		fmt.Printf("   Imports %d hub(s): %s\n", len(hubImports), strings.Join(hubImports, ", "))
-		fmt.Println()
+	if err := os.WriteFile(filepath.Join(root, scanner.ConfigFile), []byte("hook_max_importers = 8\nhook_max_hubs = 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CODEMAP_HOOK_MAX_IMPORTERS", "")
+	t.Setenv("CODEMAP_HOOK_MAX_HUBS", "")
+
+	if got := loadHookLimits(t.TempDir()); got != defaultHookLimits {
+		t.Errorf("Expected defaults without config, got %+v", got)
+	}
+	if got, want := loadHookLimits(root), (hookLimits{Importers: 8, Hubs: 3}); got != want {
+		t.Errorf("loadHookLimits = %+v, want %+v", got, want)
+	}
+	t.Setenv("CODEMAP_HOOK_MAX_IMPORTERS", "12")
+	if got := loadHookLimits(root); got.Importers != 12 || got.Hubs != 3 {
+		t.Errorf("Expected the environment to override importers only, got %+v", got)
 	}
 }
 
@@ -460,3 +496,57 @@ func captureOutput(f func()) string {
 	io.Copy(&buf, r)
 	return buf.String()
 }
+
+// TestSessionChanges tests that files changed on disk after the last
+// daemon's state was written are reported at the next session start
+func TestSessionChanges(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n")
+	write("util.go", "package main\n")
+	write("old.go", "package main\n")
+
+	// Record the state a daemon would have left behind
+	files, err := scanner.ScanFiles(root, scanner.NewGitIgnoreCache(root), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := watch.State{UpdatedAt: time.Now().Add(-2 * time.Hour), Sizes: make(map[string]int64)}
+	for _, f := range files {
+		state.Sizes[f.Path] = f.Size
+	}
+	data, _ := json.Marshal(state)
+	os.MkdirAll(filepath.Join(root, ".codemap"), 0755)
+	os.WriteFile(filepath.Join(root, ".codemap", "state.json"), data, 0644)
+
+	last := watch.ReadLastState(root)
+	if last == nil {
+		t.Fatal("Expected ReadLastState to load a stale state")
+	}
+	if diff := sessionChanges(root, last); diff != nil {
+		t.Errorf("Expected no changes for an untouched tree, got %+v", diff)
+	}
+
+	// Between sessions: a pull adds, removes, and edits files
+	write("new.go", "package main\n")
+	write("util.go", "package main\n\nfunc helper() {}\n")
+	os.Remove(filepath.Join(root, "old.go"))
+
+	diff := sessionChanges(root, last)
+	if diff == nil {
+		t.Fatal("Expected changes since the last session")
+	}
+	out := captureOutput(func() { showSessionChanges(*diff, last.UpdatedAt, watch.TimeFormat{}) })
+	for _, want := range []string{"1 added, 1 removed, 1 resized", "+ new.go", "- old.go", "~ util.go (+18 bytes)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "main.go") {
+		t.Errorf("Unchanged main.go should not be listed:\n%s", out)
+	}
+}
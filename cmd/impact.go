@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codemap/scanner"
+)
+
+// RunImpact implements "codemap impact": a CI gate that fails when a changed
+// file is imported by more than --max-importers files. With --range A..B it
+// reports on a historical commit range, using the graph as of B.
+func RunImpact(args []string) error {
+	fs := flag.NewFlagSet("impact", flag.ContinueOnError)
+	ref := fs.String("ref", "main", "Branch/ref to compare against")
+	maxImporters := fs.Int("max-importers", 20, "Fail if a changed file has more importers than this")
+	commitRange := fs.String("range", "", "Check a commit range A..B instead of the working tree vs --ref")
+	overrideFile := fs.String("override-file", ".codemap/impact-override", "If this file exists (relative to root), report but don't fail")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	var diffInfo *scanner.DiffInfo
+	var fg *scanner.FileGraph
+	if *commitRange != "" {
+		from, to, ok := strings.Cut(*commitRange, "..")
+		if !ok || from == "" || to == "" {
+			return fmt.Errorf("--range must look like A..B, got %q", *commitRange)
+		}
+		diffInfo, err = scanner.ChangedFilesBetween(absRoot, from, to)
+		if err != nil {
+			return err
+		}
+		if len(diffInfo.Changed) == 0 {
+			fmt.Printf("No files changed in %s\n", *commitRange)
+			return nil
+		}
+		fg, err = scanner.BuildFileGraphAt(absRoot, to)
+		if err != nil {
+			return fmt.Errorf("building file graph at %s: %w", to, err)
+		}
+	} else {
+		diffInfo, err = scanner.GitDiffInfo(absRoot, *ref)
+		if err != nil {
+			return fmt.Errorf("git diff against %s failed: %w", *ref, err)
+		}
+		if len(diffInfo.Changed) == 0 {
+			fmt.Printf("No files changed vs %s\n", *ref)
+			return nil
+		}
+		fg, err = scanner.BuildFileGraph(absRoot)
+		if err != nil {
+			return fmt.Errorf("building file graph: %w", err)
+		}
+	}
+
+	violations := scanner.CheckImpactBudget(fg, diffInfo.Changed, *maxImporters)
+	if len(violations) == 0 {
+		fmt.Printf("✅ Impact budget OK: %d changed files, none with more than %d importers\n", len(diffInfo.Changed), *maxImporters)
+		return nil
+	}
+
+	fmt.Printf("⚠️  Impact budget exceeded (max %d importers):\n", *maxImporters)
+	for _, v := range violations {
+		fmt.Printf("   • %s (imported by %d files)\n", v.File, len(v.Importers))
+	}
+
+	overridePath := *overrideFile
+	if overridePath != "" && !filepath.IsAbs(overridePath) {
+		overridePath = filepath.Join(absRoot, overridePath)
+	}
+	if overridePath != "" {
+		if _, err := os.Stat(overridePath); err == nil {
+			fmt.Printf("   Override present (%s) - not failing\n", *overrideFile)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%d changed file(s) exceed the impact budget", len(violations))
+}
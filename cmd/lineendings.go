@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"codemap/scanner"
+)
+
+// RunLineEndings implements "codemap line-endings": flags source files with
+// mixed \r\n/\n endings or endings that disagree with the rest of the repo.
+func RunLineEndings(args []string) error {
+	fs := flag.NewFlagSet("line-endings", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	focus, err := scanner.ConfigFocus(absRoot)
+	if err != nil {
+		return err
+	}
+
+	files, err := scanner.ScanFiles(absRoot, scanner.NewGitIgnoreCache(absRoot), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// The majority is the whole repo's; only files under the focus are listed
+	issues, majority := scanner.FindLineEndingIssues(absRoot, files)
+	issues = slices.DeleteFunc(issues, func(i scanner.LineEndingIssue) bool { return !scanner.InFocus(i.Path, focus) })
+	printBinaryNote(absRoot, scanner.FilterToFocus(files, focus))
+	if len(issues) == 0 {
+		fmt.Printf("✅ Line endings are consistent (%s)\n", majority)
+		return nil
+	}
+
+	fmt.Printf("↵ %d file(s) with inconsistent line endings (repo majority: %s):\n", len(issues), majority)
+	for _, i := range issues {
+		if i.Style == scanner.EndingMixed {
+			fmt.Printf("   • %s (mixed: %d crlf, %d lf)\n", i.Path, i.CRLF, i.LF)
+		} else {
+			fmt.Printf("   • %s (%s)\n", i.Path, i.Style)
+		}
+	}
+	return fmt.Errorf("%d file(s) with inconsistent line endings", len(issues))
+}
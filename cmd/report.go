@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"codemap/render"
+	"codemap/scanner"
+)
+
+// RunReport implements "codemap report": writes an architecture overview
+// (stats, languages, hubs, cycles, external packages, a dependency diagram)
+// as a Markdown document for docs and PRs.
+func RunReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	markdown := fs.Bool("markdown", false, "Write the report as Markdown (currently the only format)")
+	out := fs.String("o", "", "Write to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*markdown {
+		return fmt.Errorf("report needs an output format: --markdown")
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	gitCache := scanner.NewGitIgnoreCache(absRoot)
+	files, err := scanner.ScanFiles(absRoot, gitCache, nil, nil)
+	if err != nil {
+		return err
+	}
+	report := render.ArchReport{Root: absRoot, Files: files}
+
+	// Dependency sections need ast-grep; without it the report still has
+	// the overview and language breakdown
+	if fg, analyses, err := scanner.BuildFileGraphWithAnalyses(absRoot); err == nil {
+		report.Graph = fg
+		report.External = scanner.FindExternalHubs(fg, analyses)
+	} else {
+		fmt.Fprintf(os.Stderr, "Dependency analysis unavailable: %v\n", err)
+	}
+
+	var buf bytes.Buffer
+	render.MarkdownReport(&buf, report)
+	if *out == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", *out)
+	return nil
+}
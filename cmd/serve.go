@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"codemap/scanner"
+)
+
+// ServeRequest is one line of input to "codemap serve --stdio-json".
+//
+//	{"id": 1, "cmd": "structure", "path": "."}
+//	{"id": 2, "cmd": "importers", "path": ".", "file": "scanner/types.go"}
+//	{"id": 3, "cmd": "find", "path": ".", "pattern": "usrctrl", "fuzzy": true}
+type ServeRequest struct {
+	ID      any    `json:"id,omitempty"`      // echoed back on the response
+	Cmd     string `json:"cmd"`               // one of ServeCommands
+	Path    string `json:"path,omitempty"`    // project root (default ".")
+	File    string `json:"file,omitempty"`    // importers: file relative to path
+	Pattern string `json:"pattern,omitempty"` // find: name pattern
+	Fuzzy   bool   `json:"fuzzy,omitempty"`   // find: subsequence matching
+}
+
+// ServeResponse is written as one line per request. Exactly one of Result
+// and Error is set.
+type ServeResponse struct {
+	ID     any    `json:"id,omitempty"`
+	OK     bool   `json:"ok"`
+	Result any    `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ServeCommands lists the commands accepted in ServeRequest.Cmd
+var ServeCommands = []string{"structure", "hubs", "importers", "deps", "find", "fingerprint"}
+
+// serveStructure is the result of the "structure" command
+type serveStructure struct {
+	Root  string               `json:"root"`
+	Files []scanner.FileInfo   `json:"files"`
+	Roles map[scanner.Role]int `json:"roles"`
+}
+
+// serveHub is one entry of the "hubs" result
+type serveHub struct {
+	File      string `json:"file"`
+	Importers int    `json:"importers"`
+}
+
+// serveImporters is the result of the "importers" command
+type serveImporters struct {
+	File      string   `json:"file"`
+	Importers []string `json:"importers"`
+	Hub       bool     `json:"hub"`
+}
+
+// RunServe implements "codemap serve --stdio-json": a line-based JSON protocol
+// on stdin/stdout for integrators that don't speak MCP.
+func RunServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	stdioJSON := fs.Bool("stdio-json", false, "Read one JSON request per line on stdin, write one JSON response per line")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*stdioJSON {
+		return errors.New("serve needs a transport: codemap serve --stdio-json")
+	}
+	return ServeJSON(os.Stdin, os.Stdout)
+}
+
+// ServeJSON answers newline-delimited ServeRequests from in until EOF.
+// Malformed lines and failed commands produce an error response; the loop
+// only stops on read/write failure.
+func ServeJSON(in io.Reader, out io.Writer) error {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	enc := json.NewEncoder(out)
+
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+
+		var req ServeRequest
+		var resp ServeResponse
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			resp = ServeResponse{Error: "invalid request: " + err.Error()}
+		} else {
+			result, err := dispatchServe(req)
+			resp = ServeResponse{ID: req.ID, OK: err == nil, Result: result}
+			if err != nil {
+				resp.Result = nil
+				resp.Error = err.Error()
+			}
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// dispatchServe runs a single request
+func dispatchServe(req ServeRequest) (any, error) {
+	root := req.Path
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(absRoot); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("path %q is not a directory", root)
+	}
+
+	switch req.Cmd {
+	case "structure":
+		files, err := scanner.ScanFiles(absRoot, scanner.NewGitIgnoreCache(absRoot), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if files == nil {
+			files = []scanner.FileInfo{}
+		}
+		return serveStructure{Root: absRoot, Files: files, Roles: scanner.CountRoles(files)}, nil
+
+	case "hubs":
+		fg, err := scanner.BuildFileGraphCached(absRoot)
+		if err != nil {
+			return nil, err
+		}
+		hubs := []serveHub{}
+		for _, h := range fg.HubFiles() {
+			hubs = append(hubs, serveHub{File: h, Importers: len(fg.ImportersOf(h))})
+		}
+		sort.Slice(hubs, func(i, j int) bool {
+			if hubs[i].Importers != hubs[j].Importers {
+				return hubs[i].Importers > hubs[j].Importers
+			}
+			return hubs[i].File < hubs[j].File
+		})
+		return hubs, nil
+
+	case "importers":
+		if req.File == "" {
+			return nil, errors.New("importers needs a file")
+		}
+		fg, err := scanner.BuildFileGraphCached(absRoot)
+		if err != nil {
+			return nil, err
+		}
+		importers := fg.ImportersOf(req.File)
+		if importers == nil {
+			importers = []string{}
+		}
+		return serveImporters{File: req.File, Importers: importers, Hub: fg.IsHub(req.File)}, nil
+
+	case "deps":
+		fg, err := scanner.BuildFileGraphCached(absRoot)
+		if err != nil {
+			return nil, err
+		}
+		return fg.Imports, nil
+
+	case "find":
+		if req.Pattern == "" {
+			return nil, errors.New("find needs a pattern")
+		}
+		files, err := scanner.ScanFiles(absRoot, scanner.NewGitIgnoreCache(absRoot), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		matches := []string{}
+		if req.Fuzzy {
+			paths := make([]string, len(files))
+			for i, f := range files {
+				paths[i] = f.Path
+			}
+			matches = append(matches, scanner.FuzzyMatch(req.Pattern, paths)...)
+		} else {
+			pattern := strings.ToLower(req.Pattern)
+			for _, f := range files {
+				if strings.Contains(strings.ToLower(f.Path), pattern) {
+					matches = append(matches, f.Path)
+				}
+			}
+		}
+		return matches, nil
+
+	case "fingerprint":
+		return scanner.Fingerprint(absRoot)
+
+	case "":
+		return nil, fmt.Errorf("missing cmd (one of: %s)", strings.Join(ServeCommands, ", "))
+	default:
+		return nil, fmt.Errorf("unknown cmd %q (one of: %s)", req.Cmd, strings.Join(ServeCommands, ", "))
+	}
+}
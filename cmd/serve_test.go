@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServeJSONStructure(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"main.go", "api/handler.go", "api/handler_test.go"} {
+		path := filepath.Join(root, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req, _ := json.Marshal(ServeRequest{ID: 7, Cmd: "structure", Path: root})
+	in := strings.NewReader(string(req) + "\n" + `{"id":8,"cmd":"explode"}` + "\n" + "not json\n")
+	var out bytes.Buffer
+	if err := ServeJSON(in, &out); err != nil {
+		t.Fatalf("ServeJSON failed: %v", err)
+	}
+
+	var lines []string
+	sc := bufio.NewScanner(&out)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Expected one response per request, got %d:\n%s", len(lines), out.String())
+	}
+
+	var resp struct {
+		ID     int  `json:"id"`
+		OK     bool `json:"ok"`
+		Result struct {
+			Root  string `json:"root"`
+			Files []struct {
+				Path string `json:"path"`
+			} `json:"files"`
+			Roles map[string]int `json:"roles"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &resp); err != nil {
+		t.Fatalf("Bad structure response %q: %v", lines[0], err)
+	}
+	if !resp.OK || resp.ID != 7 {
+		t.Errorf("Expected ok response with id 7, got %s", lines[0])
+	}
+	if len(resp.Result.Files) != 3 {
+		t.Errorf("Expected 3 files, got %+v", resp.Result.Files)
+	}
+	if resp.Result.Roles["test"] != 1 || resp.Result.Roles["source"] != 2 {
+		t.Errorf("Unexpected roles: %v", resp.Result.Roles)
+	}
+
+	var bad ServeResponse
+	json.Unmarshal([]byte(lines[1]), &bad)
+	if bad.OK || !strings.Contains(bad.Error, "unknown cmd") || bad.ID != float64(8) {
+		t.Errorf("Expected unknown-command error echoing id 8, got %s", lines[1])
+	}
+	json.Unmarshal([]byte(lines[2]), &bad)
+	if bad.OK || !strings.Contains(bad.Error, "invalid request") {
+		t.Errorf("Expected invalid-request error, got %s", lines[2])
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"codemap/scanner"
+)
+
+// RunTodos implements "codemap todos": counts TODO/FIXME/HACK/XXX markers
+// per source file as a quick tech-debt signal.
+func RunTodos(args []string) error {
+	fs := flag.NewFlagSet("todos", flag.ContinueOnError)
+	showText := fs.Bool("text", false, "Also list each marker with its line and comment text")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	focus, err := scanner.ConfigFocus(absRoot)
+	if err != nil {
+		return err
+	}
+
+	files, err := scanner.ScanFiles(absRoot, scanner.NewGitIgnoreCache(absRoot), nil, nil)
+	if err != nil {
+		return err
+	}
+	files = scanner.FilterToFocus(files, focus)
+
+	todos := scanner.FindTodos(absRoot, files)
+	printBinaryNote(absRoot, files)
+	if len(todos) == 0 {
+		fmt.Println("✅ No TODO/FIXME/HACK/XXX markers")
+		return nil
+	}
+
+	fmt.Printf("📝 %s\n", todoSummary(todos))
+	for _, ft := range todos {
+		fmt.Printf("   %4d  %s\n", len(ft.Items), ft.Path)
+		if *showText {
+			for _, item := range ft.Items {
+				fmt.Printf("         L%d %s %s\n", item.Line, item.Marker, item.Text)
+			}
+		}
+	}
+	return nil
+}
+
+// todoSummary is the one-line total, e.g. "5 markers in 2 files (TODO 3, FIXME 2)"
+func todoSummary(todos []scanner.FileTodos) string {
+	counts := scanner.TodoCounts(todos)
+	total := 0
+	var parts []string
+	for _, marker := range scanner.TodoMarkers {
+		if counts[marker] > 0 {
+			total += counts[marker]
+			parts = append(parts, fmt.Sprintf("%s %d", marker, counts[marker]))
+		}
+	}
+	return fmt.Sprintf("%d marker(s) in %d file(s) (%s)", total, len(todos), strings.Join(parts, ", "))
+}
+
+// printBinaryNote says which source files a content scan skipped because
+// they're binary or not UTF-8 (listing up to 5), or nothing if none were
+func printBinaryNote(root string, files []scanner.FileInfo) {
+	skipped := scanner.BinarySources(root, files)
+	if len(skipped) == 0 {
+		return
+	}
+	list := strings.Join(skipped[:min(len(skipped), 5)], ", ")
+	if len(skipped) > 5 {
+		list += fmt.Sprintf(", +%d more", len(skipped)-5)
+	}
+	fmt.Printf("ℹ Skipped %d binary or non-UTF-8 file(s): %s\n", len(skipped), list)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"codemap/scanner"
+)
+
+// RunUntested implements "codemap untested": lists source files that have no
+// corresponding test file by naming convention or import.
+func RunUntested(args []string) error {
+	fs := flag.NewFlagSet("untested", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	focus, err := scanner.ConfigFocus(absRoot)
+	if err != nil {
+		return err
+	}
+
+	fg, err := scanner.BuildFileGraph(absRoot)
+	if err != nil {
+		return err
+	}
+
+	tc := scanner.TestMapping(fg)
+	tc.Untested = slices.DeleteFunc(tc.Untested, func(f string) bool { return !scanner.InFocus(f, focus) })
+	if len(tc.Untested) == 0 {
+		fmt.Println("✅ Every source file has a matching test")
+		return nil
+	}
+
+	fmt.Printf("🧪 %d source file(s) without a matching test (%d covered):\n", len(tc.Untested), len(tc.Tests))
+	for _, f := range tc.Untested {
+		marker := ""
+		if fg.IsHub(f) {
+			marker = " ⚠️ HUB"
+		}
+		fmt.Printf("   • %s%s\n", f, marker)
+	}
+	return nil
+}
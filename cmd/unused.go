@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"codemap/scanner"
+)
+
+// RunUnusedDeps implements "codemap unused-deps": lists dependencies
+// declared in manifests that no source file imports. Only confident findings
+// fail the command; low-confidence ones (tooling, indirect) are listed apart.
+func RunUnusedDeps(args []string) error {
+	fs := flag.NewFlagSet("unused-deps", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := fs.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	analyses, err := scanner.ScanForDeps(absRoot)
+	if err != nil {
+		return err
+	}
+
+	unused := scanner.FindUnusedDeps(absRoot, analyses)
+	var sure, maybe []scanner.UnusedDep
+	for _, u := range unused {
+		if u.LowConfidence {
+			maybe = append(maybe, u)
+		} else {
+			sure = append(sure, u)
+		}
+	}
+	if len(sure) == 0 && len(maybe) == 0 {
+		fmt.Println("✅ Every declared dependency is imported somewhere")
+		return nil
+	}
+
+	if len(sure) > 0 {
+		fmt.Printf("🧹 %s declared but never imported:\n", plural(len(sure), "dependency package"))
+		for _, u := range sure {
+			fmt.Printf("   • %s (%s, %s)\n", u.Name, u.Language, strings.Join(u.Sources, ", "))
+		}
+	}
+	if len(maybe) > 0 {
+		if len(sure) > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("❔ %s not imported, but possibly used another way:\n", plural(len(maybe), "more package"))
+		for _, u := range maybe {
+			fmt.Printf("   • %s (%s, %s): %s\n", u.Name, u.Language, strings.Join(u.Sources, ", "), u.Reason)
+		}
+	}
+	if len(sure) > 0 {
+		return fmt.Errorf("%s unused", plural(len(sure), "dependency package"))
+	}
+	return nil
+}
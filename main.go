@@ -2,33 +2,67 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"codemap/cmd"
 	"codemap/render"
 	"codemap/scanner"
 	"codemap/watch"
+
+	"golang.org/x/term"
 )
 
+// subcommands are dispatched before flag parsing and receive the remaining args
+var subcommands = map[string]func(args []string) error{
+	"impact":         cmd.RunImpact,
+	"go-internal":    cmd.RunGoInternal,
+	"conventions":    cmd.RunConventions,
+	"untested":       cmd.RunUntested,
+	"line-endings":   cmd.RunLineEndings,
+	"todos":          cmd.RunTodos,
+	"ext-hubs":       cmd.RunExtHubs,
+	"dsm":            cmd.RunDSM,
+	"unused-deps":    cmd.RunUnusedDeps,
+	"broken-imports": cmd.RunBrokenImports,
+	"age":            cmd.RunAge,
+	"fingerprint":    cmd.RunFingerprint,
+	"serve":          cmd.RunServe,
+	"report":         cmd.RunReport,
+	"file":           cmd.RunFile,
+	"graph-diff":     cmd.RunGraphDiff,
+}
+
 func main() {
+	// Handle registered subcommands before flag parsing
+	if len(os.Args) >= 2 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// Handle "watch" subcommand before flag parsing
 	if len(os.Args) >= 2 && os.Args[1] == "watch" {
 		subCmd := "status"
 		if len(os.Args) >= 3 {
 			subCmd = os.Args[2]
 		}
-		root, _ := os.Getwd()
-		if len(os.Args) >= 4 {
-			root = os.Args[3]
-		}
-		runWatchSubcommand(subCmd, root)
+		runWatchSubcommand(subCmd, os.Args[3:])
 		return
 	}
 
@@ -40,11 +74,14 @@ func main() {
 			os.Exit(1)
 		}
 		hookName := os.Args[2]
+		fs := flag.NewFlagSet("hook "+hookName, flag.ExitOnError)
+		byImpact := fs.Bool("by-impact", false, "Order session-stop edits by impact (hubs first, then line delta)")
+		fs.Parse(os.Args[3:])
 		root, _ := os.Getwd()
-		if len(os.Args) >= 4 {
-			root = os.Args[3]
+		if fs.Arg(0) != "" {
+			root = fs.Arg(0)
 		}
-		if err := cmd.RunHook(hookName, root); err != nil {
+		if err := cmd.RunHookWithOptions(hookName, root, cmd.HookOptions{ByImpact: *byImpact}); err != nil {
 			fmt.Fprintf(os.Stderr, "Hook error: %v\n", err)
 			os.Exit(1)
 		}
@@ -53,16 +90,39 @@ func main() {
 
 	skylineMode := flag.Bool("skyline", false, "Enable skyline visualization mode")
 	animateMode := flag.Bool("animate", false, "Enable animation (use with --skyline)")
+	topLangs := flag.Int("top-langs", 0, "Skyline: show the N largest languages and roll the rest into 'other' (0 = all)")
+	detailedMode := flag.Bool("detailed", false, "Skyline: show each building's file count (terminals 80+ columns wide)")
+	svgMode := flag.Bool("svg", false, "Render the skyline as an SVG image (use with --skyline)")
+	outputFile := flag.String("o", "", "Write output to a file instead of stdout (use with --svg)")
 	depsMode := flag.Bool("deps", false, "Enable dependency graph mode (function/import analysis)")
+	chainDepth := flag.Int("chain-depth", 0, "Deps: expand internal dependency chains up to N hops (0 = default view)")
 	diffMode := flag.Bool("diff", false, "Only show files changed vs main (or use --ref to specify branch)")
 	diffRef := flag.String("ref", "main", "Branch/ref to compare against (use with --diff)")
+	outputWidth := flag.Int("width", 0, "Render at a fixed width instead of detecting the terminal (0 = detect, respects COLUMNS)")
 	depthLimit := flag.Int("depth", 0, "Limit tree depth (0 = unlimited)")
 	onlyExts := flag.String("only", "", "Only show files with these extensions (comma-separated, e.g., 'swift,go')")
+	barsMode := flag.Bool("bars", false, "Tree: list files one per line with a size bar scaled to the largest file")
+	barsAll := flag.Bool("bars-all", false, "With --bars: give assets (images, fonts, media) bars and count them in the scale")
+	collapseDirs := flag.String("collapse", "", "Show these directories as one summary line (comma-separated names, e.g., 'vendor,node_modules')")
+	focusDir := flag.String("focus", "", "Scope output to this subdirectory; imports still resolve against the whole project (default: focus in .codemap/config.toml)")
 	excludePatterns := flag.String("exclude", "", "Exclude files matching patterns (comma-separated, e.g., '.xcassets,Fonts')")
 	jsonMode := flag.Bool("json", false, "Output JSON (for Python renderer compatibility)")
+	compactJSON := flag.Bool("compact-json", false, "Output JSON without empty fields (\"\", 0, false, null, [], {}); implies --json")
 	debugMode := flag.Bool("debug", false, "Show debug info (gitignore loading, paths, etc.)")
 	watchMode := flag.Bool("watch", false, "Live file watcher daemon (experimental)")
 	importersMode := flag.String("importers", "", "Check file impact: who imports it, is it a hub?")
+	importPaths := flag.Bool("import-paths", false, "With --importers: show Go package and Python module paths instead of file paths")
+	dedupeHubs := flag.Bool("dedupe-hubs", false, "With --deps: count a JS/TS barrel and the module it re-exports as one hub")
+	hubIgnoreFlag := flag.String("hub-ignore-importers", "", "Importers that don't count toward hub status (comma-separated globs, e.g. 'examples/**,docs/**')")
+	graphJSON := flag.Bool("graph-json", false, "Output the file graph (imports, importers, packages, hubs) as sorted JSON")
+	formatMode := flag.String("format", "", "Export the dependency graph in another format (jgf, dot, mermaid)")
+	minSize := flag.Int64("min-size", 0, "Hide files smaller than N bytes (0 = no minimum)")
+	maxSize := flag.Int64("max-size", 0, "Hide files larger than N bytes (0 = no maximum)")
+	trackedMode := flag.Bool("tracked", false, "Scan only the files git tracks (git ls-files) instead of walking the directory")
+	streamMode := flag.Bool("stream", false, "Print the tree incrementally while scanning (for huge repos)")
+	anonymize := flag.Bool("anonymize", false, "Rename paths, functions, and internal imports to stable hashes for sharing (mapping saved to .codemap/anonymize.json)")
+	quietMode := flag.Bool("quiet", false, "Don't show scan progress on stderr")
+	autoRoot := flag.Bool("auto-root", false, "Walk up to the nearest .git/go.mod/package.json and use it as the project root")
 	helpMode := flag.Bool("help", false, "Show help")
 	// Short flag aliases
 	flag.IntVar(depthLimit, "d", 0, "Limit tree depth (shorthand)")
@@ -77,26 +137,82 @@ func main() {
 		fmt.Println("  --help              Show this help message")
 		fmt.Println("  --skyline           City skyline visualization")
 		fmt.Println("  --animate           Animated skyline (use with --skyline)")
+		fmt.Println("  --svg, -o <file>    Export skyline as SVG (use with --skyline)")
+		fmt.Println("  --top-langs <n>     Skyline: top N languages, the rest as 'other'")
+		fmt.Println("  --detailed          Skyline: file count on each building (80+ columns)")
 		fmt.Println("  --deps              Dependency flow map (functions & imports)")
+		fmt.Println("  --chain-depth <n>   Deps: expand dependency chains up to N hops")
 		fmt.Println("  --diff              Only show files changed vs main")
 		fmt.Println("  --ref <branch>      Branch to compare against (default: main)")
 		fmt.Println("  --depth, -d <n>     Limit tree depth (0 = unlimited)")
+		fmt.Println("  --width <n>         Fixed output width (default: terminal or $COLUMNS)")
 		fmt.Println("  --only <exts>       Only show files with these extensions (e.g., 'swift,go')")
 		fmt.Println("  --exclude <patterns> Exclude paths matching patterns (e.g., '.xcassets,Fonts')")
+		fmt.Println("  --collapse <dirs>   Show directories as one summary line (e.g., 'vendor,third_party')")
+		fmt.Println("  --bars              Tree: one file per line with a size bar (████▌)")
+		fmt.Println("  --bars-all          With --bars: include assets in the bars and their scale")
+		fmt.Println("  --focus <dir>       Scope tree, deps, and diff output to one subdirectory")
 		fmt.Println("  --importers <file>  Check file impact (who imports it, hub status)")
+		fmt.Println("  --import-paths      With --importers: show import paths (codemap/scanner) not file paths")
+		fmt.Println("  --dedupe-hubs       With --deps: merge a barrel (index.ts) and its re-export target into one hub")
+		fmt.Println("  --hub-ignore-importers <globs>  Importers that don't make a file a hub (e.g. 'examples/**')")
+		fmt.Println("  --format jgf        Export the dependency graph as JSON Graph Format")
+		fmt.Println("  --format dot        Export the dependency graph as a Graphviz digraph")
+		fmt.Println("  --format mermaid    Export the dependency graph as a Mermaid flowchart (for Markdown)")
+		fmt.Println("  --compact-json      JSON output without empty fields (smaller for big repos)")
+		fmt.Println("  --graph-json        Output the file graph as JSON (imports, importers, hubs, packages)")
+		fmt.Println("  --min-size <bytes>  Hide files smaller than N bytes")
+		fmt.Println("  --max-size <bytes>  Hide files larger than N bytes")
+		fmt.Println("  --tracked           Only files git tracks (git ls-files); walks as usual outside git")
+		fmt.Println("  --stream            Print tree incrementally while scanning (huge repos)")
+		fmt.Println("  --quiet             Don't show scan progress on stderr")
+		fmt.Println("  --auto-root         Use the nearest ancestor with .git/go.mod/package.json as root")
+		fmt.Println("  --anonymize         Hash names in tree/deps/graph output; mapping kept in .codemap/")
 		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  codemap .                       # Basic tree view")
 		fmt.Println("  codemap --skyline .             # Skyline visualization")
 		fmt.Println("  codemap --skyline --animate     # Animated skyline")
+		fmt.Println("  codemap --skyline --svg -o skyline.svg .  # Skyline image for a README")
 		fmt.Println("  codemap --deps /path/to/proj    # Dependency flow map")
+		fmt.Println("  codemap --deps web api          # One map over several roots, imports resolved across them")
 		fmt.Println("  codemap --diff                  # Files changed vs main")
 		fmt.Println("  codemap --diff --ref develop    # Files changed vs develop")
+		fmt.Println("  codemap --deps --diff .         # Dependency context of a changeset")
 		fmt.Println("  codemap --depth 3 .             # Show only 3 levels deep")
 		fmt.Println("  codemap --only swift .          # Just Swift files")
 		fmt.Println("  codemap --exclude .xcassets,Fonts,.png  # Hide assets")
 		fmt.Println("  codemap --importers scanner/types.go  # Check file impact")
 		fmt.Println()
+		fmt.Println("Commands:")
+		fmt.Println("  codemap impact --ref main --max-importers 20 .  # CI gate on hub changes")
+		fmt.Println("  codemap go-internal .           # Go internal/ package visibility check")
+		fmt.Println("  codemap conventions .           # Flag files breaking naming conventions")
+		fmt.Println("  codemap untested .              # Source files with no matching test")
+		fmt.Println("  codemap line-endings .          # Files with mixed or off-majority CRLF/LF endings")
+		fmt.Println("  codemap todos --text .          # TODO/FIXME/HACK/XXX counts per file")
+		fmt.Println("  codemap ext-hubs .              # Third-party packages by number of importing files")
+		fmt.Println("  codemap dsm --dsm-level dir .   # Dependency structure matrix, cycles clustered")
+		fmt.Println("  codemap unused-deps .           # Declared dependencies no file imports")
+		fmt.Println("  codemap broken-imports .        # Imports of files that no longer exist")
+		fmt.Println("  codemap age .                   # Commit dates, authors, stalest/freshest files")
+		fmt.Println("  codemap fingerprint .           # Stable hash of the file set (--content, --json)")
+		fmt.Println("  codemap serve --stdio-json      # Line-based JSON requests on stdin (non-MCP clients)")
+		fmt.Println("  codemap file api/server.go      # One file: functions, imports, importers, hub status")
+		fmt.Println("  codemap graph-diff ./fork ./upstream  # Edges, hubs, and files in one graph but not the other")
+		fmt.Println("  codemap report --markdown -o ARCHITECTURE.md .  # Architecture doc with a Mermaid diagram")
+		fmt.Println("  codemap watch report --markdown # Standup report from watch activity")
+		fmt.Println("  codemap watch status .          # One-line daemon snapshot (uptime, last edit, net lines)")
+		fmt.Println("  codemap watch metrics .         # Daemon counters in Prometheus text format")
+		fmt.Println("  codemap watch export-session -o session.json .  # Archive the session as JSON")
+		fmt.Println("  codemap watch export-traces --otlp http://localhost:4318 .  # Edit bursts as OTel traces")
+		fmt.Println("  codemap watch start --watch-ignore '.cache' .  # Keep paths out of live activity")
+		fmt.Println("  codemap watch start --related-window 15m .     # Wider co-edit window")
+		fmt.Println("  codemap watch start --snapshots 50 .           # Snapshot structure on each commit")
+		fmt.Println("  codemap watch start --only a.go,b.go .         # Only report edits to these files")
+		fmt.Println("  codemap watch start --no-poll .                # Don't poll dirs past the OS watch limit")
+		fmt.Println("  codemap watch start --content-hash .           # Ignore saves that change nothing")
+		fmt.Println()
 		fmt.Println("Hooks (for Claude Code integration):")
 		fmt.Println("  codemap hook session-start      # Show project context")
 		fmt.Println("  codemap hook pre-edit           # Check before editing (stdin)")
@@ -104,25 +220,51 @@ func main() {
 		fmt.Println("  codemap hook prompt-submit      # Parse user prompt (stdin)")
 		fmt.Println("  codemap hook pre-compact        # Save state before compact")
 		fmt.Println("  codemap hook session-stop       # Session summary")
+		fmt.Println("  codemap hook session-stop --by-impact  # Summary with hub edits first")
 		os.Exit(0)
 	}
+	if *compactJSON {
+		*jsonMode = true
+	}
+
+	// --deps over several roots maps them as one project
+	if *depsMode && flag.NArg() > 1 {
+		if *diffMode || *focusDir != "" || *anonymize || *formatMode != "" || *onlyExts != "" || *excludePatterns != "" || *hubIgnoreFlag != "" {
+			fmt.Fprintln(os.Stderr, "Error: --diff, --focus, --anonymize, --format, --only, --exclude, and --hub-ignore-importers take a single root")
+			os.Exit(1)
+		}
+		runMultiRootDepsMode(flag.Args(), *jsonMode, *compactJSON, *jsonMode || *quietMode, *outputWidth, *chainDepth, *dedupeHubs)
+		return
+	}
 
 	root := flag.Arg(0)
 	if root == "" {
 		root = "."
 	}
+	if *autoRoot {
+		if found, ok := scanner.FindProjectRoot(root, scanner.RootMarkers); ok {
+			root = found
+		}
+	}
 
 	absRoot, err := filepath.Abs(root)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting absolute path: %v\n", err)
 		os.Exit(1)
 	}
+	if info, err := os.Stat(absRoot); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	} else if !info.IsDir() {
+		fmt.Fprintf(os.Stderr, "Error: %s is a file, expected a directory; for a single file use `codemap file %s`\n", root, root)
+		os.Exit(1)
+	}
 
 	// Initialize gitignore cache (supports nested .gitignore files)
 	gitCache := scanner.NewGitIgnoreCache(root)
 
-	// Parse --only and --exclude flags
-	var only, exclude []string
+	// Parse --only, --exclude, --collapse and --hub-ignore-importers flags
+	var only, exclude, collapse, hubIgnore []string
 	if *onlyExts != "" {
 		for _, ext := range strings.Split(*onlyExts, ",") {
 			if trimmed := strings.TrimSpace(ext); trimmed != "" {
@@ -137,6 +279,46 @@ func main() {
 			}
 		}
 	}
+	if *collapseDirs != "" {
+		for _, name := range strings.Split(*collapseDirs, ",") {
+			if trimmed := strings.Trim(strings.TrimSpace(name), "/"); trimmed != "" {
+				collapse = append(collapse, trimmed)
+			}
+		}
+	}
+	if *hubIgnoreFlag != "" {
+		for _, pattern := range strings.Split(*hubIgnoreFlag, ",") {
+			if trimmed := strings.TrimSpace(pattern); trimmed != "" {
+				hubIgnore = append(hubIgnore, trimmed)
+			}
+		}
+	}
+
+	// --focus overrides the persistent focus from .codemap/config.toml
+	cfg, err := scanner.LoadConfig(absRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		os.Exit(1)
+	}
+	focus := cfg.Focus
+	if *focusDir != "" {
+		focus = *focusDir
+	}
+	focus, err = scanner.ResolveFocus(absRoot, focus)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// --anonymize: stable aliases across runs, from the project's own mapping
+	var anon *scanner.Anonymizer
+	if *anonymize {
+		if anon, err = scanner.LoadAnonymizer(absRoot); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", scanner.AnonymizeMapFile, err)
+			os.Exit(1)
+		}
+		defer saveAnonymizer(anon, absRoot)
+	}
 
 	if *debugMode {
 		fmt.Fprintf(os.Stderr, "[debug] Root path: %s\n", root)
@@ -152,7 +334,17 @@ func main() {
 
 	// Importers mode - check file impact
 	if *importersMode != "" {
-		runImportersMode(absRoot, *importersMode)
+		runImportersMode(absRoot, *importersMode, hubIgnore, *importPaths)
+		return
+	}
+
+	// Graph export - machine-readable dependency graph
+	if *graphJSON {
+		runGraphJSONMode(absRoot, focus, hubIgnore, anon)
+		return
+	}
+	if *formatMode != "" {
+		runFormatMode(absRoot, *formatMode, focus, hubIgnore, anon)
 		return
 	}
 
@@ -172,13 +364,20 @@ func main() {
 		}
 	}
 
+	// Long scans report progress on a terminal's stderr (not while
+	// streaming, which shows the files themselves)
+	if !*streamMode {
+		startProgress(*jsonMode || *quietMode)
+		defer stopProgress()
+	}
+
 	// Handle --deps mode separately
 	if *depsMode {
 		var changedFiles map[string]bool
 		if diffInfo != nil {
 			changedFiles = diffInfo.Changed
 		}
-		runDepsMode(absRoot, root, *jsonMode, *diffRef, changedFiles)
+		runDepsMode(absRoot, root, *jsonMode, *compactJSON, *diffRef, changedFiles, *outputWidth, *chainDepth, focus, hubIgnore, *dedupeHubs, anon)
 		return
 	}
 
@@ -187,12 +386,35 @@ func main() {
 		mode = "skyline"
 	}
 
+	// Streaming tree: render directories as the walk discovers them
+	if *streamMode && mode == "tree" && !*jsonMode && diffInfo == nil && anon == nil && !*trackedMode {
+		stream, errc := scanner.ScanFilesStream(root, gitCache, only, exclude)
+		if *minSize > 0 || *maxSize > 0 || focus != "" {
+			stream = filterStream(stream, func(f scanner.FileInfo) bool {
+				return scanner.InFocus(f.Path, focus) && len(scanner.FilterBySize([]scanner.FileInfo{f}, *minSize, *maxSize)) == 1
+			})
+		}
+		render.TreeStream(absRoot, stream)
+		if err := <-errc; err != nil {
+			fmt.Fprintf(os.Stderr, "Error walking tree: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Scan files
-	files, err := scanner.ScanFiles(root, gitCache, only, exclude)
+	scanFiles := scanner.ScanFiles
+	if *trackedMode {
+		scanFiles = scanner.ScanTrackedFiles
+	}
+	files, err := scanFiles(root, gitCache, only, exclude)
 	if err != nil {
+		stopProgress()
 		fmt.Fprintf(os.Stderr, "Error walking tree: %v\n", err)
 		os.Exit(1)
 	}
+	files = scanner.FilterBySize(files, *minSize, *maxSize)
+	files = scanner.FilterToFocus(files, focus)
 
 	// Filter to changed files if --diff specified (with diff info annotations)
 	var impact []scanner.ImpactInfo
@@ -204,20 +426,43 @@ func main() {
 	}
 
 	project := scanner.Project{
-		Root:    absRoot,
-		Mode:    mode,
-		Animate: *animateMode,
-		Files:   files,
-		DiffRef: activeDiffRef,
-		Impact:  impact,
-		Depth:   *depthLimit,
-		Only:    only,
-		Exclude: exclude,
+		Root:     absRoot,
+		Mode:     mode,
+		Animate:  *animateMode,
+		Files:    files,
+		DiffRef:  activeDiffRef,
+		Impact:   impact,
+		Depth:    *depthLimit,
+		Only:     only,
+		Exclude:  exclude,
+		MinSize:  *minSize,
+		MaxSize:  *maxSize,
+		TopLangs: *topLangs,
+		Width:    *outputWidth,
+		Collapse: collapse,
+		Focus:    focus,
+		Detailed: *detailedMode,
+		Bars:     *barsMode,
+		BarsAll:  *barsAll,
 	}
+	if anon != nil {
+		project = anon.Project(project)
+	}
+	stopProgress()
 
 	// Render or output JSON
-	if *jsonMode {
+	if *compactJSON {
+		render.WriteCompactJSON(os.Stdout, project)
+	} else if *jsonMode {
 		json.NewEncoder(os.Stdout).Encode(project)
+	} else if *skylineMode && *svgMode {
+		svg := render.SkylineSVG(project)
+		if *outputFile == "" {
+			fmt.Print(svg)
+		} else if err := os.WriteFile(*outputFile, []byte(svg), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *outputFile, err)
+			os.Exit(1)
+		}
 	} else if *skylineMode {
 		render.Skyline(project, *animateMode)
 	} else {
@@ -225,9 +470,24 @@ func main() {
 	}
 }
 
-func runDepsMode(absRoot, root string, jsonMode bool, diffRef string, changedFiles map[string]bool) {
+// filterStream applies --min-size/--max-size and --focus to a streaming scan
+func filterStream(in <-chan scanner.FileInfo, keep func(scanner.FileInfo) bool) <-chan scanner.FileInfo {
+	out := make(chan scanner.FileInfo, cap(in))
+	go func() {
+		defer close(out)
+		for f := range in {
+			if keep(f) {
+				out <- f
+			}
+		}
+	}()
+	return out
+}
+
+func runDepsMode(absRoot, root string, jsonMode, compactJSON bool, diffRef string, changedFiles map[string]bool, width, chainDepth int, focus string, hubIgnore []string, dedupeHubs bool, anon *scanner.Anonymizer) {
 	analyses, err := scanner.ScanForDeps(root)
 	if err != nil {
+		stopProgress()
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		fmt.Fprintln(os.Stderr, "")
 		fmt.Fprintln(os.Stderr, "The --deps feature requires ast-grep. Install it with:")
@@ -238,10 +498,26 @@ func runDepsMode(absRoot, root string, jsonMode bool, diffRef string, changedFil
 		os.Exit(1)
 	}
 
-	// Filter to changed files if --diff specified
+	// The renderer resolves edges from the file graph; build it once here
+	// (while progress is still showing) unless JSON output without --diff,
+	// --anonymize, or --hub-ignore-importers has no use for it
+	var fg *scanner.FileGraph
+	if !jsonMode || changedFiles != nil || anon != nil || len(hubIgnore) > 0 {
+		if fg, _ = scanner.BuildFileGraph(absRoot); fg != nil && hubIgnore != nil {
+			fg.HubIgnoreImporters = hubIgnore
+		}
+	}
+
+	// With --diff, show changed files and their direct neighbors
+	var changed map[string]bool
 	if changedFiles != nil {
-		analyses = scanner.FilterAnalysisToChanged(analyses, changedFiles)
+		analyses = scanner.FilterAnalysisToChangedNeighbors(analyses, changedFiles, fg)
+		changed = make(map[string]bool, len(changedFiles))
+		for path := range changedFiles {
+			changed[filepath.ToSlash(path)] = true
+		}
 	}
+	analyses = scanner.FilterAnalysisToFocus(analyses, focus)
 
 	depsProject := scanner.DepsProject{
 		Root:         absRoot,
@@ -249,16 +525,118 @@ func runDepsMode(absRoot, root string, jsonMode bool, diffRef string, changedFil
 		Files:        analyses,
 		ExternalDeps: scanner.ReadExternalDeps(absRoot),
 		DiffRef:      diffRef,
+		Width:        width,
+		ChainDepth:   chainDepth,
+		Focus:        focus,
+		Graph:        fg,
+		Changed:      changed,
+		DedupeHubs:   dedupeHubs,
+	}
+	if anon != nil {
+		depsProject = anon.DepsProject(depsProject, fg)
 	}
+	stopProgress()
 
 	// Render or output JSON
-	if jsonMode {
+	if compactJSON {
+		render.WriteCompactJSON(os.Stdout, depsProject)
+	} else if jsonMode {
 		json.NewEncoder(os.Stdout).Encode(depsProject)
 	} else {
 		render.Depgraph(depsProject)
 	}
 }
 
+// runMultiRootDepsMode is --deps over several roots that form one system
+// (see scanner.BuildMultiRootGraph): paths are relative to the roots'
+// common parent and imports resolve across roots
+func runMultiRootDepsMode(roots []string, jsonMode, compactJSON, quiet bool, width, chainDepth int, dedupeHubs bool) {
+	abs, _, err := scanner.RootPrefixes(roots)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	startProgress(quiet)
+	fg, analyses, err := scanner.BuildMultiRootGraph(abs)
+	if err != nil {
+		stopProgress()
+		fmt.Fprintf(os.Stderr, "Error building file graph: %v\n", err)
+		os.Exit(1)
+	}
+
+	external := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, root := range abs {
+		for lang, deps := range scanner.ReadExternalDeps(root) {
+			for _, dep := range deps {
+				if !seen[lang+"\x00"+dep] {
+					seen[lang+"\x00"+dep] = true
+					external[lang] = append(external[lang], dep)
+				}
+			}
+		}
+	}
+
+	depsProject := scanner.DepsProject{
+		Root:         fg.Root,
+		Mode:         "deps",
+		Files:        analyses,
+		ExternalDeps: external,
+		Width:        width,
+		ChainDepth:   chainDepth,
+		Graph:        fg,
+		DedupeHubs:   dedupeHubs,
+	}
+	stopProgress()
+
+	if compactJSON {
+		render.WriteCompactJSON(os.Stdout, depsProject)
+	} else if jsonMode {
+		json.NewEncoder(os.Stdout).Encode(depsProject)
+	} else {
+		render.Depgraph(depsProject)
+	}
+}
+
+// progressDelay is how long a scan runs before progress shows up
+const progressDelay = 500 * time.Millisecond
+
+// progressOut and stderrIsTerminal are swapped in tests
+var (
+	progressOut      io.Writer = os.Stderr
+	stderrIsTerminal           = func() bool { return term.IsTerminal(int(os.Stderr.Fd())) }
+)
+
+// progressTicks, when set (by tests), paces progress redraws in place of a
+// real ticker
+var progressTicks <-chan time.Time
+
+// stopProgress clears the progress line; a no-op until startProgress runs
+var stopProgress = func() {}
+
+// startProgress shows scan progress on stderr when it's a terminal, unless
+// suppressed (--json, --quiet). Call stopProgress before printing results.
+func startProgress(suppress bool) {
+	if suppress || !stderrIsTerminal() {
+		return
+	}
+	p := &scanner.Progress{}
+	scanner.TrackProgress(p)
+	var stop func()
+	if progressTicks != nil {
+		stop = render.StartProgressTicks(progressOut, p, progressDelay, progressTicks)
+	} else {
+		stop = render.StartProgress(progressOut, p, progressDelay)
+	}
+	var once sync.Once
+	stopProgress = func() {
+		once.Do(func() {
+			stop()
+			scanner.TrackProgress(nil)
+		})
+	}
+}
+
 func runWatchMode(root string, verbose bool) {
 	fmt.Println("codemap watch - Live code graph daemon")
 	fmt.Println()
@@ -276,6 +654,9 @@ func runWatchMode(root string, verbose bool) {
 
 	fmt.Printf("Watching: %s\n", root)
 	fmt.Printf("Files tracked: %d\n", daemon.FileCount())
+	if stats := daemon.WatchStats(); stats.Skipped > 0 {
+		fmt.Printf("Directories: %d watched, %d skipped\n", stats.Watched, stats.Skipped)
+	}
 	fmt.Println("Event log: .codemap/events.log")
 	fmt.Println()
 	fmt.Println("Press Ctrl+C to stop")
@@ -298,12 +679,72 @@ func runWatchMode(root string, verbose bool) {
 	fmt.Printf("  Events logged: %d\n", len(events))
 }
 
-func runImportersMode(root, file string) {
+func runFormatMode(root, format, focus string, hubIgnore []string, anon *scanner.Anonymizer) {
+	if format != "jgf" && format != "dot" && format != "mermaid" {
+		fmt.Fprintf(os.Stderr, "Unknown --format %q (supported: jgf, dot, mermaid)\n", format)
+		os.Exit(1)
+	}
+
+	fg := exportGraph(root, focus, hubIgnore, anon)
+	switch format {
+	case "dot":
+		fmt.Print(render.DepgraphDOT(scanner.DepsProject{Root: fg.Root, Graph: fg}))
+		return
+	case "mermaid":
+		fmt.Print(render.DepgraphMermaid(scanner.DepsProject{Root: fg.Root, Graph: fg}))
+		return
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(render.JGF(fg))
+}
+
+// runGraphJSONMode prints the file graph as scanner.GraphExport JSON
+func runGraphJSONMode(root, focus string, hubIgnore []string, anon *scanner.Anonymizer) {
+	fg := exportGraph(root, focus, hubIgnore, anon)
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(fg.Export())
+}
+
+// exportGraph builds the file graph that --format and --graph-json write
+// out, scoped to focus and anonymized when asked; it exits on failure
+func exportGraph(root, focus string, hubIgnore []string, anon *scanner.Anonymizer) *scanner.FileGraph {
 	fg, err := scanner.BuildFileGraph(root)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error building file graph: %v\n", err)
 		os.Exit(1)
 	}
+	if hubIgnore != nil {
+		fg.HubIgnoreImporters = hubIgnore
+	}
+
+	fg = fg.FocusGraph(focus)
+	if anon != nil {
+		fg = anon.FileGraph(fg)
+	}
+	return fg
+}
+
+// saveAnonymizer writes the --anonymize mapping so the owner can translate
+// shared output back
+func saveAnonymizer(anon *scanner.Anonymizer, root string) {
+	if err := anon.Save(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save %s: %v\n", scanner.AnonymizeMapFile, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Anonymized names; mapping in %s (keep it private)\n", scanner.AnonymizeMapFile)
+}
+
+func runImportersMode(root, file string, hubIgnore []string, importPaths bool) {
+	fg, err := scanner.BuildFileGraph(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error building file graph: %v\n", err)
+		os.Exit(1)
+	}
+	if hubIgnore != nil {
+		fg.HubIgnoreImporters = hubIgnore
+	}
 
 	// Handle absolute paths - convert to relative
 	if filepath.IsAbs(file) {
@@ -312,15 +753,24 @@ func runImportersMode(root, file string) {
 		}
 	}
 
-	importers := fg.Importers[file]
-	if len(importers) >= 3 {
+	// With --import-paths, files in one Go package collapse to one line
+	display := func(files []string) []string {
+		if importPaths {
+			return fg.ImportPaths(files)
+		}
+		return files
+	}
+
+	importers := fg.ImportersOf(file)
+	shown := display(importers)
+	if fg.IsHub(file) {
 		fmt.Printf("⚠️  HUB FILE: %s\n", file)
 		fmt.Printf("   Imported by %d files - changes have wide impact!\n", len(importers))
 		fmt.Println()
 		fmt.Println("   Dependents:")
-		for i, imp := range importers {
+		for i, imp := range shown {
 			if i >= 5 {
-				fmt.Printf("   ... and %d more\n", len(importers)-5)
+				fmt.Printf("   ... and %d more\n", len(shown)-5)
 				break
 			}
 			fmt.Printf("   • %s\n", imp)
@@ -328,7 +778,7 @@ func runImportersMode(root, file string) {
 	} else if len(importers) > 0 {
 		fmt.Printf("📍 File: %s\n", file)
 		fmt.Printf("   Imported by %d file(s)\n", len(importers))
-		for _, imp := range importers {
+		for _, imp := range shown {
 			fmt.Printf("   • %s\n", imp)
 		}
 	}
@@ -345,11 +795,30 @@ func runImportersMode(root, file string) {
 		if len(importers) == 0 {
 			fmt.Printf("📍 File: %s\n", file)
 		}
-		fmt.Printf("   Imports %d hub(s): %s\n", len(hubImports), strings.Join(hubImports, ", "))
+		fmt.Printf("   Imports %d hub(s): %s\n", len(hubImports), strings.Join(display(hubImports), ", "))
 	}
 }
 
-func runWatchSubcommand(subCmd, root string) {
+func runWatchSubcommand(subCmd string, args []string) {
+	fs := flag.NewFlagSet("watch "+subCmd, flag.ExitOnError)
+	markdown := fs.Bool("markdown", false, "Format the report as Markdown (report)")
+	since := fs.Duration("since", 0, "Report window, e.g. 8h (report; default: since midnight)")
+	out := fs.String("o", "", "Write to this file instead of stdout (export-session, export-traces)")
+	otlp := fs.String("otlp", "", "Send spans to this OTLP/HTTP collector, e.g. http://localhost:4318 (export-traces)")
+	burstGap := fs.Duration("burst-gap", watch.DefaultBurstGap, "Idle time that ends an edit burst, i.e. a trace (export-traces)")
+	var opts daemonOptions
+	fs.Var(&opts.ignore, "watch-ignore", "Glob of paths to leave out of the activity stream (start; repeatable)")
+	fs.DurationVar(&opts.relatedWindow, "related-window", watch.DefaultRelatedWindow, "How recently a connected file must be edited to count as related (start)")
+	fs.IntVar(&opts.snapshots, "snapshots", 0, "Save a structure snapshot on each commit, keeping the last N (start; 0 = off)")
+	fs.StringVar(&opts.only, "only", "", "Only report events for these files (start; comma-separated, e.g. a.go,b.go)")
+	fs.BoolVar(&opts.noPoll, "no-poll", false, "Don't poll directories past the OS watch limit (start)")
+	fs.BoolVar(&opts.contentHash, "content-hash", false, "Drop saves that leave a file's content unchanged (start)")
+	fs.Parse(args)
+
+	root, _ := os.Getwd()
+	if fs.Arg(0) != "" {
+		root = fs.Arg(0)
+	}
 	absRoot, err := filepath.Abs(root)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -362,13 +831,18 @@ func runWatchSubcommand(subCmd, root string) {
 			fmt.Println("Watch daemon already running")
 			return
 		}
+		// Validate --only before forking so typos fail loudly
+		if _, err := watch.ResolveOnlyFiles(absRoot, opts.onlyFiles()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 		// Fork a background daemon
 		exe, err := os.Executable()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		cmd := exec.Command(exe, "watch", "daemon", absRoot)
+		cmd := exec.Command(exe, append(append([]string{"watch", "daemon"}, opts.args()...), absRoot)...)
 		cmd.Stdout = nil
 		cmd.Stderr = nil
 		cmd.Stdin = nil
@@ -382,7 +856,7 @@ func runWatchSubcommand(subCmd, root string) {
 
 	case "daemon":
 		// Internal: run as the actual daemon process
-		runDaemon(absRoot)
+		runDaemon(absRoot, opts)
 
 	case "stop":
 		if !watch.IsRunning(absRoot) {
@@ -396,33 +870,198 @@ func runWatchSubcommand(subCmd, root string) {
 		fmt.Println("Watch daemon stopped")
 
 	case "status":
-		if watch.IsRunning(absRoot) {
-			state := watch.ReadState(absRoot)
-			if state != nil {
-				fmt.Printf("Watch daemon running\n")
-				fmt.Printf("  Files: %d\n", state.FileCount)
-				fmt.Printf("  Hubs: %d\n", len(state.Hubs))
-				fmt.Printf("  Updated: %s\n", state.UpdatedAt.Format("15:04:05"))
-			} else {
-				fmt.Println("Watch daemon running (no state)")
-			}
-		} else {
-			fmt.Println("Watch daemon not running")
+		if !watch.IsRunning(absRoot) {
+			fmt.Println("Watch daemon not running (start it with: codemap watch start .)")
+			return
 		}
+		pid, _ := watch.ReadPID(absRoot)
+		fmt.Println(watch.StatusLine(watch.ReadState(absRoot), pid, time.Now()))
+
+	case "report":
+		runWatchReport(absRoot, *markdown, *since)
+
+	case "metrics":
+		watch.WriteMetrics(os.Stdout, watch.ReadState(absRoot), time.Now())
+
+	case "export-session":
+		runExportSession(absRoot, *out)
+
+	case "export-traces":
+		runExportTraces(absRoot, *out, *otlp, *burstGap)
 
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown watch command: %s\n", subCmd)
-		fmt.Fprintln(os.Stderr, "Usage: codemap watch [start|stop|status]")
+		fmt.Fprintln(os.Stderr, "Usage: codemap watch [start|stop|status|report|metrics|export-session|export-traces]")
+		os.Exit(1)
+	}
+}
+
+// daemonOptions are the `watch start` flags forwarded to the background daemon
+type daemonOptions struct {
+	ignore        stringList
+	relatedWindow time.Duration
+	snapshots     int
+	only          string // comma-separated --only files
+	noPoll        bool
+	contentHash   bool
+}
+
+// args re-encodes the options as flags for the forked `watch daemon` process
+func (o daemonOptions) args() []string {
+	var args []string
+	for _, pattern := range o.ignore {
+		args = append(args, "--watch-ignore", pattern)
+	}
+	if o.relatedWindow != watch.DefaultRelatedWindow {
+		args = append(args, "--related-window", o.relatedWindow.String())
+	}
+	if o.snapshots > 0 {
+		args = append(args, "--snapshots", strconv.Itoa(o.snapshots))
+	}
+	if o.only != "" {
+		args = append(args, "--only", o.only)
+	}
+	if o.noPoll {
+		args = append(args, "--no-poll")
+	}
+	if o.contentHash {
+		args = append(args, "--content-hash")
+	}
+	return args
+}
+
+// onlyFiles splits --only into file paths
+func (o daemonOptions) onlyFiles() []string {
+	var files []string
+	for _, f := range strings.Split(o.only, ",") {
+		if trimmed := strings.TrimSpace(f); trimmed != "" {
+			files = append(files, trimmed)
+		}
+	}
+	return files
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// runWatchReport summarizes logged watch activity as a standup report
+func runWatchReport(root string, markdown bool, since time.Duration) {
+	events, err := watch.ReadEventLog(root)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "No watch activity recorded (.codemap/events.log not found)")
 		os.Exit(1)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading events.log: %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	title := "Today"
+	if since > 0 {
+		cutoff = now.Add(-since)
+		title = fmt.Sprintf("Last %s", since)
 	}
+	var recent []watch.Event
+	for _, e := range events {
+		if !e.Time.Before(cutoff) {
+			recent = append(recent, e)
+		}
+	}
+
+	// Hub status comes from the running daemon's state when available
+	hubs := make(map[string]bool)
+	if state := watch.ReadState(root); state != nil {
+		for _, h := range state.Hubs {
+			hubs[h] = true
+		}
+	} else if fg, err := scanner.BuildFileGraph(root); err == nil {
+		for _, h := range fg.HubFiles() {
+			hubs[h] = true
+		}
+	}
+
+	summary := watch.Summarize(recent, hubs)
+	if markdown {
+		fmt.Print(watch.MarkdownReport(fmt.Sprintf("Standup: %s (%s)", title, now.Format("2006-01-02")), summary))
+		return
+	}
+	if len(summary.Files) == 0 {
+		fmt.Printf("%s: no file activity recorded\n", title)
+		return
+	}
+	fmt.Printf("%s: %s\n", title, watch.StandupLine(summary))
 }
 
-func runDaemon(root string) {
+// runExportSession writes the current or last watch session as JSON
+func runExportSession(root, out string) {
+	session, err := watch.ExportSession(root, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+	if out == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s (%d events)\n", out, session.Totals.Events)
+}
+
+func runExportTraces(root, out, otlp string, gap time.Duration) {
+	events, err := watch.ReadEventLog(root)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "Error: no watch activity recorded (.codemap/events.log not found)")
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading events.log: %v\n", err)
+		os.Exit(1)
+	}
+	var exporter watch.SpanExporter = watch.FileSpanExporter{Path: out}
+	if otlp != "" {
+		exporter = watch.OTLPHTTPExporter{Endpoint: otlp}
+	}
+	spans := watch.EventSpans(root, events, gap)
+	if err := exporter.ExportSpans(spans); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if out != "" || otlp != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d spans in %d edit bursts\n", len(spans), len(watch.SplitBursts(events, gap)))
+	}
+}
+
+func runDaemon(root string, opts daemonOptions) {
 	daemon, err := watch.NewDaemon(root, false)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	daemon.SetIgnorePatterns(opts.ignore)
+	daemon.SetRelatedWindow(opts.relatedWindow)
+	daemon.SetSnapshotLimit(opts.snapshots)
+	daemon.SetPollFallback(!opts.noPoll)
+	daemon.SetContentHash(opts.contentHash)
+	if err := daemon.SetOnlyFiles(opts.onlyFiles()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	if err := daemon.Start(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error starting watch: %v\n", err)
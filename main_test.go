@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"codemap/scanner"
 )
@@ -127,6 +128,16 @@ func TestNonexistentPath(t *testing.T) {
 	}
 }
 
+func TestFileAsPath(t *testing.T) {
+	out, err := runCodemap("main.go")
+	if err == nil {
+		t.Fatal("Should fail when the path is a file")
+	}
+	if !strings.Contains(out, "expected a directory; for a single file use `codemap file main.go`") {
+		t.Errorf("Expected guidance toward codemap file, got: %s", out)
+	}
+}
+
 func TestDiffModeInGitRepo(t *testing.T) {
 	// This should either work or say "No files changed"
 	output, err := runCodemap("--diff", ".")
@@ -261,3 +272,61 @@ func TestCurrentDirectory(t *testing.T) {
 		t.Error("No arg and '.' should produce similar results")
 	}
 }
+
+func TestProgressGoesToStderr(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"main.go", "api/server.go", "api/routes.go"} {
+		os.MkdirAll(filepath.Join(root, filepath.Dir(f)), 0755)
+		os.WriteFile(filepath.Join(root, f), []byte("package x\n"), 0644)
+	}
+
+	var stderr bytes.Buffer
+	ticks := make(chan time.Time)
+	origOut, origTTY, origTicks, origStdout := progressOut, stderrIsTerminal, progressTicks, os.Stdout
+	r, w, _ := os.Pipe()
+	progressOut, stderrIsTerminal, progressTicks, os.Stdout = &stderr, func() bool { return true }, ticks, w
+	defer func() {
+		progressOut, stderrIsTerminal, progressTicks, os.Stdout = origOut, origTTY, origTicks, origStdout
+		stopProgress = func() {}
+	}()
+
+	startProgress(false)
+	if _, err := scanner.ScanFiles(root, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	// A tick before the delay draws nothing; one after it draws the line.
+	// Ticks are unbuffered, so each is handled before stopProgress clears.
+	ticks <- time.Now()
+	if stderr.Len() != 0 {
+		t.Errorf("Expected no progress before %v, got %q", progressDelay, stderr.String())
+	}
+	ticks <- time.Now().Add(progressDelay)
+	stopProgress()
+	w.Close()
+	var stdout bytes.Buffer
+	stdout.ReadFrom(r)
+
+	if !strings.Contains(stderr.String(), "⏳ scanning… 3 files") {
+		t.Errorf("Expected a progress line on stderr, got %q", stderr.String())
+	}
+	if !strings.HasSuffix(stderr.String(), "\r\033[K") {
+		t.Errorf("Expected the progress line to be cleared when stopped, got %q", stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected nothing on stdout, got %q", stdout.String())
+	}
+
+	// --json/--quiet: no progress at all
+	stderr.Reset()
+	startProgress(true)
+	scanner.ScanFiles(root, nil, nil, nil)
+	select {
+	case ticks <- time.Now().Add(progressDelay):
+		t.Error("Expected suppressed progress not to start a redraw loop")
+	default:
+	}
+	stopProgress()
+	if stderr.Len() != 0 {
+		t.Errorf("Expected suppressed progress to print nothing, got %q", stderr.String())
+	}
+}
@@ -32,19 +32,85 @@ type PathInput struct {
 	Path string `json:"path" jsonschema:"Path to the project directory to analyze"`
 }
 
+type HubsInput struct {
+	Path   string `json:"path" jsonschema:"Path to the project directory to analyze"`
+	Sort   string `json:"sort,omitempty" jsonschema:"Ranking: importers (direct importer count, default) or blast (transitive importer count)"`
+	Hot    bool   `json:"hot,omitempty" jsonschema:"Rank by importer recency instead: importers changed recently (git history and watch events) count more than untouched ones"`
+	Dedupe bool   `json:"dedupe,omitempty" jsonschema:"Count a JS/TS barrel (index file that only re-exports one module) and that module as one hub, with the importers of both"`
+}
+
+type TodosInput struct {
+	Path string `json:"path" jsonschema:"Path to the project directory"`
+	Text bool   `json:"text,omitempty" jsonschema:"Also list each marker with its line number and comment text"`
+}
+
 type DiffInput struct {
-	Path string `json:"path" jsonschema:"Path to the project directory to analyze"`
-	Ref  string `json:"ref,omitempty" jsonschema:"Git branch/ref to compare against (default: main)"`
+	Path       string `json:"path" jsonschema:"Path to the project directory to analyze"`
+	Ref        string `json:"ref,omitempty" jsonschema:"Git branch/ref to compare against (default: main)"`
+	Boundaries bool   `json:"boundaries,omitempty" jsonschema:"Also list package-to-package dependencies the changed files introduce vs ref; slower, as it builds the dependency graph at ref in a temporary git worktree"`
+}
+
+type StructureInput struct {
+	Path   string `json:"path" jsonschema:"Path to the project directory to analyze"`
+	Hubs   int    `json:"hubs,omitempty" jsonschema:"Max hub files to list after the tree (default: 5)"`
+	NoHubs bool   `json:"no_hubs,omitempty" jsonschema:"Leave out the hub summary for a pure structure view"`
+}
+
+type SnapshotDiffInput struct {
+	Path   string `json:"path" jsonschema:"Path to the project directory to analyze"`
+	Create bool   `json:"create,omitempty" jsonschema:"Save the current working tree as the new baseline snapshot instead of comparing against the old one"`
+}
+
+type SubtreeInput struct {
+	Path   string `json:"path" jsonschema:"Path to the project directory"`
+	Subdir string `json:"subdir" jsonschema:"Directory within the project to show, relative to path (e.g. api or src/components)"`
 }
 
 type FindInput struct {
 	Path    string `json:"path" jsonschema:"Path to the project directory to search"`
 	Pattern string `json:"pattern" jsonschema:"Filename pattern to search for (case-insensitive substring match)"`
+	Fuzzy   bool   `json:"fuzzy,omitempty" jsonschema:"Match abbreviations as a subsequence (e.g. usrctrl finds user_controller.ts), best match first"`
+}
+
+type SuggestLocationInput struct {
+	Path        string `json:"path" jsonschema:"Path to the project directory"`
+	Description string `json:"description" jsonschema:"What the new file is or does, as keywords or a short phrase (e.g. 'payment controller')"`
 }
 
 type ImportersInput struct {
-	Path string `json:"path" jsonschema:"Path to the project directory"`
-	File string `json:"file" jsonschema:"Relative path to the file to check (e.g. src/utils.ts)"`
+	Path     string `json:"path" jsonschema:"Path to the project directory"`
+	File     string `json:"file" jsonschema:"Relative path to the file to check (e.g. src/utils.ts)"`
+	DirIndex bool   `json:"dir_index,omitempty" jsonschema:"Show JS/TS directory index files as their directory (src/foo/index.ts as src/foo/); file may then name the directory"`
+	Group    bool   `json:"group,omitempty" jsonschema:"Group importers under language and role headings (e.g. Go test) with counts instead of one flat list"`
+}
+
+type BulkImportersInput struct {
+	Path  string   `json:"path" jsonschema:"Path to the project directory"`
+	Files []string `json:"files" jsonschema:"Relative paths of the files to check (e.g. the files in a changeset)"`
+	Limit int      `json:"limit,omitempty" jsonschema:"Max importers to list per file (default: 20)"`
+}
+
+type RelatedInput struct {
+	Path  string `json:"path" jsonschema:"Path to the project directory"`
+	File  string `json:"file" jsonschema:"Relative path to the file to start from (e.g. src/utils.ts)"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Max files to list (default: 15)"`
+}
+
+type FileContextInput struct {
+	Path     string `json:"path" jsonschema:"Path to the project directory"`
+	File     string `json:"file" jsonschema:"Relative path to the file to check (e.g. src/utils.ts)"`
+	Limit    int    `json:"limit,omitempty" jsonschema:"Max imports/importers to list (default: 20)"`
+	DirIndex bool   `json:"dir_index,omitempty" jsonschema:"Show JS/TS directory index files as their directory (src/foo/index.ts as src/foo/); file may then name the directory"`
+}
+
+type CoreInput struct {
+	Path  string `json:"path" jsonschema:"Path to the project directory"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Max files to list (default: 15)"`
+}
+
+type ExternalHubsInput struct {
+	Path  string `json:"path" jsonschema:"Path to the project directory"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Max packages to list (default: 20)"`
 }
 
 type ListProjectsInput struct {
@@ -57,8 +123,10 @@ type WatchInput struct {
 }
 
 type WatchActivityInput struct {
-	Path    string `json:"path" jsonschema:"Path to the project directory"`
-	Minutes int    `json:"minutes,omitempty" jsonschema:"Look back this many minutes (default: 30)"`
+	Path       string `json:"path" jsonschema:"Path to the project directory"`
+	Minutes    int    `json:"minutes,omitempty" jsonschema:"Look back this many minutes; 0 or omitted means the default of 30, so use since_start for the whole session"`
+	SinceStart bool   `json:"since_start,omitempty" jsonschema:"Show every event since the watcher started, ignoring minutes; the only way to see the whole session"`
+	Bytes      bool   `json:"bytes,omitempty" jsonschema:"Also show net byte change per file and for the session"`
 }
 
 func main() {
@@ -70,33 +138,63 @@ func main() {
 	// Tool: get_structure - Get project tree view
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_structure",
-		Description: "Get the project structure as a tree view. Shows files organized by directory with language detection, file sizes, and highlights the top 5 largest source files. Use this to understand how a codebase is organized.",
+		Description: "Get the project structure as a tree view. Shows files organized by directory with language detection, file sizes, and highlights the top 5 largest source files, followed by the top hub files (hubs sets how many, default 5; no_hubs=true leaves them out). Use this to understand how a codebase is organized.",
 	}, handleGetStructure)
 
+	// Tool: get_subtree - Tree view of one directory
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_subtree",
+		Description: "Get the tree view of a single directory within a project (e.g. subdir: api). Only that subtree is scanned, and paths are shown relative to it. Use this to drill into one area of a large repo instead of dumping the whole structure.",
+	}, handleGetSubtree)
+
 	// Tool: get_dependencies - Get dependency graph
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_dependencies",
 		Description: "Get the dependency flow of a project. Shows external dependencies by language, internal import chains between files, hub files (most-imported), and function counts. Use this to understand how code connects and which files are most critical.",
 	}, handleGetDependencies)
 
+	// Tool: get_external_deps - Third-party deps with the manifests declaring them
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_external_deps",
+		Description: "List third-party dependencies by language, each attributed to the manifest(s) that declare it (go.mod, package.json, requirements.txt, Podfile, Package.swift). Use this in monorepos to see which service or package pulls in a dependency.",
+	}, handleGetExternalDeps)
+
 	// Tool: get_diff - Get changed files with impact analysis
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_diff",
-		Description: "Get files changed compared to a git branch, with line counts and impact analysis showing which changed files are imported by others. Use this to understand what work has been done and what might break.",
+		Description: "Get files changed compared to a git branch, with line counts and impact analysis showing which changed files are imported by others. With boundaries=true it also lists package-to-package dependencies the changes introduce (e.g. service A now importing service B). Use this to understand what work has been done and what might break.",
 	}, handleGetDiff)
 
+	// Tool: get_snapshot_diff - Changes since a saved snapshot, no git needed
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_snapshot_diff",
+		Description: "Compare the working tree against a saved structure snapshot: added, removed, and resized files, with importer counts for each so you can see the impact. Works without git (pre-commit, non-git projects). Call with create=true first to save the baseline; same-size edits are not detected.",
+	}, handleGetSnapshotDiff)
+
 	// Tool: find_file - Find files by pattern
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "find_file",
-		Description: "Find files in a project matching a name pattern. Returns file paths with their sizes and languages.",
+		Description: "Find files in a project matching a name pattern. Returns file paths with their sizes and languages. Set fuzzy=true to match abbreviations (usrctrl -> user_controller.ts), ranked best-first.",
 	}, handleFindFile)
 
+	// Tool: suggest_location - Where a new file conventionally goes
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "suggest_location",
+		Description: "Suggest where a new file should live: matches the description's keywords against existing directory and file names and returns the directories where similar files already are, densest match first, with example files. Use this before creating a file to follow the project's layout conventions.",
+	}, handleSuggestLocation)
+
 	// Tool: get_importers - Find what imports a file
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_importers",
-		Description: "Find all files that import/depend on a specific file. Use this to understand the impact of changing a file.",
+		Description: "Find all files that import/depend on a specific file. Use this to understand the impact of changing a file. Set dir_index=true to show JS/TS index files as their directory (src/foo/index.ts as src/foo/).",
 	}, handleGetImporters)
 
+	// Tool: get_importers_bulk - Importers for several files at once
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_importers_bulk",
+		Description: "Find the importers of several files in one call, from a single dependency graph build. Use this when reviewing a changeset instead of calling get_importers once per file. Each file's list is capped (limit, default 20) with a total count.",
+	}, handleGetImportersBulk)
+
 	// Tool: status - Verify MCP connection
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "status",
@@ -126,7 +224,7 @@ func main() {
 	// Tool: get_activity - Get recent coding activity
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_activity",
-		Description: "Get recent coding activity for a watched project. Shows what files were edited, when, and how much changed. Use this to understand what the user has been working on. Returns hot files, recent changes, and session summary.",
+		Description: "Get recent coding activity for a watched project. Shows what files were edited, when, and how much changed. Use this to understand what the user has been working on. Returns hot files, recent changes, and session summary. Set bytes: true to add net byte change per file, and since_start: true to cover everything since the watcher started instead of the last N minutes (minutes: 0 means the default 30, not everything).",
 	}, handleGetActivity)
 
 	// === FILE GRAPH TOOLS ===
@@ -134,15 +232,63 @@ func main() {
 	// Tool: get_hubs - Get critical hub files
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_hubs",
-		Description: "Get all hub files in a project (files imported by 3+ other files). These are the critical files where changes have the most impact. Use this before making changes to understand what's important.",
+		Description: "Get all hub files in a project (files imported by at least hub_threshold other files, 3 unless .codemap/config.toml sets it). These are the critical files where changes have the most impact. Use this before making changes to understand what's important. Set sort=blast to rank by transitive importers (everything affected through import chains).",
 	}, handleGetHubs)
 
 	// Tool: get_file_context - Get full context for a file
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_file_context",
-		Description: "Get complete dependency context for a specific file: what it imports, what imports it, whether it's a hub, and all connected files. Use this before editing a file to understand its role in the codebase.",
+		Description: "Get complete dependency context for a specific file: what it imports, what imports it, whether it's a hub, the nearest hub it depends on and the nearest hub depending on it (with hop counts), and all connected files. Use this before editing a file to understand its role in the codebase.",
 	}, handleGetFileContext)
 
+	// Tool: get_related - Files nearest to a file in the import graph
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_related",
+		Description: "Rank the files closest to a file in the import graph, following imports in both directions: direct neighbors first, then two hops away, and so on, ties broken by importer count. Use this as a focused reading list of what to understand before changing a file.",
+	}, handleGetRelated)
+
+	// Tool: get_core - Rank files by PageRank centrality
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_core",
+		Description: "Get the architectural core of a project: files ranked by PageRank over the import graph. Unlike hub detection (raw importer count), a file imported by other central files ranks higher than one imported by many leaves. Use this to find the files that hold the codebase together.",
+	}, handleGetCore)
+
+	// Tool: get_untested - Find source files without tests
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_untested",
+		Description: "List source files that have no corresponding test file, pairing tests to sources by naming convention (foo_test.go, foo.test.ts, test_foo.py) and by what the tests import. Use this to find gaps in test coverage before changing a file.",
+	}, handleGetUntested)
+
+	// Tool: get_broken_imports - Find imports of files that no longer exist
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_broken_imports",
+		Description: "Find imports that point inside the project (relative paths, Go module paths, TS/JS path aliases) but resolve to no file on disk - usually left behind when a file was deleted or moved. External packages are not reported. Use this after a refactor to catch dangling references.",
+	}, handleGetBrokenImports)
+
+	// Tool: get_external_hubs - Most-imported third-party packages
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_external_hubs",
+		Description: "Rank third-party packages by how many project files import them (e.g. '142 files import lodash'), with subpath imports folded into their package and the Go standard library left out. Use this to gauge what upgrading or replacing a dependency would touch.",
+	}, handleGetExternalHubs)
+
+	// Tool: get_packages - Logical package layout
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_packages",
+		Description: "Get the project's logical package structure as a map: the Go module and each Go package import path with its files, Python packages (directories with __init__.py, as dotted names), and JS/TS directory modules (directories with an index file). Use this to understand how code is organized into packages rather than just folders.",
+	}, handleGetPackages)
+
+	// Tool: get_file_graph - The whole file graph as JSON
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_file_graph",
+		Description: "Get the project's full file-level dependency graph as JSON: Go module, files, what each file imports and is imported by, per-file importer counts, Go packages, and hub files, with every list sorted so results compare cleanly between runs. Use this to feed your own analysis or visualization rather than asking file by file.",
+	}, handleGetFileGraph)
+
+	// Tool: get_todos - TODO/FIXME density per file
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_todos",
+		Description: "Count TODO, FIXME, HACK, and XXX markers per source file, most first, with a project total. Set text=true to include each marker's line and comment. Use this as a quick tech-debt signal or to find unfinished work near a file you're changing.",
+	}, handleGetTodos)
+
 	// Run server on stdio
 	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
 		log.Printf("Server error: %v", err)
@@ -166,57 +312,237 @@ func errorResult(text string) *mcp.CallToolResult {
 	}
 }
 
-func handleGetStructure(ctx context.Context, req *mcp.CallToolRequest, input PathInput) (*mcp.CallToolResult, any, error) {
+func handleGetStructure(ctx context.Context, req *mcp.CallToolRequest, input StructureInput) (*mcp.CallToolResult, any, error) {
 	absRoot, err := filepath.Abs(input.Path)
 	if err != nil {
 		return errorResult("Invalid path: " + err.Error()), nil, nil
 	}
 
+	focus, err := scanner.ConfigFocus(absRoot)
+	if err != nil {
+		return errorResult("Config error: " + err.Error()), nil, nil
+	}
+
 	gitCache := scanner.NewGitIgnoreCache(input.Path)
 	files, err := scanner.ScanFiles(input.Path, gitCache, nil, nil)
 	if err != nil {
 		return errorResult("Scan error: " + err.Error()), nil, nil
 	}
+	files = scanner.FilterToFocus(files, focus)
 
 	project := scanner.Project{
 		Root:  absRoot,
 		Mode:  "tree",
 		Files: files,
+		Focus: focus,
 	}
 
 	output := captureOutput(func() {
 		render.Tree(project)
 	})
 
+	if roles := roleBreakdown(files); roles != "" {
+		output += "\nFiles by role: " + roles + "\n"
+	}
+
+	if input.NoHubs {
+		return textResult(output), nil, nil
+	}
+
 	// Add hub file summary
-	fg, err := scanner.BuildFileGraph(input.Path)
-	if err == nil {
-		hubs := fg.HubFiles()
-		if len(hubs) > 0 {
-			output += "\n⚠️  HUB FILES (high-impact, 3+ dependents):\n"
-			// Sort by importer count
-			sort.Slice(hubs, func(i, j int) bool {
-				return len(fg.Importers[hubs[i]]) > len(fg.Importers[hubs[j]])
-			})
-			for i, hub := range hubs {
-				if i >= 5 {
-					output += fmt.Sprintf("   ... and %d more hubs\n", len(hubs)-5)
-					break
-				}
-				output += fmt.Sprintf("   %s (%d importers)\n", hub, len(fg.Importers[hub]))
-			}
+	limit := input.Hubs
+	if limit <= 0 {
+		limit = 5
+	}
+	if fg, err := scanner.BuildFileGraphCached(input.Path); err == nil {
+		output += hubSummary(fg.FocusGraph(focus), limit)
+	}
+
+	return textResult(output), nil, nil
+}
+
+// hubSummary lists up to limit hubs, most importers first (ties by path),
+// or returns "" when there are none
+func hubSummary(fg *scanner.FileGraph, limit int) string {
+	hubs := fg.SortedHubs(false)
+	if len(hubs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n⚠️  HUB FILES (high-impact, %s dependents):\n", hubRule(fg)))
+	for i, hub := range hubs {
+		if i >= limit {
+			sb.WriteString(fmt.Sprintf("   ... and %d more hubs\n", len(hubs)-limit))
+			break
 		}
+		sb.WriteString(fmt.Sprintf("   %s (%d importers)\n", hub, len(fg.ImportersOf(hub))))
+	}
+	return sb.String()
+}
+
+func handleGetSubtree(ctx context.Context, req *mcp.CallToolRequest, input SubtreeInput) (*mcp.CallToolResult, any, error) {
+	absRoot, err := filepath.Abs(input.Path)
+	if err != nil {
+		return errorResult("Invalid path: " + err.Error()), nil, nil
+	}
+	subRoot, err := resolveSubdir(absRoot, input.Subdir)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+
+	// Ignore rules still come from the project root down
+	gitCache := scanner.NewGitIgnoreCache(absRoot)
+	files, err := scanner.ScanFiles(subRoot, gitCache, nil, nil)
+	if err != nil {
+		return errorResult("Scan error: " + err.Error()), nil, nil
+	}
+
+	project := scanner.Project{
+		Root:  subRoot,
+		Mode:  "tree",
+		Files: files,
+	}
+
+	output := captureOutput(func() {
+		render.Tree(project)
+	})
+
+	if roles := roleBreakdown(files); roles != "" {
+		output += "\nFiles by role: " + roles + "\n"
 	}
 
 	return textResult(output), nil, nil
 }
 
+// invalidPathCode prefixes the error for a path input that fails validation,
+// so clients can tell a rejected path from a failed analysis
+const invalidPathCode = "INVALID_PATH"
+
+// invalidPathResult reports a rejected path input
+func invalidPathResult(err error) *mcp.CallToolResult {
+	return errorResult(invalidPathCode + ": " + err.Error())
+}
+
+// safeRoot checks a project path input: it must be an existing directory.
+// Returns the absolute path.
+func safeRoot(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", path)
+	}
+	return abs, nil
+}
+
+// safeFile checks a file input against the project root and returns it as a
+// clean root-relative path with OS separators, the form FileGraph keys use.
+// Absolute paths are accepted if they're inside root; anything escaping it -
+// via .. or a symlink - is rejected. The file itself need not exist (it may
+// have been deleted).
+func safeFile(root, file string) (string, error) {
+	if file == "" {
+		return "", fmt.Errorf("file is required")
+	}
+	target := file
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, file)
+	}
+	target = filepath.Clean(target)
+	if err := withinRoot(root, target); err != nil {
+		return "", fmt.Errorf("%s is outside %s", file, root)
+	}
+	return filepath.Rel(root, target)
+}
+
+// withinRoot checks that target stays inside root, both as written and with
+// symlinks resolved. A target that doesn't exist is resolved through its
+// deepest existing ancestor.
+func withinRoot(root, target string) error {
+	if !isUnder(root, target) {
+		return fmt.Errorf("escapes root")
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	existing, rest := target, ""
+	for {
+		real, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			if !isUnder(realRoot, filepath.Join(real, rest)) {
+				return fmt.Errorf("escapes root")
+			}
+			return nil
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return err
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
+
+// isUnder reports whether path is root or inside it (lexically)
+func isUnder(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resolveSubdir joins subdir onto root and rejects anything that escapes the
+// root (../, absolute paths elsewhere, symlinks pointing outside) or isn't a directory
+func resolveSubdir(root, subdir string) (string, error) {
+	if subdir == "" {
+		return "", fmt.Errorf("subdir is required")
+	}
+	rel, err := safeFile(root, subdir)
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Join(root, rel)
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", subdir)
+	}
+	return target, nil
+}
+
+// roleBreakdown summarizes files by role, e.g. "80 source, 20 test, 15 config"
+func roleBreakdown(files []scanner.FileInfo) string {
+	counts := scanner.CountRoles(files)
+	var parts []string
+	for _, role := range scanner.Roles {
+		if counts[role] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[role], role))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
 func handleGetDependencies(ctx context.Context, req *mcp.CallToolRequest, input PathInput) (*mcp.CallToolResult, any, error) {
 	absRoot, err := filepath.Abs(input.Path)
 	if err != nil {
 		return errorResult("Invalid path: " + err.Error()), nil, nil
 	}
 
+	focus, err := scanner.ConfigFocus(absRoot)
+	if err != nil {
+		return errorResult("Config error: " + err.Error()), nil, nil
+	}
+
 	analyses, err := scanner.ScanForDeps(input.Path)
 	if err != nil {
 		return errorResult("Scan error: " + err.Error()), nil, nil
@@ -225,8 +551,9 @@ func handleGetDependencies(ctx context.Context, req *mcp.CallToolRequest, input
 	depsProject := scanner.DepsProject{
 		Root:         absRoot,
 		Mode:         "deps",
-		Files:        analyses,
+		Files:        scanner.FilterAnalysisToFocus(analyses, focus),
 		ExternalDeps: scanner.ReadExternalDeps(absRoot),
+		Focus:        focus,
 	}
 
 	output := captureOutput(func() {
@@ -236,6 +563,34 @@ func handleGetDependencies(ctx context.Context, req *mcp.CallToolRequest, input
 	return textResult(output), nil, nil
 }
 
+func handleGetExternalDeps(ctx context.Context, req *mcp.CallToolRequest, input PathInput) (*mcp.CallToolResult, any, error) {
+	absRoot, err := filepath.Abs(input.Path)
+	if err != nil {
+		return errorResult("Invalid path: " + err.Error()), nil, nil
+	}
+
+	deps := scanner.ReadExternalDepSources(absRoot)
+	if len(deps) == 0 {
+		return textResult("No external dependencies found (no go.mod, package.json, requirements.txt, Podfile or Package.swift)."), nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== External Dependencies (%d) ===\n", len(deps)))
+	lang := ""
+	for _, d := range deps {
+		if d.Language != lang {
+			lang = d.Language
+			name := scanner.LangDisplay[lang]
+			if name == "" {
+				name = lang
+			}
+			sb.WriteString(fmt.Sprintf("\n%s:\n", name))
+		}
+		sb.WriteString(fmt.Sprintf("  %s (%s)\n", d.Name, strings.Join(d.Sources, ", ")))
+	}
+	return textResult(sb.String()), nil, nil
+}
+
 func handleGetDiff(ctx context.Context, req *mcp.CallToolRequest, input DiffInput) (*mcp.CallToolResult, any, error) {
 	ref := input.Ref
 	if ref == "" {
@@ -256,13 +611,18 @@ func handleGetDiff(ctx context.Context, req *mcp.CallToolRequest, input DiffInpu
 		return textResult("No files changed vs " + ref), nil, nil
 	}
 
+	focus, err := scanner.ConfigFocus(absRoot)
+	if err != nil {
+		return errorResult("Config error: " + err.Error()), nil, nil
+	}
+
 	gitCache := scanner.NewGitIgnoreCache(input.Path)
 	files, err := scanner.ScanFiles(input.Path, gitCache, nil, nil)
 	if err != nil {
 		return errorResult("Scan error: " + err.Error()), nil, nil
 	}
 
-	files = scanner.FilterToChangedWithInfo(files, diffInfo)
+	files = scanner.FilterToFocus(scanner.FilterToChangedWithInfo(files, diffInfo), focus)
 	impact := scanner.AnalyzeImpact(absRoot, files)
 
 	project := scanner.Project{
@@ -271,31 +631,161 @@ func handleGetDiff(ctx context.Context, req *mcp.CallToolRequest, input DiffInpu
 		Files:   files,
 		DiffRef: ref,
 		Impact:  impact,
+		Focus:   focus,
 	}
 
 	output := captureOutput(func() {
 		render.Tree(project)
 	})
 
+	if input.Boundaries {
+		report, err := boundaryCrossingsReport(absRoot, ref, diffInfo.Changed)
+		if err != nil {
+			report = "\nCould not check package boundaries: " + err.Error() + "\n"
+		}
+		output += report
+	}
 	return textResult(output), nil, nil
 }
 
-func handleFindFile(ctx context.Context, req *mcp.CallToolRequest, input FindInput) (*mcp.CallToolResult, any, error) {
-	gitCache := scanner.NewGitIgnoreCache(input.Path)
-	files, err := scanner.ScanFiles(input.Path, gitCache, nil, nil)
+// boundaryCrossingsReport lists the package dependencies the changed files
+// add over ref, or "" when there are none. changed is keyed as git reports
+// it: paths from the repository's top level, or from root for untracked files.
+func boundaryCrossingsReport(root, ref string, changed map[string]bool) (string, error) {
+	prefix := scanner.GitPrefix(root)
+	rel := make(map[string]bool, len(changed))
+	for p := range changed {
+		rel[strings.TrimPrefix(p, prefix)] = true
+	}
+
+	before, err := scanner.BuildFileGraphAt(root, ref)
+	if err != nil {
+		return "", err
+	}
+	after, err := scanner.BuildFileGraphCached(root)
+	if err != nil {
+		return "", err
+	}
+	crossings := scanner.NewBoundaryCrossings(before, after, rel)
+	if len(crossings) == 0 {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\nNew cross-package dependencies vs %s (%d):\n", ref, len(crossings)))
+	for _, c := range crossings {
+		sb.WriteString(fmt.Sprintf("  %s -> %s\n", c.From, c.To))
+		for _, e := range c.Edges {
+			sb.WriteString(fmt.Sprintf("    %s -> %s\n", e.From, e.To))
+		}
+	}
+	return sb.String(), nil
+}
+
+func handleGetSnapshotDiff(ctx context.Context, req *mcp.CallToolRequest, input SnapshotDiffInput) (*mcp.CallToolResult, any, error) {
+	root, err := safeRoot(input.Path)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+
+	if input.Create {
+		fg, _ := scanner.BuildFileGraphCached(root) // without a graph the snapshot still has sizes
+		snap, err := watch.SaveWorkingSnapshot(root, fg)
+		if err != nil {
+			return errorResult("Failed to save snapshot: " + err.Error()), nil, nil
+		}
+		return textResult(fmt.Sprintf("Saved snapshot of %d files. Call get_snapshot_diff again later to see what changed since now.", snap.FileCount)), nil, nil
+	}
+
+	snap, err := watch.ReadSnapshot(root, watch.WorkingSnapshot)
+	if os.IsNotExist(err) {
+		return textResult("No snapshot saved for this project yet. Call get_snapshot_diff with create=true to save the current working tree as the baseline."), nil, nil
+	}
+	if err != nil {
+		return errorResult("Failed to read snapshot: " + err.Error()), nil, nil
+	}
+
+	if fp, err := scanner.Fingerprint(root); err == nil && fp.Hash == snap.Fingerprint {
+		return textResult(fmt.Sprintf("No files added, removed, or resized since the snapshot (%s).", snap.Time.Format(time.RFC3339))), nil, nil
+	}
+
+	files, err := scanner.ScanFiles(root, scanner.NewGitIgnoreCache(root), nil, nil)
 	if err != nil {
 		return errorResult("Scan error: " + err.Error()), nil, nil
 	}
+	fg, err := scanner.BuildFileGraphCached(root)
+	if err != nil {
+		fg = nil // report the file changes without impact
+	}
+	return textResult(snapshotDiffReport(snap, watch.DiffSnapshot(snap, files), fg)), nil, nil
+}
+
+// snapshotDiffReport lists the changes since a snapshot, each with who
+// depends on it: current importers for added and resized files (fg may be
+// nil), and the snapshot's importers for removed ones, which likely now break
+func snapshotDiffReport(snap *watch.Snapshot, diff watch.SnapshotDiff, fg *scanner.FileGraph) string {
+	if diff.Empty() {
+		return fmt.Sprintf("No files added, removed, or resized since the snapshot (%s).", snap.Time.Format(time.RFC3339))
+	}
+
+	impact := func(importers []string, hub bool) string {
+		switch {
+		case len(importers) == 0:
+			return ""
+		case hub:
+			return fmt.Sprintf(" - HUB, %d importers", len(importers))
+		default:
+			return fmt.Sprintf(" - %d importers", len(importers))
+		}
+	}
+	current := func(path string) string {
+		if fg == nil {
+			return ""
+		}
+		return impact(fg.ImportersOf(path), fg.IsHub(path))
+	}
 
-	// Filter files matching pattern (case-insensitive)
-	var matches []string
-	pattern := strings.ToLower(input.Pattern)
-	for _, f := range files {
-		if strings.Contains(strings.ToLower(f.Path), pattern) {
-			matches = append(matches, f.Path)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Changes since snapshot (%s) ===\n", snap.Time.Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("%d added, %d removed, %d resized\n", len(diff.Added), len(diff.Removed), len(diff.Resized)))
+
+	if len(diff.Added) > 0 {
+		sb.WriteString("\nAdded:\n")
+		for _, path := range diff.Added {
+			sb.WriteString(fmt.Sprintf("  + %s%s\n", path, current(path)))
 		}
 	}
+	if len(diff.Removed) > 0 {
+		sb.WriteString("\nRemoved:\n")
+		hubs := make(map[string]bool, len(snap.Hubs))
+		for _, h := range snap.Hubs {
+			hubs[h] = true
+		}
+		for _, path := range diff.Removed {
+			sb.WriteString(fmt.Sprintf("  - %s%s\n", path, impact(snap.Importers[path], hubs[path])))
+		}
+	}
+	if len(diff.Resized) > 0 {
+		sb.WriteString("\nResized:\n")
+		for _, r := range diff.Resized {
+			sb.WriteString(fmt.Sprintf("  ~ %s (%s)%s\n", r.Path, render.FormatSizeDelta(r.NewSize-r.OldSize), current(r.Path)))
+		}
+	}
+	return sb.String()
+}
 
+func handleFindFile(ctx context.Context, req *mcp.CallToolRequest, input FindInput) (*mcp.CallToolResult, any, error) {
+	root, err := safeRoot(input.Path)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+	gitCache := scanner.NewGitIgnoreCache(root)
+	files, err := scanner.ScanFiles(root, gitCache, nil, nil)
+	if err != nil {
+		return errorResult("Scan error: " + err.Error()), nil, nil
+	}
+
+	matches := matchFiles(files, input.Pattern, input.Fuzzy)
 	if len(matches) == 0 {
 		return textResult("No files found matching '" + input.Pattern + "'"), nil, nil
 	}
@@ -303,6 +793,63 @@ func handleFindFile(ctx context.Context, req *mcp.CallToolRequest, input FindInp
 	return textResult(fmt.Sprintf("Found %d files:\n%s", len(matches), strings.Join(matches, "\n"))), nil, nil
 }
 
+// suggestLocationLimit caps the directories suggest_location lists
+const suggestLocationLimit = 5
+
+func handleSuggestLocation(ctx context.Context, req *mcp.CallToolRequest, input SuggestLocationInput) (*mcp.CallToolResult, any, error) {
+	root, err := safeRoot(input.Path)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+	files, err := scanner.ScanFiles(root, scanner.NewGitIgnoreCache(root), nil, nil)
+	if err != nil {
+		return errorResult("Scan error: " + err.Error()), nil, nil
+	}
+
+	suggestions := scanner.SuggestLocations(files, input.Description)
+	if len(suggestions) == 0 {
+		return textResult("No existing files match '" + input.Description + "'"), nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Suggested locations for '%s' ===\n", input.Description))
+	for _, s := range suggestions[:min(len(suggestions), suggestLocationLimit)] {
+		dir := s.Dir + "/"
+		if s.Dir == "." {
+			dir = "(root)"
+		}
+		sb.WriteString(fmt.Sprintf("%s  %d of %d files match\n", dir, s.Matches, s.Files))
+		for _, ex := range s.Examples {
+			sb.WriteString("    " + ex + "\n")
+		}
+	}
+	if len(suggestions) > suggestLocationLimit {
+		sb.WriteString(fmt.Sprintf("... and %d more directories\n", len(suggestions)-suggestLocationLimit))
+	}
+	return textResult(sb.String()), nil, nil
+}
+
+// matchFiles returns file paths matching pattern: a case-insensitive substring
+// match in walk order, or with fuzzy a subsequence match ranked best-first
+func matchFiles(files []scanner.FileInfo, pattern string, fuzzy bool) []string {
+	if fuzzy {
+		paths := make([]string, len(files))
+		for i, f := range files {
+			paths[i] = f.Path
+		}
+		return scanner.FuzzyMatch(pattern, paths)
+	}
+
+	var matches []string
+	pattern = strings.ToLower(pattern)
+	for _, f := range files {
+		if strings.Contains(strings.ToLower(f.Path), pattern) {
+			matches = append(matches, f.Path)
+		}
+	}
+	return matches
+}
+
 // EmptyInput for tools that don't need parameters
 type EmptyInput struct{}
 
@@ -416,7 +963,7 @@ func getProjectStats(path string) string {
 	// Count files by language
 	langCounts := make(map[string]int)
 	for _, f := range files {
-		lang := scanner.DetectLanguage(f.Path)
+		lang := scanner.DetectLanguageAt(path, f.Path)
 		if lang != "" {
 			langCounts[lang]++
 		}
@@ -445,23 +992,162 @@ func getProjectStats(path string) string {
 }
 
 func handleGetImporters(ctx context.Context, req *mcp.CallToolRequest, input ImportersInput) (*mcp.CallToolResult, any, error) {
-	fg, err := scanner.BuildFileGraph(input.Path)
+	root, err := safeRoot(input.Path)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+	file, err := safeFile(root, input.File)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+	fg, err := scanner.BuildFileGraphCached(root)
 	if err != nil {
 		return errorResult("Failed to build file graph: " + err.Error()), nil, nil
 	}
+	return textResult(importersReport(fg, file, input.DirIndex, input.Group)), nil, nil
+}
 
-	importers := fg.Importers[input.File]
+func handleGetImportersBulk(ctx context.Context, req *mcp.CallToolRequest, input BulkImportersInput) (*mcp.CallToolResult, any, error) {
+	if len(input.Files) == 0 {
+		return errorResult("files is required: list the files to check"), nil, nil
+	}
+	root, err := safeRoot(input.Path)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+	files := make([]string, len(input.Files))
+	for i, f := range input.Files {
+		if files[i], err = safeFile(root, f); err != nil {
+			return invalidPathResult(err), nil, nil
+		}
+	}
+	fg, err := scanner.BuildFileGraphCached(root)
+	if err != nil {
+		return errorResult("Failed to build file graph: " + err.Error()), nil, nil
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultContextLimit
+	}
+	return textResult(bulkImportersReport(fg, files, limit)), nil, nil
+}
+
+// bulkImportersReport lists each file's importers under its own header,
+// capping each list at limit
+func bulkImportersReport(fg *scanner.FileGraph, files []string, limit int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Importers of %d files ===\n", len(files)))
+	for _, file := range files {
+		importers := fg.ImportersOf(file)
+		switch {
+		case len(importers) == 0:
+			sb.WriteString(fmt.Sprintf("\n%s: no importers\n", file))
+			continue
+		case fg.IsHub(file):
+			sb.WriteString(fmt.Sprintf("\n%s (%d importers) ⚠️ HUB FILE\n", file, len(importers)))
+		default:
+			sb.WriteString(fmt.Sprintf("\n%s (%d importers)\n", file, len(importers)))
+		}
+		writeCappedList(&sb, importers, limit, "<-")
+	}
+	return sb.String()
+}
+
+// displayLabels returns the paths as shown to the user: with dirIndex,
+// directory index files are labeled as their directory
+func displayLabels(paths []string, dirIndex bool) []string {
+	if !dirIndex {
+		return paths
+	}
+	labels := make([]string, len(paths))
+	for i, p := range paths {
+		labels[i] = scanner.DirIndexLabel(p)
+	}
+	return labels
+}
+
+// resolveTarget maps a directory label back to its index file when dirIndex
+// is on and file isn't itself a graph node
+func resolveTarget(fg *scanner.FileGraph, file string, dirIndex bool) string {
+	if dirIndex && len(fg.ImportersOf(file)) == 0 && len(fg.Imports[file]) == 0 {
+		if index := fg.ResolveDirIndex(file); index != "" {
+			return index
+		}
+	}
+	return file
+}
+
+// importersReport formats get_importers output for file, optionally grouping
+// the importers by language and role
+func importersReport(fg *scanner.FileGraph, file string, dirIndex, group bool) string {
+	file = resolveTarget(fg, file, dirIndex)
+	label := displayLabels([]string{file}, dirIndex)[0]
+
+	importers := fg.ImportersOf(file)
+	if !fg.OnDisk(file) {
+		// A fresh scan can't see edges to a deleted file, but a running
+		// watch daemon still holds them from before the delete
+		if len(importers) == 0 {
+			if state := watch.ReadState(fg.Root); state != nil {
+				importers = state.Graph(fg.Root).ImportersOf(file)
+			}
+		}
+		if len(importers) > 0 {
+			return fmt.Sprintf("%d files import '%s': ⚠️ target file no longer exists — these imports are broken.\n%s", len(importers), label, importerList(fg.Root, importers, dirIndex, group))
+		}
+	}
 	if len(importers) == 0 {
-		return textResult("No files import '" + input.File + "'"), nil, nil
+		return "No files import '" + label + "'"
 	}
 
-	isHub := len(importers) >= 3
 	hubNote := ""
-	if isHub {
+	if fg.IsHub(file) {
 		hubNote = " ⚠️ HUB FILE"
 	}
 
-	return textResult(fmt.Sprintf("%d files import '%s':%s\n%s", len(importers), input.File, hubNote, strings.Join(importers, "\n"))), nil, nil
+	return fmt.Sprintf("%d files import '%s':%s\n%s", len(importers), label, hubNote, importerList(fg.Root, importers, dirIndex, group))
+}
+
+// importerList lists importers one per line, or with group set, under
+// "<Language> <role> (N):" headings, largest group first
+func importerList(root string, importers []string, dirIndex, group bool) string {
+	labels := displayLabels(importers, dirIndex)
+	if !group {
+		return strings.Join(labels, "\n")
+	}
+
+	groups := make(map[string][]string)
+	var order []string
+	for i, imp := range importers {
+		lang := scanner.LangDisplay[scanner.DetectLanguageAt(root, imp)]
+		if lang == "" {
+			lang = "Other"
+		}
+		heading := lang + " " + string(scanner.ClassifyFile(imp))
+		if _, ok := groups[heading]; !ok {
+			order = append(order, heading)
+		}
+		groups[heading] = append(groups[heading], labels[i])
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if len(groups[order[i]]) != len(groups[order[j]]) {
+			return len(groups[order[i]]) > len(groups[order[j]])
+		}
+		return order[i] < order[j]
+	})
+
+	var sb strings.Builder
+	for i, heading := range order {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("%s (%d):\n", heading, len(groups[heading])))
+		for _, label := range groups[heading] {
+			sb.WriteString("  " + label + "\n")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
 }
 
 // ANSI escape code pattern
@@ -582,24 +1268,25 @@ func handleGetActivity(ctx context.Context, req *mcp.CallToolRequest, input Watc
 		return errorResult(fmt.Sprintf("No active watcher for: %s\nUse start_watch first.", absPath)), nil, nil
 	}
 
+	// minutes is omitempty, so 0 can't mean "everything": since_start does
 	minutes := input.Minutes
 	if minutes <= 0 {
 		minutes = 30
 	}
 
 	events := daemon.GetEvents(0)
-	cutoff := time.Now().Add(-time.Duration(minutes) * time.Minute)
-
-	// Filter to recent events
-	var recent []watch.Event
-	for _, e := range events {
-		if e.Time.After(cutoff) {
-			recent = append(recent, e)
-		}
+	recent := activityEvents(events, minutes, input.SinceStart, time.Now())
+	window := fmt.Sprintf("Last %d minutes", minutes)
+	none := fmt.Sprintf("in the last %d minutes", minutes)
+	if input.SinceStart {
+		tf, _ := watch.LoadTimeFormat(absPath)
+		started := tf.FormatClock(daemon.StartedAt())
+		window = "Since watcher start at " + started
+		none = "since the watcher started at " + started
 	}
 
 	if len(recent) == 0 {
-		return textResult(fmt.Sprintf(`No activity in the last %d minutes.
+		return textResult(fmt.Sprintf(`No activity %s.
 
 Watcher is running for: %s
 Files tracked: %d
@@ -609,15 +1296,41 @@ The user may be:
 - Reading code
 - Thinking/planning
 - Working in a different project
-- Taking a break`, minutes, absPath, daemon.FileCount(), len(events))), nil, nil
+- Taking a break`, none, absPath, daemon.FileCount(), len(events))), nil, nil
 	}
 
+	return textResult(activityReport(absPath, window, recent, input.Bytes)), nil, nil
+}
+
+// activityEvents picks get_activity's events: those in the last minutes
+// before now, or all of them (everything since the watcher started) with
+// sinceStart
+func activityEvents(events []watch.Event, minutes int, sinceStart bool, now time.Time) []watch.Event {
+	if sinceStart {
+		return events
+	}
+	cutoff := now.Add(-time.Duration(minutes) * time.Minute)
+	var recent []watch.Event
+	for _, e := range events {
+		if e.Time.After(cutoff) {
+			recent = append(recent, e)
+		}
+	}
+	return recent
+}
+
+// activityReport renders get_activity's hot files, session summary, and
+// timeline for the recent events under a header naming their window;
+// showBytes adds byte deltas
+func activityReport(absPath, window string, recent []watch.Event, showBytes bool) string {
+	tf, _ := watch.LoadTimeFormat(absPath) // a bad setting falls back to defaults
 	// Aggregate by file
 	type fileStats struct {
-		edits    int
-		netDelta int
-		lastEdit time.Time
-		dirty    bool
+		edits     int
+		netDelta  int
+		sizeDelta int64
+		lastEdit  time.Time
+		dirty     bool
 	}
 	byFile := make(map[string]*fileStats)
 
@@ -630,6 +1343,7 @@ The user may be:
 			}
 			stats.edits++
 			stats.netDelta += e.Delta
+			stats.sizeDelta += e.SizeDelta
 			if e.Time.After(stats.lastEdit) {
 				stats.lastEdit = e.Time
 			}
@@ -641,20 +1355,22 @@ The user may be:
 
 	// Sort files by edit count (hot files first)
 	type fileSummary struct {
-		path     string
-		edits    int
-		delta    int
-		lastEdit time.Time
-		dirty    bool
+		path      string
+		edits     int
+		delta     int
+		sizeDelta int64
+		lastEdit  time.Time
+		dirty     bool
 	}
 	var summaries []fileSummary
 	for path, stats := range byFile {
 		summaries = append(summaries, fileSummary{
-			path:     path,
-			edits:    stats.edits,
-			delta:    stats.netDelta,
-			lastEdit: stats.lastEdit,
-			dirty:    stats.dirty,
+			path:      path,
+			edits:     stats.edits,
+			delta:     stats.netDelta,
+			sizeDelta: stats.sizeDelta,
+			lastEdit:  stats.lastEdit,
+			dirty:     stats.dirty,
 		})
 	}
 	sort.Slice(summaries, func(i, j int) bool {
@@ -663,7 +1379,7 @@ The user may be:
 
 	// Build output
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("=== Activity: Last %d minutes ===\n", minutes))
+	sb.WriteString(fmt.Sprintf("=== Activity: %s ===\n", window))
 	sb.WriteString(fmt.Sprintf("Project: %s\n\n", absPath))
 
 	// Hot files
@@ -683,17 +1399,23 @@ The user may be:
 		if s.dirty {
 			dirtyStr = " [uncommitted]"
 		}
-		sb.WriteString(fmt.Sprintf("  %-40s %2d edits  %6s lines%s\n",
-			s.path, s.edits, deltaStr, dirtyStr))
+		bytesStr := ""
+		if showBytes {
+			bytesStr = fmt.Sprintf("  %9s", render.FormatSizeDelta(s.sizeDelta))
+		}
+		sb.WriteString(fmt.Sprintf("  %-40s %2d edits  %6s lines%s%s\n",
+			s.path, s.edits, deltaStr, bytesStr, dirtyStr))
 	}
 
 	// Session summary
 	totalEdits := 0
 	totalDelta := 0
+	var totalBytes int64
 	dirtyCount := 0
 	for _, s := range summaries {
 		totalEdits += s.edits
 		totalDelta += s.delta
+		totalBytes += s.sizeDelta
 		if s.dirty {
 			dirtyCount++
 		}
@@ -710,8 +1432,24 @@ The user may be:
 		deltaStr = fmt.Sprintf("%d", totalDelta)
 	}
 	sb.WriteString(fmt.Sprintf("  Net line change: %s\n", deltaStr))
+	if showBytes {
+		sb.WriteString(fmt.Sprintf("  Net byte change: %s\n", render.FormatSizeDelta(totalBytes)))
+	}
 	sb.WriteString(fmt.Sprintf("  Uncommitted:    %d files\n", dirtyCount))
 
+	// Highest-impact edits (hubs first, then largest line deltas)
+	if top := watch.HighestImpact(recent, 3); len(top) > 0 {
+		sb.WriteString("\nHIGHEST IMPACT:\n")
+		for _, e := range top {
+			hubStr := ""
+			if e.IsHub {
+				hubStr = fmt.Sprintf(" ⚠️ HUB (%d importers)", e.Importers)
+			}
+			sb.WriteString(fmt.Sprintf("  %s  %-6s  %s (%+d)%s\n",
+				tf.FormatClock(e.Time), e.Op, e.Path, e.Delta, hubStr))
+		}
+	}
+
 	// Recent timeline (last 5 events)
 	sb.WriteString("\nRECENT TIMELINE:\n")
 	start := len(recent) - 5
@@ -728,37 +1466,105 @@ The user may be:
 			}
 		}
 		sb.WriteString(fmt.Sprintf("  %s  %-6s  %s%s\n",
-			e.Time.Format("15:04:05"), e.Op, e.Path, deltaStr))
+			tf.FormatClock(e.Time), e.Op, e.Path, deltaStr))
+		if len(e.RelatedHot) > 0 {
+			sb.WriteString(fmt.Sprintf("            edited alongside %s\n", strings.Join(e.RelatedHot, ", ")))
+		}
 	}
 
-	return textResult(sb.String()), nil, nil
+	return sb.String()
 }
 
 // === FILE GRAPH HANDLERS ===
 
-func handleGetHubs(ctx context.Context, req *mcp.CallToolRequest, input PathInput) (*mcp.CallToolResult, any, error) {
-	fg, err := scanner.BuildFileGraph(input.Path)
+// hubRule is fg's hub threshold as report text, such as "3+"; per-directory
+// configs may raise or lower it below the root
+func hubRule(fg *scanner.FileGraph) string {
+	return fmt.Sprintf("%d+", fg.HubThreshold("."))
+}
+
+// noHubsMessage is the get_hubs reply when fg has no hubs
+func noHubsMessage(fg *scanner.FileGraph) string {
+	return fmt.Sprintf("No hub files found (no files with %s importers).", hubRule(fg))
+}
+
+// dedupedHubsReport lists hubs with barrels folded into their targets
+func dedupedHubsReport(fg *scanner.FileGraph, hubs []scanner.HubEntry) string {
+	if len(hubs) == 0 {
+		return noHubsMessage(fg)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Hub Files (%d total, barrels merged) ===\n", len(hubs)))
+	sb.WriteString(fmt.Sprintf("These files are imported by %s other files, directly or through a barrel that re-exports them.\n\n", hubRule(fg)))
+	for _, h := range hubs {
+		if h.Barrel != "" {
+			sb.WriteString(fmt.Sprintf("  %s (%d importers, incl. via %s)\n", h.Path, len(h.Importers), h.Barrel))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s (%d importers)\n", h.Path, len(h.Importers)))
+		}
+		for i, imp := range h.Importers {
+			if i >= 3 {
+				sb.WriteString(fmt.Sprintf("      ... and %d more\n", len(h.Importers)-3))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("      <- %s\n", imp))
+		}
+	}
+	return sb.String()
+}
+
+func handleGetHubs(ctx context.Context, req *mcp.CallToolRequest, input HubsInput) (*mcp.CallToolResult, any, error) {
+	var byBlast bool
+	switch input.Sort {
+	case "", "importers":
+	case "blast":
+		byBlast = true
+	default:
+		return errorResult(fmt.Sprintf("Unknown sort %q (use importers or blast)", input.Sort)), nil, nil
+	}
+	if input.Hot && byBlast {
+		return errorResult("hot and sort=blast are separate rankings; pick one"), nil, nil
+	}
+	if input.Dedupe && (input.Hot || byBlast) {
+		return errorResult("dedupe applies to the importer ranking only"), nil, nil
+	}
+
+	focus, err := scanner.ConfigFocus(input.Path)
+	if err != nil {
+		return errorResult("Config error: " + err.Error()), nil, nil
+	}
+	fg, err := scanner.BuildFileGraphCached(input.Path)
 	if err != nil {
 		return errorResult("Failed to build file graph: " + err.Error()), nil, nil
 	}
+	fg = fg.FocusGraph(focus)
+	if input.Hot {
+		return textResult(hotHubsReport(fg, importerRecency(fg.Root), time.Now())), nil, nil
+	}
+	if input.Dedupe {
+		return textResult(dedupedHubsReport(fg, fg.DedupedHubs())), nil, nil
+	}
 
-	hubs := fg.HubFiles()
+	hubs := fg.SortedHubs(byBlast)
 	if len(hubs) == 0 {
-		return textResult("No hub files found (no files with 3+ importers)."), nil, nil
+		return textResult(noHubsMessage(fg)), nil, nil
 	}
 
-	// Sort by importer count
-	sort.Slice(hubs, func(i, j int) bool {
-		return len(fg.Importers[hubs[i]]) > len(fg.Importers[hubs[j]])
-	})
-
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("=== Hub Files (%d total) ===\n", len(hubs)))
-	sb.WriteString("These files are imported by 3+ other files. Changes here have wide impact.\n\n")
+	sb.WriteString(fmt.Sprintf("These files are imported by %s other files. Changes here have wide impact.\n", hubRule(fg)))
+	if byBlast {
+		sb.WriteString("Ranked by blast radius: every file reached through chains of importers.\n")
+	}
+	sb.WriteString("\n")
 
 	for _, hub := range hubs {
-		importers := fg.Importers[hub]
-		sb.WriteString(fmt.Sprintf("  %s (%d importers)\n", hub, len(importers)))
+		importers := fg.ImportersOf(hub)
+		if byBlast {
+			sb.WriteString(fmt.Sprintf("  %s (%d importers, %d transitive)\n", hub, len(importers), fg.BlastRadius()[hub]))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s (%d importers)\n", hub, len(importers)))
+		}
 		// Show first few importers
 		for i, imp := range importers {
 			if i >= 3 {
@@ -768,24 +1574,104 @@ func handleGetHubs(ctx context.Context, req *mcp.CallToolRequest, input PathInpu
 			sb.WriteString(fmt.Sprintf("      <- %s\n", imp))
 		}
 	}
+	if len(fg.Minified) > 0 {
+		sb.WriteString(fmt.Sprintf("\n(%d minified file(s) excluded; include_minified = true in .codemap/config.toml counts them)\n", len(fg.Minified)))
+	}
 
 	return textResult(sb.String()), nil, nil
 }
 
-func handleGetFileContext(ctx context.Context, req *mcp.CallToolRequest, input ImportersInput) (*mcp.CallToolResult, any, error) {
-	fg, err := scanner.BuildFileGraph(input.Path)
+// importerRecency maps files to when they last changed: the latest commit
+// touching them, overridden by newer watch events. Either source may be
+// missing (not a git repo, no daemon); what's there is used.
+func importerRecency(root string) map[string]time.Time {
+	changed, err := scanner.GitLastChanged(root)
+	if err != nil {
+		changed = make(map[string]time.Time)
+	}
+	events, _ := watch.ReadEventLog(root)
+	for _, e := range events {
+		if e.Time.After(changed[e.Path]) {
+			changed[e.Path] = e.Time
+		}
+	}
+	return changed
+}
+
+// hotHubsReport formats get_hubs output ranked by importer recency
+func hotHubsReport(fg *scanner.FileGraph, lastChanged map[string]time.Time, now time.Time) string {
+	hubs := fg.HotHubs(lastChanged, now)
+	if len(hubs) == 0 {
+		return noHubsMessage(fg)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Hot Hubs (%d total) ===\n", len(hubs)))
+	sb.WriteString("Hubs ranked by how recently their importers changed: each importer counts 1 if edited now, halving every 7 days.\n\n")
+	for _, hub := range hubs {
+		sb.WriteString(fmt.Sprintf("  %s (%d importers, hot %.2f)\n", hub.Path, hub.Importers, hub.Score))
+		// Show the most recently changed importers
+		importers := append([]string(nil), fg.ImportersOf(hub.Path)...)
+		sort.SliceStable(importers, func(i, j int) bool {
+			return lastChanged[importers[i]].After(lastChanged[importers[j]])
+		})
+		for i, imp := range importers {
+			if i >= 3 {
+				sb.WriteString(fmt.Sprintf("      ... and %d more\n", len(importers)-3))
+				break
+			}
+			if t, ok := lastChanged[imp]; ok {
+				sb.WriteString(fmt.Sprintf("      <- %s (changed %s)\n", imp, t.Format("2006-01-02")))
+			} else {
+				sb.WriteString(fmt.Sprintf("      <- %s\n", imp))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// defaultContextLimit caps the import/importer lists in get_file_context
+const defaultContextLimit = 20
+
+// writeCappedList writes up to limit items with the given arrow prefix,
+// followed by a "... and N more" footer when the list is truncated
+func writeCappedList(sb *strings.Builder, items []string, limit int, arrow string) {
+	for i, item := range items {
+		if i >= limit {
+			sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(items)-limit))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("  %s %s\n", arrow, item))
+	}
+}
+
+func handleGetFileContext(ctx context.Context, req *mcp.CallToolRequest, input FileContextInput) (*mcp.CallToolResult, any, error) {
+	root, err := safeRoot(input.Path)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+	target, err := safeFile(root, input.File)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+	fg, err := scanner.BuildFileGraphCached(root)
 	if err != nil {
 		return errorResult("Failed to build file graph: " + err.Error()), nil, nil
 	}
 
-	file := input.File
-	imports := fg.Imports[file]
-	importers := fg.Importers[file]
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultContextLimit
+	}
+
+	file := resolveTarget(fg, target, input.DirIndex)
+	imports := displayLabels(fg.Imports[file], input.DirIndex)
+	importers := displayLabels(fg.ImportersOf(file), input.DirIndex)
 	isHub := fg.IsHub(file)
 	connected := fg.ConnectedFiles(file)
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("=== File Context: %s ===\n\n", file))
+	sb.WriteString(fmt.Sprintf("=== File Context: %s ===\n\n", displayLabels([]string{file}, input.DirIndex)[0]))
 
 	// Hub status
 	if isHub {
@@ -793,12 +1679,24 @@ func handleGetFileContext(ctx context.Context, req *mcp.CallToolRequest, input I
 		sb.WriteString("    Changes here affect many parts of the codebase.\n\n")
 	}
 
+	// Where the file sits relative to the hubs
+	upHub, upDist := fg.NearestHubDependency(file)
+	downHub, downDist := fg.NearestHubDependent(file)
+	if upHub != "" || downHub != "" {
+		sb.WriteString("NEAREST HUBS:\n")
+		if upHub != "" {
+			sb.WriteString(fmt.Sprintf("  depends on:     %s (%d hop(s))\n", displayLabels([]string{upHub}, input.DirIndex)[0], upDist))
+		}
+		if downHub != "" {
+			sb.WriteString(fmt.Sprintf("  depended on by: %s (%d hop(s))\n", displayLabels([]string{downHub}, input.DirIndex)[0], downDist))
+		}
+		sb.WriteString("\n")
+	}
+
 	// What this file imports
 	if len(imports) > 0 {
 		sb.WriteString(fmt.Sprintf("IMPORTS (%d files):\n", len(imports)))
-		for _, imp := range imports {
-			sb.WriteString(fmt.Sprintf("  -> %s\n", imp))
-		}
+		writeCappedList(&sb, imports, limit, "->")
 		sb.WriteString("\n")
 	} else {
 		sb.WriteString("IMPORTS: none (leaf file)\n\n")
@@ -807,9 +1705,7 @@ func handleGetFileContext(ctx context.Context, req *mcp.CallToolRequest, input I
 	// What imports this file
 	if len(importers) > 0 {
 		sb.WriteString(fmt.Sprintf("IMPORTED BY (%d files):\n", len(importers)))
-		for _, imp := range importers {
-			sb.WriteString(fmt.Sprintf("  <- %s\n", imp))
-		}
+		writeCappedList(&sb, importers, limit, "<-")
 		sb.WriteString("\n")
 	} else {
 		sb.WriteString("IMPORTED BY: none (entry point or unused)\n\n")
@@ -820,3 +1716,217 @@ func handleGetFileContext(ctx context.Context, req *mcp.CallToolRequest, input I
 
 	return textResult(sb.String()), nil, nil
 }
+
+// defaultRelatedLimit caps the list in get_related
+const defaultRelatedLimit = 15
+
+func handleGetRelated(ctx context.Context, req *mcp.CallToolRequest, input RelatedInput) (*mcp.CallToolResult, any, error) {
+	root, err := safeRoot(input.Path)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+	file, err := safeFile(root, input.File)
+	if err != nil {
+		return invalidPathResult(err), nil, nil
+	}
+	fg, err := scanner.BuildFileGraphCached(root)
+	if err != nil {
+		return errorResult("Failed to build file graph: " + err.Error()), nil, nil
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultRelatedLimit
+	}
+	dist := fg.Distances(file)
+	if len(dist) == 0 {
+		return textResult(fmt.Sprintf("%s has no imports or importers in the dependency graph", file)), nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Related to %s: %d connected files ===\n", file, len(dist)))
+	hop := 0
+	for _, f := range fg.Nearest(file, limit) {
+		if dist[f] != hop {
+			hop = dist[f]
+			sb.WriteString(fmt.Sprintf("\n%d hop(s):\n", hop))
+		}
+		sb.WriteString(fmt.Sprintf("  %s (%d importers)\n", f, len(fg.ImportersOf(f))))
+	}
+	if len(dist) > limit {
+		sb.WriteString(fmt.Sprintf("\n... and %d more\n", len(dist)-limit))
+	}
+	return textResult(sb.String()), nil, nil
+}
+
+// defaultCoreLimit caps the ranked list in get_core
+const defaultCoreLimit = 15
+
+func handleGetCore(ctx context.Context, req *mcp.CallToolRequest, input CoreInput) (*mcp.CallToolResult, any, error) {
+	fg, err := scanner.BuildFileGraphCached(input.Path)
+	if err != nil {
+		return errorResult("Failed to build file graph: " + err.Error()), nil, nil
+	}
+	if len(fg.Imports) == 0 {
+		return textResult("No internal imports found - nothing to rank."), nil, nil
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultCoreLimit
+	}
+
+	core := fg.CoreFiles()
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Core Files (PageRank, top %d of %d) ===\n", min(limit, len(core)), len(core)))
+	sb.WriteString("Score is relative to an average file (1.00x). Higher = more central.\n\n")
+	avg := 1 / float64(len(core))
+	for i, f := range core {
+		if i >= limit {
+			break
+		}
+		hubNote := ""
+		if fg.IsHub(f.Path) {
+			hubNote = " ⚠️ HUB"
+		}
+		sb.WriteString(fmt.Sprintf("  %2d. %s  %.2fx (%d importers)%s\n", i+1, f.Path, f.Score/avg, len(fg.ImportersOf(f.Path)), hubNote))
+	}
+	return textResult(sb.String()), nil, nil
+}
+
+func handleGetUntested(ctx context.Context, req *mcp.CallToolRequest, input PathInput) (*mcp.CallToolResult, any, error) {
+	fg, err := scanner.BuildFileGraphCached(input.Path)
+	if err != nil {
+		return errorResult("Failed to build file graph: " + err.Error()), nil, nil
+	}
+
+	tc := scanner.TestMapping(fg)
+	if len(tc.Untested) == 0 {
+		return textResult("Every source file has a matching test."), nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Untested Files (%d, %d covered) ===\n", len(tc.Untested), len(tc.Tests)))
+	for _, f := range tc.Untested {
+		if fg.IsHub(f) {
+			sb.WriteString(fmt.Sprintf("  %s ⚠️ HUB (%d importers)\n", f, len(fg.ImportersOf(f))))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s\n", f))
+		}
+	}
+	return textResult(sb.String()), nil, nil
+}
+
+func handleGetBrokenImports(ctx context.Context, req *mcp.CallToolRequest, input PathInput) (*mcp.CallToolResult, any, error) {
+	fg, analyses, err := scanner.BuildFileGraphWithAnalyses(input.Path)
+	if err != nil {
+		return errorResult("Failed to build file graph: " + err.Error()), nil, nil
+	}
+
+	broken := scanner.FindBrokenImports(fg, analyses)
+	if len(broken) == 0 {
+		return textResult("No broken imports - every internal import resolves to a file."), nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Broken Imports (%d) ===\n", len(broken)))
+	for _, b := range broken {
+		sb.WriteString(fmt.Sprintf("  %s → %s (no file on disk)\n", b.File, b.Import))
+	}
+	return textResult(sb.String()), nil, nil
+}
+
+// defaultExternalHubsLimit caps the ranked list in get_external_hubs
+const defaultExternalHubsLimit = 20
+
+func handleGetExternalHubs(ctx context.Context, req *mcp.CallToolRequest, input ExternalHubsInput) (*mcp.CallToolResult, any, error) {
+	fg, analyses, err := scanner.BuildFileGraphWithAnalyses(input.Path)
+	if err != nil {
+		return errorResult("Failed to build file graph: " + err.Error()), nil, nil
+	}
+
+	hubs := scanner.FindExternalHubs(fg, analyses)
+	if len(hubs) == 0 {
+		return textResult("No external dependencies imported."), nil, nil
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultExternalHubsLimit
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== External Hubs (top %d of %d packages) ===\n", min(limit, len(hubs)), len(hubs)))
+	for i, h := range hubs {
+		if i >= limit {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("  %s (%s): %d files\n", h.Package, h.Language, len(h.Files)))
+	}
+	return textResult(sb.String()), nil, nil
+}
+
+func handleGetPackages(ctx context.Context, req *mcp.CallToolRequest, input PathInput) (*mcp.CallToolResult, scanner.PackageLayout, error) {
+	fg, err := scanner.BuildFileGraphCached(input.Path)
+	if err != nil {
+		return errorResult("Failed to build file graph: " + err.Error()), scanner.PackageLayout{}, nil
+	}
+	// The SDK returns the layout as structured content and JSON text
+	return nil, fg.PackageLayout(), nil
+}
+
+func handleGetFileGraph(ctx context.Context, req *mcp.CallToolRequest, input PathInput) (*mcp.CallToolResult, scanner.GraphExport, error) {
+	root, err := safeRoot(input.Path)
+	if err != nil {
+		return invalidPathResult(err), scanner.GraphExport{}, nil
+	}
+	focus, err := scanner.ConfigFocus(root)
+	if err != nil {
+		return errorResult("Config error: " + err.Error()), scanner.GraphExport{}, nil
+	}
+	fg, err := scanner.BuildFileGraphCached(root)
+	if err != nil {
+		return errorResult("Failed to build file graph: " + err.Error()), scanner.GraphExport{}, nil
+	}
+	// The SDK returns the graph as structured content and JSON text
+	return nil, fg.FocusGraph(focus).Export(), nil
+}
+
+func handleGetTodos(ctx context.Context, req *mcp.CallToolRequest, input TodosInput) (*mcp.CallToolResult, any, error) {
+	files, err := scanner.ScanFiles(input.Path, scanner.NewGitIgnoreCache(input.Path), nil, nil)
+	if err != nil {
+		return errorResult("Scan error: " + err.Error()), nil, nil
+	}
+
+	todos := scanner.FindTodos(input.Path, files)
+	var note string
+	if skipped := scanner.BinarySources(input.Path, files); len(skipped) > 0 {
+		note = fmt.Sprintf("(Skipped %d binary or non-UTF-8 file(s): %s)\n", len(skipped), strings.Join(skipped, ", "))
+	}
+	if len(todos) == 0 {
+		return textResult(note + "No TODO/FIXME/HACK/XXX markers found."), nil, nil
+	}
+
+	counts := scanner.TodoCounts(todos)
+	total := 0
+	var parts []string
+	for _, marker := range scanner.TodoMarkers {
+		if counts[marker] > 0 {
+			total += counts[marker]
+			parts = append(parts, fmt.Sprintf("%s %d", marker, counts[marker]))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("=== Debt Markers: %d in %d files (%s) ===\n", total, len(todos), strings.Join(parts, ", ")))
+	sb.WriteString(note)
+	for _, ft := range todos {
+		sb.WriteString(fmt.Sprintf("  %s (%d)\n", ft.Path, len(ft.Items)))
+		if input.Text {
+			for _, item := range ft.Items {
+				sb.WriteString(fmt.Sprintf("      L%d %s %s\n", item.Line, item.Marker, item.Text))
+			}
+		}
+	}
+	return textResult(sb.String()), nil, nil
+}
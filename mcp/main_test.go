@@ -0,0 +1,441 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"codemap/scanner"
+	"codemap/watch"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestWriteCappedList(t *testing.T) {
+	var importers []string
+	for i := 0; i < 45; i++ {
+		importers = append(importers, fmt.Sprintf("pkg/file%02d.go", i))
+	}
+
+	var sb strings.Builder
+	writeCappedList(&sb, importers, defaultContextLimit, "<-")
+	out := sb.String()
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != defaultContextLimit+1 {
+		t.Fatalf("Expected %d lines (cap + footer), got %d", defaultContextLimit+1, len(lines))
+	}
+	if !strings.Contains(lines[len(lines)-1], "... and 25 more") {
+		t.Errorf("Expected truncation footer, got %q", lines[len(lines)-1])
+	}
+	if strings.Contains(out, "file20.go") {
+		t.Error("Entries beyond the cap should not be listed")
+	}
+
+	// Short lists are printed in full without a footer
+	sb.Reset()
+	writeCappedList(&sb, importers[:3], defaultContextLimit, "->")
+	if strings.Contains(sb.String(), "more") {
+		t.Errorf("Short list should not have a footer: %q", sb.String())
+	}
+}
+
+func TestRoleBreakdown(t *testing.T) {
+	files := []scanner.FileInfo{
+		{Path: "main.go"}, {Path: "util.go"}, {Path: "util_test.go"}, {Path: "README.md"},
+	}
+	if got, want := roleBreakdown(files), "2 source, 1 test, 1 docs"; got != want {
+		t.Errorf("roleBreakdown = %q, want %q", got, want)
+	}
+}
+
+func TestMatchFilesSubstringVsFuzzy(t *testing.T) {
+	files := []scanner.FileInfo{
+		{Path: "src/models/user.ts"},
+		{Path: "src/controllers/user_controller.ts"},
+		{Path: "src/controllers/order_controller.ts"},
+	}
+
+	if got := matchFiles(files, "usrctrl", false); len(got) != 0 {
+		t.Errorf("Substring match should not find an abbreviation, got %v", got)
+	}
+
+	got := matchFiles(files, "usrctrl", true)
+	if len(got) != 1 || got[0] != "src/controllers/user_controller.ts" {
+		t.Errorf("Fuzzy match = %v, want [src/controllers/user_controller.ts]", got)
+	}
+
+	// Plain substrings still work in both modes
+	if got := matchFiles(files, "controller", false); len(got) != 2 {
+		t.Errorf("Substring match = %v, want 2 controllers", got)
+	}
+	if got := matchFiles(files, "controller", true); len(got) != 2 {
+		t.Errorf("Fuzzy match = %v, want 2 controllers", got)
+	}
+}
+
+func TestGetSubtree(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"api/handlers.go", "api/v1/routes.go", "web/app.ts", "main.go"} {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, _, err := handleGetSubtree(context.Background(), nil, SubtreeInput{Path: root, Subdir: "api"})
+	if err != nil || res.IsError {
+		t.Fatalf("get_subtree failed: %v %v", err, res.Content)
+	}
+	out := res.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{"handlers.go", "routes.go", "v1/"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in subtree output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"app.ts", "main.go", "web/"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Did not expect %q outside the subtree:\n%s", unwanted, out)
+		}
+	}
+
+	for _, bad := range []string{"../", "api/../..", "main.go", ""} {
+		res, _, _ := handleGetSubtree(context.Background(), nil, SubtreeInput{Path: root, Subdir: bad})
+		if !res.IsError {
+			t.Errorf("Expected subdir %q to be rejected", bad)
+		}
+	}
+}
+
+func TestSuggestLocation(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"app/controllers/users_controller.rb", "app/controllers/orders_controller.rb", "app/models/user.rb", "lib/tasks.rb"} {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("class X\nend\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, _, err := handleSuggestLocation(context.Background(), nil, SuggestLocationInput{Path: root, Description: "controller"})
+	if err != nil || res.IsError {
+		t.Fatalf("suggest_location failed: %v %v", err, res.Content)
+	}
+	out := res.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(out, "app/controllers/  2 of 2 files match") {
+		t.Errorf("Expected app/controllers/ suggested:\n%s", out)
+	}
+	if strings.Contains(out, "app/models/") || strings.Contains(out, "lib/") {
+		t.Errorf("Did not expect unrelated directories:\n%s", out)
+	}
+}
+
+func TestActivityReportBytes(t *testing.T) {
+	now := time.Now()
+	recent := []watch.Event{
+		{Time: now.Add(-2 * time.Minute), Op: "WRITE", Path: "assets/data.json", Delta: 10, SizeDelta: 1024},
+		{Time: now.Add(-time.Minute), Op: "WRITE", Path: "assets/data.json", Delta: 2, SizeDelta: 512},
+		{Time: now, Op: "WRITE", Path: "main.go", Delta: -3, SizeDelta: -300},
+	}
+
+	plain := activityReport("/tmp/proj", "Last 30 minutes", recent, false)
+	if strings.Contains(plain, "KB") || strings.Contains(plain, "byte change") {
+		t.Errorf("Byte deltas should be hidden by default:\n%s", plain)
+	}
+
+	out := activityReport("/tmp/proj", "Last 30 minutes", recent, true)
+	for _, want := range []string{"+1.5KB", "-300.0B", "Net byte change: +1.2KB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in report:\n%s", want, out)
+		}
+	}
+}
+
+func TestActivityEventsSinceStart(t *testing.T) {
+	now := time.Now()
+	events := []watch.Event{
+		{Time: now.Add(-3 * time.Hour), Op: "CREATE", Path: "old.go"},
+		{Time: now.Add(-45 * time.Minute), Op: "WRITE", Path: "main.go"},
+		{Time: now.Add(-time.Minute), Op: "WRITE", Path: "main.go"},
+	}
+
+	if got := activityEvents(events, 30, false, now); len(got) != 1 {
+		t.Errorf("Expected 1 event in the last 30 minutes, got %d", len(got))
+	}
+	if got := activityEvents(events, 30, true, now); len(got) != len(events) {
+		t.Errorf("Expected since_start to return all %d events, got %d", len(events), len(got))
+	}
+
+	out := activityReport("/tmp/proj", "Since watcher start at 09:00:00", events, false)
+	if !strings.Contains(out, "=== Activity: Since watcher start at 09:00:00 ===") {
+		t.Errorf("Expected the since-start header:\n%s", out)
+	}
+}
+
+func TestImportersDirIndexLabel(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "src", "components"), 0755)
+	os.WriteFile(filepath.Join(root, "src", "components", "index.ts"), []byte("export {}\n"), 0644)
+
+	fg := &scanner.FileGraph{
+		Root:  root,
+		Files: []string{"src/app.ts", "src/components/index.ts", "src/pages/index.tsx"},
+		Importers: map[string][]string{
+			"src/components/index.ts": {"src/app.ts", "src/pages/index.tsx"},
+		},
+	}
+
+	// Off by default: real file paths
+	out := importersReport(fg, "src/components/index.ts", false, false)
+	if !strings.Contains(out, "'src/components/index.ts'") || !strings.Contains(out, "src/pages/index.tsx") {
+		t.Errorf("default output should use real paths:\n%s", out)
+	}
+
+	// On: index files shown as their directory, and the directory resolves as a target
+	for _, target := range []string{"src/components/index.ts", "src/components/", "src/components"} {
+		out = importersReport(fg, target, true, false)
+		if !strings.HasPrefix(out, "2 files import 'src/components/':") {
+			t.Errorf("target %q: want directory label, got:\n%s", target, out)
+		}
+		if !strings.Contains(out, "\nsrc/pages/\n") && !strings.HasSuffix(out, "\nsrc/pages/") {
+			t.Errorf("target %q: importer index file should be labeled src/pages/:\n%s", target, out)
+		}
+		if strings.Contains(out, "index.ts") {
+			t.Errorf("target %q: index file names should be hidden:\n%s", target, out)
+		}
+	}
+}
+
+func TestBulkImportersMatchesSingle(t *testing.T) {
+	fg := &scanner.FileGraph{
+		Root: t.TempDir(),
+		Importers: map[string][]string{
+			"types.go":  {"a.go", "b.go", "c.go", "d.go"},
+			"util.go":   {"a.go"},
+			"config.go": {"main.go", "server.go"},
+		},
+	}
+	files := []string{"types.go", "util.go", "config.go", "leaf.go"}
+
+	out := bulkImportersReport(fg, files, 20)
+
+	// Split into per-file sections and compare with individual lookups
+	sections := strings.Split(out, "\n\n")[1:]
+	if len(sections) != len(files) {
+		t.Fatalf("got %d sections, want %d:\n%s", len(sections), len(files), out)
+	}
+	for i, file := range files {
+		lines := strings.Split(strings.TrimSpace(sections[i]), "\n")
+		if !strings.HasPrefix(lines[0], file+" ") && lines[0] != file+": no importers" {
+			t.Errorf("section %d header = %q, want %s first", i, lines[0], file)
+		}
+		var got []string
+		for _, l := range lines[1:] {
+			got = append(got, strings.TrimPrefix(strings.TrimSpace(l), "<- "))
+		}
+
+		single := importersReport(fg, file, false, false)
+		var want []string
+		if !strings.HasPrefix(single, "No files import") {
+			want = strings.Split(single, "\n")[1:]
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: bulk importers %v, single lookup %v", file, got, want)
+		}
+	}
+	if !strings.Contains(out, "types.go (4 importers) ⚠️ HUB FILE") {
+		t.Errorf("hub should be marked:\n%s", out)
+	}
+
+	// Per-file cap
+	capped := bulkImportersReport(fg, []string{"types.go"}, 2)
+	if !strings.Contains(capped, "... and 2 more") || strings.Contains(capped, "c.go") {
+		t.Errorf("expected types.go capped at 2:\n%s", capped)
+	}
+}
+
+func TestSafePathGuard(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "project")
+	if err := os.MkdirAll(filepath.Join(root, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pkg", "a.go"), []byte("package pkg\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(parent, "secret.txt")
+	if err := os.WriteFile(secret, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(parent, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	for _, ok := range []string{"pkg/a.go", "./pkg/../pkg/a.go", filepath.Join(root, "pkg", "a.go"), "pkg/deleted.go"} {
+		if got, err := safeFile(root, ok); err != nil || got == "" {
+			t.Errorf("safeFile(%q) = %q, %v; want accepted", ok, got, err)
+		}
+	}
+	// Results are graph keys, which use OS separators
+	if got, _ := safeFile(root, "pkg/a.go"); got != filepath.Join("pkg", "a.go") {
+		t.Errorf("safeFile(pkg/a.go) = %q, want %q", got, filepath.Join("pkg", "a.go"))
+	}
+
+	bad := []string{"../secret.txt", "pkg/../../secret.txt", secret, "escape/secret.txt", "escape/missing.go", ""}
+	for _, file := range bad {
+		if _, err := safeFile(root, file); err == nil {
+			t.Errorf("safeFile(%q) accepted a path outside the root", file)
+		}
+
+		results := map[string]*mcp.CallToolResult{}
+		results["get_importers"], _, _ = handleGetImporters(context.Background(), nil, ImportersInput{Path: root, File: file})
+		results["get_file_context"], _, _ = handleGetFileContext(context.Background(), nil, FileContextInput{Path: root, File: file})
+		results["get_importers_bulk"], _, _ = handleGetImportersBulk(context.Background(), nil, BulkImportersInput{Path: root, Files: []string{"pkg/a.go", file}})
+		for tool, res := range results {
+			if !res.IsError || !strings.HasPrefix(res.Content[0].(*mcp.TextContent).Text, invalidPathCode) {
+				t.Errorf("%s: expected %s for %q, got %v", tool, invalidPathCode, file, res.Content)
+			}
+		}
+	}
+
+	res, _, _ := handleFindFile(context.Background(), nil, FindInput{Path: secret, Pattern: "secret"})
+	if !res.IsError || !strings.HasPrefix(res.Content[0].(*mcp.TextContent).Text, invalidPathCode) {
+		t.Errorf("find_file: expected %s for a non-directory path, got %v", invalidPathCode, res.Content)
+	}
+}
+
+func TestImportersHubMatchesIsHub(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "types.go"), []byte("package core\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fg := &scanner.FileGraph{
+		Root: root,
+		Importers: map[string][]string{
+			"types.go": {"a.go", "b.go", "examples/demo.go"},
+		},
+		HubIgnoreImporters: []string{"examples/**"},
+	}
+	// Three raw importers reach the default threshold, but one is ignored
+	if fg.IsHub("types.go") {
+		t.Fatal("Expected the ignored importer to keep types.go below the hub threshold")
+	}
+	out := importersReport(fg, "types.go", false, false)
+	if !strings.HasPrefix(out, "3 files import 'types.go':") || strings.Contains(out, "HUB") {
+		t.Errorf("Expected all 3 importers listed without a hub mark, as get_hubs sees it:\n%s", out)
+	}
+
+	fg.HubIgnoreImporters = nil
+	if out := importersReport(fg, "types.go", false, false); !strings.Contains(out, "⚠️ HUB FILE") {
+		t.Errorf("Expected a hub mark once every importer counts:\n%s", out)
+	}
+}
+
+func TestImportersGroupedByLanguageAndRole(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "core"), 0755)
+	if err := os.WriteFile(filepath.Join(root, "core", "types.go"), []byte("package core\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fg := &scanner.FileGraph{
+		Root: root,
+		Importers: map[string][]string{
+			"core/types.go": {"api/server.go", "api/server_test.go", "gen/types.pb.go", "cli/main.go", "core/types_test.go", "web/client.ts"},
+		},
+		Imports: map[string][]string{},
+	}
+
+	out := importersReport(fg, "core/types.go", false, true)
+	want := `6 files import 'core/types.go': ⚠️ HUB FILE
+Go source (2):
+  api/server.go
+  cli/main.go
+
+Go test (2):
+  api/server_test.go
+  core/types_test.go
+
+Go generated (1):
+  gen/types.pb.go
+
+TypeScript source (1):
+  web/client.ts`
+	if out != want {
+		t.Errorf("grouped importers =\n%s\nwant\n%s", out, want)
+	}
+
+	flat := importersReport(fg, "core/types.go", false, false)
+	if strings.Contains(flat, "(2):") || !strings.Contains(flat, "\napi/server.go\napi/server_test.go\n") {
+		t.Errorf("Expected the default list to stay flat:\n%s", flat)
+	}
+}
+
+func TestStructureHonorsConfigFocus(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"services/api/handlers.go", "services/web/app.ts", "main.go", ".codemap/config.toml"} {
+		path := filepath.Join(root, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("package x\n"), 0644)
+	}
+	os.WriteFile(filepath.Join(root, ".codemap", "config.toml"), []byte("focus = \"services/api\"\n"), 0644)
+
+	res, _, _ := handleGetStructure(context.Background(), nil, StructureInput{Path: root, NoHubs: true})
+	text := res.Content[0].(*mcp.TextContent).Text
+	if res.IsError || !strings.Contains(text, "handlers.go") || strings.Contains(text, "app.ts") || strings.Contains(text, "main.go") {
+		t.Errorf("Expected the tree scoped to services/api:\n%s", text)
+	}
+
+	os.WriteFile(filepath.Join(root, ".codemap", "config.toml"), []byte("focus = \"../other\"\n"), 0644)
+	if res, _, _ := handleGetStructure(context.Background(), nil, StructureInput{Path: root, NoHubs: true}); !res.IsError {
+		t.Error("Expected a focus outside the root to be rejected")
+	}
+}
+
+func TestStructureHubSummaryOptions(t *testing.T) {
+	importers := func(n int) []string {
+		var list []string
+		for i := 0; i < n; i++ {
+			list = append(list, fmt.Sprintf("user%d.go", i))
+		}
+		return list
+	}
+	fg := &scanner.FileGraph{Importers: map[string][]string{
+		"b.go":    importers(4),
+		"a.go":    importers(4),
+		"core.go": importers(9),
+		"c.go":    importers(3),
+		"leaf.go": importers(1),
+	}}
+
+	got := hubSummary(fg, 2)
+	want := "\n⚠️  HUB FILES (high-impact, 3+ dependents):\n" +
+		"   core.go (9 importers)\n" +
+		"   a.go (4 importers)\n" +
+		"   ... and 2 more hubs\n"
+	if got != want {
+		t.Errorf("hubSummary(limit 2) =\n%s\nwant\n%s", got, want)
+	}
+	if got := hubSummary(fg, 10); strings.Contains(got, "more hubs") || !strings.Contains(got, "   b.go (4 importers)\n   c.go") {
+		t.Errorf("hubSummary(limit 10) should list all hubs with ties by path:\n%s", got)
+	}
+	if got := hubSummary(&scanner.FileGraph{}, 5); got != "" {
+		t.Errorf("hubSummary with no hubs = %q, want empty", got)
+	}
+
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+	res, _, _ := handleGetStructure(context.Background(), nil, StructureInput{Path: root, NoHubs: true})
+	text := res.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "main.go") || strings.Contains(text, "HUB FILES") {
+		t.Errorf("no_hubs output should be the tree alone:\n%s", text)
+	}
+}
@@ -2,8 +2,12 @@ package render
 
 import (
 	"os"
+	"strconv"
 	"strings"
 
+	"codemap/scanner"
+
+	"github.com/mattn/go-runewidth"
 	"golang.org/x/term"
 )
 
@@ -26,18 +30,6 @@ const (
 	BoldGreen = "\033[1;32m"
 )
 
-// Asset extensions to exclude from "top large files"
-var assetExtensions = map[string]bool{
-	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".ico": true, ".webp": true,
-	".ttf": true, ".otf": true, ".woff": true, ".woff2": true, ".eot": true,
-	".mp3": true, ".wav": true, ".mp4": true, ".mov": true,
-	".zip": true, ".tar": true, ".gz": true, ".7z": true, ".rar": true,
-	".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true,
-	".exe": true, ".dll": true, ".so": true, ".dylib": true, ".bin": true,
-	".lock": true, ".resolved": true, ".sum": true,
-	".map": true, ".nib": true, ".xib": true, ".storyboard": true,
-}
-
 // GetFileColor returns ANSI color code based on file extension
 func GetFileColor(ext string) string {
 	ext = strings.ToLower(ext)
@@ -75,8 +67,12 @@ func GetFileColor(ext string) string {
 	}
 }
 
-// GetTerminalWidth returns terminal width or default
+// GetTerminalWidth returns terminal width or default.
+// A positive COLUMNS environment variable takes precedence over detection.
 func GetTerminalWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
 	width, _, err := term.GetSize(int(os.Stdout.Fd()))
 	if err != nil || width <= 0 {
 		return 80
@@ -84,17 +80,56 @@ func GetTerminalWidth() int {
 	return width
 }
 
-// CenterString centers a string in the given width
+// ResolveWidth returns width if it was set explicitly (--width), otherwise the
+// detected terminal width
+func ResolveWidth(width int) int {
+	if width > 0 {
+		return width
+	}
+	return GetTerminalWidth()
+}
+
+// DisplayWidth returns the number of terminal columns s occupies: one per
+// rune, two for wide (CJK) glyphs, none for combining marks
+func DisplayWidth(s string) int {
+	return runewidth.StringWidth(s)
+}
+
+// PadRight pads s with spaces to width columns (fmt's %-*s counts runes,
+// which misaligns wide glyphs)
+func PadRight(s string, width int) string {
+	if w := DisplayWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// CenterString centers a string in the given width (in display columns)
 func CenterString(s string, width int) string {
-	if len(s) >= width {
+	w := DisplayWidth(s)
+	if w >= width {
 		return s
 	}
-	leftPad := (width - len(s)) / 2
-	rightPad := width - len(s) - leftPad
+	leftPad := (width - w) / 2
+	rightPad := width - w - leftPad
 	return strings.Repeat(" ", leftPad) + s + strings.Repeat(" ", rightPad)
 }
 
+// widthIndex returns the byte length of the longest prefix of s that fits
+// in width display columns
+func widthIndex(s string, width int) int {
+	w := 0
+	for i, r := range s {
+		w += runewidth.RuneWidth(r)
+		if w > width {
+			return i
+		}
+	}
+	return len(s)
+}
+
 // IsAssetExtension returns true if the extension is an asset
+// (excluded from "top large files")
 func IsAssetExtension(ext string) bool {
-	return assetExtensions[strings.ToLower(ext)]
+	return scanner.IsAssetExtension(ext)
 }
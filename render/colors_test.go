@@ -173,6 +173,35 @@ func TestCenterStringLength(t *testing.T) {
 	}
 }
 
+func TestCenterStringMultibyte(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"café", 10, "   café   "},  // 4 columns, 5 bytes
+		{"東京", 10, "   東京   "},      // 4 columns (wide glyphs), 6 bytes
+		{"Ünïcødé", 9, " Ünïcødé "}, // 7 columns
+		{"日本語", 5, "日本語"},           // 6 columns: wider than width, unchanged
+	}
+	for _, tt := range tests {
+		got := CenterString(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("CenterString(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if DisplayWidth(tt.s) < tt.width && DisplayWidth(got) != tt.width {
+			t.Errorf("CenterString(%q, %d) is %d columns wide", tt.s, tt.width, DisplayWidth(got))
+		}
+	}
+
+	if got := PadRight("東京", 6); got != "東京  " {
+		t.Errorf("PadRight = %q, want 2 spaces after 4 columns", got)
+	}
+	if got := widthIndex("a東京b", 4); got != len("a東") {
+		t.Errorf("widthIndex = %d, want %d (a wide glyph must not be split)", got, len("a東"))
+	}
+}
+
 func TestGetTerminalWidth(t *testing.T) {
 	// This test just ensures GetTerminalWidth doesn't panic
 	// and returns a reasonable value
@@ -187,6 +216,19 @@ func TestGetTerminalWidth(t *testing.T) {
 	}
 }
 
+func TestResolveWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "132")
+	if got := GetTerminalWidth(); got != 132 {
+		t.Errorf("GetTerminalWidth() with COLUMNS=132 = %d, want 132", got)
+	}
+	if got := ResolveWidth(0); got != 132 {
+		t.Errorf("ResolveWidth(0) = %d, want COLUMNS value 132", got)
+	}
+	if got := ResolveWidth(60); got != 60 {
+		t.Errorf("ResolveWidth(60) = %d, want explicit width to win", got)
+	}
+}
+
 func TestANSIConstants(t *testing.T) {
 	// Verify ANSI constants are properly defined escape sequences
 	constants := map[string]string{
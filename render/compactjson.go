@@ -0,0 +1,116 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CompactJSON encodes v as JSON with every empty member dropped from its
+// object: "", 0, false, null, [], and {}, checked after the member's own
+// contents are compacted. Keys keep their order and array elements are
+// always kept, so positions still line up. Decoding the result treats an
+// absent member as its zero value, which is what the dropped one was.
+func CompactJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out bytes.Buffer
+	if _, err := compactValue(dec, &out); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// WriteCompactJSON writes v as CompactJSON followed by a newline, like
+// json.Encoder.Encode
+func WriteCompactJSON(w io.Writer, v any) error {
+	data, err := CompactJSON(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// compactValue copies the next JSON value from dec to out, dropping empty
+// object members, and reports whether the value itself is empty
+func compactValue(dec *json.Decoder, out *bytes.Buffer) (empty bool, err error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			out.WriteByte('{')
+			members := 0
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return false, err
+				}
+				key, _ := json.Marshal(keyTok.(string))
+				var value bytes.Buffer
+				empty, err := compactValue(dec, &value)
+				if err != nil {
+					return false, err
+				}
+				if empty {
+					continue
+				}
+				if members > 0 {
+					out.WriteByte(',')
+				}
+				out.Write(key)
+				out.WriteByte(':')
+				out.Write(value.Bytes())
+				members++
+			}
+			if _, err := dec.Token(); err != nil {
+				return false, err
+			}
+			out.WriteByte('}')
+			return members == 0, nil
+		case '[':
+			out.WriteByte('[')
+			elems := 0
+			for dec.More() {
+				if elems > 0 {
+					out.WriteByte(',')
+				}
+				if _, err := compactValue(dec, out); err != nil {
+					return false, err
+				}
+				elems++
+			}
+			if _, err := dec.Token(); err != nil {
+				return false, err
+			}
+			out.WriteByte(']')
+			return elems == 0, nil
+		}
+		return false, fmt.Errorf("unexpected %v in JSON", t)
+	case nil:
+		out.WriteString("null")
+		return true, nil
+	case bool:
+		out.WriteString(strconv.FormatBool(t))
+		return !t, nil
+	case json.Number:
+		out.WriteString(t.String())
+		f, err := t.Float64()
+		return err == nil && f == 0, nil
+	case string:
+		s, _ := json.Marshal(t)
+		out.Write(s)
+		return t == "", nil
+	}
+	return false, fmt.Errorf("unexpected JSON token %v", tok)
+}
@@ -0,0 +1,63 @@
+package render
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"codemap/scanner"
+)
+
+func TestCompactJSONMatchesFull(t *testing.T) {
+	project := scanner.Project{
+		Root: "/repo",
+		Mode: "tree",
+		Files: []scanner.FileInfo{
+			{Path: "main.go", Size: 120, Ext: ".go", IsNew: true, Added: 4},
+			{Path: "empty.go", Ext: ".go"},
+		},
+		Only: []string{"go"},
+	}
+
+	full, err := json.Marshal(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compact, err := CompactJSON(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compact) >= len(full) {
+		t.Errorf("Expected compact JSON to be smaller, got %d bytes vs %d", len(compact), len(full))
+	}
+	for _, dropped := range []string{`"animate"`, `"size":0`, `"removed"`} {
+		if strings.Contains(string(compact), dropped) {
+			t.Errorf("Expected %s to be dropped, got %s", dropped, compact)
+		}
+	}
+	if !strings.HasPrefix(string(compact), `{"root":"/repo","mode":"tree","files":[`) {
+		t.Errorf("Expected keys in struct order, got %s", compact)
+	}
+
+	var fromFull, fromCompact scanner.Project
+	if err := json.Unmarshal(full, &fromFull); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(compact, &fromCompact); err != nil {
+		t.Fatalf("Compact JSON doesn't decode: %v\n%s", err, compact)
+	}
+	if !reflect.DeepEqual(fromFull, fromCompact) {
+		t.Errorf("Compact JSON decodes differently:\nfull    %+v\ncompact %+v", fromFull, fromCompact)
+	}
+}
+
+func TestCompactJSONKeepsArrayElements(t *testing.T) {
+	got, err := CompactJSON(map[string]any{"a": []any{0, "", map[string]any{"x": false}}, "b": map[string]any{"c": nil}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":[0,"",{}]}`; string(got) != want {
+		t.Errorf("CompactJSON = %s, want %s", got, want)
+	}
+}
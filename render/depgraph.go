@@ -68,7 +68,11 @@ func Depgraph(project scanner.DepsProject) {
 	}
 
 	// Use BuildFileGraph for accurate file-level dependency resolution
-	fg, err := scanner.BuildFileGraph(project.Root)
+	fg := project.Graph
+	var err error
+	if fg == nil {
+		fg, err = scanner.BuildFileGraph(project.Root)
+	}
 	var internalDeps map[string][]string
 	var depCounts map[string]int
 	if err == nil && fg != nil {
@@ -78,7 +82,14 @@ func Depgraph(project scanner.DepsProject) {
 			displayedFiles[f.Path] = true
 		}
 
-		// Filter imports to only include displayed files
+		// With a changeset, neighbors are shown for context but edges
+		// between two of them are not part of the change
+		touchesChange := func(a, b string) bool {
+			return project.Changed == nil || project.Changed[a] || project.Changed[b]
+		}
+
+		// Filter imports to only include displayed files - plus, with a
+		// focus, edges that leave it for the rest of the repo
 		internalDeps = make(map[string][]string)
 		for file, imports := range fg.Imports {
 			if !displayedFiles[file] {
@@ -86,7 +97,10 @@ func Depgraph(project scanner.DepsProject) {
 			}
 			var filtered []string
 			for _, imp := range imports {
-				if displayedFiles[imp] {
+				if !touchesChange(file, imp) {
+					continue
+				}
+				if displayedFiles[imp] || (project.Focus != "" && !scanner.InFocus(imp, project.Focus)) {
 					filtered = append(filtered, imp)
 				}
 			}
@@ -95,15 +109,19 @@ func Depgraph(project scanner.DepsProject) {
 			}
 		}
 
-		// Count importers only among displayed files
+		// Count importers only among displayed files, leaving out those
+		// excluded from hub counting
+		counts := func(file, imp string) bool {
+			return displayedFiles[imp] && touchesChange(file, imp) && !fg.IgnoredForHubs(imp)
+		}
 		depCounts = make(map[string]int)
-		for file, importers := range fg.Importers {
-			if !displayedFiles[file] {
+		for file := range displayedFiles {
+			if fg.Minified[file] {
 				continue
 			}
 			count := 0
-			for _, imp := range importers {
-				if displayedFiles[imp] {
+			for _, imp := range fg.ImportersOf(file) {
+				if counts(file, imp) {
 					count++
 				}
 			}
@@ -111,6 +129,30 @@ func Depgraph(project scanner.DepsProject) {
 				depCounts[file] = count
 			}
 		}
+
+		// With --dedupe-hubs, a barrel and the module it re-exports are one
+		// hub: the target gets both importer sets, minus the barrel
+		if project.DedupeHubs {
+			for target, barrel := range fg.FoldedBarrels() {
+				if !displayedFiles[barrel] || !displayedFiles[target] {
+					continue
+				}
+				seen := make(map[string]bool)
+				for _, f := range []string{target, barrel} {
+					for _, imp := range fg.ImportersOf(f) {
+						if imp != barrel && counts(f, imp) {
+							seen[imp] = true
+						}
+					}
+				}
+				delete(depCounts, barrel)
+				if len(seen) > 0 {
+					depCounts[target] = len(seen)
+				} else {
+					delete(depCounts, target)
+				}
+			}
+		}
 	} else {
 		internalDeps = make(map[string][]string)
 		depCounts = make(map[string]int)
@@ -157,7 +199,7 @@ func Depgraph(project scanner.DepsProject) {
 
 	// Calculate box width
 	title := fmt.Sprintf("%s - Dependency Flow", projectName)
-	maxWidth := len(title) + 6
+	maxWidth := DisplayWidth(title) + 6
 
 	// Format dep lines
 	var depLines []string
@@ -171,15 +213,15 @@ func Depgraph(project scanner.DepsProject) {
 			}
 			line := fmt.Sprintf("%s: %s", label, strings.Join(names, ", "))
 			depLines = append(depLines, line)
-			if len(line)+4 > maxWidth {
-				maxWidth = len(line) + 4
+			if DisplayWidth(line)+4 > maxWidth {
+				maxWidth = DisplayWidth(line) + 4
 			}
 		}
 	}
 
-	// Cap at 80
-	if maxWidth > 80 {
-		maxWidth = 80
+	// Cap at 80, or the output width if narrower (never below 20 so lines can wrap)
+	if limit := max(20, min(80, ResolveWidth(project.Width))); maxWidth > limit {
+		maxWidth = limit
 	}
 	innerWidth := maxWidth - 2
 
@@ -193,17 +235,17 @@ func Depgraph(project scanner.DepsProject) {
 		contentWidth := innerWidth - 2
 
 		for _, line := range depLines {
-			for len(line) > contentWidth {
-				breakAt := strings.LastIndex(line[:contentWidth], ", ")
+			for DisplayWidth(line) > contentWidth {
+				breakAt := strings.LastIndex(line[:widthIndex(line, contentWidth)], ", ")
 				if breakAt == -1 {
-					breakAt = contentWidth - 1
+					breakAt = widthIndex(line, contentWidth-1)
 				} else {
 					breakAt++
 				}
-				fmt.Printf("│ %-*s │\n", contentWidth, line[:breakAt])
+				fmt.Printf("│ %s │\n", PadRight(line[:breakAt], contentWidth))
 				line = "    " + strings.TrimLeft(line[breakAt:], " ")
 			}
-			fmt.Printf("│ %-*s │\n", contentWidth, line)
+			fmt.Printf("│ %s │\n", PadRight(line, contentWidth))
 		}
 	}
 
@@ -256,7 +298,15 @@ func Depgraph(project scanner.DepsProject) {
 				continue
 			}
 
-			if len(targets) == 1 {
+			if project.ChainDepth > 0 {
+				chains, more := dependencyChains(internalDeps, f.Path, project.ChainDepth, maxChainsPerFile)
+				for _, c := range chains {
+					fmt.Printf("  %s\n", formatChain(c, nameNoExt, extPattern))
+				}
+				if more {
+					fmt.Printf("  %s   + more chains\n", strings.Repeat(" ", len(nameNoExt)))
+				}
+			} else if len(targets) == 1 {
 				t := targets[0]
 				tName := extPattern.ReplaceAllString(t, "")
 
@@ -350,6 +400,9 @@ func Depgraph(project scanner.DepsProject) {
 			fmt.Printf("HUBS: %s\n", strings.Join(hubStrs, ", "))
 		}
 	}
+	if fg != nil && len(fg.Minified) > 0 {
+		fmt.Printf("(%d minified file(s) not counted as hubs; set include_minified = true to count them)\n", len(fg.Minified))
+	}
 
 	// Summary
 	totalFuncs := 0
@@ -363,3 +416,72 @@ func Depgraph(project scanner.DepsProject) {
 	fmt.Printf("%d files · %d functions · %d deps\n", len(files), totalFuncs, internalCount)
 	fmt.Println()
 }
+
+// maxChainsPerFile caps --chain-depth output per source file
+const maxChainsPerFile = 8
+
+// depChain is one path through the import graph starting at a source file
+type depChain struct {
+	nodes []string // file paths, source first
+	cut   bool     // stopped at the depth limit with more dependencies below
+	cycle bool     // last node is already earlier in the chain
+}
+
+// dependencyChains walks deps from start up to depth hops and returns every
+// maximal chain (in import order), at most limit of them, and whether any
+// were left out. The walk stops at the first chain past limit: counting the
+// rest would enumerate every path, which grows exponentially with depth.
+func dependencyChains(deps map[string][]string, start string, depth, limit int) ([]depChain, bool) {
+	var chains []depChain
+	more := false
+	onPath := map[string]bool{start: true}
+
+	add := func(c depChain) {
+		if len(chains) < limit {
+			chains = append(chains, c)
+		} else {
+			more = true
+		}
+	}
+	var walk func(path []string)
+	walk = func(path []string) {
+		last := path[len(path)-1]
+		next := deps[last]
+		hops := len(path) - 1
+		if len(next) == 0 || hops >= depth {
+			add(depChain{nodes: append([]string(nil), path...), cut: len(next) > 0})
+			return
+		}
+		for _, n := range next {
+			if more {
+				return
+			}
+			if onPath[n] {
+				add(depChain{nodes: append(append([]string(nil), path...), n), cycle: true})
+				continue
+			}
+			onPath[n] = true
+			walk(append(path, n))
+			onPath[n] = false
+		}
+	}
+	walk([]string{start})
+	return chains, more
+}
+
+// formatChain renders a chain as "a ───▶ b ───▶ c", with a trailing "…" when
+// cut at the depth limit and "↺" when it loops back
+func formatChain(c depChain, sourceName string, extPattern *regexp.Regexp) string {
+	parts := []string{sourceName}
+	for _, n := range c.nodes[1:] {
+		parts = append(parts, extPattern.ReplaceAllString(n, ""))
+	}
+	out := strings.Join(parts, " ───▶ ")
+	if c.cut {
+		out += " ───▶ …"
+	}
+	if c.cycle {
+		out += " ↺"
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"codemap/scanner"
+)
+
+func TestDependencyChains(t *testing.T) {
+	deps := map[string][]string{
+		"api/server.go":   {"api/routes.go"},
+		"api/routes.go":   {"core/service.go"},
+		"core/service.go": {"db/store.go"},
+	}
+	ext := regexp.MustCompile(`\.[^.]+$`)
+
+	chains, more := dependencyChains(deps, "api/server.go", 3, maxChainsPerFile)
+	if len(chains) != 1 || more {
+		t.Fatalf("Expected one chain, got %d (more: %v)", len(chains), more)
+	}
+	if got := formatChain(chains[0], "server", ext); got != "server ───▶ api/routes ───▶ core/service ───▶ db/store" {
+		t.Errorf("depth=3 chain = %q", got)
+	}
+
+	chains, _ = dependencyChains(deps, "api/server.go", 1, maxChainsPerFile)
+	if got := formatChain(chains[0], "server", ext); got != "server ───▶ api/routes ───▶ …" {
+		t.Errorf("depth=1 chain = %q, want truncated after one hop", got)
+	}
+}
+
+func TestDependencyChainsCycleAndCap(t *testing.T) {
+	deps := map[string][]string{
+		"a.go": {"b.go"},
+		"b.go": {"a.go"},
+	}
+	chains, _ := dependencyChains(deps, "a.go", 10, maxChainsPerFile)
+	if len(chains) != 1 || !chains[0].cycle || len(chains[0].nodes) != 3 {
+		t.Fatalf("Expected a single cycle-terminated chain a→b→a, got %+v", chains)
+	}
+
+	// A wide fan-out is capped
+	fan := map[string][]string{"hub.go": nil}
+	for _, n := range []string{"1", "2", "3", "4", "5"} {
+		fan["hub.go"] = append(fan["hub.go"], n+".go")
+	}
+	chains, more := dependencyChains(fan, "hub.go", 2, 3)
+	if len(chains) != 3 || !more {
+		t.Errorf("Expected 3 chains and more left out, got %d and %v", len(chains), more)
+	}
+	if _, more := dependencyChains(fan, "hub.go", 2, 5); more {
+		t.Error("Expected no more chains when all fit")
+	}
+
+	// A deep, wide graph has more chains than could ever be walked; the
+	// walk must stop once the limit is passed
+	wide := map[string][]string{}
+	for level := 0; level < 40; level++ {
+		for _, n := range []string{"a", "b", "c"} {
+			from := fmt.Sprintf("%s%d.go", n, level)
+			wide[from] = []string{fmt.Sprintf("a%d.go", level+1), fmt.Sprintf("b%d.go", level+1), fmt.Sprintf("c%d.go", level+1)}
+		}
+	}
+	if chains, more := dependencyChains(wide, "a0.go", 40, 3); len(chains) != 3 || !more {
+		t.Errorf("Expected 3 chains and more left out, got %d and %v", len(chains), more)
+	}
+}
+
+func TestDepgraphChangedNeighbors(t *testing.T) {
+	// api/handler.go changed; api/server.go imports it and core/store.go is
+	// imported by it. core/db.go only touches a neighbor, util/log.go nothing.
+	fg := &scanner.FileGraph{
+		Imports: map[string][]string{
+			"api/server.go":  {"api/handler.go"},
+			"api/handler.go": {"core/store.go"},
+			"core/store.go":  {"core/db.go"},
+			"util/log.go":    {"core/db.go"},
+		},
+		Importers: map[string][]string{
+			"api/handler.go": {"api/server.go"},
+			"core/store.go":  {"api/handler.go"},
+			"core/db.go":     {"core/store.go", "util/log.go"},
+		},
+	}
+	var all []scanner.FileAnalysis
+	for _, p := range []string{"api/server.go", "api/handler.go", "core/store.go", "core/db.go", "util/log.go"} {
+		all = append(all, scanner.FileAnalysis{Path: p, Language: "go"})
+	}
+	changed := map[string]bool{"api/handler.go": true}
+
+	files := scanner.FilterAnalysisToChangedNeighbors(all, changed, fg)
+	var shown []string
+	for _, f := range files {
+		shown = append(shown, f.Path)
+	}
+	if got := strings.Join(shown, ","); got != "api/server.go,api/handler.go,core/store.go" {
+		t.Fatalf("Displayed files = %s, want the changed file and its direct neighbors", got)
+	}
+
+	out := captureStdout(t, func() {
+		Depgraph(scanner.DepsProject{Root: "/tmp/proj", Files: files, Graph: fg, Changed: changed, Width: 80})
+	})
+	for _, want := range []string{"server ───▶ api/handler", "handler ───▶ core/store"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected edge %q in output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"db", "log"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Unexpected %q in output:\n%s", unwanted, out)
+		}
+	}
+}
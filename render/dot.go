@@ -0,0 +1,93 @@
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"codemap/scanner"
+)
+
+// dotHubColor fills hub nodes so they stand out in Graphviz output
+const dotHubColor = "#f4a261"
+
+// DepgraphDOT renders the project's internal import graph as a Graphviz
+// digraph: one node per file path, one edge per resolved import, and hubs
+// filled in color. Nodes and edges are sorted so the output is stable.
+// project.Graph is used when set, otherwise the graph is built from
+// project.Root; if that fails the digraph is empty.
+func DepgraphDOT(project scanner.DepsProject) string {
+	fg := projectGraph(project)
+	nodes, edges := sortedGraph(fg, true)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(filepath.Base(fg.Root)))
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	for _, n := range nodes {
+		path := filepath.ToSlash(n)
+		if fg.IsHub(n) {
+			fmt.Fprintf(&sb, "  %s [style=filled, fillcolor=%s, tooltip=%s];\n",
+				dotQuote(path), dotQuote(dotHubColor), dotQuote(fmt.Sprintf("hub: imported by %d files", len(fg.ImportersOf(n)))))
+		} else {
+			fmt.Fprintf(&sb, "  %s;\n", dotQuote(path))
+		}
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&sb, "  %s -> %s;\n", dotQuote(filepath.ToSlash(e[0])), dotQuote(filepath.ToSlash(e[1])))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// projectGraph is project.Graph, or the graph built from project.Root (an
+// empty one if that fails)
+func projectGraph(project scanner.DepsProject) *scanner.FileGraph {
+	if project.Graph != nil {
+		return project.Graph
+	}
+	fg, err := scanner.BuildFileGraph(project.Root)
+	if err != nil {
+		return &scanner.FileGraph{Root: project.Root}
+	}
+	return fg
+}
+
+// sortedGraph returns fg's nodes and import edges, sorted. Nodes are the
+// files at either end of an edge, plus every file in fg.Files with allFiles.
+func sortedGraph(fg *scanner.FileGraph, allFiles bool) ([]string, [][2]string) {
+	nodeSet := make(map[string]bool)
+	if allFiles {
+		for _, f := range fg.Files {
+			nodeSet[f] = true
+		}
+	}
+	var edges [][2]string
+	for from, targets := range fg.Imports {
+		nodeSet[from] = true
+		for _, to := range targets {
+			nodeSet[to] = true
+			edges = append(edges, [2]string{from, to})
+		}
+	}
+	nodes := make([]string, 0, len(nodeSet))
+	for n := range nodeSet {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+	return nodes, edges
+}
+
+// dotQuote makes s a DOT quoted string, escaping quotes, backslashes, and
+// line breaks
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"codemap/scanner"
+)
+
+func TestDepgraphDOT(t *testing.T) {
+	fg := &scanner.FileGraph{
+		Root:  "/tmp/myproject",
+		Files: []string{"main.go", "a.go", "b.go", `we"ird dir/x.go`, "util/util.go"},
+		Imports: map[string][]string{
+			"main.go":         {"util/util.go"},
+			"b.go":            {"util/util.go"},
+			"a.go":            {"util/util.go", `we"ird dir/x.go`},
+			`we"ird dir/x.go`: {"b.go"},
+		},
+		Importers: map[string][]string{
+			"util/util.go":    {"a.go", "b.go", "main.go"},
+			`we"ird dir/x.go`: {"a.go"},
+			"b.go":            {`we"ird dir/x.go`},
+		},
+	}
+
+	out := DepgraphDOT(scanner.DepsProject{Root: fg.Root, Graph: fg})
+	if out != DepgraphDOT(scanner.DepsProject{Root: fg.Root, Graph: fg}) {
+		t.Error("Expected deterministic output")
+	}
+	if !strings.HasPrefix(out, `digraph "myproject" {`) || !strings.HasSuffix(out, "}\n") {
+		t.Errorf("Expected a digraph named after the root, got:\n%s", out)
+	}
+
+	want := []string{
+		`"a.go";`,
+		`"util/util.go" [style=filled, fillcolor="#f4a261"`,
+		`"a.go" -> "util/util.go";`,
+		`"a.go" -> "we\"ird dir/x.go";`,
+		`"we\"ird dir/x.go" -> "b.go";`,
+	}
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("Expected %s in output:\n%s", w, out)
+		}
+	}
+	if strings.Contains(out, `"main.go" [style=filled`) {
+		t.Error("Expected only hubs to be filled")
+	}
+
+	// Edges come out sorted
+	if strings.Index(out, `"a.go" -> "util/util.go"`) > strings.Index(out, `"b.go" -> "util/util.go"`) {
+		t.Errorf("Expected edges sorted by source:\n%s", out)
+	}
+}
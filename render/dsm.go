@@ -0,0 +1,97 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"codemap/scanner"
+)
+
+// DSM prints a Design Structure Matrix: rows import columns, numbered so the
+// matrix stays narrow. Feedback cells (above the diagonal, i.e. inside an
+// import cycle) are red and long-range dependencies yellow.
+func DSM(m *scanner.DSM) {
+	fmt.Print(formatDSM(m, true))
+}
+
+// formatDSM renders m, with ANSI colors when color is set
+func formatDSM(m *scanner.DSM, color bool) string {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + Reset
+	}
+
+	n := len(m.Labels)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n", paint(Bold, fmt.Sprintf("Dependency Structure Matrix (%s level, %d entries)", m.Level, n)))
+	if n == 0 {
+		sb.WriteString("No files to show\n")
+		return sb.String()
+	}
+	sb.WriteString(paint(Dim, "Row imports column; cells count file-level imports") + "\n\n")
+
+	numWidth := len(strconv.Itoa(n))
+	labelWidth := 0
+	for _, l := range m.Labels {
+		labelWidth = max(labelWidth, DisplayWidth(l))
+	}
+	cellWidth := numWidth + 1
+	prefix := strings.Repeat(" ", numWidth+2+labelWidth+1)
+
+	sb.WriteString(prefix)
+	for j := range m.Labels {
+		fmt.Fprintf(&sb, "%*d", cellWidth, j+1)
+	}
+	sb.WriteString("\n")
+
+	for i, label := range m.Labels {
+		fmt.Fprintf(&sb, "%*d  %s ", numWidth, i+1, PadRight(label, labelWidth))
+		for j := range m.Labels {
+			pad := strings.Repeat(" ", cellWidth-1)
+			cell := m.Cells[i][j]
+			switch {
+			case i == j:
+				sb.WriteString(pad + paint(Dim, "■"))
+			case cell == 0:
+				sb.WriteString(pad + paint(Dim, "·"))
+			case m.IsFeedback(i, j):
+				sb.WriteString(pad + paint(BoldRed, dsmCount(cell)))
+			case m.IsLongRange(i, j):
+				sb.WriteString(pad + paint(Yellow, dsmCount(cell)))
+			default:
+				sb.WriteString(pad + dsmCount(cell))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	// Spell out the clusters that make up the feedback cells
+	var cycles []string
+	for start := 0; start < n; {
+		end := start
+		for end+1 < n && m.Groups[end+1] == m.Groups[start] {
+			end++
+		}
+		if end > start {
+			cycles = append(cycles, fmt.Sprintf("%d-%d (%s)", start+1, end+1, strings.Join(m.Labels[start:end+1], ", ")))
+		}
+		start = end + 1
+	}
+	sb.WriteString("\n")
+	if len(cycles) > 0 {
+		sb.WriteString(paint(BoldRed, "Cycles:") + " " + strings.Join(cycles, "; ") + "\n")
+	}
+	sb.WriteString(paint(Dim, "Legend: ") + paint(BoldRed, "red") + paint(Dim, " = feedback inside a cycle, ") + paint(Yellow, "yellow") + paint(Dim, " = long-range, + = 10 or more") + "\n")
+	return sb.String()
+}
+
+// dsmCount formats a cell count as one character
+func dsmCount(n int) string {
+	if n > 9 {
+		return "+"
+	}
+	return strconv.Itoa(n)
+}
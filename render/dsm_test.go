@@ -0,0 +1,33 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"codemap/scanner"
+)
+
+func TestFormatDSM(t *testing.T) {
+	m := &scanner.DSM{
+		Level:  scanner.DSMLevelFile,
+		Labels: []string{"a.go", "b.go", "main.go"},
+		Groups: []int{0, 0, 1},
+		Cells: [][]int{
+			{0, 1, 0},
+			{1, 0, 0},
+			{12, 1, 0},
+		},
+	}
+	out := formatDSM(m, false)
+	for _, want := range []string{
+		"(file level, 3 entries)",
+		"1  a.go     ■ 1 ·",
+		"2  b.go     1 ■ ·",
+		"3  main.go  + 1 ■",
+		"Cycles: 1-2 (a.go, b.go)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+}
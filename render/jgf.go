@@ -0,0 +1,97 @@
+package render
+
+import (
+	"path/filepath"
+	"sort"
+
+	"codemap/scanner"
+)
+
+// JGFDocument is the top-level JSON Graph Format object (https://jsongraphformat.info)
+type JGFDocument struct {
+	Graph JGFGraph `json:"graph"`
+}
+
+// JGFGraph is a single directed graph of file-level imports
+type JGFGraph struct {
+	ID       string             `json:"id,omitempty"`
+	Type     string             `json:"type,omitempty"`
+	Label    string             `json:"label,omitempty"`
+	Directed bool               `json:"directed"`
+	Metadata map[string]any     `json:"metadata,omitempty"`
+	Nodes    map[string]JGFNode `json:"nodes"`
+	Edges    []JGFEdge          `json:"edges"`
+}
+
+// JGFNode is one file, keyed by its relative path in JGFGraph.Nodes
+type JGFNode struct {
+	Label    string          `json:"label"`
+	Metadata JGFNodeMetadata `json:"metadata"`
+}
+
+// JGFNodeMetadata carries codemap's structural context for a file
+type JGFNodeMetadata struct {
+	Language  string `json:"language,omitempty"`
+	Imports   int    `json:"imports"`
+	Importers int    `json:"importers"`
+	Hub       bool   `json:"hub"`
+}
+
+// JGFEdge is an import: source imports target
+type JGFEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+}
+
+// JGF converts a file graph into a JSON Graph Format document.
+// Edges are sorted so the output is stable across runs.
+func JGF(fg *scanner.FileGraph) JGFDocument {
+	nodes := make(map[string]JGFNode)
+	addNode := func(path string) {
+		if _, ok := nodes[path]; ok {
+			return
+		}
+		nodes[path] = JGFNode{
+			Label: filepath.Base(path),
+			Metadata: JGFNodeMetadata{
+				Language:  scanner.DetectLanguageAt(fg.Root, path),
+				Imports:   len(fg.Imports[path]),
+				Importers: len(fg.ImportersOf(path)),
+				Hub:       fg.IsHub(path),
+			},
+		}
+	}
+
+	for _, path := range fg.Files {
+		addNode(path)
+	}
+
+	edges := []JGFEdge{}
+	for from, targets := range fg.Imports {
+		addNode(from)
+		for _, to := range targets {
+			addNode(to)
+			edges = append(edges, JGFEdge{Source: from, Target: to, Relation: "imports"})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		return edges[i].Target < edges[j].Target
+	})
+
+	graph := JGFGraph{
+		ID:       "codemap",
+		Type:     "dependency",
+		Label:    filepath.Base(fg.Root),
+		Directed: true,
+		Nodes:    nodes,
+		Edges:    edges,
+	}
+	if fg.Module != "" {
+		graph.Metadata = map[string]any{"module": fg.Module}
+	}
+	return JGFDocument{Graph: graph}
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"encoding/json"
+	"testing"
+
+	"codemap/scanner"
+)
+
+func TestJGF(t *testing.T) {
+	fg := &scanner.FileGraph{
+		Root:  "/tmp/myproject",
+		Files: []string{"main.go", "a.go", "b.go", "c.go", "util/util.go", "README.md"},
+		Imports: map[string][]string{
+			"main.go": {"util/util.go"},
+			"a.go":    {"util/util.go"},
+			"b.go":    {"util/util.go"},
+		},
+		Importers: map[string][]string{
+			"util/util.go": {"a.go", "b.go", "main.go"},
+		},
+	}
+
+	data, err := json.Marshal(JGF(fg))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	// Decode generically to check the shape against the JGF spec
+	var doc map[string]map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	graph, ok := doc["graph"]
+	if !ok {
+		t.Fatal("Expected top-level 'graph' object")
+	}
+	if graph["directed"] != true {
+		t.Error("Expected a directed graph")
+	}
+
+	nodes, ok := graph["nodes"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected 'nodes' to be an object keyed by id, got %T", graph["nodes"])
+	}
+	if len(nodes) != 6 {
+		t.Errorf("Expected 6 nodes, got %d", len(nodes))
+	}
+	util, ok := nodes["util/util.go"].(map[string]any)
+	if !ok {
+		t.Fatal("Expected node for util/util.go")
+	}
+	if util["label"] != "util.go" {
+		t.Errorf("Expected label util.go, got %v", util["label"])
+	}
+	meta := util["metadata"].(map[string]any)
+	if meta["hub"] != true || meta["importers"] != float64(3) || meta["language"] != "go" {
+		t.Errorf("Unexpected util.go metadata: %v", meta)
+	}
+
+	edges, ok := graph["edges"].([]any)
+	if !ok {
+		t.Fatalf("Expected 'edges' to be an array, got %T", graph["edges"])
+	}
+	if len(edges) != 3 {
+		t.Fatalf("Expected 3 edges, got %d", len(edges))
+	}
+	for _, e := range edges {
+		edge := e.(map[string]any)
+		src, _ := edge["source"].(string)
+		dst, _ := edge["target"].(string)
+		if _, ok := nodes[src]; !ok {
+			t.Errorf("Edge source %q is not a node", src)
+		}
+		if _, ok := nodes[dst]; !ok {
+			t.Errorf("Edge target %q is not a node", dst)
+		}
+	}
+	if first := edges[0].(map[string]any); first["source"] != "a.go" {
+		t.Errorf("Expected edges sorted by source, first was %v", first["source"])
+	}
+}
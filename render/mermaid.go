@@ -0,0 +1,59 @@
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"codemap/scanner"
+)
+
+// DepgraphMermaid renders the project's internal import graph as a Mermaid
+// flowchart for Markdown docs: one node per file that imports or is
+// imported (labelled with its path), one edge per resolved import, and hubs
+// tagged with the "hub" class so they can be restyled with classDef. Node
+// IDs follow sorted path order, so the output is stable across runs.
+func DepgraphMermaid(project scanner.DepsProject) string {
+	fg := projectGraph(project)
+	nodes, edges := sortedGraph(fg, false)
+
+	ids := mermaidIDs("n", nodes)
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	var hubs []string
+	for _, n := range nodes {
+		fmt.Fprintf(&sb, "  %s\n", mermaidNode(ids[n], filepath.ToSlash(n)))
+		if fg.IsHub(n) {
+			hubs = append(hubs, ids[n])
+		}
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&sb, "  %s --> %s\n", ids[e[0]], ids[e[1]])
+	}
+	sb.WriteString("  classDef hub fill:" + dotHubColor + ",stroke:#e76f51,font-weight:bold\n")
+	if len(hubs) > 0 {
+		fmt.Fprintf(&sb, "  class %s hub\n", strings.Join(hubs, ","))
+	}
+	return sb.String()
+}
+
+// mermaidIDs gives each of the sorted names a node ID, prefix plus its
+// index, so IDs are stable across runs and never need escaping
+func mermaidIDs(prefix string, names []string) map[string]string {
+	ids := make(map[string]string, len(names))
+	for i, n := range names {
+		ids[n] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return ids
+}
+
+// mermaidNode declares node id with a quoted label
+func mermaidNode(id, label string) string {
+	return id + "[" + mermaidQuote(label) + "]"
+}
+
+// mermaidQuote makes s a quoted Mermaid label; quotes become the #quot;
+// entity since Mermaid labels have no backslash escapes
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"codemap/scanner"
+)
+
+func TestDepgraphMermaid(t *testing.T) {
+	fg := &scanner.FileGraph{
+		Root:  "/tmp/myproject",
+		Files: []string{"main.go", "a.go", "b.go", "lonely.go", "scanner/types.go"},
+		Imports: map[string][]string{
+			"main.go": {"scanner/types.go"},
+			"a.go":    {"scanner/types.go"},
+			"b.go":    {"scanner/types.go", "a.go"},
+		},
+		Importers: map[string][]string{
+			"scanner/types.go": {"a.go", "b.go", "main.go"},
+			"a.go":             {"b.go"},
+		},
+	}
+
+	out := DepgraphMermaid(scanner.DepsProject{Root: fg.Root, Graph: fg})
+	want := `flowchart LR
+  n0["a.go"]
+  n1["b.go"]
+  n2["main.go"]
+  n3["scanner/types.go"]
+  n0 --> n3
+  n1 --> n0
+  n1 --> n3
+  n2 --> n3
+  classDef hub fill:#f4a261,stroke:#e76f51,font-weight:bold
+  class n3 hub
+`
+	if out != want {
+		t.Errorf("DepgraphMermaid =\n%s\nwant\n%s", out, want)
+	}
+	if strings.Contains(out, "lonely.go") {
+		t.Error("Expected files without imports or importers to be left out")
+	}
+
+	if got := mermaidQuote(`say "hi".go`); got != `"say #quot;hi#quot;.go"` {
+		t.Errorf("mermaidQuote = %s", got)
+	}
+
+	// The report's directory graph declares nodes the same way
+	dirs := mermaidDirGraph(&scanner.FileGraph{Imports: map[string][]string{
+		`cmd/"q"/main.go`: {"lib/util.go"},
+		"main.go":         {"lib/util.go"},
+	}})
+	for _, want := range []string{`    d0["(root)"]`, `    d1["cmd/#quot;q#quot;/"]`, `    d2["lib/"]`, "    d0 -->|1| d2"} {
+		if !strings.Contains(dirs, want+"\n") {
+			t.Errorf("Expected %q in the directory graph:\n%s", want, dirs)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"codemap/scanner"
+)
+
+// progressTick is how often the progress line is redrawn
+const progressTick = 100 * time.Millisecond
+
+// StartProgress redraws a one-line status for p on w (a terminal) every
+// tick, starting only once delay has passed so quick scans stay silent.
+// The returned stop function clears the line and must be called before
+// anything else is printed.
+func StartProgress(w io.Writer, p *scanner.Progress, delay time.Duration) (stop func()) {
+	ticker := time.NewTicker(progressTick)
+	stopDrawing := StartProgressTicks(w, p, delay, ticker.C)
+	return func() {
+		stopDrawing()
+		ticker.Stop()
+	}
+}
+
+// StartProgressTicks is StartProgress redrawing on each time received from
+// ticks rather than on its own ticker, so a caller (or test) controls the
+// pace; elapsed time is measured up to the received time
+func StartProgressTicks(w io.Writer, p *scanner.Progress, delay time.Duration, ticks <-chan time.Time) (stop func()) {
+	start := time.Now()
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		drawn := false
+		for {
+			select {
+			case <-done:
+				if drawn {
+					fmt.Fprint(w, "\r\033[K")
+				}
+				return
+			case now := <-ticks:
+				elapsed := now.Sub(start)
+				if elapsed < delay || p.Stage() == "" {
+					continue
+				}
+				fmt.Fprint(w, "\r\033[K"+progressLine(p, elapsed))
+				drawn = true
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// progressLine describes the current stage, e.g.
+// "⏳ analyzing imports… 12840 files scanned (4s)"
+func progressLine(p *scanner.Progress, elapsed time.Duration) string {
+	counts := fmt.Sprintf("%d files", p.Scanned())
+	switch p.Stage() {
+	case scanner.StageAnalyzing:
+		counts += " scanned"
+	case scanner.StageResolving:
+		counts = fmt.Sprintf("%d files analyzed", p.Analyzed())
+	}
+	return fmt.Sprintf("⏳ %s… %s (%ds)", p.Stage(), counts, int(elapsed.Seconds()))
+}
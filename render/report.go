@@ -0,0 +1,250 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"codemap/scanner"
+)
+
+// ArchReport is the input for MarkdownReport: the scanned files plus, when
+// dependency analysis ran, the file graph and the external packages it uses
+type ArchReport struct {
+	Root     string
+	Files    []scanner.FileInfo
+	Graph    *scanner.FileGraph    // nil when ast-grep is unavailable
+	External []scanner.ExternalHub // most imported first
+}
+
+// Report section headers, in order
+const (
+	reportOverview  = "## Overview"
+	reportLanguages = "## Languages"
+	reportHubs      = "## Hub Files"
+	reportCycles    = "## Import Cycles"
+	reportExternal  = "## External Dependencies"
+	reportDiagram   = "## Dependency Diagram"
+)
+
+// reportListLimit caps the hub, cycle, and external package lists
+const reportListLimit = 20
+
+// mermaidEdgeLimit caps the diagram's edges, heaviest first, so it stays
+// renderable on GitHub
+const mermaidEdgeLimit = 40
+
+// MarkdownReport writes an architecture document for the project: overview
+// stats, language breakdown, hub files, import cycles, external packages, and
+// a directory-level Mermaid dependency diagram
+func MarkdownReport(w io.Writer, r ArchReport) {
+	fg := r.Graph
+	fmt.Fprintf(w, "# Architecture: %s\n\n", filepath.Base(r.Root))
+	fmt.Fprintln(w, "_Generated by codemap._")
+
+	var total int64
+	for _, f := range r.Files {
+		total += f.Size
+	}
+	langs := languageBreakdown(r.Root, r.Files)
+	fmt.Fprintf(w, "\n%s\n\n", reportOverview)
+	fmt.Fprintln(w, "| | |")
+	fmt.Fprintln(w, "|---|---|")
+	fmt.Fprintf(w, "| Files | %d |\n", len(r.Files))
+	fmt.Fprintf(w, "| Total size | %s |\n", formatSize(total))
+	if len(langs) > 0 {
+		fmt.Fprintf(w, "| Primary language | %s |\n", langs[0].name)
+	}
+	if fg != nil {
+		edges := 0
+		for _, targets := range fg.Imports {
+			edges += len(targets)
+		}
+		fmt.Fprintf(w, "| Internal imports | %d |\n", edges)
+		fmt.Fprintf(w, "| Hub files | %d |\n", len(fg.HubFiles()))
+		fmt.Fprintf(w, "| External packages | %d |\n", len(r.External))
+	}
+
+	fmt.Fprintf(w, "\n%s\n\n", reportLanguages)
+	if len(langs) == 0 {
+		fmt.Fprintln(w, "No source files.")
+	} else {
+		fmt.Fprintln(w, "| Language | Files | Size |")
+		fmt.Fprintln(w, "|---|---:|---:|")
+		for _, l := range langs {
+			fmt.Fprintf(w, "| %s | %d | %s |\n", l.name, l.files, formatSize(l.size))
+		}
+	}
+
+	if fg == nil {
+		for _, header := range []string{reportHubs, reportCycles, reportExternal, reportDiagram} {
+			fmt.Fprintf(w, "\n%s\n\n", header)
+			fmt.Fprintln(w, "Dependency analysis unavailable (install ast-grep).")
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "\n%s\n\n", reportHubs)
+	hubs := fg.SortedHubs(false)
+	if len(hubs) == 0 {
+		fmt.Fprintln(w, "No hub files.")
+	} else {
+		blast := fg.BlastRadius()
+		fmt.Fprintln(w, "| File | Importers | Blast radius |")
+		fmt.Fprintln(w, "|---|---:|---:|")
+		for _, h := range hubs[:min(len(hubs), reportListLimit)] {
+			fmt.Fprintf(w, "| `%s` | %d | %d |\n", h, len(fg.ImportersOf(h)), blast[h])
+		}
+		moreLine(w, len(hubs))
+	}
+
+	fmt.Fprintf(w, "\n%s\n\n", reportCycles)
+	cycles := fg.ImportCycles()
+	if len(cycles) == 0 {
+		fmt.Fprintln(w, "No import cycles.")
+	} else {
+		for _, c := range cycles[:min(len(cycles), reportListLimit)] {
+			fmt.Fprintf(w, "- `%s`\n", strings.Join(c, "` ⇄ `"))
+		}
+		moreLine(w, len(cycles))
+	}
+
+	fmt.Fprintf(w, "\n%s\n\n", reportExternal)
+	if len(r.External) == 0 {
+		fmt.Fprintln(w, "No external packages imported.")
+	} else {
+		fmt.Fprintln(w, "| Package | Language | Importing files |")
+		fmt.Fprintln(w, "|---|---|---:|")
+		for _, h := range r.External[:min(len(r.External), reportListLimit)] {
+			fmt.Fprintf(w, "| `%s` | %s | %d |\n", h.Package, langName(h.Language), len(h.Files))
+		}
+		moreLine(w, len(r.External))
+	}
+
+	fmt.Fprintf(w, "\n%s\n\n", reportDiagram)
+	fmt.Fprint(w, mermaidDirGraph(fg))
+}
+
+// langStat is one row of the language breakdown
+type langStat struct {
+	name  string
+	files int
+	size  int64
+}
+
+// languageBreakdown totals source files per language, largest first
+func languageBreakdown(root string, files []scanner.FileInfo) []langStat {
+	byLang := make(map[string]*langStat)
+	for _, f := range files {
+		lang := scanner.DetectLanguageAt(root, f.Path)
+		if lang == "" {
+			continue
+		}
+		s := byLang[lang]
+		if s == nil {
+			s = &langStat{name: langName(lang)}
+			byLang[lang] = s
+		}
+		s.files++
+		s.size += f.Size
+	}
+	stats := make([]langStat, 0, len(byLang))
+	for _, s := range byLang {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].size != stats[j].size {
+			return stats[i].size > stats[j].size
+		}
+		return stats[i].name < stats[j].name
+	})
+	return stats
+}
+
+// langName is a language's display name, falling back to its internal name
+func langName(lang string) string {
+	if name, ok := scanner.LangDisplay[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+// moreLine notes how many entries a capped list left out
+func moreLine(w io.Writer, total int) {
+	if total > reportListLimit {
+		fmt.Fprintf(w, "\n_…and %d more._\n", total-reportListLimit)
+	}
+}
+
+// mermaidDirGraph renders imports between directories as a Mermaid
+// flowchart, keeping the mermaidEdgeLimit heaviest edges. Node ids are
+// assigned in sorted order so the output is stable across runs.
+func mermaidDirGraph(fg *scanner.FileGraph) string {
+	type edge struct {
+		from, to string
+		n        int
+	}
+	counts := make(map[[2]string]int)
+	for from, targets := range fg.Imports {
+		for _, to := range targets {
+			a, b := path.Dir(from), path.Dir(to)
+			if a != b {
+				counts[[2]string{a, b}]++
+			}
+		}
+	}
+	edges := make([]edge, 0, len(counts))
+	for k, n := range counts {
+		edges = append(edges, edge{k[0], k[1], n})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].n != edges[j].n {
+			return edges[i].n > edges[j].n
+		}
+		if edges[i].from != edges[j].from {
+			return edges[i].from < edges[j].from
+		}
+		return edges[i].to < edges[j].to
+	})
+	if len(edges) > mermaidEdgeLimit {
+		edges = edges[:mermaidEdgeLimit]
+	}
+	if len(edges) == 0 {
+		return "No imports between directories.\n"
+	}
+
+	dirs := make(map[string]bool)
+	for _, e := range edges {
+		dirs[e.from], dirs[e.to] = true, true
+	}
+	names := make([]string, 0, len(dirs))
+	for d := range dirs {
+		names = append(names, d)
+	}
+	sort.Strings(names)
+	ids := mermaidIDs("d", names)
+
+	var b strings.Builder
+	b.WriteString("```mermaid\nflowchart LR\n")
+	for _, d := range names {
+		label := d + "/"
+		if d == "." {
+			label = "(root)"
+		}
+		fmt.Fprintf(&b, "    %s\n", mermaidNode(ids[d], label))
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].from != edges[j].from {
+			return edges[i].from < edges[j].from
+		}
+		return edges[i].to < edges[j].to
+	})
+	for _, e := range edges {
+		fmt.Fprintf(&b, "    %s -->|%d| %s\n", ids[e.from], e.n, ids[e.to])
+	}
+	b.WriteString("```\n")
+	return b.String()
+}
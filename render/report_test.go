@@ -0,0 +1,72 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"codemap/scanner"
+)
+
+func TestMarkdownReportSections(t *testing.T) {
+	fg := &scanner.FileGraph{
+		Root:  "/tmp/shop",
+		Files: []string{"main.go", "api/server.go", "api/routes.go", "db/conn.go", "db/pool.go"},
+		Imports: map[string][]string{
+			"main.go":       {"api/server.go", "db/conn.go"},
+			"api/server.go": {"api/routes.go", "db/conn.go"},
+			"api/routes.go": {"db/conn.go"},
+			"db/conn.go":    {"db/pool.go"},
+			"db/pool.go":    {"db/conn.go"},
+		},
+		Importers: map[string][]string{
+			"api/server.go": {"main.go"},
+			"api/routes.go": {"api/server.go"},
+			"db/conn.go":    {"api/routes.go", "api/server.go", "db/pool.go", "main.go"},
+			"db/pool.go":    {"db/conn.go"},
+		},
+	}
+	r := ArchReport{
+		Root: "/tmp/shop",
+		Files: []scanner.FileInfo{
+			{Path: "main.go", Size: 400}, {Path: "api/server.go", Size: 900},
+			{Path: "api/routes.go", Size: 300}, {Path: "db/conn.go", Size: 500},
+			{Path: "db/pool.go", Size: 200}, {Path: "web/app.ts", Size: 100},
+		},
+		Graph:    fg,
+		External: []scanner.ExternalHub{{Package: "github.com/lib/pq", Language: "go", Files: []string{"db/conn.go"}}},
+	}
+
+	var buf bytes.Buffer
+	MarkdownReport(&buf, r)
+	out := buf.String()
+
+	last := -1
+	for _, want := range []string{
+		"# Architecture: shop",
+		"## Overview", "| Files | 6 |", "| Primary language | Go |",
+		"## Languages", "| Go | 5 | 2.2KB |", "| TypeScript | 1 | 100.0B |",
+		"## Hub Files", "| `db/conn.go` | 4 |",
+		"## Import Cycles", "- `db/conn.go` ⇄ `db/pool.go`",
+		"## External Dependencies", "| `github.com/lib/pq` | Go | 1 |",
+		"## Dependency Diagram", "```mermaid\nflowchart LR\n", `d0["(root)"]`, "d0 -->|1| d2",
+	} {
+		i := strings.Index(out, want)
+		if i < 0 {
+			t.Errorf("Expected %q in:\n%s", want, out)
+			continue
+		}
+		if i < last {
+			t.Errorf("%q is out of order", want)
+		}
+		last = i
+	}
+
+	// Without a graph the dependency sections say why they're empty
+	buf.Reset()
+	r.Graph, r.External = nil, nil
+	MarkdownReport(&buf, r)
+	if out := buf.String(); strings.Count(out, "Dependency analysis unavailable") != 4 || !strings.Contains(out, "## Dependency Diagram") {
+		t.Errorf("Expected placeholder dependency sections, got:\n%s", out)
+	}
+}
@@ -4,16 +4,15 @@ import (
 	"fmt"
 	"math"
 	"math/rand/v2"
-	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"codemap/scanner"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"golang.org/x/term"
 )
 
 // rng is a reproducible random generator for consistent skyline layouts
@@ -40,6 +39,13 @@ const (
 	maxHeight     = 12
 	minHeight     = 2
 	skyHeight     = 6
+
+	// detailedMinWidth is the narrowest output --detailed adds file counts at
+	detailedMinWidth = 80
+
+	// skylineMinWidth fits one building with its widest gap, plus the
+	// margins createBuildings keeps
+	skylineMinWidth = buildingWidth + 3 + 8
 )
 
 // Building colors
@@ -60,23 +66,28 @@ var buildingColors = []string{
 
 // Building data
 type building struct {
-	height   int
-	char     rune
-	color    string
-	ext      string
-	extLabel string
-	count    int
-	size     int64
-	gap      int
+	height     int
+	char       rune
+	color      string
+	ext        string
+	extLabel   string
+	countLabel string // file count shown under extLabel (--detailed), "" = none
+	count      int
+	size       int64
+	gap        int
 }
 
 // Aggregated extension data
 type extAgg struct {
-	ext   string
-	size  int64
-	count int
+	ext    string
+	size   int64
+	count  int
+	merged int // for the "other" entry: how many extensions were rolled into it
 }
 
+// otherExt labels the entry that --top-langs rolls minor languages into
+const otherExt = "other"
+
 // filterCodeFiles returns only source code files
 func filterCodeFiles(files []scanner.FileInfo) []scanner.FileInfo {
 	var result []scanner.FileInfo
@@ -116,6 +127,43 @@ func aggregateByExtension(files []scanner.FileInfo) []extAgg {
 	return result
 }
 
+// capLanguages keeps the n largest extensions (sorted is largest first) and
+// rolls the rest into a single "other" entry. n <= 0 keeps everything.
+func capLanguages(sorted []extAgg, n int) []extAgg {
+	if n <= 0 || len(sorted) <= n {
+		return sorted
+	}
+	other := extAgg{ext: otherExt}
+	for _, agg := range sorted[n:] {
+		other.size += agg.size
+		other.count += agg.count
+		other.merged++
+	}
+	capped := append(append([]extAgg{}, sorted[:n]...), other)
+	// Keep largest-first order even if the tail outweighs the smallest kept entry
+	sort.SliceStable(capped, func(i, j int) bool {
+		return capped[i].size > capped[j].size
+	})
+	return capped
+}
+
+// languageSummary describes the language count for the stats footer,
+// e.g. "3 languages" or "3 languages + 9 other"
+func languageSummary(sorted []extAgg) string {
+	langs, other := 0, 0
+	for _, agg := range sorted {
+		if agg.ext == otherExt && agg.merged > 0 {
+			other = agg.merged
+		} else {
+			langs++
+		}
+	}
+	if other > 0 {
+		return fmt.Sprintf("%d languages + %d other", langs, other)
+	}
+	return fmt.Sprintf("%d languages", langs)
+}
+
 // getBuildingChar returns building texture character
 func getBuildingChar(ext string) rune {
 	ext = strings.ToLower(ext)
@@ -206,24 +254,54 @@ func createBuildings(sorted []extAgg, width int) []building {
 	return arranged
 }
 
+// facadeRune returns what building b shows at column j of a body row offset
+// rows below its center row: the extension label on the center row, the file
+// count (--detailed) just below it, and the building character elsewhere
+func (b building) facadeRune(offset, j, bodyHeight int) rune {
+	label := ""
+	switch {
+	case offset == 0 && bodyHeight >= 3:
+		label = b.extLabel
+	case offset == 1 && bodyHeight >= 4:
+		label = b.countLabel
+	}
+	start := (buildingWidth - len(label)) / 2
+	if label != "" && j >= start && j < start+len(label) {
+		return rune(label[j-start])
+	}
+	return b.char
+}
+
 // Skyline renders the city skyline visualization
 func Skyline(project scanner.Project, animate bool) {
 	files := project.Files
 	projectName := filepath.Base(project.Root)
 
-	width, _, err := term.GetSize(int(os.Stdout.Fd()))
-	if err != nil || width <= 0 {
-		width = 80
-	}
+	width := ResolveWidth(project.Width)
 
 	codeFiles := filterCodeFiles(files)
-	sorted := aggregateByExtension(codeFiles)
+	sorted := capLanguages(aggregateByExtension(codeFiles), project.TopLangs)
 	arranged := createBuildings(sorted, width)
 
-	if len(arranged) == 0 {
+	if len(sorted) == 0 {
 		fmt.Println(Dim + "No source files to display" + Reset)
 		return
 	}
+	if len(arranged) == 0 {
+		// There are files, just no room to draw even one building
+		fmt.Printf("%sTerminal too narrow for the skyline (%d columns, need %d); widen it or use --width %d%s\n", Yellow, width, skylineMinWidth, skylineMinWidth, Reset)
+		printSkylineStats(projectName, codeFiles, sorted, width)
+		return
+	}
+
+	// Detailed mode: file counts under the labels, when there's room for them
+	if project.Detailed && width >= detailedMinWidth {
+		for i := range arranged {
+			if label := strconv.Itoa(arranged[i].count); len(label) <= buildingWidth {
+				arranged[i].countLabel = label
+			}
+		}
+	}
 
 	// Calculate layout
 	totalWidth := 0
@@ -293,17 +371,7 @@ func renderStatic(arranged []building, width, leftMargin, sceneLeft, sceneRight,
 		for row := buildingTop + 1; row < skyHeight+maxHeight+1; row++ {
 			for j := 0; j < buildingWidth; j++ {
 				if col+j < width {
-					if row == centerRow && buildingHeight >= 3 {
-						extStart := (buildingWidth - len(b.extLabel)) / 2
-						extEnd := extStart + len(b.extLabel)
-						if j >= extStart && j < extEnd {
-							grid[row][col+j] = rune(b.extLabel[j-extStart])
-						} else {
-							grid[row][col+j] = b.char
-						}
-					} else {
-						grid[row][col+j] = b.char
-					}
+					grid[row][col+j] = b.facadeRune(row-centerRow, j, buildingHeight)
 				}
 			}
 		}
@@ -375,6 +443,12 @@ func renderStatic(arranged []building, width, leftMargin, sceneLeft, sceneRight,
 
 	// Stats
 	fmt.Println()
+	printSkylineStats(projectName, codeFiles, sorted, width)
+}
+
+// printSkylineStats prints the project title and language/file/size summary
+// that close a skyline
+func printSkylineStats(projectName string, codeFiles []scanner.FileInfo, sorted []extAgg, width int) {
 	title := fmt.Sprintf("─── %s ───", projectName)
 	fmt.Printf("%s%s%s\n", BoldWhite, CenterString(title, width), Reset)
 
@@ -382,7 +456,7 @@ func renderStatic(arranged []building, width, leftMargin, sceneLeft, sceneRight,
 	for _, f := range codeFiles {
 		codeSize += f.Size
 	}
-	stats := fmt.Sprintf("%d languages · %d files · %s", len(sorted), len(codeFiles), formatSize(codeSize))
+	stats := fmt.Sprintf("%s · %d files · %s", languageSummary(sorted), len(codeFiles), formatSize(codeSize))
 	fmt.Printf("%s%s%s\n", Cyan, CenterString(stats, width), Reset)
 	fmt.Println()
 }
@@ -539,17 +613,7 @@ func (m animationModel) View() string {
 				} else if row > buildingTop {
 					for j := 0; j < buildingWidth; j++ {
 						if col+j < m.width {
-							if row == centerRow && buildingHeight >= 3 {
-								extStart := (buildingWidth - len(b.extLabel)) / 2
-								extEnd := extStart + len(b.extLabel)
-								if j >= extStart && j < extEnd {
-									line[col+j] = rune(b.extLabel[j-extStart])
-								} else {
-									line[col+j] = b.char
-								}
-							} else {
-								line[col+j] = b.char
-							}
+							line[col+j] = b.facadeRune(row-centerRow, j, buildingHeight)
 						}
 					}
 				}
@@ -0,0 +1,124 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"codemap/scanner"
+)
+
+func TestCapLanguages(t *testing.T) {
+	sorted := []extAgg{
+		{ext: ".go", size: 5000, count: 20},
+		{ext: ".ts", size: 3000, count: 10},
+		{ext: ".py", size: 1000, count: 4},
+		{ext: ".sh", size: 300, count: 3},
+		{ext: ".rb", size: 200, count: 2},
+	}
+
+	capped := capLanguages(sorted, 2)
+	if len(capped) != 3 {
+		t.Fatalf("Expected 2 languages + other, got %d entries", len(capped))
+	}
+	if capped[0].ext != ".go" || capped[1].ext != ".ts" {
+		t.Errorf("Expected the two largest languages kept, got %s, %s", capped[0].ext, capped[1].ext)
+	}
+	other := capped[2]
+	if other.ext != otherExt || other.size != 1500 || other.count != 9 || other.merged != 3 {
+		t.Errorf("Unexpected other entry: %+v (want size 1500, count 9, merged 3)", other)
+	}
+	if got := languageSummary(capped); got != "2 languages + 3 other" {
+		t.Errorf("languageSummary = %q", got)
+	}
+
+	// Default (0) and N >= len keep everything
+	if got := capLanguages(sorted, 0); len(got) != len(sorted) {
+		t.Errorf("Expected all %d languages with no cap, got %d", len(sorted), len(got))
+	}
+	if got := capLanguages(sorted, 5); len(got) != len(sorted) {
+		t.Errorf("Expected no other entry when N covers every language, got %d", len(got))
+	}
+	if got := languageSummary(sorted); got != "5 languages" {
+		t.Errorf("languageSummary = %q", got)
+	}
+
+	// A heavy tail outweighing a kept language stays largest-first
+	capped = capLanguages([]extAgg{
+		{ext: ".go", size: 1000, count: 5},
+		{ext: ".ts", size: 900, count: 5},
+		{ext: ".py", size: 800, count: 5},
+	}, 1)
+	if capped[0].ext != otherExt || capped[1].ext != ".go" {
+		t.Errorf("Expected other (1700) before .go (1000), got %s, %s", capped[0].ext, capped[1].ext)
+	}
+}
+
+func TestSkylineDetailedCounts(t *testing.T) {
+	var files []scanner.FileInfo
+	for i := 0; i < 12; i++ {
+		files = append(files, scanner.FileInfo{Path: fmt.Sprintf("g%d.go", i), Size: 1000, Ext: ".go"})
+	}
+	for i := 0; i < 7; i++ {
+		files = append(files, scanner.FileInfo{Path: fmt.Sprintf("p%d.py", i), Size: 1000, Ext: ".py"})
+	}
+	files = append(files, scanner.FileInfo{Path: "run.sh", Size: 100, Ext: ".sh"})
+
+	// buildingRows returns the skyline rows above the ground line
+	buildingRows := func(width int, detailed bool) string {
+		out := captureStdout(t, func() {
+			Skyline(scanner.Project{Root: "/tmp/city", Files: files, Width: width, Detailed: detailed}, false)
+		})
+		out = ansiEscape.ReplaceAllString(out, "")
+		ground, _, _ := strings.Cut(out, "▀")
+		return ground
+	}
+
+	rows := buildingRows(100, true)
+	for _, count := range []string{"12", "7"} {
+		if !strings.Contains(rows, count) {
+			t.Errorf("detailed skyline at width 100: expected count %s on a building:\n%s", count, rows)
+		}
+	}
+	if !strings.Contains(rows, ".go") {
+		t.Errorf("extension labels should still be shown:\n%s", rows)
+	}
+
+	// Off by default, and skipped when the output is too narrow
+	for _, tc := range []struct {
+		width    int
+		detailed bool
+	}{{100, false}, {60, true}} {
+		if rows := buildingRows(tc.width, tc.detailed); strings.ContainsAny(rows, "0123456789") {
+			t.Errorf("width %d detailed=%v: expected no counts:\n%s", tc.width, tc.detailed, rows)
+		}
+	}
+}
+
+func TestSkylineTooNarrow(t *testing.T) {
+	files := []scanner.FileInfo{
+		{Path: "main.go", Size: 2048, Ext: ".go"},
+		{Path: "util.go", Size: 1024, Ext: ".go"},
+		{Path: "app.py", Size: 512, Ext: ".py"},
+	}
+
+	out := captureStdout(t, func() {
+		Skyline(scanner.Project{Root: "/tmp/city", Files: files, Width: 10}, false)
+	})
+	out = ansiEscape.ReplaceAllString(out, "")
+	if strings.Contains(out, "No source files") {
+		t.Errorf("width-starved skyline claimed the project is empty:\n%s", out)
+	}
+	for _, want := range []string{"too narrow", "--width", "3 files", "3.5KB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q at width 10:\n%s", want, out)
+		}
+	}
+
+	empty := captureStdout(t, func() {
+		Skyline(scanner.Project{Root: "/tmp/city", Width: 10}, false)
+	})
+	if !strings.Contains(empty, "No source files") {
+		t.Errorf("Expected a project without source files to say so:\n%s", empty)
+	}
+}
@@ -0,0 +1,104 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+
+	"codemap/scanner"
+)
+
+// SVG cell size: one terminal column/row of the ASCII skyline
+const (
+	svgCellW   = 10
+	svgCellH   = 16
+	svgColumns = 80
+)
+
+// svgColors maps building ANSI colors to hex fills
+var svgColors = map[string]string{
+	"\033[36m": "#2aa1b3", // cyan
+	"\033[33m": "#c4a000", // yellow
+	"\033[35m": "#a347ba", // magenta
+	"\033[31m": "#c91b00", // red
+	"\033[32m": "#00a600", // green
+	"\033[34m": "#3465a4", // blue
+	"\033[96m": "#34e2e2", // bright cyan
+	"\033[93m": "#fce94f", // bright yellow
+	"\033[95m": "#ef72ef", // bright magenta
+	"\033[91m": "#ef2929", // bright red
+	"\033[92m": "#8ae234", // bright green
+	"\033[94m": "#729fcf", // bright blue
+}
+
+// SkylineSVG renders the skyline as a standalone SVG image, using the same
+// building layout as the terminal view (one building per language extension)
+func SkylineSVG(project scanner.Project) string {
+	projectName := filepath.Base(project.Root)
+	codeFiles := filterCodeFiles(project.Files)
+	sorted := capLanguages(aggregateByExtension(codeFiles), project.TopLangs)
+	arranged := createBuildings(sorted, svgColumns)
+
+	totalWidth := 0
+	for _, b := range arranged {
+		totalWidth += buildingWidth + b.gap
+	}
+	leftMargin := (svgColumns - totalWidth) / 2
+	sceneLeft := max(0, leftMargin-4)
+	sceneRight := min(svgColumns, leftMargin+totalWidth+4)
+
+	width := svgColumns * svgCellW
+	groundY := (skyHeight + maxHeight + 1) * svgCellH
+	height := groundY + 3*svgCellH
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace">`+"\n", width, height, width, height)
+	fmt.Fprintf(&sb, `  <rect class="sky" width="%d" height="%d" fill="#0d1117"/>`+"\n", width, height)
+
+	// Stars and moon
+	if sceneRight > sceneLeft {
+		for row := 0; row < skyHeight; row++ {
+			for i := 0; i < (sceneRight-sceneLeft)/10; i++ {
+				col := rng.IntN(sceneRight-sceneLeft) + sceneLeft
+				fmt.Fprintf(&sb, `  <circle class="star" cx="%d" cy="%d" r="1.5" fill="#8b949e"/>`+"\n",
+					col*svgCellW+svgCellW/2, row*svgCellH+svgCellH/2)
+			}
+		}
+		fmt.Fprintf(&sb, `  <circle class="moon" cx="%d" cy="%d" r="%d" fill="#f2cc60"/>`+"\n",
+			(sceneRight-3)*svgCellW+svgCellW/2, svgCellH+svgCellH/2, svgCellH/2)
+	}
+
+	// Buildings with their extension label
+	col := leftMargin
+	for _, b := range arranged {
+		x := col * svgCellW
+		y := groundY - b.height*svgCellH
+		w := buildingWidth * svgCellW
+		fill := svgColors[b.color]
+		if fill == "" {
+			fill = "#c9d1d9"
+		}
+		fmt.Fprintf(&sb, `  <rect class="building" x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%s: %d files, %s</title></rect>`+"\n",
+			x, y, w, b.height*svgCellH, fill, html.EscapeString(b.ext), b.count, formatSize(b.size))
+		if b.height >= 3 {
+			fmt.Fprintf(&sb, `  <text x="%d" y="%d" text-anchor="middle" font-size="12" fill="#ffffff">%s</text>`+"\n",
+				x+w/2, y+b.height*svgCellH/2, html.EscapeString(b.extLabel))
+		}
+		col += buildingWidth + b.gap
+	}
+
+	// Ground and stats
+	fmt.Fprintf(&sb, `  <rect class="ground" x="%d" y="%d" width="%d" height="%d" fill="#30363d"/>`+"\n",
+		sceneLeft*svgCellW, groundY, (sceneRight-sceneLeft)*svgCellW, svgCellH/2)
+
+	var codeSize int64
+	for _, f := range codeFiles {
+		codeSize += f.Size
+	}
+	stats := fmt.Sprintf("%s · %s · %d files · %s", projectName, languageSummary(sorted), len(codeFiles), formatSize(codeSize))
+	fmt.Fprintf(&sb, `  <text x="%d" y="%d" text-anchor="middle" font-size="14" fill="#c9d1d9">%s</text>`+"\n",
+		width/2, groundY+2*svgCellH, html.EscapeString(stats))
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"codemap/scanner"
+)
+
+func TestSkylineSVG(t *testing.T) {
+	project := scanner.Project{
+		Root: "/tmp/myproject",
+		Files: []scanner.FileInfo{
+			{Path: "main.go", Size: 4000, Ext: ".go"},
+			{Path: "util.go", Size: 2000, Ext: ".go"},
+			{Path: "web/app.ts", Size: 3000, Ext: ".ts"},
+			{Path: "scripts/build.py", Size: 500, Ext: ".py"},
+			{Path: "README.md", Size: 900, Ext: ".md"}, // not code, no building
+		},
+	}
+
+	svg := SkylineSVG(project)
+
+	if got := strings.Count(svg, `class="building"`); got != 3 {
+		t.Errorf("Expected 3 building rects (one per language), got %d", got)
+	}
+	if !strings.Contains(svg, `class="moon"`) {
+		t.Error("Expected a moon in the sky")
+	}
+	if !strings.Contains(svg, "myproject") {
+		t.Error("Expected project name in the stats line")
+	}
+
+	// Must be well-formed XML to embed in a README
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("SVG is not well-formed: %v", err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package render
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
@@ -18,6 +19,45 @@ type treeNode struct {
 	children map[string]*treeNode
 }
 
+// treeOptions carries the per-render settings printTreeNode needs at every level
+type treeOptions struct {
+	topLarge map[string]bool // paths of the largest source files (starred)
+	maxDepth int             // 0 = unlimited
+	width    int             // output width used to lay out file grids
+	collapse map[string]bool // directory names shown as one summary line
+	barMax   int64           // with --bars: size of a full bar (0 = grid layout)
+	barAll   bool            // with --bars: assets get bars too
+}
+
+// barWidth is how many cells a full --bars bar spans
+const barWidth = 10
+
+// barEighths are the partial block characters, by eighths of a cell
+var barEighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// sizeBar draws size as a bar of up to width cells in eighth-cell steps,
+// scaled so full fills it. Any nonzero size gets at least a sliver.
+func sizeBar(size, full int64, width int) string {
+	if size <= 0 || full <= 0 {
+		return ""
+	}
+	eighths := int(math.Round(float64(size) / float64(full) * float64(width*8)))
+	eighths = min(max(eighths, 1), width*8)
+	return strings.Repeat("█", eighths/8) + barEighths[eighths%8]
+}
+
+// largestForBars returns the size that fills a --bars bar: the largest
+// file's, leaving assets out unless all is set
+func largestForBars(files []scanner.FileInfo, all bool) int64 {
+	var largest int64
+	for _, f := range files {
+		if (all || !IsAssetExtension(f.Ext)) && f.Size > largest {
+			largest = f.Size
+		}
+	}
+	return largest
+}
+
 // getTopLargeFiles returns paths of top 5 largest source code files
 func getTopLargeFiles(files []scanner.FileInfo) map[string]bool {
 	// Filter out assets and binaries (no extension = likely binary)
@@ -103,12 +143,21 @@ func formatSize(size int64) string {
 	return fmt.Sprintf("%.1f%s", fsize, units[len(units)-1])
 }
 
+// FormatSizeDelta formats a byte change with a sign, e.g. "+1.5KB" or "-300.0B"
+func FormatSizeDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + formatSize(-delta)
+	}
+	return "+" + formatSize(delta)
+}
+
 // Tree renders the file tree to stdout
 func Tree(project scanner.Project) {
 	files := project.Files
 	projectName := filepath.Base(project.Root)
 	isDiffMode := project.DiffRef != ""
 	maxDepth := project.Depth // 0 = unlimited
+	width := ResolveWidth(project.Width)
 
 	// Calculate stats
 	totalFiles := len(files)
@@ -159,13 +208,16 @@ func Tree(project scanner.Project) {
 	// Print header (match Python rich panel exactly - title in top border)
 	innerWidth := 64
 	// Expand width if extension line is longer
-	if len(extLine)+4 > innerWidth {
-		innerWidth = len(extLine) + 4
+	if DisplayWidth(extLine)+4 > innerWidth {
+		innerWidth = DisplayWidth(extLine) + 4
 	}
 
 	// Title in top border line (like rich panel)
 	titleLine := fmt.Sprintf(" %s ", projectName)
-	padding := innerWidth - len(titleLine)
+	if DisplayWidth(titleLine)+2 > innerWidth {
+		innerWidth = DisplayWidth(titleLine) + 2
+	}
+	padding := innerWidth - DisplayWidth(titleLine)
 	leftPad := padding / 2
 	rightPad := padding - leftPad
 	fmt.Printf("╭%s%s%s╮\n", strings.Repeat("─", leftPad), titleLine, strings.Repeat("─", rightPad))
@@ -181,11 +233,11 @@ func Tree(project scanner.Project) {
 	} else {
 		statsLine = fmt.Sprintf("Files: %d | Size: %s", totalFiles, formatSize(totalSize))
 	}
-	fmt.Printf("│ %-*s │\n", innerWidth-2, statsLine)
+	fmt.Printf("│ %s │\n", PadRight(statsLine, innerWidth-2))
 
 	// Extensions line
 	if extLine != "" {
-		fmt.Printf("│ %-*s │\n", innerWidth-2, extLine)
+		fmt.Printf("│ %s │\n", PadRight(extLine, innerWidth-2))
 	}
 
 	fmt.Printf("╰%s╯\n", strings.Repeat("─", innerWidth))
@@ -193,7 +245,16 @@ func Tree(project scanner.Project) {
 	// Build and render tree
 	root := buildTreeStructure(files)
 	fmt.Printf("%s%s%s\n", Bold, projectName, Reset)
-	printTreeNode(root, "", true, topLarge, 1, maxDepth)
+	collapse := make(map[string]bool)
+	for _, name := range project.Collapse {
+		collapse[name] = true
+	}
+	opts := &treeOptions{topLarge: topLarge, maxDepth: maxDepth, width: width, collapse: collapse}
+	if project.Bars {
+		opts.barMax = largestForBars(files, project.BarsAll)
+		opts.barAll = project.BarsAll
+	}
+	printTreeNode(root, "", true, 1, opts)
 
 	// Print impact footer for diff mode
 	if isDiffMode && len(project.Impact) > 0 {
@@ -209,8 +270,11 @@ func Tree(project scanner.Project) {
 }
 
 // printTreeNode recursively prints tree nodes
-// currentDepth starts at 1 for the root level, maxDepth 0 means unlimited
-func printTreeNode(node *treeNode, prefix string, isLast bool, topLarge map[string]bool, currentDepth, maxDepth int) {
+// currentDepth starts at 1 for the root level
+func printTreeNode(node *treeNode, prefix string, isLast bool, currentDepth int, opts *treeOptions) {
+	maxDepth := opts.maxDepth
+	topLarge := opts.topLarge
+
 	// Check if we've exceeded depth limit
 	if maxDepth > 0 && currentDepth > maxDepth {
 		return
@@ -234,7 +298,20 @@ func printTreeNode(node *treeNode, prefix string, isLast bool, topLarge map[stri
 	for i, dir := range dirs {
 		isLastDir := i == len(dirs)-1 && len(fileNodes) == 0
 
-		// Flatten single-child directories
+		connector := "├── "
+		if isLastDir {
+			connector = "└── "
+		}
+
+		// Collapsed directories (e.g. vendored trees) get one summary line
+		if opts.collapse[dir.name] {
+			fileCount, totalSize := getDirStats(dir)
+			fmt.Printf("%s%s%s  %s/%s %s(collapsed, %d files, %s)%s\n",
+				prefix, connector, BoldBlue, dir.name, Reset, Dim, fileCount, formatSize(totalSize), Reset)
+			continue
+		}
+
+		// Flatten single-child directories (stopping short of a collapsed one)
 		mergedName := dir.name
 		current := dir
 		for len(current.children) == 1 {
@@ -242,7 +319,7 @@ func printTreeNode(node *treeNode, prefix string, isLast bool, topLarge map[stri
 			for _, c := range current.children {
 				onlyChild = c
 			}
-			if onlyChild.isFile {
+			if onlyChild.isFile || opts.collapse[onlyChild.name] {
 				break
 			}
 			mergedName = mergedName + "/" + onlyChild.name
@@ -284,11 +361,6 @@ func printTreeNode(node *treeNode, prefix string, isLast bool, topLarge map[stri
 			statsParts = append(statsParts, fmt.Sprintf("all %s", commonExt))
 		}
 
-		connector := "├── "
-		if isLastDir {
-			connector = "└── "
-		}
-
 		fmt.Printf("%s%s%s  %s/%s %s(%s)%s\n",
 			prefix, connector, BoldBlue, mergedName, Reset, Dim, strings.Join(statsParts, ", "), Reset)
 
@@ -328,15 +400,14 @@ func printTreeNode(node *treeNode, prefix string, isLast bool, topLarge map[stri
 				fmt.Printf("%s└── %s... %s%s\n", newPrefix, Dim, strings.Join(parts, ", "), Reset)
 			}
 		} else {
-			printTreeNode(current, newPrefix, isLastDir, topLarge, currentDepth+1, maxDepth)
+			printTreeNode(current, newPrefix, isLastDir, currentDepth+1, opts)
 		}
 	}
 
 	// Print files as a grid (multi-column layout like Python)
 	if len(fileNodes) > 0 {
 		connector := "└── "
-		termWidth := GetTerminalWidth()
-		availableWidth := termWidth - len(prefix) - len(connector)
+		availableWidth := opts.width - len(prefix) - len(connector)
 		if availableWidth < 40 {
 			availableWidth = 40
 		}
@@ -409,7 +480,7 @@ func printTreeNode(node *treeNode, prefix string, isLast bool, topLarge map[stri
 
 			display := prefix + displayName + suffix
 			colored := fmt.Sprintf("%s%s%s%s%s%s", color, prefix, displayName, Reset, Dim, suffix+Reset)
-			width := prefixWidth + len(displayName) + suffixWidth
+			width := prefixWidth + DisplayWidth(displayName) + suffixWidth
 			entries = append(entries, fileEntry{display, colored, width})
 		}
 
@@ -420,6 +491,24 @@ func printTreeNode(node *treeNode, prefix string, isLast bool, topLarge map[stri
 				maxWidth = e.width
 			}
 		}
+
+		// --bars: one file per line, name, bar, and size aligned
+		if opts.barMax > 0 {
+			for i, e := range entries {
+				connector := "├── "
+				if i == len(entries)-1 {
+					connector = "└── "
+				}
+				f := fileNodes[i].file
+				bar := ""
+				if opts.barAll || !IsAssetExtension(f.Ext) {
+					bar = sizeBar(f.Size, opts.barMax, barWidth)
+				}
+				fmt.Printf("%s%s%s%s %s%s%s %s%s%s\n", prefix, connector, e.colored, strings.Repeat(" ", maxWidth-e.width),
+					Cyan, PadRight(bar, barWidth), Reset, Dim, formatSize(f.Size), Reset)
+			}
+			return
+		}
 		colWidth := maxWidth + 1
 		numCols := availableWidth / colWidth
 		if numCols < 1 {
@@ -455,3 +544,66 @@ func printTreeNode(node *treeNode, prefix string, isLast bool, topLarge map[stri
 		}
 	}
 }
+
+// TreeStream renders files as they arrive from a streaming scan, in walk
+// order. Like Tree, each directory lists its subdirectories first, then its
+// own files: a directory's header is printed when the walk enters it and its
+// files are held until the walk leaves it, so only the directories open along
+// the current path are buffered and memory stays bounded on huge repos.
+// Sizes and flattening need the whole subtree, so they are left to Tree.
+// Returns the files seen and their total size for the caller's summary.
+func TreeStream(root string, files <-chan scanner.FileInfo) (int, int64) {
+	projectName := filepath.Base(root)
+	fmt.Printf("%s%s%s\n", Bold, projectName, Reset)
+
+	// open[0] is the root; open[i] is the directory at depth i
+	type openDir struct {
+		name  string
+		files []scanner.FileInfo
+	}
+	open := []openDir{{}}
+	var (
+		count     int
+		totalSize int64
+	)
+
+	// closeTo prints and drops the directories deeper than depth
+	closeTo := func(depth int) {
+		for len(open) > depth+1 {
+			d := len(open) - 1
+			if pending := open[d].files; len(pending) > 0 {
+				names := make([]string, len(pending))
+				for i, f := range pending {
+					names[i] = GetFileColor(f.Ext) + filepath.Base(f.Path) + Reset
+				}
+				fmt.Printf("%s└── %s\n", strings.Repeat("    ", d), strings.Join(names, "  "))
+			}
+			open = open[:d]
+		}
+	}
+
+	for f := range files {
+		var parts []string
+		if dir := filepath.Dir(f.Path); dir != "." {
+			parts = strings.Split(dir, string(os.PathSeparator))
+		}
+		// Keep the directories this file shares with the open path, then
+		// enter (and print) the rest
+		shared := 0
+		for shared < len(parts) && shared+1 < len(open) && open[shared+1].name == parts[shared] {
+			shared++
+		}
+		closeTo(shared)
+		for _, name := range parts[shared:] {
+			fmt.Printf("%s├── %s%s/%s\n", strings.Repeat("    ", len(open)-1), BoldBlue, name, Reset)
+			open = append(open, openDir{name: name})
+		}
+		open[len(open)-1].files = append(open[len(open)-1].files, f)
+		count++
+		totalSize += f.Size
+	}
+	closeTo(-1)
+
+	fmt.Printf("%sFiles: %d | Size: %s%s\n", Dim, count, formatSize(totalSize), Reset)
+	return count, totalSize
+}
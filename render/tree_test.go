@@ -1,7 +1,15 @@
 package render
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"codemap/scanner"
 )
@@ -254,3 +262,187 @@ func TestTreeNodeStructure(t *testing.T) {
 		t.Errorf("Expected 1 child, got %d", len(node.children))
 	}
 }
+
+// captureStdout returns everything fn writes to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	fn()
+	w.Close()
+	os.Stdout = orig
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestTreeFixedWidth(t *testing.T) {
+	var files []scanner.FileInfo
+	for i := 0; i < 30; i++ {
+		name := fmt.Sprintf("handler_%02d.go", i)
+		files = append(files, scanner.FileInfo{Path: name, Size: int64(100 + i), Ext: ".go"})
+	}
+	project := scanner.Project{Root: "/tmp/proj", Files: files, Width: 60}
+
+	out := captureStdout(t, func() { Tree(project) })
+	if again := captureStdout(t, func() { Tree(project) }); again != out {
+		t.Error("Expected identical output for the same fixed width")
+	}
+
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	for _, line := range lines[4:] { // skip header box and project name
+		if w := utf8.RuneCountInString(ansiEscape.ReplaceAllString(line, "")); w > 60 {
+			t.Errorf("Line exceeds width 60 (%d): %q", w, line)
+		}
+	}
+
+	project.Width = 200
+	wide := captureStdout(t, func() { Tree(project) })
+	if strings.Count(wide, "\n") >= strings.Count(out, "\n") {
+		t.Error("Expected a wider layout to use fewer lines")
+	}
+}
+
+func TestTreeCollapsedDirectory(t *testing.T) {
+	project := scanner.Project{
+		Root: "/tmp/proj",
+		Files: []scanner.FileInfo{
+			{Path: "main.go", Size: 100, Ext: ".go"},
+			{Path: "vendor/github.com/a/a.go", Size: 300, Ext: ".go"},
+			{Path: "vendor/github.com/b/b.go", Size: 300, Ext: ".go"},
+			{Path: "vendor/modules.txt", Size: 50, Ext: ".txt"},
+		},
+		Width:    80,
+		Collapse: []string{"vendor"},
+	}
+
+	out := ansiEscape.ReplaceAllString(captureStdout(t, func() { Tree(project) }), "")
+
+	if !strings.Contains(out, "vendor/ (collapsed, 3 files") {
+		t.Errorf("Expected a collapsed summary line for vendor/, got:\n%s", out)
+	}
+	for _, child := range []string{"github.com", "a.go", "b.go", "modules"} {
+		if strings.Contains(out, child) {
+			t.Errorf("Collapsed directory should not list %q:\n%s", child, out)
+		}
+	}
+	// Still counted in the header stats
+	if !strings.Contains(out, "Files: 4") {
+		t.Errorf("Expected collapsed files to count toward stats, got:\n%s", out)
+	}
+	if !strings.Contains(out, "main") {
+		t.Errorf("Expected non-collapsed files to be listed, got:\n%s", out)
+	}
+}
+
+func TestSizeBarScales(t *testing.T) {
+	cases := []struct {
+		size int64
+		want string
+	}{
+		{1000, "██████████"},
+		{500, "█████"},
+		{250, "██▌"},
+		{125, "█▎"},
+		{1, "▏"}, // never invisible
+		{0, ""},
+	}
+	for _, c := range cases {
+		if got := sizeBar(c.size, 1000, barWidth); got != c.want {
+			t.Errorf("sizeBar(%d, 1000) = %q, want %q", c.size, got, c.want)
+		}
+	}
+
+	// Assets don't set the scale unless asked to, and get no bar
+	files := []scanner.FileInfo{
+		{Path: "main.go", Size: 400, Ext: ".go"},
+		{Path: "util.go", Size: 200, Ext: ".go"},
+		{Path: "logo.png", Size: 4000, Ext: ".png"},
+	}
+	if got := largestForBars(files, false); got != 400 {
+		t.Errorf("largestForBars(no assets) = %d, want 400", got)
+	}
+	if got := largestForBars(files, true); got != 4000 {
+		t.Errorf("largestForBars(all) = %d, want 4000", got)
+	}
+
+	out := ansiEscape.ReplaceAllString(captureStdout(t, func() {
+		Tree(scanner.Project{Root: "/tmp/proj", Files: files, Bars: true, Width: 80})
+	}), "")
+	for _, want := range []string{"main.go ██████████ 400.0B", "util.go █████      200.0B", "logo.png              3.9KB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+// treeSkeleton reduces tree output to one "depth:name" entry per directory
+// and file, dropping colors, sizes, stars, and file extensions (which Tree
+// strips when a directory's files share one)
+func treeSkeleton(out string) []string {
+	var skeleton []string
+	for _, line := range strings.Split(ansiEscape.ReplaceAllString(out, ""), "\n") {
+		i := strings.Index(line, "├── ")
+		if j := strings.Index(line, "└── "); i < 0 || (j >= 0 && j < i) {
+			i = j
+		}
+		if i < 0 {
+			continue
+		}
+		depth := utf8.RuneCountInString(line[:i]) / 4
+		for _, field := range strings.Fields(line[i+len("├── "):]) {
+			if strings.HasPrefix(field, "(") {
+				break // directory stats
+			}
+			if field == "⭐️" {
+				continue
+			}
+			name, _, _ := strings.Cut(field, ".")
+			skeleton = append(skeleton, fmt.Sprintf("%d:%s", depth, name))
+		}
+	}
+	return skeleton
+}
+
+func TestTreeStreamMatchesTree(t *testing.T) {
+	// Walk order: root files interleave with directories, a directory's files
+	// come before and after its subdirectories, and "lib" holds no files itself
+	paths := []string{
+		"a.go",
+		"cmd/main.go",
+		"cmd/sub/one.go",
+		"cmd/sub/two.py",
+		"cmd/util.go",
+		"lib/core/x.ts",
+		"lib/core/y.js",
+		"lib/extra/w.c",
+		"lib/extra/z.rs",
+		"m.md",
+		"z.txt",
+	}
+	var files []scanner.FileInfo
+	for _, p := range paths {
+		p = filepath.FromSlash(p)
+		files = append(files, scanner.FileInfo{Path: p, Size: 10, Ext: filepath.Ext(p)})
+	}
+
+	want := treeSkeleton(captureStdout(t, func() {
+		Tree(scanner.Project{Root: "/tmp/proj", Files: files, Width: 200})
+	}))
+	got := treeSkeleton(captureStdout(t, func() {
+		ch := make(chan scanner.FileInfo, len(files))
+		for _, f := range files {
+			ch <- f
+		}
+		close(ch)
+		TreeStream("/tmp/proj", ch)
+	}))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TreeStream skeleton %v, Tree skeleton %v", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileAge is when a file was last touched by a commit
+type FileAge struct {
+	Path        string
+	LastChanged time.Time
+}
+
+// RepoAge summarizes a repository's history for a quick maturity read
+type RepoAge struct {
+	FirstCommit time.Time
+	LastCommit  time.Time
+	Commits     int
+	Authors     int
+	Files       []FileAge // current source files, most recently changed first
+}
+
+// GitAge reads the history under root (paths relative to root) with two
+// git log passes: one for commit dates and authors, one for per-file
+// last-change times. Only source files that still exist are included.
+func GitAge(root string) (*RepoAge, error) {
+	cmd := exec.Command("git", "log", "--relative", "--format=%at%x09%aE")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+
+	age := &RepoAge{}
+	authors := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		ts, email, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			continue
+		}
+		t := time.Unix(sec, 0)
+		if age.Commits == 0 || t.Before(age.FirstCommit) {
+			age.FirstCommit = t
+		}
+		if t.After(age.LastCommit) {
+			age.LastCommit = t
+		}
+		age.Commits++
+		authors[strings.ToLower(email)] = true
+	}
+	age.Authors = len(authors)
+
+	cmd = exec.Command("git", "log", "--relative", "--no-renames", "--name-only", "--format=@%at")
+	cmd.Dir = root
+	out, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log --name-only: %w", err)
+	}
+	age.Files = parseFileAges(root, string(out))
+	return age, nil
+}
+
+// GitLastChanged maps each existing source file under root to the time of
+// the latest commit that touched it
+func GitLastChanged(root string) (map[string]time.Time, error) {
+	cmd := exec.Command("git", "log", "--relative", "--no-renames", "--name-only", "--format=@%at")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log --name-only: %w", err)
+	}
+	changed := make(map[string]time.Time)
+	for _, f := range parseFileAges(root, string(out)) {
+		changed[f.Path] = f.LastChanged
+	}
+	return changed, nil
+}
+
+// parseFileAges reads "@<unix time>" headers followed by file names (newest
+// commit first) and keeps each file's first, i.e. latest, appearance
+func parseFileAges(root, log string) []FileAge {
+	seen := make(map[string]bool)
+	var files []FileAge
+	var current time.Time
+
+	sc := bufio.NewScanner(strings.NewReader(log))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if ts, ok := strings.CutPrefix(line, "@"); ok {
+			if sec, err := strconv.ParseInt(ts, 10, 64); err == nil {
+				current = time.Unix(sec, 0)
+			}
+			continue
+		}
+		if seen[line] {
+			continue
+		}
+		seen[line] = true
+		if DetectLanguageAt(root, line) == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, line)); err != nil {
+			continue // deleted since
+		}
+		files = append(files, FileAge{Path: line, LastChanged: current})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].LastChanged.After(files[j].LastChanged)
+	})
+	return files
+}
@@ -0,0 +1,317 @@
+package scanner
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AnonymizeMapFile holds the alias -> original name mapping written by
+// --anonymize, relative to the project root. It stays local so the owner
+// can translate a shared dump back.
+const AnonymizeMapFile = ".codemap/anonymize.json"
+
+// Anonymizer consistently renames path components, function names, and
+// internal import segments so a codemap dump keeps its shape without leaking
+// names. Aliases are salted hashes: the same name always gets the same alias
+// for a project, and common names like "auth" can't be looked up in a table.
+type Anonymizer struct {
+	Salt    string            `json:"salt"`
+	Names   map[string]string `json:"names"` // alias -> original
+	aliases map[string]string // original (namespaced) -> alias
+}
+
+// NewAnonymizer starts an empty mapping with a random salt
+func NewAnonymizer() *Anonymizer {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return &Anonymizer{Salt: hex.EncodeToString(b), Names: make(map[string]string), aliases: make(map[string]string)}
+}
+
+// LoadAnonymizer reads root's AnonymizeMapFile so aliases stay stable across
+// runs, or starts a new mapping if there is none
+func LoadAnonymizer(root string) (*Anonymizer, error) {
+	data, err := os.ReadFile(filepath.Join(root, AnonymizeMapFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return NewAnonymizer(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	a := &Anonymizer{}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, err
+	}
+	if a.Names == nil {
+		a.Names = make(map[string]string)
+	}
+	a.aliases = make(map[string]string, len(a.Names))
+	for alias, original := range a.Names {
+		a.aliases[original] = alias
+	}
+	return a, nil
+}
+
+// Save writes the mapping to root's AnonymizeMapFile
+func (a *Anonymizer) Save(root string) error {
+	path := filepath.Join(root, AnonymizeMapFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// alias returns the stable alias for name, prefixed to hint at its kind
+// ("d" for path components, "f" for functions). Aliases start at 6 hex
+// digits and grow on the rare collision.
+func (a *Anonymizer) alias(prefix, name string) string {
+	if name == "" {
+		return ""
+	}
+	key := prefix + ":" + name
+	if alias, ok := a.aliases[key]; ok {
+		return alias
+	}
+	sum := sha256.Sum256([]byte(a.Salt + key))
+	digest := hex.EncodeToString(sum[:])
+	for n := 6; ; n++ {
+		alias := prefix + digest[:min(n, len(digest))]
+		if _, taken := a.Names[alias]; !taken || n >= len(digest) {
+			a.aliases[key] = alias
+			a.Names[alias] = key
+			return alias
+		}
+	}
+}
+
+// Component renames one path component, keeping its extension
+// ("handlers.go" -> "d1a2b3c.go") and leaving "." and ".." alone
+func (a *Anonymizer) Component(name string) string {
+	if name == "" || name == "." || name == ".." {
+		return name
+	}
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if stem == "" { // dotfile like .github: no extension to keep
+		stem, ext = name, ""
+	}
+	return a.alias("d", stem) + ext
+}
+
+// Path renames every component of a slash- or OS-separated path
+func (a *Anonymizer) Path(p string) string {
+	if p == "" {
+		return ""
+	}
+	parts := strings.Split(filepath.ToSlash(p), "/")
+	for i, part := range parts {
+		parts[i] = a.Component(part)
+	}
+	return filepath.FromSlash(strings.Join(parts, "/"))
+}
+
+// Function renames a function name
+func (a *Anonymizer) Function(name string) string {
+	return a.alias("f", name)
+}
+
+// Export renames an exported name like a function, keeping the "default" and
+// "* from <import>" markers readable
+func (a *Anonymizer) Export(name string) string {
+	if name == "default" {
+		return name
+	}
+	if from, ok := strings.CutPrefix(name, "* from "); ok {
+		return "* from " + a.Import(from)
+	}
+	return a.Function(name)
+}
+
+// Import renames the segments of an import path that name something in the
+// project (a directory, a file stem, or a module path segment) and leaves
+// third-party ones readable: "myapp/internal/auth" loses its names, "fmt"
+// and "react" don't. Call after the project's files have been registered
+// via Path so their names are known.
+func (a *Anonymizer) Import(imp string) string {
+	var sb strings.Builder
+	start := 0
+	flush := func(end int) {
+		seg := imp[start:end]
+		if _, known := a.aliases["d:"+seg]; known {
+			seg = a.alias("d", seg)
+		}
+		sb.WriteString(seg)
+	}
+	for i, r := range imp {
+		if r == '/' || r == '.' || r == '\\' || r == ':' {
+			flush(i)
+			sb.WriteRune(r)
+			start = i + 1
+		}
+	}
+	flush(len(imp))
+	return sb.String()
+}
+
+// registerModule marks a Go module path's segments as project names, so
+// imports under it are renamed even where no directory shares the name
+func (a *Anonymizer) registerModule(module string) {
+	for _, seg := range strings.Split(module, "/") {
+		if seg != "" && !strings.Contains(seg, ".") {
+			a.alias("d", seg)
+		}
+	}
+}
+
+// Project returns p with its root name, file paths, impact list, focus,
+// and collapsed directories renamed. --exclude patterns are dropped since
+// they can name what was hidden.
+func (a *Anonymizer) Project(p Project) Project {
+	p.Root = a.Component(filepath.Base(p.Root))
+	files := make([]FileInfo, len(p.Files))
+	for i, f := range p.Files {
+		f.Path = a.Path(f.Path)
+		files[i] = f
+	}
+	p.Files = files
+	impact := make([]ImpactInfo, len(p.Impact))
+	for i, im := range p.Impact {
+		im.File = a.Path(im.File)
+		impact[i] = im
+	}
+	if p.Impact == nil {
+		impact = nil
+	}
+	p.Impact = impact
+	p.Focus = a.Path(p.Focus)
+	collapse := make([]string, len(p.Collapse))
+	for i, c := range p.Collapse {
+		collapse[i] = a.Path(c)
+	}
+	if p.Collapse == nil {
+		collapse = nil
+	}
+	p.Collapse = collapse
+	p.Exclude = nil
+	return p
+}
+
+// DepsProject returns p with file paths, function names, and internal
+// imports renamed, and Graph set to the renamed file graph fg (which may be
+// nil) so the renderer doesn't rebuild it from the real tree
+func (a *Anonymizer) DepsProject(p DepsProject, fg *FileGraph) DepsProject {
+	a.registerModule(detectModule(p.Root))
+	for _, f := range p.Files {
+		a.Path(f.Path)
+	}
+	files := make([]FileAnalysis, len(p.Files))
+	for i, f := range p.Files {
+		f.Path = a.Path(f.Path)
+		f.Functions = a.mapAll(f.Functions, a.Function)
+		f.Imports = a.mapAll(f.Imports, a.Import)
+		f.Exports = a.mapAll(f.Exports, a.Export)
+		files[i] = f
+	}
+	p.Files = files
+	p.Root = a.Component(filepath.Base(p.Root))
+	p.Focus = a.Path(p.Focus)
+	p.Graph = a.FileGraph(fg)
+	if p.Changed != nil {
+		changed := make(map[string]bool, len(p.Changed))
+		for path := range p.Changed {
+			changed[a.Path(path)] = true
+		}
+		p.Changed = changed
+	}
+	return p
+}
+
+// FileGraph returns a renamed copy of fg: same files and edges, new names
+func (a *Anonymizer) FileGraph(fg *FileGraph) *FileGraph {
+	if fg == nil {
+		return nil
+	}
+	a.registerModule(fg.Module)
+	out := &FileGraph{
+		Root:        a.Component(filepath.Base(fg.Root)),
+		Module:      a.Import(fg.Module),
+		Files:       a.mapAll(fg.Files, a.Path),
+		Imports:     a.mapEdges(fg.Imports, a.Path),
+		Importers:   a.mapEdges(fg.Importers, a.Path),
+		Packages:    a.mapEdges(fg.Packages, a.Import),
+		PathAliases: make(map[string][]string),
+
+		GoPackageEdges: fg.GoPackageEdges,
+		HubThresholds:  make(map[string]int),
+	}
+	// The renamed Root has no config to read, so pin each file's resolved
+	// hub threshold; files left out use the default, as before
+	for _, p := range append([]string{"."}, fg.Files...) {
+		if n := fg.HubThreshold(p); n != DefaultHubThreshold {
+			out.HubThresholds[a.Path(p)] = n
+		}
+	}
+	for p := range fg.Importers {
+		if n := fg.HubThreshold(p); n != DefaultHubThreshold {
+			out.HubThresholds[a.Path(p)] = n
+		}
+	}
+	if fg.Barrels != nil {
+		out.Barrels = make(map[string]string, len(fg.Barrels))
+		for barrel, target := range fg.Barrels {
+			out.Barrels[a.Path(barrel)] = a.Path(target)
+		}
+	}
+	if fg.Minified != nil {
+		out.Minified = make(map[string]bool, len(fg.Minified))
+		for f := range fg.Minified {
+			out.Minified[a.Path(f)] = true
+		}
+	}
+	// The globs would leak names and no longer match the hashed paths, so
+	// list the importers they matched instead; hashed names hold no glob
+	// metacharacters and match only themselves
+	for _, importers := range fg.Importers {
+		for _, imp := range importers {
+			if fg.IgnoredForHubs(imp) {
+				out.HubIgnoreImporters = append(out.HubIgnoreImporters, a.Path(imp))
+			}
+		}
+	}
+	if out.HubIgnoreImporters != nil {
+		out.HubIgnoreImporters = dedupe(out.HubIgnoreImporters)
+		sort.Strings(out.HubIgnoreImporters)
+	}
+	sort.Strings(out.Files)
+	return out
+}
+
+func (a *Anonymizer) mapAll(items []string, fn func(string) string) []string {
+	if items == nil {
+		return nil
+	}
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = fn(item)
+	}
+	return out
+}
+
+// mapEdges renames the values with a.Path and the keys with key
+func (a *Anonymizer) mapEdges(edges map[string][]string, key func(string) string) map[string][]string {
+	out := make(map[string][]string, len(edges))
+	for k, vs := range edges {
+		out[key(k)] = a.mapAll(vs, a.Path)
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizerPreservesShape(t *testing.T) {
+	root := t.TempDir()
+	secret := []string{"billing", "ledger", "invoice", "acme", "chargeCard", "refundAll"}
+
+	fg := &FileGraph{
+		Root:   filepath.Join(root, "acme"),
+		Module: "github.com/acme/billing",
+		Files:  []string{"main.go", "ledger/ledger.go", "ledger/invoice.go", "api/handlers.go"},
+		Imports: map[string][]string{
+			"main.go":         {"api/handlers.go"},
+			"api/handlers.go": {"ledger/ledger.go", "ledger/invoice.go"},
+		},
+		Importers: map[string][]string{
+			"api/handlers.go":   {"main.go"},
+			"ledger/ledger.go":  {"api/handlers.go"},
+			"ledger/invoice.go": {"api/handlers.go"},
+		},
+		HubIgnoreImporters: []string{"api/**"},
+	}
+	project := Project{
+		Root: fg.Root,
+		Files: []FileInfo{
+			{Path: "main.go", Size: 10, Ext: ".go"},
+			{Path: "ledger/ledger.go", Size: 20, Ext: ".go"},
+			{Path: "ledger/invoice.go", Size: 30, Ext: ".go"},
+			{Path: "api/handlers.go", Size: 40, Ext: ".go"},
+		},
+		Exclude: []string{"billing_secrets"},
+	}
+	deps := DepsProject{
+		Root: fg.Root,
+		Files: []FileAnalysis{
+			{Path: "ledger/ledger.go", Language: "go", Functions: []string{"chargeCard", "refundAll"}, Imports: []string{"fmt", "github.com/acme/billing/ledger"}},
+		},
+	}
+
+	a := NewAnonymizer()
+	anonProject := a.Project(project)
+	anonGraph := a.FileGraph(fg)
+	anonDeps := a.DepsProject(deps, fg)
+
+	// Same shape: file count, sizes, extensions, edge counts
+	if len(anonProject.Files) != len(project.Files) || len(anonGraph.Files) != len(fg.Files) {
+		t.Fatalf("file counts changed: %d/%d vs %d", len(anonProject.Files), len(anonGraph.Files), len(fg.Files))
+	}
+	for i, f := range anonProject.Files {
+		if f.Size != project.Files[i].Size || filepath.Ext(f.Path) != ".go" || strings.Count(f.Path, "/") != strings.Count(project.Files[i].Path, "/") {
+			t.Errorf("file %d lost its shape: %+v vs %+v", i, f, project.Files[i])
+		}
+	}
+	edges := func(m map[string][]string) (n int) {
+		for _, v := range m {
+			n += len(v)
+		}
+		return n
+	}
+	if edges(anonGraph.Imports) != edges(fg.Imports) || edges(anonGraph.Importers) != edges(fg.Importers) {
+		t.Errorf("edge counts changed: %v", anonGraph.Imports)
+	}
+	// Consistent: both ledger files live in the same renamed directory, and
+	// the graph and the tree agree on names
+	if filepath.Dir(a.Path("ledger/ledger.go")) != filepath.Dir(a.Path("ledger/invoice.go")) {
+		t.Error("files in one directory were split across aliases")
+	}
+	if got := anonGraph.Importers[a.Path("api/handlers.go")]; len(got) != 1 || got[0] != a.Path("main.go") {
+		t.Errorf("graph and tree disagree on names: %v", got)
+	}
+	if anonDeps.Graph == nil || anonDeps.Files[0].Imports[0] != "fmt" {
+		t.Errorf("third-party imports should stay readable: %+v", anonDeps.Files[0])
+	}
+
+	// --hub-ignore-importers still discounts the same importers
+	if !anonGraph.IgnoredForHubs(a.Path("api/handlers.go")) || anonGraph.IgnoredForHubs(a.Path("main.go")) {
+		t.Errorf("hub-ignore globs not carried over: %v", anonGraph.HubIgnoreImporters)
+	}
+
+	// No original names anywhere in the output
+	for _, v := range []any{anonProject, anonGraph, anonDeps} {
+		out, _ := json.Marshal(v)
+		for _, name := range append(secret, "handlers", "main") {
+			if strings.Contains(string(out), name) {
+				t.Errorf("%q leaked into %s", name, out)
+			}
+		}
+	}
+
+	// The saved mapping reproduces the same aliases and translates back
+	if err := a.Save(root); err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadAnonymizer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alias := b.Path("ledger/invoice.go")
+	if alias != a.Path("ledger/invoice.go") {
+		t.Errorf("reloaded mapping gave %q, want %q", alias, a.Path("ledger/invoice.go"))
+	}
+	stem := strings.TrimSuffix(filepath.Base(alias), ".go")
+	if b.Names[stem] != "d:invoice" {
+		t.Errorf("mapping for %s = %q, want d:invoice", stem, b.Names[stem])
+	}
+}
+
+func TestAnonymizedGraphKeepsHubRules(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "ledger", ".codemap"), 0755)
+	os.WriteFile(filepath.Join(root, "ledger", ConfigFile), []byte("hub_threshold = 2\n"), 0644)
+
+	fg := &FileGraph{
+		Root:  root,
+		Files: []string{"ledger/ledger.go", "api/handlers.go", "api/routes.go", "main.go"},
+		Importers: map[string][]string{
+			"ledger/ledger.go": {"api/handlers.go", "api/routes.go"},
+			"api/handlers.go":  {"main.go", "api/routes.go"},
+		},
+		GoPackageEdges: true,
+	}
+	a := NewAnonymizer()
+	anon := a.FileGraph(fg)
+
+	if !anon.GoPackageEdges {
+		t.Error("Expected GoPackageEdges to carry over")
+	}
+	if !anon.IsHub(a.Path("ledger/ledger.go")) {
+		t.Errorf("Expected ledger's hub_threshold = 2 to carry over, got thresholds %v", anon.HubThresholds)
+	}
+	if anon.IsHub(a.Path("api/handlers.go")) {
+		t.Error("Expected files outside ledger/ to keep the default threshold")
+	}
+}
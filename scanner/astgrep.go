@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -147,6 +148,9 @@ func (s *AstGrepScanner) ScanDirectory(root string) ([]FileAnalysis, error) {
 		if relPath == "" {
 			relPath = m.File
 		}
+		if IsCodemapPath(relPath) {
+			continue
+		}
 
 		if fileMap[relPath] == nil {
 			lang := detectLangFromRuleID(m.RuleID)
@@ -167,6 +171,13 @@ func (s *AstGrepScanner) ScanDirectory(root string) ([]FileAnalysis, error) {
 			if mod != "" {
 				fileMap[relPath].Imports = append(fileMap[relPath].Imports, mod)
 			}
+		} else if strings.HasSuffix(m.RuleID, "-exports") {
+			// Re-exports (export ... from "./x") also depend on their source
+			names, from := extractExports(m.Text, fileMap[relPath].Language)
+			fileMap[relPath].Exports = append(fileMap[relPath].Exports, names...)
+			if from != "" {
+				fileMap[relPath].Imports = append(fileMap[relPath].Imports, from)
+			}
 		} else if strings.HasSuffix(m.RuleID, "-functions") {
 			// Extract function name from text
 			name := extractFunctionName(m.Text, fileMap[relPath].Language)
@@ -181,6 +192,7 @@ func (s *AstGrepScanner) ScanDirectory(root string) ([]FileAnalysis, error) {
 	for _, a := range fileMap {
 		a.Functions = dedupe(a.Functions)
 		a.Imports = dedupe(a.Imports)
+		a.Exports = dedupe(a.Exports)
 		results = append(results, *a)
 	}
 
@@ -215,6 +227,8 @@ func detectLangFromRuleID(ruleID string) string {
 			return "cpp"
 		case "bash":
 			return "bash"
+		case "csharp", "php", "lua", "scala", "elixir", "solidity":
+			return parts[0]
 		}
 	}
 	return ""
@@ -308,8 +322,8 @@ func extractFunctionName(text string, lang string) string {
 					text = strings.TrimSpace(text[idx+1:])
 				}
 			}
-			// Get function name (up to paren)
-			if idx := strings.Index(text, "("); idx > 0 {
+			// Get function name (up to paren or type parameters)
+			if idx := strings.IndexAny(text, "[("); idx > 0 {
 				return text[:idx]
 			}
 		}
@@ -340,7 +354,8 @@ func extractFunctionName(text string, lang string) string {
 		}
 
 	case "python":
-		// def name(...):
+		// def name(...): or async def name(...):
+		text = strings.TrimPrefix(text, "async ")
 		if strings.HasPrefix(text, "def ") {
 			text = strings.TrimPrefix(text, "def ")
 			if paren := strings.Index(text, "("); paren > 0 {
@@ -362,21 +377,26 @@ func extractFunctionName(text string, lang string) string {
 			}
 		}
 
-	case "java":
+	case "java", "csharp":
 		// public void name(...) - method declaration
-		// Find last word before (
+		// Find last word before (, dropping generic parameters
 		if paren := strings.Index(text, "("); paren > 0 {
 			before := strings.TrimSpace(text[:paren])
 			parts := strings.Fields(before)
 			if len(parts) > 0 {
-				return parts[len(parts)-1]
+				name := parts[len(parts)-1]
+				if bracket := strings.Index(name, "<"); bracket > 0 {
+					name = name[:bracket]
+				}
+				return name
 			}
 		}
 
 	case "ruby":
-		// def name or def name(...)
+		// def name, def name(...) or def self.name
 		if strings.HasPrefix(text, "def ") {
 			text = strings.TrimPrefix(text, "def ")
+			text = strings.TrimPrefix(text, "self.")
 			if paren := strings.Index(text, "("); paren > 0 {
 				return text[:paren]
 			}
@@ -421,6 +441,56 @@ func extractFunctionName(text string, lang string) string {
 			}
 		}
 
+	case "php", "solidity":
+		// [modifiers] function name(...) or function &name(...)
+		if idx := strings.Index(text, "function "); idx >= 0 {
+			text = text[idx+9:]
+			if paren := strings.Index(text, "("); paren > 0 {
+				name := strings.TrimSpace(strings.TrimPrefix(text[:paren], "&"))
+				if isValidIdentifier(name) {
+					return name
+				}
+			}
+		}
+
+	case "lua":
+		// function name(...), local function name(...), function M.name(...) or M:name(...)
+		if idx := strings.Index(text, "function "); idx >= 0 {
+			text = text[idx+9:]
+			if paren := strings.Index(text, "("); paren > 0 {
+				name := strings.TrimSpace(text[:paren])
+				if sep := strings.LastIndexAny(name, ".:"); sep >= 0 {
+					name = name[sep+1:]
+				}
+				if isValidIdentifier(name) {
+					return name
+				}
+			}
+		}
+
+	case "scala":
+		// def name(...), def name[T](...), def name: T = ...
+		if idx := strings.Index(text, "def "); idx >= 0 {
+			text = text[idx+4:]
+			if end := strings.IndexAny(text, "([:= \n"); end > 0 {
+				return text[:end]
+			}
+		}
+
+	case "elixir":
+		// def name(...) do, defp name(...), def name, do: ...
+		for _, prefix := range []string{"defp ", "def "} {
+			if strings.HasPrefix(text, prefix) {
+				text = strings.TrimPrefix(text, prefix)
+				if end := strings.IndexAny(text, "(, \n"); end > 0 {
+					text = text[:end]
+				}
+				if isValidIdentifier(strings.TrimRight(text, "?!")) {
+					return text
+				}
+			}
+		}
+
 	case "bash":
 		// function name() or name()
 		text = strings.TrimPrefix(text, "function ")
@@ -435,6 +505,97 @@ func extractFunctionName(text string, lang string) string {
 	return ""
 }
 
+// pyAllNameRe matches the quoted names in a Python __all__ list
+var pyAllNameRe = regexp.MustCompile(`["']([A-Za-z_][A-Za-z0-9_]*)["']`)
+
+// extractExports returns the names an export statement provides and, for a
+// re-export (export ... from "./x"), the module it re-exports from
+func extractExports(text string, lang string) (names []string, from string) {
+	text = strings.TrimSpace(text)
+
+	switch lang {
+	case "python":
+		// __all__ = ["a", "b"] or __all__ += ("c",)
+		if eq := strings.Index(text, "="); eq >= 0 {
+			for _, m := range pyAllNameRe.FindAllStringSubmatch(text[eq+1:], -1) {
+				names = append(names, m[1])
+			}
+		}
+		return names, ""
+
+	case "typescript", "javascript":
+		if !strings.HasPrefix(text, "export") {
+			return nil, ""
+		}
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export"))
+
+		switch {
+		case strings.HasPrefix(text, "default"), strings.HasPrefix(text, "="):
+			// export default ..., or TS export = ...
+			return []string{"default"}, ""
+
+		case strings.HasPrefix(text, "*"):
+			// export * from "./x" or export * as ns from "./x"
+			from = extractImportPath(text)
+			rest := strings.TrimSpace(strings.TrimPrefix(text, "*"))
+			if strings.HasPrefix(rest, "as ") {
+				if name := leadingIdentifier(strings.TrimPrefix(rest, "as ")); name != "" {
+					return []string{name}, from
+				}
+			}
+			if from == "" {
+				return nil, ""
+			}
+			return []string{"* from " + from}, from
+
+		case strings.HasPrefix(text, "{"), strings.HasPrefix(text, "type {"):
+			// export { a, b as c } [from "./x"]
+			text = strings.TrimPrefix(text, "type ")
+			end := strings.Index(text, "}")
+			if end < 0 {
+				return nil, ""
+			}
+			for _, spec := range strings.Split(text[1:end], ",") {
+				spec = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(spec), "type "))
+				if idx := strings.LastIndex(spec, " as "); idx >= 0 {
+					spec = strings.TrimSpace(spec[idx+4:])
+				}
+				if isValidIdentifier(spec) || spec == "default" {
+					names = append(names, spec)
+				}
+			}
+			if rest := text[end+1:]; strings.Contains(rest, "from") {
+				from = extractImportPath(rest)
+			}
+			return names, from
+		}
+
+		// export [declare] [abstract|async] function|class|const|... name
+		for _, mod := range []string{"declare ", "abstract ", "async "} {
+			text = strings.TrimPrefix(text, mod)
+		}
+		for _, kw := range []string{"function*", "function ", "class ", "interface ", "type ", "const enum ", "enum ", "const ", "let ", "var ", "namespace ", "module "} {
+			if strings.HasPrefix(text, kw) {
+				if name := leadingIdentifier(strings.TrimSpace(strings.TrimPrefix(text, kw))); name != "" {
+					return []string{name}, ""
+				}
+				return nil, ""
+			}
+		}
+	}
+
+	return nil, ""
+}
+
+// leadingIdentifier returns the identifier at the start of s, or ""
+func leadingIdentifier(s string) string {
+	end := 0
+	for end < len(s) && (s[end] == '_' || s[end] == '$' || (s[end] >= 'a' && s[end] <= 'z') || (s[end] >= 'A' && s[end] <= 'Z') || (end > 0 && s[end] >= '0' && s[end] <= '9')) {
+		end++
+	}
+	return s[:end]
+}
+
 func isValidIdentifier(s string) bool {
 	if s == "" {
 		return false
@@ -3,6 +3,8 @@ package scanner
 import (
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -107,3 +109,271 @@ def greet():
 		t.Errorf("Expected os import, got: %v", analysis.Imports)
 	}
 }
+
+func TestExtractFunctionName(t *testing.T) {
+	tests := []struct {
+		lang, text, want string
+	}{
+		{"go", "func Map[T any](xs []T) []T {", "Map"},
+		{"go", "func (s *Server) Start() error {", "Start"},
+		{"python", "async def fetch(url):", "fetch"},
+		{"java", "public <T> List<T> wrap(T item) {", "wrap"},
+		{"csharp", "public static Task<int> CountAsync<T>(IEnumerable<T> xs) {", "CountAsync"},
+		{"ruby", "def self.build(opts)", "build"},
+		{"php", "public static function &make(array $args) {", "make"},
+		{"lua", "function M.setup(opts)", "setup"},
+		{"lua", "local function helper()", "helper"},
+		{"scala", "def sum[A](xs: List[A]): A = xs.sum", "sum"},
+		{"scala", "def name: String = \"x\"", "name"},
+		{"elixir", "def valid?(x), do: x > 0", "valid?"},
+		{"elixir", "defp parse(input) do", "parse"},
+		{"solidity", "function transfer(address to, uint amount) public returns (bool) {", "transfer"},
+	}
+	for _, tt := range tests {
+		if got := extractFunctionName(tt.text, tt.lang); got != tt.want {
+			t.Errorf("extractFunctionName(%q, %s) = %q, want %q", tt.text, tt.lang, got, tt.want)
+		}
+	}
+}
+
+// TestAstGrepFunctionPolicy checks every language against the counting policy
+// documented on FileAnalysis: declared functions and methods count; closures,
+// lambdas, and functions nested in another function's body do not.
+func TestAstGrepFunctionPolicy(t *testing.T) {
+	analyzer := NewAstGrepAnalyzer()
+	if !analyzer.Available() {
+		t.Skip("ast-grep (sg) not installed")
+	}
+
+	fixtures := []struct {
+		file, src string
+		want      []string
+	}{
+		{"policy.go", `package p
+
+type S struct{}
+
+func Top() {
+	f := func() {}
+	f()
+}
+
+func (s *S) Method() {}
+`, []string{"Method", "Top"}},
+		{"policy.py", `def top():
+    def inner():
+        pass
+    square = lambda x: x * x
+
+class C:
+    def method(self):
+        pass
+
+async def fetch():
+    pass
+`, []string{"fetch", "method", "top"}},
+		{"policy.js", `function top() {
+  function inner() {}
+  const arrow = () => {};
+}
+
+class C {
+  method() {
+    return [1].map(function (x) { return x; });
+  }
+}
+
+const handler = () => {};
+`, []string{"method", "top"}},
+		{"policy.ts", `export function top(): void {
+  function inner() {}
+}
+
+class C {
+  method(): number { return 1; }
+}
+
+const cb = (x: number) => x;
+`, []string{"method", "top"}},
+		{"policy.rs", `fn top() {
+    fn inner() {}
+    let c = |x: i32| x + 1;
+}
+
+struct S;
+
+impl S {
+    fn method(&self) {}
+}
+`, []string{"method", "top"}},
+		{"Policy.java", `class Policy {
+    Policy() {}
+
+    void top() {
+        Runnable r = () -> {};
+        Object o = new Object() {
+            public String toString() { return ""; }
+        };
+    }
+
+    static int helper() { return 1; }
+}
+`, []string{"helper", "top"}},
+		{"policy.rb", `class C
+  def top
+    [1].each { |x| x }
+  end
+
+  def self.build
+  end
+end
+`, []string{"build", "top"}},
+		{"policy.kt", `fun top() {
+    fun inner() {}
+    val l = { x: Int -> x }
+}
+
+class C {
+    fun method() {}
+}
+`, []string{"method", "top"}},
+		{"policy.swift", `func top() {
+    func inner() {}
+    let c = { (x: Int) in x }
+}
+
+struct S {
+    func method() {}
+}
+`, []string{"method", "top"}},
+		{"policy.lua", `local M = {}
+
+function M.setup()
+  local function inner() end
+  local cb = function() end
+end
+
+local function helper() end
+
+return M
+`, []string{"helper", "setup"}},
+		{"policy.php", `<?php
+function top() {
+    function inner() {}
+    $f = function () {};
+}
+
+class C {
+    public function method() {}
+}
+`, []string{"method", "top"}},
+	}
+
+	tmpDir := t.TempDir()
+	for _, fx := range fixtures {
+		if err := os.WriteFile(filepath.Join(tmpDir, fx.file), []byte(fx.src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err := analyzer.ScanDirectory(tmpDir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	byFile := make(map[string][]string)
+	for _, r := range results {
+		funcs := append([]string(nil), r.Functions...)
+		sort.Strings(funcs)
+		byFile[filepath.Base(r.Path)] = funcs
+	}
+
+	for _, fx := range fixtures {
+		got := byFile[fx.file]
+		if strings.Join(got, ",") != strings.Join(fx.want, ",") {
+			t.Errorf("%s: functions = %v, want %v", fx.file, got, fx.want)
+		}
+	}
+}
+
+func TestExtractExports(t *testing.T) {
+	tests := []struct {
+		lang, text string
+		names      []string
+		from       string
+	}{
+		{"typescript", "export function handler(req: Request) {\n  return 1;\n}", []string{"handler"}, ""},
+		{"typescript", "export async function load() {}", []string{"load"}, ""},
+		{"typescript", "export const API_URL = \"x\", TIMEOUT = 5;", []string{"API_URL"}, ""},
+		{"typescript", "export abstract class Base {}", []string{"Base"}, ""},
+		{"typescript", "export interface Props { id: string }", []string{"Props"}, ""},
+		{"typescript", "export type ID = string;", []string{"ID"}, ""},
+		{"typescript", "export const enum Mode { A }", []string{"Mode"}, ""},
+		{"typescript", "export default class App {}", []string{"default"}, ""},
+		{"typescript", "export = Legacy;", []string{"default"}, ""},
+		{"typescript", "export { a, b as c, type D };", []string{"a", "c", "D"}, ""},
+		{"typescript", "export { default as Button, size } from \"./button\";", []string{"Button", "size"}, "./button"},
+		{"typescript", "export { default } from './card'", []string{"default"}, "./card"},
+		{"typescript", "export type { Props } from \"./types\";", []string{"Props"}, "./types"},
+		{"typescript", "export * from \"./utils\";", []string{"* from ./utils"}, "./utils"},
+		{"javascript", "export * as helpers from './helpers'", []string{"helpers"}, "./helpers"},
+		{"javascript", "export function* ids() {}", []string{"ids"}, ""},
+		{"python", "__all__ = [\"Client\", 'connect']", []string{"Client", "connect"}, ""},
+		{"python", "__all__ += (\"extra\",)", []string{"extra"}, ""},
+	}
+	for _, tt := range tests {
+		names, from := extractExports(tt.text, tt.lang)
+		if strings.Join(names, ",") != strings.Join(tt.names, ",") || from != tt.from {
+			t.Errorf("extractExports(%q, %s) = %v, %q; want %v, %q", tt.text, tt.lang, names, from, tt.names, tt.from)
+		}
+	}
+}
+
+func TestAstGrepExports(t *testing.T) {
+	analyzer := NewAstGrepAnalyzer()
+	if !analyzer.Available() {
+		t.Skip("ast-grep (sg) not installed")
+	}
+
+	tmpDir := t.TempDir()
+	fixtures := map[string]string{
+		"index.ts": `export * from "./utils";
+export { default as Button } from "./button";
+export const VERSION = "1.0";
+export default function init() {}
+`,
+		"pkg.py": `__all__ = ["Client", "connect"]
+
+def connect():
+    pass
+`,
+	}
+	for name, src := range fixtures {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte(src), 0644)
+	}
+
+	results, err := analyzer.ScanDirectory(tmpDir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	got := make(map[string]FileAnalysis)
+	for _, r := range results {
+		got[r.Path] = r
+	}
+
+	want := map[string][]string{
+		"index.ts": {"* from ./utils", "Button", "VERSION", "default"},
+		"pkg.py":   {"Client", "connect"},
+	}
+	for file, exports := range want {
+		a := got[file]
+		sorted := append([]string(nil), a.Exports...)
+		sort.Strings(sorted)
+		if strings.Join(sorted, ",") != strings.Join(exports, ",") {
+			t.Errorf("%s exports = %v, want %v", file, sorted, exports)
+		}
+	}
+	// Re-export sources are dependencies too
+	imports := strings.Join(got["index.ts"].Imports, ",")
+	if !strings.Contains(imports, "./utils") || !strings.Contains(imports, "./button") {
+		t.Errorf("index.ts imports = %v, want re-export sources", got["index.ts"].Imports)
+	}
+}
@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+)
+
+// HubEntry is one line of a deduplicated hub list: a hub, the barrel folded
+// into it (if any), and the combined importers of both
+type HubEntry struct {
+	Path      string
+	Barrel    string   // the barrel re-exporting Path, "" if none was folded in
+	Importers []string // sorted, without the barrel itself
+}
+
+// findBarrels picks out JS/TS barrel files: files that export something,
+// define no functions of their own, import exactly one project file, and
+// only re-export it - every export is an "export * from" or a name the
+// imported module exports itself. A constants file that imports a types
+// module is not a barrel.
+func (fg *FileGraph) findBarrels(analyses []FileAnalysis) map[string]string {
+	exports := make(map[string]map[string]bool, len(analyses))
+	for _, a := range analyses {
+		exports[a.Path] = make(map[string]bool, len(a.Exports))
+		for _, name := range a.Exports {
+			exports[a.Path][name] = true
+		}
+	}
+
+	barrels := make(map[string]string)
+	for _, a := range analyses {
+		if a.Language != "javascript" && a.Language != "typescript" {
+			continue
+		}
+		if len(a.Exports) == 0 || len(a.Functions) > 0 {
+			continue
+		}
+		targets := fg.Imports[a.Path]
+		if len(targets) != 1 || targets[0] == a.Path {
+			continue
+		}
+		reexports := true
+		for _, name := range a.Exports {
+			if !strings.HasPrefix(name, "* from ") && !exports[targets[0]][name] {
+				reexports = false
+				break
+			}
+		}
+		if reexports {
+			barrels[a.Path] = targets[0]
+		}
+	}
+	return barrels
+}
+
+// FoldedBarrels maps each re-export target to the barrel folded into it.
+// When several barrels re-export one module, only the first by path is
+// folded; the others stay separate entries.
+func (fg *FileGraph) FoldedBarrels() map[string]string {
+	folded := make(map[string]string)
+	for barrel, target := range fg.Barrels {
+		if prev, taken := folded[target]; !taken || barrel < prev {
+			folded[target] = barrel
+		}
+	}
+	return folded
+}
+
+// DedupedHubs is the hub list with each barrel and its re-export target
+// counted as one module: the pair becomes a single entry under the target,
+// with the importers of both (less the barrel itself), and is a hub when
+// that combined count reaches the target's hub threshold. Files that aren't
+// part of a barrel pair are listed as HubFiles would. Most importers first.
+func (fg *FileGraph) DedupedHubs() []HubEntry {
+	folded := fg.FoldedBarrels()
+
+	var entries []HubEntry
+	for _, path := range fg.graphFiles() {
+		if target, ok := fg.Barrels[path]; ok && folded[target] == path {
+			continue // counted under its target
+		}
+		if fg.Minified[path] {
+			continue // never a hub, see IsHub
+		}
+		barrel := folded[path]
+		seen := make(map[string]bool)
+		var importers []string
+		count := 0
+		for _, f := range []string{path, barrel} {
+			if f == "" {
+				continue
+			}
+			for _, imp := range fg.ImportersOf(f) {
+				if imp == barrel || seen[imp] {
+					continue
+				}
+				seen[imp] = true
+				importers = append(importers, imp)
+				if !fg.IgnoredForHubs(imp) {
+					count++
+				}
+			}
+		}
+		if count > 0 && count >= fg.HubThreshold(path) {
+			sort.Strings(importers)
+			entries = append(entries, HubEntry{Path: path, Barrel: barrel, Importers: importers})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if len(entries[i].Importers) != len(entries[j].Importers) {
+			return len(entries[i].Importers) > len(entries[j].Importers)
+		}
+		return entries[i].Path < entries[j].Path
+	})
+	return entries
+}
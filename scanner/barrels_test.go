@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDedupedHubsFoldsBarrel(t *testing.T) {
+	analyses := []FileAnalysis{
+		{Path: "ui/index.ts", Language: "typescript", Exports: []string{"* from ./button"}, Imports: []string{"./button"}},
+		{Path: "ui/button.ts", Language: "typescript", Exports: []string{"Button"}, Functions: []string{"Button"}},
+		{Path: "lib/util.ts", Language: "typescript", Exports: []string{"clamp"}, Functions: []string{"clamp"}},
+		{Path: "ui/icons.ts", Language: "typescript", Exports: []string{"Icon", "default"}, Imports: []string{"./svg"}},
+		{Path: "ui/svg.ts", Language: "typescript", Exports: []string{"Icon", "default"}, Functions: []string{"Icon"}},
+		// Imports one module but exports its own constants
+		{Path: "lib/constants.ts", Language: "typescript", Exports: []string{"MAX_RETRIES", "TIMEOUT"}, Imports: []string{"./types"}},
+		{Path: "lib/types.ts", Language: "typescript", Exports: []string{"Options"}},
+	}
+	// Pages import the barrel, a few reach past it to button.ts directly;
+	// form.ts does both
+	fg := &FileGraph{
+		Imports: map[string][]string{
+			"ui/index.ts":      {"ui/button.ts"},
+			"ui/icons.ts":      {"ui/svg.ts"},
+			"lib/constants.ts": {"lib/types.ts"},
+		},
+		Importers: map[string][]string{
+			"ui/index.ts":  {"pages/a.ts", "pages/b.ts", "pages/c.ts", "pages/form.ts"},
+			"ui/button.ts": {"pages/form.ts", "pages/d.ts", "ui/index.ts"},
+			"lib/util.ts":  {"pages/a.ts", "pages/b.ts", "pages/c.ts"},
+		},
+	}
+	fg.Barrels = fg.findBarrels(analyses)
+	if want := map[string]string{"ui/index.ts": "ui/button.ts", "ui/icons.ts": "ui/svg.ts"}; !reflect.DeepEqual(fg.Barrels, want) {
+		t.Fatalf("Barrels = %v, want %v", fg.Barrels, want)
+	}
+
+	// Without deduping both the barrel and button.ts are hubs
+	if hubs := fg.SortedHubs(false); !reflect.DeepEqual(hubs, []string{"ui/index.ts", "lib/util.ts", "ui/button.ts"}) {
+		t.Errorf("SortedHubs = %v", hubs)
+	}
+
+	want := []HubEntry{
+		{Path: "ui/button.ts", Barrel: "ui/index.ts", Importers: []string{"pages/a.ts", "pages/b.ts", "pages/c.ts", "pages/d.ts", "pages/form.ts"}},
+		{Path: "lib/util.ts", Importers: []string{"pages/a.ts", "pages/b.ts", "pages/c.ts"}},
+	}
+	if got := fg.DedupedHubs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DedupedHubs = %+v, want %+v", got, want)
+	}
+}
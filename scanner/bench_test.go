@@ -1,7 +1,11 @@
 package scanner
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -71,3 +75,65 @@ func TestAstGrepScanner(t *testing.T) {
 		t.Error("Expected to find some functions")
 	}
 }
+
+// langBenchFiles writes a mixed file set where a third of the files have an
+// ambiguous extension (.ts, .h) and need sniffing
+func langBenchFiles(b *testing.B) []string {
+	root := b.TempDir()
+	var paths []string
+	for i := 0; i < 3000; i++ {
+		name := fmt.Sprintf("pkg%d/file%d%s", i%50, i, []string{".go", ".ts", ".py", ".h", ".rs", ".JS"}[i%6])
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte("// generated\nexport const x = 1;\n"), 0644); err != nil {
+			b.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// BenchmarkDetectLanguage is a repeated scan over the same files: after the
+// first pass every ambiguous file costs a stat instead of a read
+func BenchmarkDetectLanguage(b *testing.B) {
+	paths := langBenchFiles(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range paths {
+			DetectLanguage(p)
+		}
+	}
+}
+
+// BenchmarkScanFiles compares the sequential walk with ScanFilesParallel on
+// the same mixed tree (run with -benchtime to smooth out disk caching)
+func BenchmarkScanFiles(b *testing.B) {
+	root := filepath.Dir(filepath.Dir(langBenchFiles(b)[0]))
+	for _, workers := range []int{1, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.Setenv(ScanWorkersEnv, strconv.Itoa(workers))
+			cache := NewGitIgnoreCache(root)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := ScanFiles(root, cache, nil, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDetectLanguageUncached is the same work without the sniff cache,
+// for comparison
+func BenchmarkDetectLanguageUncached(b *testing.B) {
+	paths := langBenchFiles(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range paths {
+			ext := strings.ToLower(filepath.Ext(p))
+			if ambiguousExts[ext] {
+				sniffLanguage(p, ext)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package scanner
+
+import "sort"
+
+// BlastRadius returns, for every imported file, the size of its transitive
+// importer set: everything that would be affected, directly or through a
+// chain of imports, by a change to it. Computed once per graph and cached.
+func (fg *FileGraph) BlastRadius() map[string]int {
+	fg.blastOnce.Do(func() {
+		fg.blast = make(map[string]int, len(fg.Importers))
+		for _, path := range fg.graphFiles() {
+			if len(fg.ImportersOf(path)) > 0 {
+				fg.blast[path] = len(fg.transitiveImporters(path))
+			}
+		}
+	})
+	return fg.blast
+}
+
+// transitiveImporters walks ImportersOf edges breadth-first from path, returning
+// every file reached (excluding path itself, even through a cycle)
+func (fg *FileGraph) transitiveImporters(path string) map[string]bool {
+	seen := map[string]bool{path: true}
+	queue := []string{path}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, imp := range fg.ImportersOf(cur) {
+			if !seen[imp] {
+				seen[imp] = true
+				queue = append(queue, imp)
+			}
+		}
+	}
+	delete(seen, path)
+	return seen
+}
+
+// SortedHubs returns HubFiles ordered by direct importer count, or by
+// BlastRadius when byBlast is set. Ties fall back to the other count, then path.
+func (fg *FileGraph) SortedHubs(byBlast bool) []string {
+	hubs := fg.HubFiles()
+	var blast map[string]int
+	if byBlast {
+		blast = fg.BlastRadius()
+	}
+	sort.Slice(hubs, func(i, j int) bool {
+		di, dj := len(fg.ImportersOf(hubs[i])), len(fg.ImportersOf(hubs[j]))
+		if byBlast {
+			if blast[hubs[i]] != blast[hubs[j]] {
+				return blast[hubs[i]] > blast[hubs[j]]
+			}
+		}
+		if di != dj {
+			return di > dj
+		}
+		return hubs[i] < hubs[j]
+	})
+	return hubs
+}
@@ -0,0 +1,88 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlastRadiusReordersHubs(t *testing.T) {
+	// util.go has 5 leaf importers; config.go has only 3 direct importers,
+	// but each of those is itself imported by several files.
+	fg := &FileGraph{
+		Importers: map[string][]string{
+			"util.go":   {"a.go", "b.go", "c.go", "d.go", "e.go"},
+			"config.go": {"server.go", "store.go", "cache.go"},
+			"server.go": {"main.go", "cmd.go", "api.go"},
+			"store.go":  {"main.go", "repo.go", "job.go"},
+			"cache.go":  {"job.go", "worker.go"},
+			"api.go":    {"server.go"}, // cycle: must not count server.go twice or loop
+		},
+	}
+
+	blast := fg.BlastRadius()
+	// server, store, cache, main, cmd, api, repo, job, worker
+	if blast["config.go"] != 9 {
+		t.Errorf("blast[config.go] = %d, want 9", blast["config.go"])
+	}
+	if blast["util.go"] != 5 {
+		t.Errorf("blast[util.go] = %d, want 5", blast["util.go"])
+	}
+
+	if got, want := fg.SortedHubs(false), []string{"util.go", "config.go", "server.go", "store.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SortedHubs(direct) = %v, want %v", got, want)
+	}
+	if got, want := fg.SortedHubs(true), []string{"config.go", "util.go", "server.go", "store.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SortedHubs(blast) = %v, want %v", got, want)
+	}
+}
+
+func TestHubIgnoreImporters(t *testing.T) {
+	fg := &FileGraph{
+		Importers: map[string][]string{
+			"api/client.go": {"cmd/main.go", "examples/basic/main.go", "examples/retry/main.go"},
+			"db/conn.go":    {"api/server.go", "cmd/main.go", "jobs/sync.go"},
+		},
+		HubIgnoreImporters: []string{"examples/**"},
+	}
+
+	if fg.IsHub("api/client.go") {
+		t.Error("Expected examples/ importers not to push api/client.go over the threshold")
+	}
+	if got := fg.HubImporterCount("api/client.go"); got != 1 {
+		t.Errorf("HubImporterCount(api/client.go) = %d, want 1", got)
+	}
+	if len(fg.Importers["api/client.go"]) != 3 {
+		t.Error("Ignored importers should stay in the importer list")
+	}
+	if got := fg.HubFiles(); !reflect.DeepEqual(got, []string{"db/conn.go"}) {
+		t.Errorf("HubFiles() = %v, want [db/conn.go]", got)
+	}
+
+	fg.HubIgnoreImporters = nil
+	if !fg.IsHub("api/client.go") {
+		t.Error("Without ignore globs every importer counts")
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"examples/**", "examples/basic/main.go", true},
+		{"examples/**", "examples", true},
+		{"examples/**", "src/examples/main.go", false},
+		{"**/gen/*.go", "api/v1/gen/types.go", true},
+		{"**/gen/*.go", "gen/types.go", true},
+		{"**/gen/*.go", "gen/sub/types.go", false},
+		{"docs/*.md", "docs/intro.md", true},
+		{"testdata", "scanner/testdata/x.go", true},
+		{"*_test.go", "api/server_test.go", true},
+		{"tmp/*", "tmp/cache/a.go", true},
+		{"docs/*.md", "src/docs/intro.md", false},
+	} {
+		if got := MatchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
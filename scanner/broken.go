@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BrokenImport is an internal-looking import that resolves to no file on disk,
+// typically a reference to a file that was deleted or moved
+type BrokenImport struct {
+	File   string // importing file (relative path)
+	Import string // the import as written
+}
+
+// looksInternal reports whether an import should point at a file in this
+// project: relative paths, Go imports under the module path, and TS/JS path
+// aliases. Anything else is treated as an external dependency.
+func looksInternal(imp, goModule string, pathAliases map[string][]string) bool {
+	imp = strings.Trim(imp, "\"'`")
+	if strings.HasPrefix(imp, "./") || strings.HasPrefix(imp, "../") {
+		return true
+	}
+	if goModule != "" && (imp == goModule || strings.HasPrefix(imp, goModule+"/")) {
+		return true
+	}
+	for pattern := range pathAliases {
+		prefix := pattern
+		if starIdx := strings.Index(pattern, "*"); starIdx >= 0 {
+			prefix = pattern[:starIdx]
+		} else if imp == pattern {
+			return true
+		}
+		if prefix != "" && strings.HasPrefix(imp, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindBrokenImports returns internal-looking imports that resolve to nothing.
+// Imports of files that exist but aren't indexed as code (e.g. "./foo.js"
+// written for foo.ts, or a directory) are not reported.
+func FindBrokenImports(fg *FileGraph, analyses []FileAnalysis) []BrokenImport {
+	files := make([]FileInfo, len(fg.Files))
+	for i, p := range fg.Files {
+		files[i] = FileInfo{Path: p}
+	}
+	idx := buildFileIndex(files, fg.Module)
+
+	var broken []BrokenImport
+	for _, a := range analyses {
+		for _, imp := range a.Imports {
+			if !looksInternal(imp, fg.Module, fg.PathAliases) {
+				continue
+			}
+			if len(fuzzyResolve(imp, a.Path, idx, fg.Module, fg.PathAliases, fg.BaseURL)) > 0 {
+				continue
+			}
+			if resolvesLoosely(imp, a.Path, idx) {
+				continue
+			}
+			broken = append(broken, BrokenImport{File: a.Path, Import: imp})
+		}
+	}
+
+	sort.Slice(broken, func(i, j int) bool {
+		if broken[i].File != broken[j].File {
+			return broken[i].File < broken[j].File
+		}
+		return broken[i].Import < broken[j].Import
+	})
+	return broken
+}
+
+// resolvesLoosely catches relative imports fuzzyResolve doesn't model: an
+// explicit extension that differs from the source file (ESM "./foo.js" for
+// foo.ts) or a directory import whose index file uses another extension.
+func resolvesLoosely(imp, fromFile string, idx *fileIndex) bool {
+	if !strings.HasPrefix(imp, ".") {
+		return false
+	}
+	fromDir := filepath.Dir(fromFile)
+	if fromDir == "." {
+		fromDir = ""
+	}
+	if ext := filepath.Ext(imp); ext != "" {
+		if len(resolveRelative(strings.TrimSuffix(imp, ext), fromDir, idx)) > 0 {
+			return true
+		}
+	}
+	target := filepath.Clean(filepath.Join(fromDir, imp))
+	for dir := range idx.byDir {
+		if dir == target || strings.HasPrefix(dir, target+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
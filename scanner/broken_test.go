@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindBrokenImports(t *testing.T) {
+	fg := &FileGraph{
+		Module: "example.com/app",
+		Files: []string{
+			"main.go",
+			"store/store.go",
+			"web/app.ts",
+			"web/util.ts",
+			"web/components/index.jsx",
+		},
+		PathAliases: map[string][]string{"@lib/*": {"web/*"}},
+	}
+	analyses := []FileAnalysis{
+		{Path: "main.go", Imports: []string{
+			"example.com/app/store",  // resolves
+			"example.com/app/legacy", // package deleted
+			"fmt",                    // stdlib
+		}},
+		{Path: "web/app.ts", Imports: []string{
+			"./util",       // resolves
+			"./util.js",    // ESM spelling of util.ts
+			"./components", // directory import
+			"./deleted",    // file removed
+			"@lib/util",    // alias resolves
+			"@lib/gone",    // alias, file removed
+			"react",        // external package
+		}},
+	}
+
+	broken := FindBrokenImports(fg, analyses)
+	want := []BrokenImport{
+		{File: "main.go", Import: "example.com/app/legacy"},
+		{File: "web/app.ts", Import: "./deleted"},
+		{File: "web/app.ts", Import: "@lib/gone"},
+	}
+	if len(broken) != len(want) {
+		t.Fatalf("Expected %d broken imports, got %d: %v", len(want), len(broken), broken)
+	}
+	for i := range want {
+		if broken[i] != want[i] {
+			t.Errorf("broken[%d] = %v, want %v", i, broken[i], want[i])
+		}
+	}
+}
+
+func TestMissingTargets(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"a.go", "b.go", "util.go"} {
+		if err := os.WriteFile(filepath.Join(root, f), []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fg := &FileGraph{
+		Root: root,
+		Importers: map[string][]string{
+			"util.go": {"a.go", "b.go"},
+			"old.go":  {"a.go", "b.go"}, // deleted after the graph was built
+		},
+	}
+
+	if !fg.OnDisk("util.go") {
+		t.Error("Expected util.go on disk")
+	}
+	if fg.OnDisk("old.go") {
+		t.Error("Expected old.go to be missing")
+	}
+
+	if err := os.Remove(filepath.Join(root, "util.go")); err != nil {
+		t.Fatal(err)
+	}
+	missing := fg.MissingTargets()
+	if len(missing) != 2 || missing[0] != "old.go" || missing[1] != "util.go" {
+		t.Errorf("Expected [old.go util.go] missing, got %v", missing)
+	}
+}
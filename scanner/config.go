@@ -0,0 +1,329 @@
+package scanner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ConfigFile is the per-project settings file, relative to the project root
+const ConfigFile = ".codemap/config.toml"
+
+// DefaultHubThreshold is how many importers make a file a hub when no
+// config sets hub_threshold
+const DefaultHubThreshold = 3
+
+// Go import edge granularities for the go_imports setting
+const (
+	GoImportsFile    = "file"    // an import links to each file of the package (default)
+	GoImportsPackage = "package" // an import links once, to the package's directory
+)
+
+// Config holds persistent per-project settings read from ConfigFile.
+// Command-line flags override anything set here.
+type Config struct {
+	Focus        string // subdirectory that output is scoped to (see FocusGraph)
+	HubThreshold int    // importers needed to count as a hub; 0 means DefaultHubThreshold
+	GoImports    string // GoImportsFile or GoImportsPackage; "" means GoImportsFile
+
+	// HubIgnoreImporters are globs for importers that don't count toward
+	// hub status (see FileGraph.HubIgnoreImporters), given comma-separated
+	HubIgnoreImporters []string
+
+	// IgnoreFiles replaces DefaultIgnoreFiles as the ignore filenames read
+	// in every directory (see NewGitIgnoreCache); nil keeps the defaults
+	IgnoreFiles []string
+
+	// IncludeMinified treats minified files (see LooksMinified) like any
+	// other: counted in line stats and eligible as hubs
+	IncludeMinified bool
+
+	// Event time rendering (see watch.LoadTimeFormat); "" keeps the defaults
+	TimeFormat    string // Go layout for event times in activity and summaries
+	LogTimeFormat string // Go layout for .codemap/events.log timestamps
+	Timezone      string // IANA zone name (e.g. "UTC", "Europe/Berlin")
+
+	// Hook output caps (see codemap hook); 0 keeps the defaults
+	HookMaxImporters int // dependents listed when editing a hub
+	HookMaxHubs      int // hubs listed at session start
+}
+
+// LoadConfig reads root's ConfigFile. A missing file is not an error and
+// yields the zero Config. Only a subset of TOML is understood: comments,
+// [section] headers, and key = value pairs whose value is a string, a bare
+// number or boolean, or a one-line array of strings; unknown keys are
+// ignored so newer configs still load.
+func LoadConfig(root string) (Config, error) {
+	return loadConfigFile(filepath.Join(root, ConfigFile))
+}
+
+// loadConfigFile parses one config file; see LoadConfig
+func loadConfigFile(path string) (Config, error) {
+	var cfg Config
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+
+	values, lists, err := parseConfigTOML(bufio.NewScanner(f))
+	if err != nil {
+		return cfg, fmt.Errorf("%s: %w", ConfigFile, err)
+	}
+	for _, key := range scalarConfigKeys {
+		if _, ok := lists[key]; ok {
+			return cfg, fmt.Errorf("%s: %s takes a single value, not an array", ConfigFile, key)
+		}
+	}
+	cfg.Focus = values["focus"]
+	cfg.TimeFormat = values["time_format"]
+	cfg.LogTimeFormat = values["log_time_format"]
+	cfg.Timezone = values["timezone"]
+	cfg.HubIgnoreImporters = configList(values, lists, "hub_ignore_importers")
+	cfg.IgnoreFiles = configList(values, lists, "ignore_files")
+	switch v := values["go_imports"]; v {
+	case "", GoImportsFile, GoImportsPackage:
+		cfg.GoImports = v
+	default:
+		return cfg, fmt.Errorf("%s: go_imports must be %q or %q, got %q", ConfigFile, GoImportsFile, GoImportsPackage, v)
+	}
+	switch v := values["include_minified"]; v {
+	case "", "false":
+	case "true":
+		cfg.IncludeMinified = true
+	default:
+		return cfg, fmt.Errorf("%s: include_minified must be true or false, got %q", ConfigFile, v)
+	}
+	if v, ok := values["hub_threshold"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("%s: hub_threshold must be a positive integer, got %q", ConfigFile, v)
+		}
+		cfg.HubThreshold = n
+	}
+	for _, c := range []struct {
+		key   string
+		field *int
+	}{{"hook_max_importers", &cfg.HookMaxImporters}, {"hook_max_hubs", &cfg.HookMaxHubs}} {
+		if v, ok := values[c.key]; ok {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return cfg, fmt.Errorf("%s: %s must be a positive integer, got %q", ConfigFile, c.key, v)
+			}
+			*c.field = n
+		}
+	}
+	return cfg, nil
+}
+
+// scalarConfigKeys are the config keys that take one value; an array for
+// any of them is an error rather than a silently misread setting
+var scalarConfigKeys = []string{
+	"focus", "hub_threshold", "go_imports", "include_minified",
+	"time_format", "log_time_format", "timezone",
+	"hook_max_importers", "hook_max_hubs",
+}
+
+// configList returns the items of a list setting, given either as an array
+// of strings or as one comma-separated string; blank items are dropped
+func configList(values map[string]string, lists map[string][]string, key string) []string {
+	items, ok := lists[key]
+	if !ok {
+		items = strings.Split(values[key], ",")
+	}
+	var out []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// overlay returns c with every field set in o replacing c's
+func (c Config) overlay(o Config) Config {
+	if o.Focus != "" {
+		c.Focus = o.Focus
+	}
+	if o.HubThreshold != 0 {
+		c.HubThreshold = o.HubThreshold
+	}
+	if o.GoImports != "" {
+		c.GoImports = o.GoImports
+	}
+	if o.HubIgnoreImporters != nil {
+		c.HubIgnoreImporters = o.HubIgnoreImporters
+	}
+	if o.IgnoreFiles != nil {
+		c.IgnoreFiles = o.IgnoreFiles
+	}
+	if o.IncludeMinified {
+		c.IncludeMinified = true
+	}
+	if o.TimeFormat != "" {
+		c.TimeFormat = o.TimeFormat
+	}
+	if o.LogTimeFormat != "" {
+		c.LogTimeFormat = o.LogTimeFormat
+	}
+	if o.Timezone != "" {
+		c.Timezone = o.Timezone
+	}
+	if o.HookMaxImporters != 0 {
+		c.HookMaxImporters = o.HookMaxImporters
+	}
+	if o.HookMaxHubs != 0 {
+		c.HookMaxHubs = o.HookMaxHubs
+	}
+	return c
+}
+
+// ConfigCache resolves per-directory settings in a monorepo. Any directory
+// may have its own ConfigFile; settings cascade like .gitignore, with the
+// nearest config overriding its ancestors for files beneath it. Configs are
+// loaded lazily as directories are asked about, and a subdirectory config
+// that fails to parse is skipped, as an unreadable .gitignore would be.
+type ConfigCache struct {
+	root    string
+	mu      sync.Mutex
+	configs map[string]*Config // abs dir path -> its own config (nil if it has none)
+}
+
+// NewConfigCache creates a cache rooted at the project root
+func NewConfigCache(root string) *ConfigCache {
+	absRoot, _ := filepath.Abs(root)
+	return &ConfigCache{root: absRoot, configs: make(map[string]*Config)}
+}
+
+// For returns the effective config for relPath (a file or directory
+// relative to root): the root config overlaid by each config on the way
+// down to relPath's directory.
+func (c *ConfigCache) For(relPath string) Config {
+	var dirs []string
+	for dir := filepath.Dir(filepath.Join(c.root, relPath)); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == c.root || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var cfg Config
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if own := c.load(dirs[i]); own != nil {
+			cfg = cfg.overlay(*own)
+		}
+	}
+	return cfg
+}
+
+// load returns dir's own config, reading it on first use. Caller holds c.mu.
+func (c *ConfigCache) load(dir string) *Config {
+	if cfg, seen := c.configs[dir]; seen {
+		return cfg
+	}
+	var own *Config
+	if cfg, err := loadConfigFile(filepath.Join(dir, ConfigFile)); err == nil && !reflect.DeepEqual(cfg, Config{}) {
+		own = &cfg
+	}
+	c.configs[dir] = own
+	return own
+}
+
+// parseConfigTOML returns key -> string value and, for keys set to an
+// array, key -> items, with keys under a [section] prefixed "section."
+// (see LoadConfig for the TOML subset read)
+func parseConfigTOML(sc *bufio.Scanner) (map[string]string, map[string][]string, error) {
+	values := make(map[string]string)
+	lists := make(map[string][]string)
+	section := ""
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1:len(line)-1]) + "."
+			continue
+		}
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		key, raw = section+strings.TrimSpace(key), strings.TrimSpace(raw)
+
+		var rest string
+		var err error
+		switch {
+		case strings.HasPrefix(raw, "["):
+			var items []string
+			items, rest, err = tomlArray(raw)
+			lists[key] = items
+		case strings.HasPrefix(raw, `"`), strings.HasPrefix(raw, "'"):
+			values[key], rest, err = tomlString(raw)
+		default:
+			// Bare values (numbers, booleans) are kept verbatim
+			value, _, _ := strings.Cut(raw, "#")
+			values[key] = strings.TrimSpace(value)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %s: %w", n, key, err)
+		}
+		if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+			return nil, nil, fmt.Errorf("line %d: %s: unexpected %q after the value", n, key, rest)
+		}
+	}
+	return values, lists, sc.Err()
+}
+
+// tomlString parses the "basic" or 'literal' string that s starts with and
+// returns it and the text after its closing quote
+func tomlString(s string) (string, string, error) {
+	if strings.HasPrefix(s, "'") {
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", errors.New("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+	quoted, err := strconv.QuotedPrefix(s)
+	if err != nil || !strings.HasPrefix(quoted, `"`) {
+		return "", "", errors.New("unterminated string")
+	}
+	value, err := strconv.Unquote(quoted)
+	return value, s[len(quoted):], err
+}
+
+// tomlArray parses the one-line array of strings that s starts with and
+// returns its items and the text after the closing bracket
+func tomlArray(s string) ([]string, string, error) {
+	items := []string{}
+	rest := strings.TrimSpace(s[1:])
+	for !strings.HasPrefix(rest, "]") {
+		if rest == "" || strings.HasPrefix(rest, "#") {
+			return nil, "", errors.New("unterminated array (arrays must fit on one line)")
+		}
+		item, after, err := tomlString(rest)
+		if err != nil {
+			return nil, "", errors.New("arrays may only hold quoted strings")
+		}
+		items = append(items, item)
+		rest = strings.TrimSpace(after)
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimSpace(rest[1:])
+		} else if !strings.HasPrefix(rest, "]") {
+			return nil, "", errors.New("expected , or ] in array")
+		}
+	}
+	return items, rest[1:], nil
+}
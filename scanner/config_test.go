@@ -0,0 +1,179 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+
+	cfg, err := LoadConfig(root)
+	if err != nil || !reflect.DeepEqual(cfg, Config{}) {
+		t.Fatalf("missing config: got %+v, %v; want zero Config, nil", cfg, err)
+	}
+
+	os.MkdirAll(filepath.Join(root, ".codemap"), 0755)
+	content := "# monorepo settings\nfocus = \"services/api\"  \n\n[future]\nfocus = \"ignored\"\nenabled = true # bare values are allowed\n"
+	if err := os.WriteFile(filepath.Join(root, ConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Focus != "services/api" {
+		t.Errorf("Focus = %q, want services/api", cfg.Focus)
+	}
+
+	os.WriteFile(filepath.Join(root, ConfigFile), []byte("focus services/api\n"), 0644)
+	if _, err := LoadConfig(root); err == nil {
+		t.Error("expected an error for a line without '='")
+	}
+
+	os.WriteFile(filepath.Join(root, ConfigFile), []byte("go_imports = \"package\"\n"), 0644)
+	if cfg, err := LoadConfig(root); err != nil || cfg.GoImports != GoImportsPackage {
+		t.Errorf("go_imports = package: got %q, %v", cfg.GoImports, err)
+	}
+	os.WriteFile(filepath.Join(root, ConfigFile), []byte("hub_ignore_importers = \"examples/**, docs/*.md,\"\n"), 0644)
+	if cfg, err := LoadConfig(root); err != nil || !reflect.DeepEqual(cfg.HubIgnoreImporters, []string{"examples/**", "docs/*.md"}) {
+		t.Errorf("hub_ignore_importers: got %q, %v", cfg.HubIgnoreImporters, err)
+	}
+	os.WriteFile(filepath.Join(root, ConfigFile), []byte("go_imports = \"module\"\n"), 0644)
+	if _, err := LoadConfig(root); err == nil {
+		t.Error("expected an error for an unknown go_imports value")
+	}
+}
+
+func TestParseConfigTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		key     string
+		value   string
+		list    []string
+		wantErr bool
+	}{
+		{name: "quoted", line: `focus = "services/api"`, key: "focus", value: "services/api"},
+		{name: "quoted with comment", line: `focus = "x" # note`, key: "focus", value: "x"},
+		{name: "hash inside quotes", line: `time_format = "15:04 #1"  # clock`, key: "time_format", value: "15:04 #1"},
+		{name: "literal string", line: `focus = 'a\b' # raw`, key: "focus", value: `a\b`},
+		{name: "bare with comment", line: "hub_threshold = 5 # five", key: "hub_threshold", value: "5"},
+		{name: "array", line: `exclude = ["a", "b"]`, key: "exclude", list: []string{"a", "b"}},
+		{name: "array with comment and trailing comma", line: `exclude = [ "a/**", 'b', ] # two`, key: "exclude", list: []string{"a/**", "b"}},
+		{name: "empty array", line: "exclude = []", key: "exclude", list: []string{}},
+		{name: "text after string", line: `focus = "a" "b"`, wantErr: true},
+		{name: "unterminated string", line: `focus = "a`, wantErr: true},
+		{name: "multi-line array", line: `exclude = ["a",`, wantErr: true},
+		{name: "array of numbers", line: "exclude = [1, 2]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, lists, err := parseConfigTOML(bufio.NewScanner(strings.NewReader(tt.line + "\n")))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got values %v, lists %v", values, lists)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.list != nil {
+				if got, ok := lists[tt.key]; !ok || !reflect.DeepEqual(got, tt.list) {
+					t.Errorf("%s = %q (array: %v), want %q", tt.key, got, ok, tt.list)
+				}
+				return
+			}
+			if got, ok := values[tt.key]; !ok || got != tt.value {
+				t.Errorf("%s = %q, want %q", tt.key, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestLoadConfigArrays(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, ".codemap"), 0755)
+
+	tests := []struct {
+		content string
+		want    []string
+		errKey  string // key the error must name
+	}{
+		{content: `hub_ignore_importers = ["examples/**", "docs/*.md"] # noisy`, want: []string{"examples/**", "docs/*.md"}},
+		{content: `hub_ignore_importers = "examples/**, docs/*.md" # noisy`, want: []string{"examples/**", "docs/*.md"}},
+		{content: `focus = ["a", "b"]`, errKey: "focus"},
+		{content: `hub_threshold = ["5"]`, errKey: "hub_threshold"},
+		{content: `future_list = ["a"]`}, // unknown keys are ignored, arrays included
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(filepath.Join(root, ConfigFile), []byte(tt.content+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfig(root)
+		if tt.errKey != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.errKey) {
+				t.Errorf("%s: expected an error naming %s, got %v", tt.content, tt.errKey, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(cfg.HubIgnoreImporters, tt.want) {
+			t.Errorf("%s: HubIgnoreImporters = %q, want %q", tt.content, cfg.HubIgnoreImporters, tt.want)
+		}
+	}
+}
+
+func TestConfigCacheInheritance(t *testing.T) {
+	root := t.TempDir()
+	write := func(dir, content string) {
+		path := filepath.Join(root, dir, ConfigFile)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".", "focus = \"services\"\nhub_threshold = 5\n")
+	write("services/api", "hub_threshold = 2\n")
+	write("services/broken", "hub_threshold = lots\n")
+
+	cache := NewConfigCache(root)
+	for path, want := range map[string]Config{
+		"main.go":                   {Focus: "services", HubThreshold: 5},
+		"services/web/app.ts":       {Focus: "services", HubThreshold: 5},
+		"services/api/handlers.go":  {Focus: "services", HubThreshold: 2},
+		"services/api/v1/routes.go": {Focus: "services", HubThreshold: 2},
+		"services/broken/thing.go":  {Focus: "services", HubThreshold: 5},
+		"services/apiary/config.go": {Focus: "services", HubThreshold: 5},
+	} {
+		if got := cache.For(path); !reflect.DeepEqual(got, want) {
+			t.Errorf("For(%q) = %+v, want %+v", path, got, want)
+		}
+	}
+
+	importers := []string{"a.go", "b.go"}
+	fg := &FileGraph{
+		Root: root,
+		Importers: map[string][]string{
+			"services/api/db.go": importers,
+			"services/web/db.go": importers,
+		},
+	}
+	if !fg.IsHub("services/api/db.go") {
+		t.Error("Expected services/api/db.go to be a hub under its subdir threshold of 2")
+	}
+	if fg.IsHub("services/web/db.go") {
+		t.Error("Expected services/web/db.go to use the root threshold of 5")
+	}
+	if hubs := fg.HubFiles(); len(hubs) != 1 || hubs[0] != "services/api/db.go" {
+		t.Errorf("HubFiles() = %v, want [services/api/db.go]", hubs)
+	}
+}
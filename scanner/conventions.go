@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Filename naming styles detected by NameStyle
+const (
+	StyleSnake  = "snake_case"
+	StyleKebab  = "kebab-case"
+	StyleCamel  = "camelCase"
+	StylePascal = "PascalCase"
+	StyleLower  = "lowercase" // single lowercase word - compatible with any style
+	StyleMixed  = "mixed"
+)
+
+// ConventionOutlier is a file whose name doesn't follow its directory's dominant style
+type ConventionOutlier struct {
+	Path     string
+	Style    string // the file's own style
+	Dominant string // the dominant style among its siblings
+}
+
+// NameStyle classifies a filename (extension is ignored) into a naming style
+func NameStyle(filename string) string {
+	name := filepath.Base(filename)
+	// Strip all extensions (foo.test.ts -> foo)
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	if name == "" {
+		return StyleMixed
+	}
+
+	hasUnderscore := strings.Contains(name, "_")
+	hasDash := strings.Contains(name, "-")
+	hasUpper, hasLower := false, false
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			hasUpper = true
+		} else if unicode.IsLower(r) {
+			hasLower = true
+		}
+	}
+	firstUpper := unicode.IsUpper([]rune(name)[0])
+
+	switch {
+	case hasUnderscore && hasDash:
+		return StyleMixed
+	case hasUnderscore && !hasUpper:
+		return StyleSnake
+	case hasDash && !hasUpper:
+		return StyleKebab
+	case hasUnderscore || hasDash:
+		return StyleMixed
+	case !hasUpper:
+		return StyleLower
+	case firstUpper && hasLower:
+		return StylePascal
+	case !firstUpper:
+		return StyleCamel
+	}
+	return StyleMixed
+}
+
+// FindConventionOutliers groups files by directory and language, finds the dominant
+// naming style in each group, and returns files that deviate from it. A group needs
+// a strict majority of styled (non-lowercase) names before outliers are reported.
+// files are relative to root.
+func FindConventionOutliers(root string, files []FileInfo) []ConventionOutlier {
+	type group struct {
+		styles map[string]int
+		files  []FileInfo
+	}
+	groups := make(map[string]*group)
+
+	for _, f := range files {
+		lang := DetectLanguageAt(root, f.Path)
+		if lang == "" {
+			continue
+		}
+		key := filepath.Dir(f.Path) + "|" + lang
+		if groups[key] == nil {
+			groups[key] = &group{styles: make(map[string]int)}
+		}
+		g := groups[key]
+		g.styles[NameStyle(f.Path)]++
+		g.files = append(g.files, f)
+	}
+
+	var outliers []ConventionOutlier
+	for _, g := range groups {
+		dominant, dominantCount, styled := "", 0, 0
+		for style, count := range g.styles {
+			if style == StyleLower {
+				continue
+			}
+			styled += count
+			if count > dominantCount || (count == dominantCount && style < dominant) {
+				dominant, dominantCount = style, count
+			}
+		}
+		if dominant == "" || dominantCount < 2 || dominantCount*2 <= styled {
+			continue
+		}
+		for _, f := range g.files {
+			style := NameStyle(f.Path)
+			if style != StyleLower && style != dominant {
+				outliers = append(outliers, ConventionOutlier{Path: f.Path, Style: style, Dominant: dominant})
+			}
+		}
+	}
+
+	sort.Slice(outliers, func(i, j int) bool {
+		return outliers[i].Path < outliers[j].Path
+	})
+	return outliers
+}
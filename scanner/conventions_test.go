@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"testing"
+)
+
+func TestNameStyle(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"user_controller.py", StyleSnake},
+		{"user-controller.ts", StyleKebab},
+		{"userController.ts", StyleCamel},
+		{"UserController.swift", StylePascal},
+		{"main.go", StyleLower},
+		{"foo.test.ts", StyleLower},
+		{"user_Controller.py", StyleMixed},
+		{"a_b-c.js", StyleMixed},
+	}
+	for _, tt := range tests {
+		if got := NameStyle(tt.name); got != tt.want {
+			t.Errorf("NameStyle(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFindConventionOutliers(t *testing.T) {
+	files := []FileInfo{
+		{Path: "app/user_model.py"},
+		{Path: "app/order_model.py"},
+		{Path: "app/db_session.py"},
+		{Path: "app/utils.py"},
+		{Path: "app/paymentService.py"},
+		// Different language in the same dir forms its own group
+		{Path: "app/AppView.swift"},
+		// README has no language and is ignored
+		{Path: "app/README.md"},
+	}
+
+	outliers := FindConventionOutliers("", files)
+	if len(outliers) != 1 {
+		t.Fatalf("Expected 1 outlier, got %d: %v", len(outliers), outliers)
+	}
+	o := outliers[0]
+	if o.Path != "app/paymentService.py" || o.Style != StyleCamel || o.Dominant != StyleSnake {
+		t.Errorf("Unexpected outlier: %+v", o)
+	}
+}
+
+func TestFindConventionOutliersNoMajority(t *testing.T) {
+	// Evenly split directories have no dominant convention to enforce
+	files := []FileInfo{
+		{Path: "src/fooBar.ts"},
+		{Path: "src/bazQux.ts"},
+		{Path: "src/foo-bar.ts"},
+		{Path: "src/baz-qux.ts"},
+	}
+	if outliers := FindConventionOutliers("", files); len(outliers) != 0 {
+		t.Errorf("Expected no outliers without a majority, got %v", outliers)
+	}
+}
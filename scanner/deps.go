@@ -3,48 +3,59 @@ package scanner
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
-// ReadExternalDeps reads manifest files (go.mod, requirements.txt, package.json)
-func ReadExternalDeps(root string) map[string][]string {
-	deps := make(map[string][]string)
+// ExternalDep is a third-party dependency and the manifests that declare it
+type ExternalDep struct {
+	Name     string   `json:"name"`
+	Language string   `json:"language"`
+	Sources  []string `json:"sources"` // manifest paths relative to root
+}
 
-	// Walk tree to find all manifest files
+// manifestParsers maps manifest filenames to their language and parser
+var manifestParsers = map[string]struct {
+	lang  string
+	parse func(string) []string
+}{
+	"go.mod":           {"go", parseGoMod},
+	"requirements.txt": {"python", parseRequirements},
+	"package.json":     {"javascript", parsePackageJson},
+	"Podfile":          {"swift", parsePodfile},
+	"Package.swift":    {"swift", parsePackageSwift},
+}
+
+// walkManifests calls fn for every manifest under root, in walk order
+func walkManifests(root string, fn func(lang, manifest string, deps []string)) {
 	filepath.Walk(root, func(path string, info os.FileInfo, _ error) error {
 		if info == nil {
 			return nil
 		}
 		if info.IsDir() {
-			if IgnoredDirs[info.Name()] {
+			if IgnoredDirs[info.Name()] || info.Name() == CodemapDir {
 				return filepath.SkipDir
 			}
 			return nil
 		}
-		switch info.Name() {
-		case "go.mod":
-			if c, err := os.ReadFile(path); err == nil {
-				deps["go"] = append(deps["go"], parseGoMod(string(c))...)
-			}
-		case "requirements.txt":
-			if c, err := os.ReadFile(path); err == nil {
-				deps["python"] = append(deps["python"], parseRequirements(string(c))...)
-			}
-		case "package.json":
-			if c, err := os.ReadFile(path); err == nil {
-				deps["javascript"] = append(deps["javascript"], parsePackageJson(string(c))...)
-			}
-		case "Podfile":
-			if c, err := os.ReadFile(path); err == nil {
-				deps["swift"] = append(deps["swift"], parsePodfile(string(c))...)
-			}
-		case "Package.swift":
-			if c, err := os.ReadFile(path); err == nil {
-				deps["swift"] = append(deps["swift"], parsePackageSwift(string(c))...)
-			}
+		parser, ok := manifestParsers[info.Name()]
+		if !ok {
+			return nil
+		}
+		if c, err := os.ReadFile(path); err == nil {
+			rel, _ := filepath.Rel(root, path)
+			fn(parser.lang, rel, parser.parse(string(c)))
 		}
 		return nil
 	})
+}
+
+// ReadExternalDeps reads manifest files (go.mod, requirements.txt, package.json)
+func ReadExternalDeps(root string) map[string][]string {
+	deps := make(map[string][]string)
+	walkManifests(root, func(lang, _ string, found []string) {
+		deps[lang] = append(deps[lang], found...)
+	})
 
 	for k, v := range deps {
 		deps[k] = dedupe(v)
@@ -52,6 +63,40 @@ func ReadExternalDeps(root string) map[string][]string {
 	return deps
 }
 
+// ReadExternalDepSources reads every manifest like ReadExternalDeps, but keeps
+// track of which manifest(s) declared each dependency. Useful in monorepos where
+// several go.mod/package.json files declare overlapping deps. Sorted by
+// language, then name.
+func ReadExternalDepSources(root string) []ExternalDep {
+	byKey := make(map[string]*ExternalDep)
+	walkManifests(root, func(lang, manifest string, found []string) {
+		for _, name := range found {
+			key := lang + "\x00" + name
+			dep, ok := byKey[key]
+			if !ok {
+				dep = &ExternalDep{Name: name, Language: lang}
+				byKey[key] = dep
+			}
+			if len(dep.Sources) == 0 || dep.Sources[len(dep.Sources)-1] != manifest {
+				dep.Sources = append(dep.Sources, manifest)
+			}
+		}
+	})
+
+	result := make([]ExternalDep, 0, len(byKey))
+	for _, dep := range byKey {
+		sort.Strings(dep.Sources)
+		result = append(result, *dep)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Language != result[j].Language {
+			return result[i].Language < result[j].Language
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
 func parseGoMod(c string) (deps []string) {
 	inReq := false
 	for _, line := range strings.Split(c, "\n") {
@@ -1,9 +1,11 @@
 package scanner
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"testing"
 )
@@ -262,6 +264,60 @@ requests
 	}
 }
 
+func TestReadExternalDepSources(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	write := func(rel, content string) {
+		path := filepath.Join(tmpDir, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two Python services share "requests"; the web app's "requests" is a
+	// different (JS) package and must stay separate
+	write("api/requirements.txt", "flask==2.0.0\nrequests\n")
+	write("worker/requirements.txt", "celery\nrequests>=2.0\n")
+	write("web/package.json", `{
+  "dependencies": {
+    "express": "4.0.0",
+    "requests": "1.0.0"
+  }
+}`)
+
+	deps := ReadExternalDepSources(tmpDir)
+
+	got := make(map[string][]string)
+	for _, d := range deps {
+		got[d.Language+":"+d.Name] = d.Sources
+	}
+
+	api := filepath.Join("api", "requirements.txt")
+	worker := filepath.Join("worker", "requirements.txt")
+	web := filepath.Join("web", "package.json")
+	expected := map[string][]string{
+		"python:flask":        {api},
+		"python:celery":       {worker},
+		"python:requests":     {api, worker},
+		"javascript:express":  {web},
+		"javascript:requests": {web},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected dep sources:\n got  %v\n want %v", got, expected)
+	}
+
+	// Sorted by language, then name
+	if deps[0].Language != "javascript" || deps[0].Name != "express" {
+		t.Errorf("Expected javascript:express first, got %s:%s", deps[0].Language, deps[0].Name)
+	}
+
+	// The per-language view still dedupes across manifests
+	if py := ReadExternalDeps(tmpDir)["python"]; len(py) != 3 {
+		t.Errorf("Expected 3 deduped python deps, got %v", py)
+	}
+}
+
 func TestReadExternalDepsIgnoresNodeModules(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -493,3 +549,173 @@ func TestResolvePathAliasNoMatch(t *testing.T) {
 		t.Errorf("Expected no results for non-existent file, got %v", result)
 	}
 }
+
+// syntheticGraphInput builds a TS project where every file imports a shared
+// util plus its neighbours, for resolution tests and benchmarks
+func syntheticGraphInput(dirs, perDir int) ([]FileInfo, []FileAnalysis) {
+	files := []FileInfo{{Path: "src/shared/util.ts"}}
+	var analyses []FileAnalysis
+	for d := 0; d < dirs; d++ {
+		for f := 0; f < perDir; f++ {
+			path := fmt.Sprintf("src/mod%d/file%d.ts", d, f)
+			files = append(files, FileInfo{Path: path})
+			analyses = append(analyses, FileAnalysis{
+				Path:    path,
+				Imports: []string{"../shared/util", fmt.Sprintf("./file%d", (f+1)%perDir), "react"},
+			})
+		}
+	}
+	return files, analyses
+}
+
+func TestResolveImportsDeterministic(t *testing.T) {
+	files, analyses := syntheticGraphInput(10, 10)
+	idx := buildFileIndex(files, "")
+
+	build := func(order []FileAnalysis) *FileGraph {
+		fg := &FileGraph{Imports: make(map[string][]string), Importers: make(map[string][]string)}
+		fg.resolveImports(order, idx)
+		return fg
+	}
+
+	first := build(analyses)
+	if got := len(first.Importers["src/shared/util.ts"]); got != 100 {
+		t.Fatalf("Expected 100 importers of util.ts, got %d", got)
+	}
+
+	// Reverse the analysis order: the merged graph must not change
+	reversed := make([]FileAnalysis, len(analyses))
+	for i, a := range analyses {
+		reversed[len(analyses)-1-i] = a
+	}
+	second := build(reversed)
+
+	if !reflect.DeepEqual(first.Importers, second.Importers) {
+		t.Error("Importers differ between runs with different analysis order")
+	}
+	if !reflect.DeepEqual(first.Imports, second.Imports) {
+		t.Error("Imports differ between runs with different analysis order")
+	}
+}
+
+func TestResolveGoImportsAsPackages(t *testing.T) {
+	files := []FileInfo{
+		{Path: "main.go"},
+		{Path: "scanner/types.go"},
+		{Path: "scanner/walker.go"},
+		{Path: "scanner/filegraph.go"},
+		{Path: "render/tree.go"},
+	}
+	analyses := []FileAnalysis{
+		{Path: "main.go", Language: "go", Imports: []string{"fmt", "codemap/scanner", "codemap/render"}},
+		{Path: "render/tree.go", Language: "go", Imports: []string{"codemap/scanner"}},
+	}
+	idx := buildFileIndex(files, "codemap")
+
+	build := func(packages bool) *FileGraph {
+		fg := &FileGraph{Module: "codemap", GoPackageEdges: packages, Imports: make(map[string][]string), Importers: make(map[string][]string)}
+		fg.resolveImports(analyses, idx)
+		return fg
+	}
+
+	pkg := build(true)
+	if got := pkg.Imports["main.go"]; !reflect.DeepEqual(got, []string{"scanner", "render"}) {
+		t.Errorf("Package mode: main.go imports = %v, want one edge per package [scanner render]", got)
+	}
+	if got := pkg.Importers["scanner"]; !reflect.DeepEqual(got, []string{"main.go", "render/tree.go"}) {
+		t.Errorf("Package mode: scanner importers = %v", got)
+	}
+	if len(pkg.Importers["scanner/types.go"]) != 0 {
+		t.Error("Package mode should not add edges to individual package files")
+	}
+	// Per-file lookups see the package's importers
+	if got := pkg.ImportersOf("scanner/types.go"); !reflect.DeepEqual(got, []string{"main.go", "render/tree.go"}) {
+		t.Errorf("Package mode: ImportersOf(scanner/types.go) = %v", got)
+	}
+	if n := pkg.HubImporterCount("scanner/walker.go"); n != 2 {
+		t.Errorf("Package mode: HubImporterCount(scanner/walker.go) = %d, want 2", n)
+	}
+
+	// File mode: a multi-file package is ambiguous and skipped, a one-file
+	// package resolves to its file
+	file := build(false)
+	if got := file.Imports["main.go"]; !reflect.DeepEqual(got, []string{"render/tree.go"}) {
+		t.Errorf("File mode: main.go imports = %v, want [render/tree.go]", got)
+	}
+}
+
+func TestHubFilesInPackageMode(t *testing.T) {
+	files := []FileInfo{
+		{Path: "main.go"},
+		{Path: "cmd/run.go"},
+		{Path: "render/tree.go"},
+		{Path: "scanner/types.go"},
+		{Path: "scanner/walker.go"},
+	}
+	analyses := []FileAnalysis{
+		{Path: "main.go", Language: "go", Imports: []string{"codemap/scanner", "codemap/render"}},
+		{Path: "cmd/run.go", Language: "go", Imports: []string{"codemap/scanner"}},
+		{Path: "render/tree.go", Language: "go", Imports: []string{"codemap/scanner"}},
+	}
+	fg := &FileGraph{Module: "codemap", GoPackageEdges: true, Imports: make(map[string][]string), Importers: make(map[string][]string)}
+	for _, f := range files {
+		fg.Files = append(fg.Files, f.Path)
+	}
+	fg.resolveImports(analyses, buildFileIndex(files, "codemap"))
+
+	want := []string{"scanner/types.go", "scanner/walker.go"}
+	check := func(name string) {
+		hubs := fg.HubFiles()
+		sort.Strings(hubs)
+		if !reflect.DeepEqual(hubs, want) {
+			t.Errorf("%s: HubFiles() = %v, want the package's files %v", name, hubs, want)
+		}
+		for _, f := range append([]string{"scanner", "render"}, fg.Files...) {
+			if in := slices.Contains(hubs, f); in != fg.IsHub(f) {
+				t.Errorf("%s: %s in HubFiles = %v, but IsHub = %v", name, f, in, fg.IsHub(f))
+			}
+		}
+	}
+	check("with Files")
+
+	// A graph rebuilt from edges alone still leaves the directory out
+	fg.Files = nil
+	want = nil
+	check("without Files")
+}
+
+func TestResolveBundlerImports(t *testing.T) {
+	files := []FileInfo{
+		{Path: "src/app.ts"},
+		{Path: "src/foo.txt"},
+		{Path: "src/styles/main.css"},
+		{Path: "assets/img.png"},
+	}
+	idx := buildFileIndex(files, "")
+
+	tests := []struct {
+		imp  string
+		want string
+	}{
+		{"!raw-loader!./foo.txt", "src/foo.txt"},
+		{"!!style-loader!css-loader!./styles/main.css", "src/styles/main.css"},
+		{"../assets/img.png?url", "assets/img.png"},
+		{"./foo.txt?raw&inline", "src/foo.txt"},
+	}
+	for _, tt := range tests {
+		got := fuzzyResolve(tt.imp, "src/app.ts", idx, "", nil, "")
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("fuzzyResolve(%q) = %v, want [%s]", tt.imp, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkResolveImports(b *testing.B) {
+	files, analyses := syntheticGraphInput(100, 50)
+	idx := buildFileIndex(files, "")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fg := &FileGraph{Imports: make(map[string][]string), Importers: make(map[string][]string)}
+		fg.resolveImports(analyses, idx)
+	}
+}
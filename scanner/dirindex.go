@@ -0,0 +1,38 @@
+package scanner
+
+import (
+	"path"
+	"strings"
+)
+
+// indexExts are the extensions of JS/TS directory entry files: importing
+// "./foo" resolves to foo/index.ts (or .js, ...)
+var indexExts = map[string]bool{
+	".ts": true, ".tsx": true, ".js": true, ".jsx": true, ".mjs": true, ".cjs": true,
+}
+
+// DirIndexLabel returns "foo/" for a directory index file like foo/index.ts,
+// matching how the directory is imported; any other path is returned as is.
+// A root-level index file is labeled "./".
+func DirIndexLabel(p string) string {
+	p = strings.ReplaceAll(p, "\\", "/")
+	base := path.Base(p)
+	ext := path.Ext(base)
+	if !indexExts[ext] || strings.TrimSuffix(base, ext) != "index" {
+		return p
+	}
+	return path.Dir(p) + "/"
+}
+
+// ResolveDirIndex maps a directory label ("foo/" or "foo") to the index file
+// in fg.Files that DirIndexLabel would give that label. Returns "" if the
+// directory has no index file.
+func (fg *FileGraph) ResolveDirIndex(label string) string {
+	label = strings.TrimSuffix(strings.ReplaceAll(label, "\\", "/"), "/") + "/"
+	for _, f := range fg.Files {
+		if f != label && DirIndexLabel(f) == label {
+			return f
+		}
+	}
+	return ""
+}
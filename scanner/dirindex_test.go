@@ -0,0 +1,28 @@
+package scanner
+
+import "testing"
+
+func TestDirIndexLabel(t *testing.T) {
+	tests := map[string]string{
+		"src/components/index.ts":  "src/components/",
+		"src/components/index.tsx": "src/components/",
+		"lib/index.js":             "lib/",
+		"index.ts":                 "./",
+		"src/components/Button.ts": "src/components/Button.ts",
+		"pkg/index.go":             "pkg/index.go", // not a JS/TS entry file
+		"src/reindex.ts":           "src/reindex.ts",
+	}
+	for in, want := range tests {
+		if got := DirIndexLabel(in); got != want {
+			t.Errorf("DirIndexLabel(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	fg := &FileGraph{Files: []string{"src/a.ts", "src/components/index.ts"}}
+	if got := fg.ResolveDirIndex("src/components"); got != "src/components/index.ts" {
+		t.Errorf("ResolveDirIndex(src/components) = %q", got)
+	}
+	if got := fg.ResolveDirIndex("src/"); got != "" {
+		t.Errorf("ResolveDirIndex(src/) = %q, want no index", got)
+	}
+}
@@ -0,0 +1,219 @@
+package scanner
+
+import (
+	"fmt"
+	"path"
+	"sort"
+)
+
+// DSM levels: one row per file, or one per directory
+const (
+	DSMLevelFile = "file"
+	DSMLevelDir  = "dir"
+)
+
+// DSMFileLimit is the most files a DSM defaults to file level for; bigger
+// graphs default to directory level to stay readable
+const DSMFileLimit = 60
+
+// DSM is a Design Structure Matrix over a file graph. Cells[i][j] counts the
+// file imports from Labels[i] (the row) into Labels[j] (the column); on the
+// diagonal at directory level that's imports within the directory.
+//
+// Entries are partitioned: mutually dependent entries (import cycles) share a
+// Group and sit together, and otherwise dependencies come first, so every
+// edge lands below the diagonal except ones inside a cycle.
+type DSM struct {
+	Level  string
+	Labels []string
+	Groups []int // cluster id per entry; entries in one import cycle share it
+	Cells  [][]int
+}
+
+// ResolveDSMLevel returns level, or when it's empty, file level for graphs
+// of up to DSMFileLimit files and directory level beyond that
+func ResolveDSMLevel(level string, files int) (string, error) {
+	switch level {
+	case DSMLevelFile, DSMLevelDir:
+		return level, nil
+	case "":
+		if files > DSMFileLimit {
+			return DSMLevelDir, nil
+		}
+		return DSMLevelFile, nil
+	}
+	return "", fmt.Errorf("unknown DSM level %q (use file or dir)", level)
+}
+
+// BuildDSM builds the matrix for fg at the given level (see ResolveDSMLevel).
+// Entries cover source files and anything taking part in an import.
+func BuildDSM(fg *FileGraph, level string) (*DSM, error) {
+	level, err := ResolveDSMLevel(level, len(fg.Files))
+	if err != nil {
+		return nil, err
+	}
+	key := func(f string) string { return f }
+	if level == DSMLevelDir {
+		key = path.Dir
+	}
+
+	// Aggregate edges at the chosen level
+	nodes := make(map[string]bool)
+	edges := make(map[string]map[string]int)
+	for _, f := range fg.Files {
+		if DetectLanguageAt(fg.Root, f) != "" {
+			nodes[key(f)] = true
+		}
+	}
+	for from, targets := range fg.Imports {
+		nodes[key(from)] = true
+		for _, to := range targets {
+			nodes[key(to)] = true
+			if edges[key(from)] == nil {
+				edges[key(from)] = make(map[string]int)
+			}
+			edges[key(from)][key(to)]++
+		}
+	}
+
+	labels, groups := partitionDSM(nodes, edges)
+	index := make(map[string]int, len(labels))
+	for i, l := range labels {
+		index[l] = i
+	}
+	cells := make([][]int, len(labels))
+	for i := range cells {
+		cells[i] = make([]int, len(labels))
+	}
+	for from, targets := range edges {
+		for to, n := range targets {
+			cells[index[from]][index[to]] += n
+		}
+	}
+	return &DSM{Level: level, Labels: labels, Groups: groups, Cells: cells}, nil
+}
+
+// IsFeedback reports whether cell (i, j) lies above the diagonal: after
+// partitioning, only dependencies inside an import cycle land there
+func (m *DSM) IsFeedback(i, j int) bool {
+	return j > i && m.Cells[i][j] > 0
+}
+
+// IsLongRange reports whether a dependency in cell (i, j) reaches across
+// more than a quarter of the matrix and out of its own cluster: the edges
+// that tie distant parts of the architecture together
+func (m *DSM) IsLongRange(i, j int) bool {
+	dist := i - j
+	if dist < 0 {
+		dist = -dist
+	}
+	return m.Cells[i][j] > 0 && m.Groups[i] != m.Groups[j] && dist > len(m.Labels)/4 && dist > 1
+}
+
+// partitionDSM orders nodes dependencies-first, collapsing strongly
+// connected components (Tarjan) into contiguous groups. Ties go by name so
+// the output is stable.
+func partitionDSM(nodes map[string]bool, edges map[string]map[string]int) ([]string, []int) {
+	names := make([]string, 0, len(nodes))
+	for n := range nodes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var sccs [][]string // Tarjan emits components dependencies-first
+	next := 0
+
+	var visit func(n string)
+	visit = func(n string) {
+		index[n], low[n] = next, next
+		next++
+		stack = append(stack, n)
+		onStack[n] = true
+
+		targets := make([]string, 0, len(edges[n]))
+		for t := range edges[n] {
+			targets = append(targets, t)
+		}
+		sort.Strings(targets)
+		for _, t := range targets {
+			if _, seen := index[t]; !seen {
+				visit(t)
+				low[n] = min(low[n], low[t])
+			} else if onStack[t] {
+				low[n] = min(low[n], index[t])
+			}
+		}
+
+		if low[n] == index[n] {
+			var scc []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				scc = append(scc, top)
+				if top == n {
+					break
+				}
+			}
+			sort.Strings(scc)
+			sccs = append(sccs, scc)
+		}
+	}
+	for _, n := range names {
+		if _, seen := index[n]; !seen {
+			visit(n)
+		}
+	}
+
+	var labels []string
+	var groups []int
+	for g, scc := range sccs {
+		for _, n := range scc {
+			labels = append(labels, n)
+			groups = append(groups, g)
+		}
+	}
+	return labels, groups
+}
+
+// ImportCycles returns the groups of files that import each other, directly
+// or through a chain: the file-level strongly connected components with more
+// than one member. Largest first, then by first file.
+func (fg *FileGraph) ImportCycles() [][]string {
+	nodes := make(map[string]bool)
+	edges := make(map[string]map[string]int)
+	for from, targets := range fg.Imports {
+		nodes[from] = true
+		for _, to := range targets {
+			nodes[to] = true
+			if edges[from] == nil {
+				edges[from] = make(map[string]int)
+			}
+			edges[from][to]++
+		}
+	}
+
+	labels, groups := partitionDSM(nodes, edges)
+	var cycles [][]string
+	for i := 0; i < len(labels); {
+		j := i
+		for j < len(labels) && groups[j] == groups[i] {
+			j++
+		}
+		if j-i > 1 {
+			cycles = append(cycles, labels[i:j])
+		}
+		i = j
+	}
+	sort.SliceStable(cycles, func(a, b int) bool {
+		if len(cycles[a]) != len(cycles[b]) {
+			return len(cycles[a]) > len(cycles[b])
+		}
+		return cycles[a][0] < cycles[b][0]
+	})
+	return cycles
+}
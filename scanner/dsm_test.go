@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildDSM(t *testing.T) {
+	fg := &FileGraph{
+		Files: []string{"main.go", "api/server.go", "api/routes.go", "db/conn.go", "db/pool.go", "util/log.go"},
+		Imports: map[string][]string{
+			"main.go":       {"api/server.go", "util/log.go"},
+			"api/server.go": {"api/routes.go", "db/conn.go"},
+			"api/routes.go": {"db/conn.go"},
+			"db/conn.go":    {"db/pool.go", "util/log.go"},
+			"db/pool.go":    {"db/conn.go"}, // cycle
+		},
+	}
+
+	m, err := BuildDSM(fg, DSMLevelFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := make(map[string]int)
+	for i, l := range m.Labels {
+		index[l] = i
+	}
+	if len(index) != len(fg.Files) {
+		t.Fatalf("Labels = %v, want one per file", m.Labels)
+	}
+
+	edges := 0
+	for from, targets := range fg.Imports {
+		for _, to := range targets {
+			edges++
+			if m.Cells[index[from]][index[to]] != 1 {
+				t.Errorf("cell %s -> %s = %d, want 1", from, to, m.Cells[index[from]][index[to]])
+			}
+		}
+	}
+	total := 0
+	for i := range m.Cells {
+		for j := range m.Cells[i] {
+			total += m.Cells[i][j]
+			if m.IsFeedback(i, j) && m.Groups[i] != m.Groups[j] {
+				t.Errorf("%s -> %s is above the diagonal outside a cycle", m.Labels[i], m.Labels[j])
+			}
+		}
+	}
+	if total != edges {
+		t.Errorf("matrix holds %d imports, want %d", total, edges)
+	}
+
+	// The conn/pool cycle is one contiguous cluster
+	conn, pool := index["db/conn.go"], index["db/pool.go"]
+	if m.Groups[conn] != m.Groups[pool] || conn-pool != 1 && pool-conn != 1 {
+		t.Errorf("Expected db/conn.go and db/pool.go clustered together: %v %v", m.Labels, m.Groups)
+	}
+	// Dependencies come before dependents
+	if index["util/log.go"] > index["db/conn.go"] || index["main.go"] != len(m.Labels)-1 {
+		t.Errorf("Unexpected partition order: %v", m.Labels)
+	}
+
+	dirs, err := BuildDSM(fg, DSMLevelDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"util", "db", "api", "."}; !reflect.DeepEqual(dirs.Labels, want) {
+		t.Fatalf("dir Labels = %v, want %v", dirs.Labels, want)
+	}
+	// api -> db: server.go and routes.go both import conn.go; db's internal cycle sits on the diagonal
+	if dirs.Cells[2][1] != 2 || dirs.Cells[1][1] != 2 || dirs.Cells[3][0] != 1 {
+		t.Errorf("dir cells = %v", dirs.Cells)
+	}
+
+	if _, err := BuildDSM(fg, "package"); err == nil {
+		t.Error("Expected an unknown level to be rejected")
+	}
+	if level, _ := ResolveDSMLevel("", DSMFileLimit+1); level != DSMLevelDir {
+		t.Errorf("Expected large graphs to default to dir level, got %s", level)
+	}
+}
+
+func TestImportCycles(t *testing.T) {
+	fg := &FileGraph{
+		Imports: map[string][]string{
+			"main.go":    {"a.go", "db/conn.go"},
+			"a.go":       {"b.go"},
+			"b.go":       {"a.go"},
+			"db/conn.go": {"db/pool.go"},
+			"db/pool.go": {"db/tx.go"},
+			"db/tx.go":   {"db/conn.go"},
+		},
+	}
+	want := [][]string{
+		{"db/conn.go", "db/pool.go", "db/tx.go"},
+		{"a.go", "b.go"},
+	}
+	if got := fg.ImportCycles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportCycles() = %v, want %v", got, want)
+	}
+}
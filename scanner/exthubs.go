@@ -0,0 +1,167 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+)
+
+// ExternalHub is a third-party package and the project files importing it
+type ExternalHub struct {
+	Package  string   // normalized package name (e.g. "lodash", "@scope/pkg", "github.com/x/y")
+	Language string   // language of the importing files
+	Files    []string // importing files, sorted
+}
+
+// FindExternalHubs counts how many files import each external dependency,
+// most-imported first (ties by language, then name). Imports are classified
+// first: anything internal-looking or resolving to a project file is
+// skipped, as are standard libraries (Go, Python, Rust) and Node builtins.
+// Subpath imports are folded into
+// their package ("lodash/fp" counts as "lodash").
+func FindExternalHubs(fg *FileGraph, analyses []FileAnalysis) []ExternalHub {
+	files := make([]FileInfo, len(fg.Files))
+	for i, p := range fg.Files {
+		files[i] = FileInfo{Path: p}
+	}
+	idx := buildFileIndex(files, fg.Module)
+
+	type key struct{ lang, pkg string }
+	importers := make(map[key]map[string]bool)
+	for _, a := range analyses {
+		for _, imp := range a.Imports {
+			imp = strings.Trim(imp, "\"'` ")
+			if imp == "" || looksInternal(imp, fg.Module, fg.PathAliases) {
+				continue
+			}
+			if len(fuzzyResolve(imp, a.Path, idx, fg.Module, fg.PathAliases, fg.BaseURL)) > 0 || resolvesLoosely(imp, a.Path, idx) {
+				continue
+			}
+			pkg := externalPackage(imp, a.Language)
+			if pkg == "" {
+				continue
+			}
+			k := key{a.Language, pkg}
+			if importers[k] == nil {
+				importers[k] = make(map[string]bool)
+			}
+			importers[k][a.Path] = true
+		}
+	}
+
+	hubs := make([]ExternalHub, 0, len(importers))
+	for k, set := range importers {
+		h := ExternalHub{Package: k.pkg, Language: k.lang}
+		for f := range set {
+			h.Files = append(h.Files, f)
+		}
+		sort.Strings(h.Files)
+		hubs = append(hubs, h)
+	}
+	sort.Slice(hubs, func(i, j int) bool {
+		if len(hubs[i].Files) != len(hubs[j].Files) {
+			return len(hubs[i].Files) > len(hubs[j].Files)
+		}
+		if hubs[i].Language != hubs[j].Language {
+			return hubs[i].Language < hubs[j].Language
+		}
+		return hubs[i].Package < hubs[j].Package
+	})
+	return hubs
+}
+
+// externalPackage folds an external import into the package it belongs to,
+// or returns "" for imports that aren't third-party dependencies
+func externalPackage(imp, lang string) string {
+	switch lang {
+	case "javascript", "typescript":
+		parts := strings.Split(imp, "/")
+		if strings.HasPrefix(imp, "@") && len(parts) > 1 {
+			return parts[0] + "/" + parts[1]
+		}
+		if strings.HasPrefix(imp, "node:") || nodeBuiltins[parts[0]] {
+			return "" // fs, node:path, fs/promises
+		}
+		return parts[0]
+	case "python":
+		pkg := strings.SplitN(imp, ".", 2)[0]
+		if pythonStdlib[pkg] {
+			return ""
+		}
+		return pkg
+	case "go":
+		parts := strings.Split(imp, "/")
+		if !strings.Contains(parts[0], ".") {
+			return "" // standard library
+		}
+		if len(parts) > 3 {
+			parts = parts[:3] // host/owner/repo
+		}
+		return strings.Join(parts, "/")
+	case "rust":
+		crate := strings.SplitN(imp, "::", 2)[0]
+		switch crate {
+		case "std", "core", "alloc", "crate", "self", "super":
+			return ""
+		}
+		return crate
+	}
+	return imp
+}
+
+// nodeBuiltins are Node's core modules, importable without the node: prefix
+var nodeBuiltins = map[string]bool{
+	"assert": true, "async_hooks": true, "buffer": true, "child_process": true, "cluster": true,
+	"console": true, "constants": true, "crypto": true, "dgram": true, "diagnostics_channel": true,
+	"dns": true, "domain": true, "events": true, "fs": true, "http": true, "http2": true, "https": true,
+	"inspector": true, "module": true, "net": true, "os": true, "path": true, "perf_hooks": true,
+	"process": true, "punycode": true, "querystring": true, "readline": true, "repl": true,
+	"stream": true, "string_decoder": true, "sys": true, "timers": true, "tls": true,
+	"trace_events": true, "tty": true, "url": true, "util": true, "v8": true, "vm": true, "wasi": true,
+	"worker_threads": true, "zlib": true,
+}
+
+// pythonStdlib are the standard library's top-level modules
+// (sys.stdlib_module_names, less private modules and demos)
+var pythonStdlib = map[string]bool{
+	"abc": true, "aifc": true, "argparse": true, "array": true, "ast": true, "asynchat": true,
+	"asyncio": true, "asyncore": true, "atexit": true, "audioop": true, "base64": true, "bdb": true,
+	"binascii": true, "bisect": true, "builtins": true, "bz2": true, "cProfile": true,
+	"calendar": true, "cgi": true, "cgitb": true, "chunk": true, "cmath": true, "cmd": true,
+	"code": true, "codecs": true, "codeop": true, "collections": true, "colorsys": true,
+	"compileall": true, "concurrent": true, "configparser": true, "contextlib": true,
+	"contextvars": true, "copy": true, "copyreg": true, "crypt": true, "csv": true, "ctypes": true,
+	"curses": true, "dataclasses": true, "datetime": true, "dbm": true, "decimal": true,
+	"difflib": true, "dis": true, "distutils": true, "doctest": true, "email": true,
+	"encodings": true, "ensurepip": true, "enum": true, "errno": true, "faulthandler": true,
+	"fcntl": true, "filecmp": true, "fileinput": true, "fnmatch": true, "fractions": true,
+	"ftplib": true, "functools": true, "gc": true, "genericpath": true, "getopt": true,
+	"getpass": true, "gettext": true, "glob": true, "graphlib": true, "grp": true, "gzip": true,
+	"hashlib": true, "heapq": true, "hmac": true, "html": true, "http": true, "imaplib": true,
+	"imghdr": true, "imp": true, "importlib": true, "inspect": true, "io": true, "ipaddress": true,
+	"itertools": true, "json": true, "keyword": true, "lib2to3": true, "linecache": true,
+	"locale": true, "logging": true, "lzma": true, "mailbox": true, "mailcap": true,
+	"marshal": true, "math": true, "mimetypes": true, "mmap": true, "modulefinder": true,
+	"msilib": true, "msvcrt": true, "multiprocessing": true, "netrc": true, "nis": true,
+	"nntplib": true, "nt": true, "ntpath": true, "nturl2path": true, "numbers": true,
+	"opcode": true, "operator": true, "optparse": true, "os": true, "ossaudiodev": true,
+	"pathlib": true, "pdb": true, "pickle": true, "pickletools": true, "pipes": true,
+	"pkgutil": true, "platform": true, "plistlib": true, "poplib": true, "posix": true,
+	"posixpath": true, "pprint": true, "profile": true, "pstats": true, "pty": true, "pwd": true,
+	"py_compile": true, "pyclbr": true, "pydoc": true, "pyexpat": true, "queue": true,
+	"quopri": true, "random": true, "re": true, "readline": true, "reprlib": true, "resource": true,
+	"rlcompleter": true, "runpy": true, "sched": true, "secrets": true, "select": true,
+	"selectors": true, "shelve": true, "shlex": true, "shutil": true, "signal": true, "site": true,
+	"smtpd": true, "smtplib": true, "sndhdr": true, "socket": true, "socketserver": true,
+	"spwd": true, "sqlite3": true, "sre_compile": true, "sre_constants": true, "sre_parse": true,
+	"ssl": true, "stat": true, "statistics": true, "string": true, "stringprep": true,
+	"struct": true, "subprocess": true, "sunau": true, "symtable": true, "sys": true,
+	"sysconfig": true, "syslog": true, "tabnanny": true, "tarfile": true, "telnetlib": true,
+	"tempfile": true, "termios": true, "textwrap": true, "threading": true, "time": true,
+	"timeit": true, "tkinter": true, "token": true, "tokenize": true, "tomllib": true,
+	"trace": true, "traceback": true, "tracemalloc": true, "tty": true, "turtle": true,
+	"types": true, "typing": true, "unicodedata": true, "unittest": true, "urllib": true,
+	"uu": true, "uuid": true, "venv": true, "warnings": true, "wave": true, "weakref": true,
+	"webbrowser": true, "winreg": true, "winsound": true, "wsgiref": true, "xdrlib": true,
+	"xml": true, "xmlrpc": true, "zipapp": true, "zipfile": true, "zipimport": true, "zlib": true,
+	"zoneinfo": true,
+}
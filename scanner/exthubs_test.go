@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindExternalHubs(t *testing.T) {
+	fg := &FileGraph{
+		Module: "example.com/app",
+		Files:  []string{"main.go", "server.go", "web/a.ts", "web/b.ts", "web/c.ts", "web/util.ts", "tools/gen.py"},
+	}
+	analyses := []FileAnalysis{
+		{Path: "main.go", Language: "go", Imports: []string{"fmt", "github.com/spf13/cobra", "example.com/app/web"}},
+		{Path: "server.go", Language: "go", Imports: []string{"github.com/spf13/cobra/doc", "net/http"}},
+		{Path: "web/a.ts", Language: "typescript", Imports: []string{"lodash", "react", "./util"}},
+		{Path: "web/b.ts", Language: "typescript", Imports: []string{"lodash/fp", "lodash", "@tanstack/query/core"}},
+		{Path: "web/c.ts", Language: "typescript", Imports: []string{"'lodash'", "@tanstack/query"}},
+		{Path: "tools/gen.py", Language: "python", Imports: []string{"yaml.loader", "tools.gen", "os.path", "json", "sys"}},
+		{Path: "web/util.ts", Language: "typescript", Imports: []string{"fs", "node:path", "fs/promises", "child_process"}},
+	}
+
+	hubs := FindExternalHubs(fg, analyses)
+
+	type entry struct {
+		pkg   string
+		count int
+	}
+	var got []entry
+	for _, h := range hubs {
+		got = append(got, entry{h.Package, len(h.Files)})
+	}
+	want := []entry{
+		{"lodash", 3},                 // b.ts imports it twice (plus a subpath): still one file
+		{"github.com/spf13/cobra", 2}, // subpackage folded into the module
+		{"@tanstack/query", 2},
+		{"yaml", 1},
+		{"react", 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindExternalHubs = %v, want %v (no stdlib, internal, or relative imports)", got, want)
+	}
+	if !reflect.DeepEqual(hubs[0].Files, []string{"web/a.ts", "web/b.ts", "web/c.ts"}) {
+		t.Errorf("lodash importers = %v", hubs[0].Files)
+	}
+}
@@ -4,19 +4,54 @@ import (
 	"bufio"
 	"encoding/json"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // FileGraph represents internal file-to-file dependencies within a project
 type FileGraph struct {
 	Root        string              // project root
 	Module      string              // go module name (e.g., "codemap")
+	Files       []string            // all scanned files (relative paths)
 	Imports     map[string][]string // file -> files it imports
 	Importers   map[string][]string // file -> files that import it
 	Packages    map[string][]string // package path -> files in that package
 	PathAliases map[string][]string // TS/JS path aliases from tsconfig.json (e.g., "@modules/*" -> ["src/modules/*"])
 	BaseURL     string              // TS/JS baseUrl from tsconfig.json
+
+	// GoPackageEdges makes a Go import a single edge to the imported
+	// package's directory (e.g. "scanner", "." for the module root) instead
+	// of edges to its files; set by go_imports = "package" in the config
+	GoPackageEdges bool
+
+	// HubIgnoreImporters are globs ("examples/**", "docs/*.md") for
+	// importers that don't count toward hub status; they stay in Importers.
+	// Set by hub_ignore_importers in the config or --hub-ignore-importers
+	HubIgnoreImporters []string
+
+	// Barrels maps each JS/TS barrel file (one that only re-exports a
+	// single module) to the file it re-exports, see DedupedHubs
+	Barrels map[string]string
+
+	// Minified holds the minified files (see LooksMinified), which are
+	// never hubs; nil when include_minified turned detection off
+	Minified map[string]bool
+
+	// HubThresholds, when non-nil, replaces the per-directory config
+	// lookup of HubThreshold: files it lists use their value, all others
+	// DefaultHubThreshold. Set on graphs whose Root has no readable
+	// config, such as an anonymized copy.
+	HubThresholds map[string]int
+
+	blastOnce sync.Once
+	blast     map[string]int // file -> transitive importer count, see BlastRadius
+
+	configOnce sync.Once
+	config     *ConfigCache // per-directory settings under Root, see HubThreshold
 }
 
 // fileIndex provides fast lookup of files by various import-like keys
@@ -30,9 +65,17 @@ type fileIndex struct {
 // BuildFileGraph analyzes a project and returns file-level dependencies
 // Uses ast-grep for multi-language support with universal fuzzy resolution
 func BuildFileGraph(root string) (*FileGraph, error) {
+	fg, _, err := BuildFileGraphWithAnalyses(root)
+	return fg, err
+}
+
+// BuildFileGraphWithAnalyses is BuildFileGraph that also returns the
+// per-file analyses the graph was resolved from, for callers that need both
+// without running ast-grep twice
+func BuildFileGraphWithAnalyses(root string) (*FileGraph, []FileAnalysis, error) {
 	absRoot, err := filepath.Abs(root)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	fg := &FileGraph{
@@ -45,6 +88,8 @@ func BuildFileGraph(root string) (*FileGraph, error) {
 
 	// Detect module name from go.mod (for Go import resolution)
 	fg.Module = detectModule(absRoot)
+	cfg, _ := LoadConfig(absRoot)
+	fg.ApplyConfig(cfg)
 
 	// Detect path aliases from tsconfig.json (for TS/JS import resolution)
 	fg.PathAliases, fg.BaseURL = detectPathAliases(absRoot)
@@ -53,7 +98,14 @@ func BuildFileGraph(root string) (*FileGraph, error) {
 	gitCache := NewGitIgnoreCache(root)
 	files, err := ScanFiles(root, gitCache, nil, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	for _, f := range files {
+		fg.Files = append(fg.Files, f.Path)
+	}
+	if !cfg.IncludeMinified {
+		fg.Minified = findMinified(absRoot, files)
 	}
 
 	// Build file index for fast fuzzy matching
@@ -63,48 +115,98 @@ func BuildFileGraph(root string) (*FileGraph, error) {
 	// Use ast-grep to extract imports for all languages
 	analyses, err := ScanForDeps(root)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Resolve imports to files using universal fuzzy matching
-	for _, a := range analyses {
-		var resolvedImports []string
+	progressStage(StageResolving)
+	fg.resolveImports(analyses, idx)
+	fg.Barrels = fg.findBarrels(analyses)
 
-		for _, imp := range a.Imports {
-			resolved := fuzzyResolve(imp, a.Path, idx, fg.Module, fg.PathAliases, fg.BaseURL)
-			// Only count imports that resolve to exactly one file.
-			// If an import resolves to multiple files, it's a package/module
-			// import (Go, Python, Rust, etc.) not a file-level import.
-			// This ensures hub detection works correctly across all languages.
-			if len(resolved) == 1 {
-				resolvedImports = append(resolvedImports, resolved[0])
+	return fg, analyses, nil
+}
+
+// resolveImports resolves every file's imports using universal fuzzy matching
+// and fills Imports/Importers. Resolution runs in parallel across files (the
+// index is read-only by now); results are merged in analysis order and each
+// importer list is sorted so the graph is identical from run to run.
+func (fg *FileGraph) resolveImports(analyses []FileAnalysis, idx *fileIndex) {
+	resolved := make([][]string, len(analyses))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				a := analyses[i]
+				var files []string
+				for _, imp := range a.Imports {
+					if fg.GoPackageEdges {
+						if dir, ok := goPackageDir(imp, idx, fg.Module); ok {
+							files = append(files, dir)
+							continue
+						}
+					}
+					matches := fuzzyResolve(imp, a.Path, idx, fg.Module, fg.PathAliases, fg.BaseURL)
+					// Only count imports that resolve to exactly one file.
+					// If an import resolves to multiple files, it's a package/module
+					// import (Go, Python, Rust, etc.) not a file-level import.
+					// This ensures hub detection works correctly across all languages.
+					if len(matches) == 1 {
+						files = append(files, matches[0])
+					}
+				}
+				resolved[i] = files
 			}
-		}
+		}()
+	}
+	for i := range analyses {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-		if len(resolvedImports) > 0 {
-			fg.Imports[a.Path] = dedupe(resolvedImports)
+	for i, a := range analyses {
+		if len(resolved[i]) == 0 {
+			continue
+		}
+		fg.Imports[a.Path] = dedupe(resolved[i])
 
-			// Build reverse map
-			for _, imported := range fg.Imports[a.Path] {
-				fg.Importers[imported] = append(fg.Importers[imported], a.Path)
-			}
+		// Build reverse map
+		for _, imported := range fg.Imports[a.Path] {
+			fg.Importers[imported] = append(fg.Importers[imported], a.Path)
 		}
 	}
-
-	return fg, nil
+	for _, importers := range fg.Importers {
+		sort.Strings(importers)
+	}
 }
 
 // buildFileIndex creates a multi-key index for fast import resolution
 func buildFileIndex(files []FileInfo, goModule string) *fileIndex {
-	idx := &fileIndex{
+	idx := newFileIndex()
+	idx.add(files, goModule, "")
+	return idx
+}
+
+// newFileIndex returns an empty fileIndex
+func newFileIndex() *fileIndex {
+	return &fileIndex{
 		byExact:  make(map[string][]string),
 		bySuffix: make(map[string][]string),
 		byDir:    make(map[string][]string),
 		goPkgs:   make(map[string][]string),
 	}
+}
 
+// add indexes files from one root under prefix (a directory prepended to
+// every path, "" for none). Go package paths come from goModule and the
+// unprefixed directory, since that is how the root's own code imports them.
+func (idx *fileIndex) add(files []FileInfo, goModule, prefix string) {
 	for _, f := range files {
-		path := f.Path
+		path := filepath.Join(prefix, f.Path)
+		pkgDir := filepath.ToSlash(filepath.Dir(f.Path))
 		dir := filepath.Dir(path)
 		if dir == "." {
 			dir = ""
@@ -135,14 +237,12 @@ func buildFileIndex(files []FileInfo, goModule string) *fileIndex {
 		// Go package index
 		if strings.HasSuffix(path, ".go") && goModule != "" {
 			pkgPath := goModule
-			if dir != "" {
-				pkgPath = goModule + "/" + dir
+			if pkgDir != "." {
+				pkgPath = goModule + "/" + pkgDir
 			}
 			idx.goPkgs[pkgPath] = append(idx.goPkgs[pkgPath], path)
 		}
 	}
-
-	return idx
 }
 
 // fuzzyResolve converts an import path to actual file paths using universal matching
@@ -153,14 +253,16 @@ func fuzzyResolve(imp, fromFile string, idx *fileIndex, goModule string, pathAli
 		fromDir = ""
 	}
 
+	// Bundler syntax around the path ('!raw-loader!./foo.txt', './img.png?url')
+	imp = stripLoaderAffixes(imp)
+
 	// Normalize the import path
 	normalized := normalizeImport(imp)
 
-	// Strategy 1: Go package lookup (if it looks like a Go module import)
-	if goModule != "" && strings.HasPrefix(imp, goModule) {
-		if files, ok := idx.goPkgs[imp]; ok {
-			return files
-		}
+	// Strategy 1: Go package lookup (only project packages are indexed,
+	// keyed by module import path, so other imports never match)
+	if files, ok := idx.goPkgs[imp]; ok {
+		return files
 	}
 
 	// Strategy 2: Relative path resolution (./foo, ../bar)
@@ -188,6 +290,33 @@ func fuzzyResolve(imp, fromFile string, idx *fileIndex, goModule string, pathAli
 	return nil
 }
 
+// goPackageDir returns the directory of the project Go package imp names,
+// relative to the root ("." for the module's root package)
+func goPackageDir(imp string, idx *fileIndex, goModule string) (string, bool) {
+	if goModule == "" || len(idx.goPkgs[imp]) == 0 {
+		return "", false
+	}
+	if imp == goModule {
+		return ".", true
+	}
+	rel, ok := strings.CutPrefix(imp, goModule+"/")
+	return filepath.FromSlash(rel), ok
+}
+
+// stripLoaderAffixes removes webpack loader prefixes (everything up to the
+// last "!", e.g. "!!raw-loader!./foo.txt" or "style-loader!css-loader!./a.css")
+// and Vite/webpack query suffixes ("./img.png?url", "./w.js?worker&inline"),
+// leaving the path the bundler actually reads
+func stripLoaderAffixes(imp string) string {
+	if i := strings.LastIndex(imp, "!"); i >= 0 {
+		imp = imp[i+1:]
+	}
+	if i := strings.Index(imp, "?"); i >= 0 {
+		imp = imp[:i]
+	}
+	return imp
+}
+
 // normalizeImport converts various import syntaxes to a path-like format
 func normalizeImport(imp string) string {
 	// Remove quotes
@@ -302,22 +431,218 @@ func detectModule(root string) string {
 	return ""
 }
 
-// IsHub returns true if a file has 3+ importers
+// HubThreshold returns how many importers make path a hub: hub_threshold
+// from the nearest .codemap/config.toml at or above path's directory, or
+// DefaultHubThreshold
+func (fg *FileGraph) HubThreshold(path string) int {
+	if fg.HubThresholds != nil {
+		if n, ok := fg.HubThresholds[path]; ok {
+			return n
+		}
+		return DefaultHubThreshold
+	}
+	if fg.Root == "" {
+		return DefaultHubThreshold
+	}
+	fg.configOnce.Do(func() { fg.config = NewConfigCache(fg.Root) })
+	if n := fg.config.For(path).HubThreshold; n > 0 {
+		return n
+	}
+	return DefaultHubThreshold
+}
+
+// IsHub returns true if a file has at least HubThreshold (default 3)
+// importers, not counting HubIgnoreImporters; minified files and Go
+// package directories never are
 func (fg *FileGraph) IsHub(path string) bool {
-	return len(fg.Importers[path]) >= 3
+	if fg.Minified[path] || fg.isPackageDir(path) {
+		return false
+	}
+	n := fg.HubImporterCount(path)
+	return n > 0 && n >= fg.HubThreshold(path)
 }
 
-// HubFiles returns all files that are imported by 3+ other files
+// HubFiles returns all files that IsHub reports as hubs: imported by at
+// least HubThreshold (default 3) other files, not counting
+// HubIgnoreImporters
 func (fg *FileGraph) HubFiles() []string {
 	var hubs []string
-	for path, importers := range fg.Importers {
-		if len(importers) >= 3 {
+	for _, path := range fg.graphFiles() {
+		if fg.IsHub(path) {
 			hubs = append(hubs, path)
 		}
 	}
 	return hubs
 }
 
+// graphFiles returns the files hub queries range over: Files, or for a
+// graph built from edges alone (no file list) every file its edges name.
+// Go package directories, the import targets of GoPackageEdges, are not
+// files and are left out; their importers count toward each Go file in
+// them, see ImportersOf.
+func (fg *FileGraph) graphFiles() []string {
+	if len(fg.Files) > 0 {
+		return fg.Files
+	}
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		if !seen[path] && !fg.isPackageDir(path) {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	for path, importers := range fg.Importers {
+		add(path)
+		for _, imp := range importers {
+			add(imp)
+		}
+	}
+	for path := range fg.Imports {
+		add(path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// isPackageDir reports whether path is a Go package directory in Importers:
+// with GoPackageEdges, a non-Go path imported only by Go files, since a Go
+// import never resolves to any other kind of file
+func (fg *FileGraph) isPackageDir(path string) bool {
+	if !fg.GoPackageEdges || filepath.Ext(path) == ".go" || len(fg.Importers[path]) == 0 {
+		return false
+	}
+	for _, imp := range fg.Importers[path] {
+		if filepath.Ext(imp) != ".go" {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyConfig sets the graph options that come from the project config;
+// BuildFileGraph applies LoadConfig(root), and a graph rebuilt from saved
+// edges (such as the watch daemon's state) needs the same to judge hubs alike
+func (fg *FileGraph) ApplyConfig(cfg Config) {
+	fg.GoPackageEdges = cfg.GoImports == GoImportsPackage
+	fg.HubIgnoreImporters = cfg.HubIgnoreImporters
+}
+
+// ImportersOf returns the files that import path. With GoPackageEdges, Go
+// imports end at the package directory rather than its files, so a Go
+// file's importers are its package's plus any that import the file itself.
+func (fg *FileGraph) ImportersOf(path string) []string {
+	importers := fg.Importers[path]
+	if !fg.GoPackageEdges || filepath.Ext(path) != ".go" {
+		return importers
+	}
+	pkg := fg.Importers[filepath.Dir(path)]
+	if len(importers) == 0 {
+		return pkg
+	}
+	if len(pkg) == 0 {
+		return importers
+	}
+	merged := dedupe(append(append([]string(nil), importers...), pkg...))
+	sort.Strings(merged)
+	return merged
+}
+
+// HubImporterCount returns how many of path's importers (see ImportersOf)
+// count toward hub status: all of them unless HubIgnoreImporters excludes some
+func (fg *FileGraph) HubImporterCount(path string) int {
+	importers := fg.ImportersOf(path)
+	if len(fg.HubIgnoreImporters) == 0 {
+		return len(importers)
+	}
+	n := 0
+	for _, imp := range importers {
+		if !fg.IgnoredForHubs(imp) {
+			n++
+		}
+	}
+	return n
+}
+
+// IgnoredForHubs reports whether importer matches a HubIgnoreImporters glob
+func (fg *FileGraph) IgnoredForHubs(importer string) bool {
+	for _, pattern := range fg.HubIgnoreImporters {
+		if MatchGlob(pattern, importer) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob matches a slash-separated relative path against a glob where
+// "**" spans any number of directories ("examples/**", "**/gen/*.go") and
+// other segments follow path.Match. A pattern without a slash matches any
+// single path component, the way .gitignore names do; one with a slash
+// matches the whole path or any directory above it, so "tmp/*" covers
+// tmp/cache/a.go. --hub-ignore-importers and --watch-ignore share it.
+func MatchGlob(pattern, rel string) bool {
+	pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+	rel = filepath.ToSlash(rel)
+	if !strings.Contains(pattern, "/") {
+		for _, part := range strings.Split(rel, "/") {
+			if ok, _ := path.Match(pattern, part); ok {
+				return true
+			}
+		}
+		return false
+	}
+	segments, parts := strings.Split(pattern, "/"), strings.Split(rel, "/")
+	for i := 1; i <= len(parts); i++ {
+		if matchSegments(segments, parts[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, with "**"
+// consuming zero or more of them
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// OnDisk reports whether a graph path (relative to Root) still exists.
+// Importer entries can outlive their target when a file is deleted or renamed.
+func (fg *FileGraph) OnDisk(path string) bool {
+	_, err := os.Stat(filepath.Join(fg.Root, path))
+	return err == nil
+}
+
+// MissingTargets returns, sorted, the imported files that no longer exist on
+// disk - every importer edge pointing at one of them is a broken import
+func (fg *FileGraph) MissingTargets() []string {
+	var missing []string
+	for path, importers := range fg.Importers {
+		if len(importers) > 0 && !fg.OnDisk(path) {
+			missing = append(missing, path)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
 // ConnectedFiles returns all files connected to the given file (imports + importers)
 func (fg *FileGraph) ConnectedFiles(path string) []string {
 	seen := make(map[string]bool)
@@ -325,7 +650,7 @@ func (fg *FileGraph) ConnectedFiles(path string) []string {
 	for _, f := range fg.Imports[path] {
 		seen[f] = true
 	}
-	for _, f := range fg.Importers[path] {
+	for _, f := range fg.ImportersOf(path) {
 		seen[f] = true
 	}
 
@@ -0,0 +1,182 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ProjectFingerprint identifies a project's current file set
+type ProjectFingerprint struct {
+	Hash            string `json:"hash"`             // sha256 over sorted paths + sizes (+ content)
+	Files           int    `json:"files"`            // number of scanned files
+	PrimaryLanguage string `json:"primary_language"` // most common source language by file count
+}
+
+// Fingerprint hashes the sorted file list and sizes under root (honoring
+// .gitignore). Two scans of an unchanged tree give the same hash; adding,
+// removing, renaming, or resizing a file changes it. Same-size edits are only
+// caught by ContentFingerprint.
+func Fingerprint(root string) (ProjectFingerprint, error) {
+	return fingerprint(root, false)
+}
+
+// ContentFingerprint is Fingerprint with each file's content hash mixed in,
+// so any edit changes it. It reads every file.
+func ContentFingerprint(root string) (ProjectFingerprint, error) {
+	return fingerprint(root, true)
+}
+
+func fingerprint(root string, withContent bool) (ProjectFingerprint, error) {
+	files, err := ScanFiles(root, NewGitIgnoreCache(root), nil, nil)
+	if err != nil {
+		return ProjectFingerprint{}, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	h := sha256.New()
+	langs := make(map[string]int)
+	for _, f := range files {
+		fmt.Fprintf(h, "%s\t%d", filepath.ToSlash(f.Path), f.Size)
+		if withContent {
+			fmt.Fprintf(h, "\t%s", HashFile(filepath.Join(root, f.Path)))
+		}
+		h.Write([]byte{'\n'})
+		if lang := DetectLanguageAt(root, f.Path); lang != "" {
+			langs[lang]++
+		}
+	}
+
+	primary := ""
+	for lang, n := range langs {
+		if primary == "" || n > langs[primary] || (n == langs[primary] && lang < primary) {
+			primary = lang
+		}
+	}
+
+	return ProjectFingerprint{
+		Hash:            hex.EncodeToString(h.Sum(nil)),
+		Files:           len(files),
+		PrimaryLanguage: primary,
+	}, nil
+}
+
+// SizesFingerprint is the Fingerprint hash of a recorded file set (path ->
+// size), so a saved listing can be checked against Fingerprint(root) without
+// keeping the tree around
+func SizesFingerprint(sizes map[string]int64) string {
+	paths := make([]string, 0, len(sizes))
+	for p := range sizes {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, p := range paths {
+		fmt.Fprintf(h, "%s\t%d\n", filepath.ToSlash(p), sizes[p])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashFile returns the hex sha256 of a file's content, or "" if unreadable;
+// content fingerprints and the watch daemon's content hashes both use it
+func HashFile(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// graphCacheVersion is the version of the graph cache format; bump it
+// whenever FileGraph gains, loses, or changes the meaning of a field, so
+// caches written by older builds are rebuilt instead of served incomplete
+const graphCacheVersion = 3
+
+// graphCache is the on-disk form of a cached file graph
+type graphCache struct {
+	Version     int        `json:"version"`
+	Fingerprint string     `json:"fingerprint"` // ContentFingerprint hash of the project
+	ConfigHash  string     `json:"config_hash"` // configHash of the config it was built with
+	Graph       *FileGraph `json:"graph"`
+}
+
+// configHash identifies the settings a graph was built with: any change to
+// the project config invalidates cached graphs, so new settings that shape
+// the graph never need their own staleness check
+func configHash(cfg Config) string {
+	data, _ := json.Marshal(cfg)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readGraphCache returns the graph cached at path if it was written by this
+// cache version for the given fingerprint and config hash, else nil
+func readGraphCache(path, fingerprint, cfgHash string) *FileGraph {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cached graphCache
+	if json.Unmarshal(data, &cached) != nil || cached.Version != graphCacheVersion ||
+		cached.Fingerprint != fingerprint || cached.ConfigHash != cfgHash || cached.Graph == nil {
+		return nil
+	}
+	return cached.Graph
+}
+
+// writeGraphCache saves fg at path for readGraphCache
+func writeGraphCache(path, fingerprint, cfgHash string, fg *FileGraph) error {
+	data, err := json.Marshal(graphCache{Version: graphCacheVersion, Fingerprint: fingerprint, ConfigHash: cfgHash, Graph: fg})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data)
+}
+
+// graphCachePath is where BuildFileGraphCached keeps its cache
+func graphCachePath(root string) string {
+	return filepath.Join(root, CodemapDir, "cache", "filegraph.json")
+}
+
+// BuildFileGraphCached returns the file graph for root, reusing the copy in
+// .codemap/cache when neither the project's content fingerprint nor its
+// config has changed since it was built. Cache read/write failures fall back
+// to a fresh build.
+func BuildFileGraphCached(root string) (*FileGraph, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	fp, err := ContentFingerprint(absRoot)
+	if err != nil {
+		return BuildFileGraph(absRoot)
+	}
+
+	cachePath := graphCachePath(absRoot)
+	cfg, _ := LoadConfig(absRoot)
+	cfgHash := configHash(cfg)
+	if fg := readGraphCache(cachePath, fp.Hash, cfgHash); fg != nil {
+		fg.Root = absRoot
+		return fg, nil
+	}
+
+	fg, err := BuildFileGraph(absRoot)
+	if err != nil {
+		return nil, err
+	}
+	writeGraphCache(cachePath, fp.Hash, cfgHash, fg)
+	return fg, nil
+}
@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n")
+	write("util/util.go", "package util\n")
+	write("web/app.ts", "export {}\n")
+
+	first, err := Fingerprint(root)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	if first.Files != 3 || first.PrimaryLanguage != "go" {
+		t.Errorf("Expected 3 files, primary go; got %+v", first)
+	}
+
+	again, _ := Fingerprint(root)
+	if again != first {
+		t.Errorf("Unchanged repo should fingerprint the same: %+v vs %+v", first, again)
+	}
+
+	// Codemap's own scratch files never affect identity
+	write(".codemap/state.json", "{}")
+	if fp, _ := Fingerprint(root); fp != first {
+		t.Error("Writing under .codemap should not change the fingerprint")
+	}
+
+	write("util/util.go", "package util\n\nfunc F() {}\n")
+	changed, _ := Fingerprint(root)
+	if changed.Hash == first.Hash {
+		t.Error("Expected a modified file to change the fingerprint")
+	}
+
+	// Same-size edits need the content fingerprint
+	before, _ := ContentFingerprint(root)
+	write("main.go", "package mian\n")
+	if fp, _ := Fingerprint(root); fp.Hash != changed.Hash {
+		t.Error("Size-only fingerprint should ignore same-size edits")
+	}
+	if after, _ := ContentFingerprint(root); after.Hash == before.Hash {
+		t.Error("Expected a same-size edit to change the content fingerprint")
+	}
+}
+
+func TestGraphCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "filegraph.json")
+	fg := &FileGraph{
+		Files:     []string{"index.ts", "lib.ts"},
+		Imports:   map[string][]string{"index.ts": {"lib.ts"}},
+		Importers: map[string][]string{"lib.ts": {"index.ts"}},
+		Barrels:   map[string]string{"index.ts": "lib.ts"},
+	}
+	cfgHash := configHash(Config{})
+	if err := writeGraphCache(path, "fp1", cfgHash, fg); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readGraphCache(path, "fp1", cfgHash)
+	if got == nil || !reflect.DeepEqual(got.Barrels, fg.Barrels) || !reflect.DeepEqual(got.Importers, fg.Importers) {
+		t.Fatalf("Expected a cache hit with the graph intact, got %+v", got)
+	}
+	if readGraphCache(path, "fp2", cfgHash) != nil {
+		t.Error("Expected a miss after the project changed")
+	}
+	if readGraphCache(path, "fp1", configHash(Config{GoImports: GoImportsPackage})) != nil {
+		t.Error("Expected a miss after the config changed")
+	}
+	if configHash(Config{}) != cfgHash {
+		t.Error("Expected the config hash to be stable")
+	}
+
+	// A cache from before versioning (no version or config hash) is stale
+	if err := os.WriteFile(path, []byte(`{"fingerprint":"fp1","graph":{"Files":["a.ts"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if readGraphCache(path, "fp1", cfgHash) != nil {
+		t.Error("Expected an unversioned cache to be rebuilt")
+	}
+}
@@ -0,0 +1,143 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NormalizeFocus cleans a --focus value into a slash-separated path relative
+// to the project root. "" and "." mean no focus; an absolute path or one
+// that climbs out of the root ("../shared") is an error.
+func NormalizeFocus(focus string) (string, error) {
+	if focus == "" {
+		return "", nil
+	}
+	if filepath.IsAbs(focus) || strings.HasPrefix(focus, "/") {
+		return "", fmt.Errorf("focus %q must be relative to the project root", focus)
+	}
+	focus = filepath.ToSlash(filepath.Clean(focus))
+	if focus == ".." || strings.HasPrefix(focus, "../") {
+		return "", fmt.Errorf("focus %q is outside the project root", focus)
+	}
+	if focus == "." {
+		return "", nil
+	}
+	return strings.TrimSuffix(focus, "/"), nil
+}
+
+// ResolveFocus normalizes focus (see NormalizeFocus) and checks that it
+// names a directory under root
+func ResolveFocus(root, focus string) (string, error) {
+	focus, err := NormalizeFocus(focus)
+	if err != nil || focus == "" {
+		return "", err
+	}
+	if info, err := os.Stat(filepath.Join(root, focus)); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("focus %q is not a directory under %s", focus, root)
+	}
+	return focus, nil
+}
+
+// ConfigFocus returns the focus set in root's ConfigFile, resolved as by
+// ResolveFocus ("" when none is set). The MCP tools and subcommands that
+// list files scope their output to it, as the main CLI does.
+func ConfigFocus(root string) (string, error) {
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		return "", err
+	}
+	return ResolveFocus(root, cfg.Focus)
+}
+
+// InFocus reports whether a root-relative path is inside focus (always true
+// when focus is empty)
+func InFocus(path, focus string) bool {
+	if focus == "" {
+		return true
+	}
+	path = filepath.ToSlash(path)
+	return path == focus || strings.HasPrefix(path, focus+"/")
+}
+
+// FilterToFocus keeps only files inside focus
+func FilterToFocus(files []FileInfo, focus string) []FileInfo {
+	if focus == "" {
+		return files
+	}
+	var result []FileInfo
+	for _, f := range files {
+		if InFocus(f.Path, focus) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// FilterAnalysisToFocus keeps only analyses of files inside focus
+func FilterAnalysisToFocus(files []FileAnalysis, focus string) []FileAnalysis {
+	if focus == "" {
+		return files
+	}
+	var result []FileAnalysis
+	for _, f := range files {
+		if InFocus(f.Path, focus) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// FocusGraph returns a copy of fg scoped to focus. Imports are resolved
+// against the whole tree first, so the scoped graph keeps every edge with at
+// least one end inside focus - including edges to and from other parts of
+// the repo - and drops only edges entirely outside it. Files lists only
+// files inside focus.
+func (fg *FileGraph) FocusGraph(focus string) *FileGraph {
+	if focus == "" {
+		return fg
+	}
+	scoped := &FileGraph{
+		Root:        fg.Root,
+		Module:      fg.Module,
+		Imports:     scopeEdges(fg.Imports, focus),
+		Importers:   scopeEdges(fg.Importers, focus),
+		Packages:    make(map[string][]string),
+		PathAliases: fg.PathAliases,
+		BaseURL:     fg.BaseURL,
+		Minified:    fg.Minified,
+		Barrels:     fg.Barrels,
+
+		GoPackageEdges:     fg.GoPackageEdges,
+		HubIgnoreImporters: fg.HubIgnoreImporters,
+		HubThresholds:      fg.HubThresholds,
+	}
+	for _, f := range fg.Files {
+		if InFocus(f, focus) {
+			scoped.Files = append(scoped.Files, f)
+		}
+	}
+	for pkg, files := range fg.Packages {
+		for _, f := range files {
+			if InFocus(f, focus) {
+				scoped.Packages[pkg] = append(scoped.Packages[pkg], f)
+			}
+		}
+	}
+	return scoped
+}
+
+// scopeEdges keeps the adjacency entries with at least one end inside focus
+func scopeEdges(edges map[string][]string, focus string) map[string][]string {
+	result := make(map[string][]string)
+	for from, targets := range edges {
+		fromIn := InFocus(from, focus)
+		for _, to := range targets {
+			if fromIn || InFocus(to, focus) {
+				result[from] = append(result[from], to)
+			}
+		}
+	}
+	return result
+}
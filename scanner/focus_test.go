@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNormalizeFocus(t *testing.T) {
+	tests := map[string]string{
+		"":                  "",
+		".":                 "",
+		"./services/api/":   "services/api",
+		"services//billing": "services/billing",
+	}
+	for in, want := range tests {
+		if got, err := NormalizeFocus(in); err != nil || got != want {
+			t.Errorf("NormalizeFocus(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"..", "../shared", "services/../../x", "/etc"} {
+		if got, err := NormalizeFocus(in); err == nil {
+			t.Errorf("NormalizeFocus(%q) = %q, want an error for a path outside the root", in, got)
+		}
+	}
+}
+
+func TestConfigFocus(t *testing.T) {
+	root := t.TempDir()
+	if focus, err := ConfigFocus(root); err != nil || focus != "" {
+		t.Errorf("no config: got %q, %v", focus, err)
+	}
+	os.MkdirAll(filepath.Join(root, ".codemap"), 0755)
+	os.MkdirAll(filepath.Join(root, "services", "api"), 0755)
+	for content, want := range map[string]string{
+		"focus = \"services/api/\"\n": "services/api",
+		"focus = \"services/web\"\n":  "", // not a directory
+		"focus = \"../elsewhere\"\n":  "",
+	} {
+		os.WriteFile(filepath.Join(root, ConfigFile), []byte(content), 0644)
+		focus, err := ConfigFocus(root)
+		if focus != want || (want == "") == (err == nil) {
+			t.Errorf("%s: got %q, %v; want %q", content, focus, err, want)
+		}
+	}
+}
+
+func TestFocusGraphKeepsCrossFocusEdges(t *testing.T) {
+	fg := &FileGraph{
+		Files: []string{
+			"services/api/handler.go", "services/api/routes.go",
+			"services/billing/invoice.go", "shared/log.go", "services/apiary/bee.go",
+		},
+		Imports: map[string][]string{
+			"services/api/routes.go":      {"services/api/handler.go"},
+			"services/api/handler.go":     {"shared/log.go"},                            // leaves the focus
+			"services/billing/invoice.go": {"services/api/handler.go", "shared/log.go"}, // one edge enters it
+			"services/apiary/bee.go":      {"shared/log.go"},                            // entirely outside
+		},
+		Importers: map[string][]string{
+			"services/api/handler.go": {"services/api/routes.go", "services/billing/invoice.go"},
+			"shared/log.go":           {"services/api/handler.go", "services/billing/invoice.go", "services/apiary/bee.go"},
+		},
+	}
+
+	scoped := fg.FocusGraph("services/api")
+
+	if want := []string{"services/api/handler.go", "services/api/routes.go"}; !reflect.DeepEqual(scoped.Files, want) {
+		t.Errorf("Files = %v, want %v (apiary must not match the api prefix)", scoped.Files, want)
+	}
+
+	wantImports := map[string][]string{
+		"services/api/routes.go":      {"services/api/handler.go"},
+		"services/api/handler.go":     {"shared/log.go"},
+		"services/billing/invoice.go": {"services/api/handler.go"},
+	}
+	if !reflect.DeepEqual(scoped.Imports, wantImports) {
+		t.Errorf("Imports = %v, want %v", scoped.Imports, wantImports)
+	}
+
+	importers := scoped.Importers["services/api/handler.go"]
+	sort.Strings(importers)
+	if want := []string{"services/api/routes.go", "services/billing/invoice.go"}; !reflect.DeepEqual(importers, want) {
+		t.Errorf("Importers[handler.go] = %v, want %v", importers, want)
+	}
+	if want := []string{"services/api/handler.go"}; !reflect.DeepEqual(scoped.Importers["shared/log.go"], want) {
+		t.Errorf("Importers[log.go] = %v, want only the in-focus importer %v", scoped.Importers["shared/log.go"], want)
+	}
+
+	if fg.FocusGraph("") != fg {
+		t.Error("empty focus should return the graph unchanged")
+	}
+}
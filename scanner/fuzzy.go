@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Fuzzy scoring weights (fzf-style subsequence matching)
+const (
+	fuzzyMatchScore    = 1
+	fuzzyConsecutive   = 4 // each char directly after the previous match
+	fuzzyBoundaryBonus = 6 // match at a word start: after / _ - . space, or camelCase hump
+	fuzzyBasenameBonus = 10
+	fuzzyGapPenalty    = 1 // per skipped char between matches (capped per gap)
+	fuzzyMaxGapPenalty = 5
+)
+
+// FuzzyScore scores target against query as a case-insensitive subsequence
+// match ("usrctrl" matches "user_controller.ts"). Higher is better; ok is false
+// when query isn't a subsequence of target.
+func FuzzyScore(query, target string) (score int, ok bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, true
+	}
+	orig := []rune(target)
+	t := []rune(strings.ToLower(target))
+
+	qi, last := 0, -1
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+		score += fuzzyMatchScore
+		if last >= 0 && ti == last+1 {
+			score += fuzzyConsecutive
+		} else if last >= 0 {
+			score -= min(ti-last-1, fuzzyMaxGapPenalty) * fuzzyGapPenalty
+		}
+		if isWordStart(orig, ti) {
+			score += fuzzyBoundaryBonus
+		}
+		last = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+
+	// Prefer hits on the file name over hits spread across directories
+	if isSubsequence(strings.ToLower(query), strings.ToLower(filepath.Base(target))) {
+		score += fuzzyBasenameBonus
+	}
+	return score, true
+}
+
+// isSubsequence reports whether every rune of q appears in s, in order
+func isSubsequence(q, s string) bool {
+	qr := []rune(q)
+	i := 0
+	for _, r := range s {
+		if i < len(qr) && qr[i] == r {
+			i++
+		}
+	}
+	return i == len(qr)
+}
+
+// isWordStart reports whether position i begins a word in s
+func isWordStart(s []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := s[i-1], s[i]
+	switch prev {
+	case '/', '\\', '_', '-', '.', ' ':
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+// FuzzyMatch returns the paths matching query as a subsequence, best first.
+// Ties prefer shorter file names (tighter matches), then shorter paths, then
+// alphabetical order.
+func FuzzyMatch(query string, paths []string) []string {
+	type scored struct {
+		path  string
+		score int
+	}
+	var hits []scored
+	for _, p := range paths {
+		if score, ok := FuzzyScore(query, p); ok {
+			hits = append(hits, scored{p, score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		bi, bj := len(filepath.Base(hits[i].path)), len(filepath.Base(hits[j].path))
+		if bi != bj {
+			return bi < bj
+		}
+		if len(hits[i].path) != len(hits[j].path) {
+			return len(hits[i].path) < len(hits[j].path)
+		}
+		return hits[i].path < hits[j].path
+	})
+
+	result := make([]string, len(hits))
+	for i, h := range hits {
+		result[i] = h.path
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package scanner
+
+import "testing"
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := FuzzyScore("usrctrl", "src/user_controller.ts"); !ok {
+		t.Error("Expected usrctrl to match user_controller.ts")
+	}
+	if _, ok := FuzzyScore("ctrlusr", "src/user_controller.ts"); ok {
+		t.Error("Out-of-order query should not match")
+	}
+	if _, ok := FuzzyScore("", "anything.go"); !ok {
+		t.Error("Empty query should match everything")
+	}
+
+	// Word-boundary and consecutive matches beat scattered ones
+	scattered, _ := FuzzyScore("fg", "scanner/fuzzy_config.go")
+	boundary, _ := FuzzyScore("fg", "scanner/file_graph.go")
+	if boundary <= scattered {
+		t.Errorf("Expected boundary match (%d) to beat scattered match (%d)", boundary, scattered)
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	paths := []string{
+		"docs/user-guide.md",
+		"src/controllers/user_controller.ts",
+		"src/models/user.ts",
+		"src/utils/string_ctrl.ts",
+		"README.md",
+	}
+
+	got := FuzzyMatch("usrctrl", paths)
+	if len(got) == 0 || got[0] != "src/controllers/user_controller.ts" {
+		t.Errorf("Expected user_controller.ts ranked first, got %v", got)
+	}
+	for _, p := range got {
+		if p == "README.md" || p == "src/models/user.ts" {
+			t.Errorf("Unexpected fuzzy match %s", p)
+		}
+	}
+
+	// Equal scores: the tightest file name wins
+	got = FuzzyMatch("user", paths)
+	if got[0] != "src/models/user.ts" {
+		t.Errorf("Expected the shortest file name first, got %v", got)
+	}
+}
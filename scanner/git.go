@@ -2,12 +2,51 @@ package scanner
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
 )
 
+// ScanTrackedFiles returns the files git tracks under root, as listed by
+// `git ls-files` (the index: staged new files are included, files deleted
+// from disk are skipped), with the only/exclude filters and CODEMAP_EXCLUDE
+// applied as in ScanFiles; tracked files ignore .gitignore, as git does.
+// Outside a git work tree it falls back to ScanFiles with cache.
+func ScanTrackedFiles(root string, cache *GitIgnoreCache, only, exclude []string) ([]FileInfo, error) {
+	cmd := exec.Command("git", "ls-files", "-z", "--cached")
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return ScanFiles(root, cache, only, exclude)
+	}
+
+	progressStage(StageScanning)
+	var files []FileInfo
+	for _, rel := range strings.Split(string(output), "\x00") {
+		if rel == "" || IsCodemapPath(rel) {
+			continue
+		}
+		relPath := filepath.FromSlash(rel)
+		absPath := filepath.Join(root, relPath)
+		if cache != nil && cache.EnvExcluded(absPath) {
+			continue
+		}
+		info, err := os.Stat(absPath)
+		if err != nil || info.IsDir() { // deleted, or a submodule
+			continue
+		}
+		ext := filepath.Ext(relPath)
+		if !shouldIncludeFile(relPath, ext, only, exclude) {
+			continue
+		}
+		progressScanned()
+		files = append(files, FileInfo{Path: relPath, Size: info.Size(), Ext: ext, ModTime: info.ModTime()})
+	}
+	return files, nil
+}
+
 // DiffInfo holds all diff-related data for changed files
 type DiffInfo struct {
 	Changed   map[string]bool     // all changed files (modified + untracked)
@@ -31,7 +70,25 @@ func GitDiffInfo(root, ref string) (*DiffInfo, error) {
 		return nil, err
 	}
 
-	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+	info.addNumstat(string(output))
+
+	// Get untracked files (new files)
+	cmd2 := exec.Command("git", "ls-files", "--others", "--exclude-standard")
+	cmd2.Dir = root
+	output2, _ := cmd2.Output()
+	for _, line := range strings.Split(strings.TrimSpace(string(output2)), "\n") {
+		if line != "" {
+			info.Changed[line] = true
+			info.Untracked[line] = true
+		}
+	}
+
+	return info, nil
+}
+
+// addNumstat records the files and line counts from `git diff --numstat` output
+func (info *DiffInfo) addNumstat(output string) {
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
 		if line == "" {
 			continue
 		}
@@ -49,21 +106,76 @@ func GitDiffInfo(root, ref string) (*DiffInfo, error) {
 			info.Stats[filename] = DiffStat{Added: added, Removed: removed}
 		}
 	}
+}
 
-	// Get untracked files (new files)
-	cmd2 := exec.Command("git", "ls-files", "--others", "--exclude-standard")
-	cmd2.Dir = root
-	output2, _ := cmd2.Output()
-	for _, line := range strings.Split(strings.TrimSpace(string(output2)), "\n") {
-		if line != "" {
-			info.Changed[line] = true
-			info.Untracked[line] = true
-		}
+// ChangedFilesBetween returns the files changed between two commits (a..b),
+// independent of the working tree. Renames show up as a removal plus an
+// addition; Untracked is always empty.
+func ChangedFilesBetween(root, a, b string) (*DiffInfo, error) {
+	info := &DiffInfo{
+		Changed:   make(map[string]bool),
+		Untracked: make(map[string]bool),
+		Stats:     make(map[string]DiffStat),
 	}
 
+	cmd := exec.Command("git", "diff", "--numstat", "--no-renames", a, b)
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s %s: %w", a, b, err)
+	}
+	info.addNumstat(string(output))
 	return info, nil
 }
 
+// GitPrefix returns root's path within its git repository, slash-separated
+// with a trailing slash, or "" at the top level or outside a repository
+func GitPrefix(root string) string {
+	cmd := exec.Command("git", "rev-parse", "--show-prefix")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// BuildFileGraphAt builds the file graph as of a commit, using a temporary
+// detached worktree so the user's checkout is never touched. When root is a
+// subdirectory of its repository only that subdirectory is graphed, so file
+// paths are relative to root as in BuildFileGraph; Root is set to root.
+func BuildFileGraphAt(root, rev string) (*FileGraph, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "codemap-rev-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	worktree := filepath.Join(tmpDir, "tree")
+	add := exec.Command("git", "worktree", "add", "--detach", worktree, rev)
+	add.Dir = absRoot
+	if out, err := add.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("git worktree add %s: %v: %s", rev, err, strings.TrimSpace(string(out)))
+	}
+	defer func() {
+		remove := exec.Command("git", "worktree", "remove", "--force", worktree)
+		remove.Dir = absRoot
+		remove.Run()
+	}()
+
+	fg, err := BuildFileGraph(filepath.Join(worktree, filepath.FromSlash(GitPrefix(absRoot))))
+	if err != nil {
+		return nil, err
+	}
+	fg.Root = absRoot
+	return fg, nil
+}
+
 // GitDiffFiles returns files changed between current HEAD and the given branch/ref
 // Also includes untracked files (new files not yet committed)
 func GitDiffFiles(root, ref string) (map[string]bool, error) {
@@ -154,6 +266,26 @@ func FilterAnalysisToChanged(files []FileAnalysis, changed map[string]bool) []Fi
 	return result
 }
 
+// FilterAnalysisToChangedNeighbors keeps changed files plus their direct
+// neighbors in fg: files a changed file imports and files that import one
+func FilterAnalysisToChangedNeighbors(files []FileAnalysis, changed map[string]bool, fg *FileGraph) []FileAnalysis {
+	keep := make(map[string]bool)
+	for path := range changed {
+		path = filepath.ToSlash(path)
+		keep[path] = true
+		if fg == nil {
+			continue
+		}
+		for _, imp := range fg.Imports[path] {
+			keep[imp] = true
+		}
+		for _, imp := range fg.ImportersOf(path) {
+			keep[imp] = true
+		}
+	}
+	return FilterAnalysisToChanged(files, keep)
+}
+
 // ImpactInfo describes which changed files are used by other files
 type ImpactInfo struct {
 	File   string // the file that changed
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -257,3 +258,190 @@ func TestAnalyzeImpactEmpty(t *testing.T) {
 		t.Errorf("Expected nil impacts for empty slice, got %v", impacts)
 	}
 }
+
+func TestChangedFilesBetween(t *testing.T) {
+	tmpDir := setupGitRepo(t)
+
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		out, err := cmd.Output()
+		if err != nil {
+			t.Skipf("git %v failed: %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Commit A
+	write("keep.go", "package main\n")
+	write("edit.go", "package main\n")
+	write("gone.go", "package main\n")
+	run("add", ".")
+	run("commit", "-m", "first")
+	first := run("rev-parse", "HEAD")
+
+	// Commit B: edit one, delete one, add one
+	write("edit.go", "package main\n\nfunc f() {}\n")
+	os.Remove(filepath.Join(tmpDir, "gone.go"))
+	write("added.go", "package main\n")
+	run("add", "-A")
+	run("commit", "-m", "second")
+	second := run("rev-parse", "HEAD")
+
+	// Working tree changes must not leak into a commit range
+	write("keep.go", "package main\n\n// dirty\n")
+	write("untracked.go", "package main\n")
+
+	info, err := ChangedFilesBetween(tmpDir, first, second)
+	if err != nil {
+		t.Fatalf("ChangedFilesBetween failed: %v", err)
+	}
+
+	for _, f := range []string{"edit.go", "gone.go", "added.go"} {
+		if !info.Changed[f] {
+			t.Errorf("Expected %s in changed files, got %v", f, info.Changed)
+		}
+	}
+	if info.Changed["keep.go"] || info.Changed["untracked.go"] {
+		t.Errorf("Working tree changes leaked into commit range: %v", info.Changed)
+	}
+	if len(info.Untracked) != 0 {
+		t.Errorf("Expected no untracked files for a commit range, got %v", info.Untracked)
+	}
+	if got := info.Stats["edit.go"].Added; got != 2 {
+		t.Errorf("Expected 2 added lines in edit.go, got %d", got)
+	}
+
+	if _, err := ChangedFilesBetween(tmpDir, first, "no-such-rev"); err == nil {
+		t.Error("Expected an error for an unknown revision")
+	}
+}
+
+func TestGitAge(t *testing.T) {
+	tmpDir := setupGitRepo(t)
+
+	commit := func(date, email string, files ...string) {
+		for _, f := range files {
+			path := filepath.Join(tmpDir, f)
+			os.MkdirAll(filepath.Dir(path), 0755)
+			if err := os.WriteFile(path, []byte(date+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		exec.Command("git", "-C", tmpDir, "add", "-A").Run()
+		cmd := exec.Command("git", "-C", tmpDir, "-c", "user.email="+email, "commit", "-m", date)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git commit failed: %v %s", err, out)
+		}
+	}
+
+	commit("2020-01-15T12:00:00Z", "alice@example.com", "old.go", "mid.go", "README.md")
+	commit("2022-06-01T12:00:00Z", "bob@example.com", "mid.go", "gone.go")
+	commit("2024-03-10T12:00:00Z", "ALICE@example.com", "new.go")
+	os.Remove(filepath.Join(tmpDir, "gone.go"))
+
+	age, err := GitAge(tmpDir)
+	if err != nil {
+		t.Fatalf("GitAge failed: %v", err)
+	}
+	if got := age.FirstCommit.UTC().Format("2006-01-02"); got != "2020-01-15" {
+		t.Errorf("FirstCommit = %s, want 2020-01-15", got)
+	}
+	if got := age.LastCommit.UTC().Format("2006-01-02"); got != "2024-03-10" {
+		t.Errorf("LastCommit = %s, want 2024-03-10", got)
+	}
+	if age.Commits != 3 {
+		t.Errorf("Commits = %d, want 3", age.Commits)
+	}
+	if age.Authors != 2 {
+		t.Errorf("Authors = %d, want 2 (emails are case-insensitive)", age.Authors)
+	}
+
+	// Source files still on disk, newest first; README and deleted files dropped
+	var paths []string
+	for _, f := range age.Files {
+		paths = append(paths, f.Path)
+	}
+	if strings.Join(paths, ",") != "new.go,mid.go,old.go" {
+		t.Errorf("Files = %v, want [new.go mid.go old.go]", paths)
+	}
+	if got := age.Files[1].LastChanged.UTC().Format("2006-01-02"); got != "2022-06-01" {
+		t.Errorf("mid.go LastChanged = %s, want 2022-06-01", got)
+	}
+}
+
+func TestGitAgeNotARepo(t *testing.T) {
+	if _, err := GitAge(t.TempDir()); err == nil {
+		t.Error("Expected an error outside a git repository")
+	}
+}
+
+func TestScanTrackedFiles(t *testing.T) {
+	tmpDir := setupGitRepo(t)
+	for name, content := range map[string]string{
+		"main.go":       "package main\n",
+		"pkg/util.go":   "package pkg\n",
+		"pkg/gone.go":   "package pkg\n",
+		"notes.md":      "# notes\n",
+		"out/app.bin":   "untracked artifact",
+		"scratch.go":    "package main\n",
+		"pkg/staged.go": "package pkg\n",
+	} {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("add", "main.go", "pkg/util.go", "pkg/gone.go", "notes.md")
+	git("commit", "-m", "initial")
+	git("add", "pkg/staged.go")
+	os.Remove(filepath.Join(tmpDir, "pkg", "gone.go"))
+
+	files, err := ScanTrackedFiles(tmpDir, NewGitIgnoreCache(tmpDir), nil, []string{".md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, filepath.ToSlash(f.Path))
+		if f.Size == 0 {
+			t.Errorf("Expected a size for %s", f.Path)
+		}
+	}
+	// Committed and staged files; not untracked ones, the deleted one, or
+	// the --exclude'd notes.md
+	want := []string{"main.go", "pkg/staged.go", "pkg/util.go"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ScanTrackedFiles = %v, want %v", got, want)
+	}
+
+	// CODEMAP_EXCLUDE applies to tracked files too
+	t.Setenv(ExcludeEnv, "pkg/")
+	files, err = ScanTrackedFiles(tmpDir, NewGitIgnoreCache(tmpDir), nil, []string{".md"})
+	if err != nil || len(files) != 1 || files[0].Path != "main.go" {
+		t.Errorf("Expected CODEMAP_EXCLUDE to drop pkg/, got %v (%v)", files, err)
+	}
+	t.Setenv(ExcludeEnv, "")
+
+	// Outside git it walks the directory
+	plain := t.TempDir()
+	os.WriteFile(filepath.Join(plain, "a.go"), []byte("package a\n"), 0644)
+	files, err = ScanTrackedFiles(plain, NewGitIgnoreCache(plain), nil, nil)
+	if err != nil || len(files) != 1 || files[0].Path != "a.go" {
+		t.Errorf("Expected the walk fallback to find a.go, got %v (%v)", files, err)
+	}
+}
@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// InternalViolation is a Go import of an internal package from outside its allowed tree
+type InternalViolation struct {
+	File    string // importing file (relative path)
+	Import  string // the offending import path
+	Allowed string // package tree allowed to import it
+}
+
+// IsGoInternal reports whether a relative path lives under an internal/ directory
+func IsGoInternal(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if part == "internal" {
+			return true
+		}
+	}
+	return false
+}
+
+// internalParent returns the import path allowed to import pkg, or "" if pkg isn't internal.
+// Per the Go spec, "a/b/internal/c" may only be imported from within "a/b".
+func internalParent(pkg string) (string, bool) {
+	parts := strings.Split(pkg, "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] == "internal" {
+			return strings.Join(parts[:i], "/"), true
+		}
+	}
+	return "", false
+}
+
+// GoInternalPackages returns the sorted set of internal package directories among files
+func GoInternalPackages(files []FileInfo) []string {
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if strings.HasSuffix(f.Path, ".go") && IsGoInternal(f.Path) {
+			seen[filepath.ToSlash(filepath.Dir(f.Path))] = true
+		}
+	}
+	var pkgs []string
+	for p := range seen {
+		pkgs = append(pkgs, p)
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// FindInternalViolations checks Go imports against internal/ visibility rules.
+// module is the go.mod module path used to compute each importer's package path.
+func FindInternalViolations(module string, analyses []FileAnalysis) []InternalViolation {
+	var violations []InternalViolation
+	for _, a := range analyses {
+		if a.Language != "go" {
+			continue
+		}
+		importerPkg := module
+		if dir := filepath.ToSlash(filepath.Dir(a.Path)); dir != "." {
+			importerPkg = module + "/" + dir
+		}
+		for _, imp := range a.Imports {
+			parent, ok := internalParent(imp)
+			if !ok {
+				continue
+			}
+			if parent == "" || importerPkg == parent || strings.HasPrefix(importerPkg, parent+"/") {
+				continue
+			}
+			violations = append(violations, InternalViolation{
+				File:    a.Path,
+				Import:  imp,
+				Allowed: parent,
+			})
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].File != violations[j].File {
+			return violations[i].File < violations[j].File
+		}
+		return violations[i].Import < violations[j].Import
+	})
+	return violations
+}
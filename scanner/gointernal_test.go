@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsGoInternal(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"internal/db/db.go", true},
+		{filepath.Join("svc", "internal", "auth.go"), true},
+		{"pkg/internalize/x.go", false},
+		{"internal.go", false},
+		{"main.go", false},
+	}
+	for _, tt := range tests {
+		if got := IsGoInternal(tt.path); got != tt.want {
+			t.Errorf("IsGoInternal(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFindInternalViolations(t *testing.T) {
+	analyses := []FileAnalysis{
+		// Allowed: svc/a imports svc/internal/store (same parent tree)
+		{Path: "svc/a/handler.go", Language: "go", Imports: []string{"example.com/app/svc/internal/store", "fmt"}},
+		// Allowed: parent package itself
+		{Path: "svc/svc.go", Language: "go", Imports: []string{"example.com/app/svc/internal/store"}},
+		// Illegal: other/ reaches into svc/internal
+		{Path: "other/client.go", Language: "go", Imports: []string{"example.com/app/svc/internal/store"}},
+		// Root internal is importable from anywhere in the module
+		{Path: "tools/gen.go", Language: "go", Imports: []string{"example.com/app/internal/version"}},
+		// Non-Go files are ignored
+		{Path: "web/app.ts", Language: "typescript", Imports: []string{"example.com/app/svc/internal/store"}},
+	}
+
+	violations := FindInternalViolations("example.com/app", analyses)
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d: %v", len(violations), violations)
+	}
+	v := violations[0]
+	if v.File != "other/client.go" {
+		t.Errorf("Expected other/client.go flagged, got %s", v.File)
+	}
+	if v.Allowed != "example.com/app/svc" {
+		t.Errorf("Expected allowed tree example.com/app/svc, got %s", v.Allowed)
+	}
+}
+
+func TestGoInternalPackages(t *testing.T) {
+	files := []FileInfo{
+		{Path: "internal/db/db.go"},
+		{Path: "internal/db/query.go"},
+		{Path: "svc/internal/auth.go"},
+		{Path: "svc/internal/README.md"},
+		{Path: "main.go"},
+	}
+	pkgs := GoInternalPackages(files)
+	if len(pkgs) != 2 || pkgs[0] != "internal/db" || pkgs[1] != "svc/internal" {
+		t.Errorf("Unexpected internal packages: %v", pkgs)
+	}
+}
@@ -0,0 +1,233 @@
+package scanner
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// GraphEdge is one import: From imports To
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RenamedFile pairs a file in graph A with the file in graph B taken to be
+// the same one under another path
+type RenamedFile struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+// GraphDiff compares two projects' dependency graphs, such as a fork and its
+// upstream. Paths are graph A's, slash-separated, with B's renamed files
+// mapped onto A's names so their edges line up.
+type GraphDiff struct {
+	OnlyA       []GraphEdge   `json:"only_a"` // edges in A missing from B
+	OnlyB       []GraphEdge   `json:"only_b"`
+	HubsOnlyA   []string      `json:"hubs_only_a"` // hubs in A that aren't hubs in B
+	HubsOnlyB   []string      `json:"hubs_only_b"`
+	FilesOnlyA  []string      `json:"files_only_a"`
+	FilesOnlyB  []string      `json:"files_only_b"`
+	Renamed     []RenamedFile `json:"renamed,omitempty"`
+	SharedEdges int           `json:"shared_edges"`
+	SharedFiles int           `json:"shared_files"`
+	Divergence  float64       `json:"divergence"` // share of all edges not in both graphs, 0 (same) to 1
+}
+
+// Empty reports whether the graphs have the same files at the same paths,
+// edges, and hubs; a moved file alone makes the diff non-empty
+func (d GraphDiff) Empty() bool {
+	return len(d.OnlyA) == 0 && len(d.OnlyB) == 0 && len(d.HubsOnlyA) == 0 && len(d.HubsOnlyB) == 0 &&
+		len(d.FilesOnlyA) == 0 && len(d.FilesOnlyB) == 0 && len(d.Renamed) == 0
+}
+
+// DiffGraphs reports the edges, hubs, and files present in one graph but not
+// the other. Files are matched by path; a file left unmatched on both sides
+// whose name is unique among the unmatched files of each graph is taken to
+// have moved (a renamed directory, say) and is aligned with its namesake.
+func DiffGraphs(a, b *FileGraph) GraphDiff {
+	var d GraphDiff
+
+	aFiles := slashSet(a.Files)
+	bFiles := slashSet(b.Files)
+	// bToA maps each of B's paths to the path it has in A's terms
+	bToA := make(map[string]string, len(bFiles))
+	for f := range bFiles {
+		if aFiles[f] {
+			bToA[f] = f
+		}
+	}
+	for _, r := range alignRenamed(aFiles, bFiles) {
+		bToA[r.B] = r.A
+		d.Renamed = append(d.Renamed, r)
+	}
+	matchedA := make(map[string]bool, len(bToA))
+	for _, f := range bToA {
+		matchedA[f] = true
+	}
+	for f := range aFiles {
+		if matchedA[f] {
+			d.SharedFiles++
+		} else {
+			d.FilesOnlyA = append(d.FilesOnlyA, f)
+		}
+	}
+	for f := range bFiles {
+		if _, ok := bToA[f]; !ok {
+			d.FilesOnlyB = append(d.FilesOnlyB, f)
+		}
+	}
+	mapB := func(f string) string {
+		if m, ok := bToA[f]; ok {
+			return m
+		}
+		return f
+	}
+
+	aEdges := graphEdges(a, func(f string) string { return f })
+	bEdges := graphEdges(b, mapB)
+	for e := range aEdges {
+		if bEdges[e] {
+			d.SharedEdges++
+		} else {
+			d.OnlyA = append(d.OnlyA, e)
+		}
+	}
+	for e := range bEdges {
+		if !aEdges[e] {
+			d.OnlyB = append(d.OnlyB, e)
+		}
+	}
+	if total := d.SharedEdges + len(d.OnlyA) + len(d.OnlyB); total > 0 {
+		d.Divergence = float64(len(d.OnlyA)+len(d.OnlyB)) / float64(total)
+	}
+
+	aHubs := make(map[string]bool)
+	for _, h := range a.HubFiles() {
+		aHubs[filepath.ToSlash(h)] = true
+	}
+	bHubs := make(map[string]bool)
+	for _, h := range b.HubFiles() {
+		bHubs[mapB(filepath.ToSlash(h))] = true
+	}
+	for h := range aHubs {
+		if !bHubs[h] {
+			d.HubsOnlyA = append(d.HubsOnlyA, h)
+		}
+	}
+	for h := range bHubs {
+		if !aHubs[h] {
+			d.HubsOnlyB = append(d.HubsOnlyB, h)
+		}
+	}
+
+	for _, s := range [][]string{d.HubsOnlyA, d.HubsOnlyB, d.FilesOnlyA, d.FilesOnlyB} {
+		sort.Strings(s)
+	}
+	sortEdges(d.OnlyA)
+	sortEdges(d.OnlyB)
+	sort.Slice(d.Renamed, func(i, j int) bool { return d.Renamed[i].A < d.Renamed[j].A })
+	return d
+}
+
+// alignRenamed pairs files found in only one graph by base name, when the
+// name is unique among the unmatched files on both sides
+func alignRenamed(aFiles, bFiles map[string]bool) []RenamedFile {
+	byName := func(files, other map[string]bool) map[string][]string {
+		m := make(map[string][]string)
+		for f := range files {
+			if !other[f] {
+				m[path.Base(f)] = append(m[path.Base(f)], f)
+			}
+		}
+		return m
+	}
+	aNames, bNames := byName(aFiles, bFiles), byName(bFiles, aFiles)
+	var pairs []RenamedFile
+	for name, as := range aNames {
+		if bs := bNames[name]; len(as) == 1 && len(bs) == 1 {
+			pairs = append(pairs, RenamedFile{A: as[0], B: bs[0]})
+		}
+	}
+	return pairs
+}
+
+// graphEdges is fg's import edges as a set, with paths slash-separated and
+// passed through name
+func graphEdges(fg *FileGraph, name func(string) string) map[GraphEdge]bool {
+	edges := make(map[GraphEdge]bool)
+	for from, targets := range fg.Imports {
+		for _, to := range targets {
+			edges[GraphEdge{From: name(filepath.ToSlash(from)), To: name(filepath.ToSlash(to))}] = true
+		}
+	}
+	return edges
+}
+
+// slashSet is files as a set of slash-separated paths
+func slashSet(files []string) map[string]bool {
+	set := make(map[string]bool, len(files))
+	for _, f := range files {
+		set[filepath.ToSlash(f)] = true
+	}
+	return set
+}
+
+// BoundaryCrossing is a dependency between two packages (directories) that
+// a change introduces, with the file imports that create it
+type BoundaryCrossing struct {
+	From  string      `json:"from"` // importing package directory
+	To    string      `json:"to"`   // imported package directory
+	Edges []GraphEdge `json:"edges"`
+}
+
+// NewBoundaryCrossings reports the package-to-package dependencies present
+// in after but not in before, such as a change that makes one service import
+// another for the first time. A new import between packages that already
+// depended on each other is not reported, nor is one within a package.
+// Only imports made by files in changed (slash paths) are considered, since
+// an unchanged file can't add one; a nil changed considers every file.
+func NewBoundaryCrossings(before, after *FileGraph, changed map[string]bool) []BoundaryCrossing {
+	pkgEdge := func(e GraphEdge) GraphEdge {
+		return GraphEdge{From: path.Dir(e.From), To: path.Dir(e.To)}
+	}
+	known := make(map[GraphEdge]bool)
+	for e := range graphEdges(before, func(f string) string { return f }) {
+		known[pkgEdge(e)] = true
+	}
+
+	byPkg := make(map[GraphEdge][]GraphEdge)
+	for e := range graphEdges(after, func(f string) string { return f }) {
+		if changed != nil && !changed[e.From] {
+			continue
+		}
+		p := pkgEdge(e)
+		if p.From != p.To && !known[p] {
+			byPkg[p] = append(byPkg[p], e)
+		}
+	}
+
+	crossings := make([]BoundaryCrossing, 0, len(byPkg))
+	for p, edges := range byPkg {
+		sortEdges(edges)
+		crossings = append(crossings, BoundaryCrossing{From: p.From, To: p.To, Edges: edges})
+	}
+	sort.Slice(crossings, func(i, j int) bool {
+		if crossings[i].From != crossings[j].From {
+			return crossings[i].From < crossings[j].From
+		}
+		return crossings[i].To < crossings[j].To
+	})
+	return crossings
+}
+
+// sortEdges orders edges by importing file, then imported file
+func sortEdges(s []GraphEdge) {
+	sort.Slice(s, func(i, j int) bool {
+		if s[i].From != s[j].From {
+			return s[i].From < s[j].From
+		}
+		return s[i].To < s[j].To
+	})
+}
@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffGraphs(t *testing.T) {
+	a := &FileGraph{
+		Files: []string{"main.go", "api/server.go", "db/conn.go"},
+		Imports: map[string][]string{
+			"main.go":       {"api/server.go"},
+			"api/server.go": {"db/conn.go"},
+		},
+	}
+	b := &FileGraph{
+		Files: []string{"main.go", "api/server.go", "db/conn.go"},
+		Imports: map[string][]string{
+			"main.go":       {"api/server.go", "db/conn.go"},
+			"api/server.go": {"db/conn.go"},
+		},
+	}
+
+	d := DiffGraphs(a, b)
+	if len(d.OnlyA) != 0 {
+		t.Errorf("Expected no edges only in A, got %v", d.OnlyA)
+	}
+	if want := []GraphEdge{{From: "main.go", To: "db/conn.go"}}; !reflect.DeepEqual(d.OnlyB, want) {
+		t.Errorf("OnlyB = %v, want %v", d.OnlyB, want)
+	}
+	if d.SharedEdges != 2 || d.SharedFiles != 3 {
+		t.Errorf("Expected 2 shared edges and 3 shared files, got %d and %d", d.SharedEdges, d.SharedFiles)
+	}
+	if d.Divergence < 0.33 || d.Divergence > 0.34 {
+		t.Errorf("Divergence = %v, want 1/3", d.Divergence)
+	}
+
+	if d := DiffGraphs(a, a); !d.Empty() || d.Divergence != 0 {
+		t.Errorf("Expected a graph to equal itself, got %+v", d)
+	}
+}
+
+func TestDiffGraphsAlignsMovedFiles(t *testing.T) {
+	a := &FileGraph{
+		Files:   []string{"main.go", "pkg/store/store.go", "pkg/store/cache.go"},
+		Imports: map[string][]string{"main.go": {"pkg/store/store.go"}},
+	}
+	// The fork moved pkg/store to internal/store; cache.go is also in util/,
+	// so its name alone can't place it
+	b := &FileGraph{
+		Files:   []string{"main.go", "internal/store/store.go", "internal/store/cache.go", "util/cache.go"},
+		Imports: map[string][]string{"main.go": {"internal/store/store.go"}},
+	}
+
+	d := DiffGraphs(a, b)
+	if len(d.OnlyA) != 0 || len(d.OnlyB) != 0 {
+		t.Errorf("Expected the moved edge to line up, got only A %v, only B %v", d.OnlyA, d.OnlyB)
+	}
+	if want := []RenamedFile{{A: "pkg/store/store.go", B: "internal/store/store.go"}}; !reflect.DeepEqual(d.Renamed, want) {
+		t.Errorf("Renamed = %v, want %v", d.Renamed, want)
+	}
+	if want := []string{"pkg/store/cache.go"}; !reflect.DeepEqual(d.FilesOnlyA, want) {
+		t.Errorf("FilesOnlyA = %v, want %v", d.FilesOnlyA, want)
+	}
+	if want := []string{"internal/store/cache.go", "util/cache.go"}; !reflect.DeepEqual(d.FilesOnlyB, want) {
+		t.Errorf("FilesOnlyB = %v, want %v", d.FilesOnlyB, want)
+	}
+
+	// A move with nothing else different still counts as a difference
+	moved := &FileGraph{
+		Files:   []string{"main.go", "internal/store/store.go"},
+		Imports: map[string][]string{"main.go": {"internal/store/store.go"}},
+	}
+	a.Files = a.Files[:2]
+	if d := DiffGraphs(a, moved); len(d.Renamed) != 1 || d.Empty() {
+		t.Errorf("Expected a non-empty diff with one move, got %+v", d)
+	}
+}
+
+func TestNewBoundaryCrossings(t *testing.T) {
+	before := &FileGraph{
+		Files: []string{"api/server.go", "api/routes.go", "db/conn.go", "billing/charge.go"},
+		Imports: map[string][]string{
+			"api/server.go": {"db/conn.go"},
+		},
+	}
+	after := &FileGraph{
+		Files: before.Files,
+		Imports: map[string][]string{
+			"api/server.go":     {"db/conn.go", "api/routes.go"},
+			"api/routes.go":     {"db/conn.go"},    // api -> db already existed
+			"billing/charge.go": {"api/server.go"}, // billing -> api is new
+		},
+	}
+
+	got := NewBoundaryCrossings(before, after, nil)
+	want := []BoundaryCrossing{{
+		From:  "billing",
+		To:    "api",
+		Edges: []GraphEdge{{From: "billing/charge.go", To: "api/server.go"}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewBoundaryCrossings = %+v, want %+v", got, want)
+	}
+
+	if got := NewBoundaryCrossings(after, after, nil); len(got) != 0 {
+		t.Errorf("Expected no crossings comparing a graph to itself, got %+v", got)
+	}
+
+	// Only changed files can introduce an import
+	if got := NewBoundaryCrossings(before, after, map[string]bool{"api/server.go": true}); len(got) != 0 {
+		t.Errorf("Expected no crossings when billing/charge.go is unchanged, got %+v", got)
+	}
+	if got := NewBoundaryCrossings(before, after, map[string]bool{"billing/charge.go": true}); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewBoundaryCrossings(changed) = %+v, want %+v", got, want)
+	}
+}
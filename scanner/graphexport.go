@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// GraphExport is a FileGraph in a stable JSON shape for external tools.
+// Paths are relative and slash-separated, every list is sorted, and
+// encoding/json writes map keys in order, so two exports of the same
+// project are byte-identical and diffs between runs show real changes.
+type GraphExport struct {
+	Root           string              `json:"root"`
+	Module         string              `json:"module,omitempty"` // Go module from go.mod
+	Files          []string            `json:"files"`
+	Imports        map[string][]string `json:"imports"`   // file -> files it imports
+	Importers      map[string][]string `json:"importers"` // file -> files that import it
+	ImporterCounts map[string]int      `json:"importer_counts"`
+	Packages       map[string][]string `json:"packages,omitempty"` // Go package import path -> files
+	Hubs           []string            `json:"hubs"`
+	HubThreshold   int                 `json:"hub_threshold"` // importers needed to be a hub at the root
+}
+
+// Export converts fg to a GraphExport
+func (fg *FileGraph) Export() GraphExport {
+	sortedSlash := func(paths []string) []string {
+		out := make([]string, len(paths))
+		for i, p := range paths {
+			out[i] = filepath.ToSlash(p)
+		}
+		sort.Strings(out)
+		return out
+	}
+	slashMap := func(m map[string][]string) map[string][]string {
+		out := make(map[string][]string, len(m))
+		for k, v := range m {
+			out[filepath.ToSlash(k)] = sortedSlash(v)
+		}
+		return out
+	}
+
+	e := GraphExport{
+		Root:           fg.Root,
+		Module:         fg.Module,
+		Files:          sortedSlash(fg.Files),
+		Imports:        slashMap(fg.Imports),
+		Importers:      slashMap(fg.Importers),
+		ImporterCounts: make(map[string]int, len(fg.Importers)),
+		Hubs:           sortedSlash(fg.HubFiles()),
+		HubThreshold:   fg.HubThreshold("."),
+	}
+	for f, importers := range fg.Importers {
+		e.ImporterCounts[filepath.ToSlash(f)] = len(importers)
+	}
+	if len(fg.Packages) > 0 {
+		e.Packages = make(map[string][]string, len(fg.Packages))
+		for pkg, files := range fg.Packages {
+			e.Packages[pkg] = sortedSlash(files)
+		}
+	}
+	return e
+}
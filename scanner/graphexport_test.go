@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGraphExport(t *testing.T) {
+	fg := &FileGraph{
+		Module: "example.com/app",
+		Files:  []string{"main.go", "util/util.go", "b.go", "a.go"},
+		Imports: map[string][]string{
+			"main.go": {"util/util.go"},
+			"b.go":    {"util/util.go"},
+			"a.go":    {"util/util.go", "b.go"},
+		},
+		Importers: map[string][]string{
+			"util/util.go": {"main.go", "b.go", "a.go"},
+			"b.go":         {"a.go"},
+		},
+		Packages: map[string][]string{"example.com/app": {"main.go", "b.go", "a.go"}},
+	}
+
+	e := fg.Export()
+	if want := []string{"a.go", "b.go", "main.go", "util/util.go"}; !reflect.DeepEqual(e.Files, want) {
+		t.Errorf("Files = %v, want %v", e.Files, want)
+	}
+	if want := []string{"a.go", "b.go", "main.go"}; !reflect.DeepEqual(e.Importers["util/util.go"], want) {
+		t.Errorf("Importers[util/util.go] = %v, want %v", e.Importers["util/util.go"], want)
+	}
+	if want := []string{"util/util.go"}; !reflect.DeepEqual(e.Hubs, want) {
+		t.Errorf("Hubs = %v, want %v", e.Hubs, want)
+	}
+	if e.ImporterCounts["util/util.go"] != 3 || e.ImporterCounts["b.go"] != 1 {
+		t.Errorf("ImporterCounts = %v", e.ImporterCounts)
+	}
+	if e.Module != "example.com/app" || e.HubThreshold != DefaultHubThreshold {
+		t.Errorf("Expected module and default hub threshold, got %q, %d", e.Module, e.HubThreshold)
+	}
+
+	// The same graph always encodes to the same bytes
+	first, _ := json.Marshal(e)
+	for i := 0; i < 5; i++ {
+		again, _ := json.Marshal(fg.Export())
+		if string(again) != string(first) {
+			t.Fatalf("Export is not stable:\n%s\n%s", first, again)
+		}
+	}
+}
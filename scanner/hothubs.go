@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// HotHalfLife is how long it takes an importer's contribution to a hot hub
+// score to halve: an importer edited just now counts 1, one edited a week
+// ago counts 0.5, and so on
+const HotHalfLife = 7 * 24 * time.Hour
+
+// HotHub is a hub scored by how recently its importers changed
+type HotHub struct {
+	Path      string
+	Importers int
+	Score     float64 // sum of recency weights over importers
+}
+
+// HotHubs ranks HubFiles by importer recency. Each importer contributes
+// 0.5^(age/HotHalfLife), where age is now minus its entry in lastChanged;
+// importers with no entry contribute nothing. Ties fall back to importer
+// count, then path.
+func (fg *FileGraph) HotHubs(lastChanged map[string]time.Time, now time.Time) []HotHub {
+	var hubs []HotHub
+	for _, path := range fg.HubFiles() {
+		importers := fg.ImportersOf(path)
+		hub := HotHub{Path: path, Importers: len(importers)}
+		for _, imp := range importers {
+			if t, ok := lastChanged[imp]; ok {
+				hub.Score += recencyWeight(now.Sub(t))
+			}
+		}
+		hubs = append(hubs, hub)
+	}
+	sort.Slice(hubs, func(i, j int) bool {
+		if hubs[i].Score != hubs[j].Score {
+			return hubs[i].Score > hubs[j].Score
+		}
+		if hubs[i].Importers != hubs[j].Importers {
+			return hubs[i].Importers > hubs[j].Importers
+		}
+		return hubs[i].Path < hubs[j].Path
+	})
+	return hubs
+}
+
+// recencyWeight decays from 1 (age <= 0) by half every HotHalfLife
+func recencyWeight(age time.Duration) float64 {
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(HotHalfLife))
+}
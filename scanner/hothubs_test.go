@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHotHubsWeightRecentImporters(t *testing.T) {
+	fg := &FileGraph{
+		Importers: map[string][]string{
+			"util.go":   {"a.go", "b.go", "c.go", "d.go"},
+			"config.go": {"x.go", "y.go", "z.go"},
+		},
+	}
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	// Nothing touched recently: plain importer count decides
+	hubs := fg.HotHubs(nil, now)
+	if len(hubs) != 2 || hubs[0].Path != "util.go" || hubs[0].Score != 0 {
+		t.Fatalf("Expected util.go first with no recency data, got %+v", hubs)
+	}
+
+	// One freshly edited importer outweighs four stale ones
+	lastChanged := map[string]time.Time{
+		"a.go": now.Add(-365 * 24 * time.Hour),
+		"b.go": now.Add(-365 * 24 * time.Hour),
+		"x.go": now.Add(-time.Hour),
+	}
+	hubs = fg.HotHubs(lastChanged, now)
+	if hubs[0].Path != "config.go" {
+		t.Fatalf("Expected config.go to rank first once its importer was touched, got %+v", hubs)
+	}
+	if hubs[0].Score <= hubs[1].Score || hubs[0].Score > 1 {
+		t.Errorf("Unexpected scores: %+v", hubs)
+	}
+
+	if w := recencyWeight(HotHalfLife); w < 0.49 || w > 0.51 {
+		t.Errorf("recencyWeight(half-life) = %v, want 0.5", w)
+	}
+}
@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// ImpactViolation describes a changed file whose importer count exceeds the budget
+type ImpactViolation struct {
+	File      string   // the changed file
+	Importers []string // files that import it
+}
+
+// CheckImpactBudget returns changed files imported by more than maxImporters files,
+// sorted by importer count descending (ties broken by path).
+func CheckImpactBudget(fg *FileGraph, changed map[string]bool, maxImporters int) []ImpactViolation {
+	var violations []ImpactViolation
+	for file := range changed {
+		importers := fg.ImportersOf(filepath.FromSlash(file))
+		if len(importers) > maxImporters {
+			violations = append(violations, ImpactViolation{
+				File:      filepath.FromSlash(file),
+				Importers: importers,
+			})
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		if len(violations[i].Importers) != len(violations[j].Importers) {
+			return len(violations[i].Importers) > len(violations[j].Importers)
+		}
+		return violations[i].File < violations[j].File
+	})
+	return violations
+}
@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"testing"
+)
+
+func testImpactGraph() *FileGraph {
+	return &FileGraph{
+		Importers: map[string][]string{
+			"types.go": {"a.go", "b.go", "c.go", "d.go"},
+			"util.go":  {"a.go"},
+		},
+	}
+}
+
+func TestCheckImpactBudgetPass(t *testing.T) {
+	fg := testImpactGraph()
+	changed := map[string]bool{"types.go": true, "util.go": true}
+
+	if violations := CheckImpactBudget(fg, changed, 4); len(violations) != 0 {
+		t.Errorf("Expected no violations at budget 4, got %v", violations)
+	}
+}
+
+func TestCheckImpactBudgetFail(t *testing.T) {
+	fg := testImpactGraph()
+	changed := map[string]bool{"types.go": true, "util.go": true, "new.go": true}
+
+	violations := CheckImpactBudget(fg, changed, 2)
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d: %v", len(violations), violations)
+	}
+	if violations[0].File != "types.go" {
+		t.Errorf("Expected types.go to trip the gate, got %s", violations[0].File)
+	}
+	if len(violations[0].Importers) != 4 {
+		t.Errorf("Expected 4 importers, got %d", len(violations[0].Importers))
+	}
+
+	// Budget of 0 trips every changed file with any importer
+	violations = CheckImpactBudget(fg, changed, 0)
+	if len(violations) != 2 {
+		t.Fatalf("Expected 2 violations at budget 0, got %d", len(violations))
+	}
+	if violations[0].File != "types.go" || violations[1].File != "util.go" {
+		t.Errorf("Expected violations sorted by importer count, got %v", violations)
+	}
+}
+
+func TestCheckImpactBudgetGoPackages(t *testing.T) {
+	// In package mode the importers of scanner/ count for each of its files
+	fg := &FileGraph{
+		GoPackageEdges: true,
+		Importers: map[string][]string{
+			"scanner": {"main.go", "cmd/run.go", "render/tree.go"},
+		},
+	}
+	violations := CheckImpactBudget(fg, map[string]bool{"scanner/types.go": true}, 2)
+	if len(violations) != 1 || len(violations[0].Importers) != 3 {
+		t.Errorf("Expected scanner/types.go to trip the gate with its package's 3 importers, got %v", violations)
+	}
+}
@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// sniffCacheLimit caps how many files' sniffLanguage verdicts are kept; a
+// long-running daemon or MCP server that visits many projects would
+// otherwise grow the cache without end
+const sniffCacheLimit = 20000
+
+// DetectLanguage runs for the same paths many times over (walk, stats, graph
+// building, every watcher event), so sniffLanguage's verdict is memoized per
+// path, safe for concurrent use. Once sniffCacheLimit paths are cached, an
+// arbitrary entry is dropped for each new one.
+var sniffCache = struct {
+	sync.Mutex
+	entries map[string]sniffEntry // path as given -> verdict
+}{entries: make(map[string]sniffEntry)}
+
+// sniffEntry is a cached sniffLanguage result, valid while the file it was
+// read from is unchanged
+type sniffEntry struct {
+	info    os.FileInfo
+	modTime time.Time
+	size    int64
+	lang    string
+}
+
+// cachedSniff is sniffLanguage, reusing the last result for filePath while
+// the file is the same one (same inode) with the same size and modification
+// time. A stat is much cheaper than reading and matching the file's head.
+func cachedSniff(filePath, ext string) string {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return sniffLanguage(filePath, ext)
+	}
+	sniffCache.Lock()
+	e, ok := sniffCache.entries[filePath]
+	sniffCache.Unlock()
+	if ok && e.size == info.Size() && e.modTime.Equal(info.ModTime()) && os.SameFile(e.info, info) {
+		return e.lang
+	}
+
+	lang := sniffLanguage(filePath, ext)
+	sniffCache.Lock()
+	if _, cached := sniffCache.entries[filePath]; !cached && len(sniffCache.entries) >= sniffCacheLimit {
+		for victim := range sniffCache.entries {
+			delete(sniffCache.entries, victim)
+			break
+		}
+	}
+	sniffCache.entries[filePath] = sniffEntry{info: info, modTime: info.ModTime(), size: info.Size(), lang: lang}
+	sniffCache.Unlock()
+	return lang
+}
@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+)
+
+// Line ending styles reported by LineEndingStyle
+const (
+	EndingLF    = "lf"
+	EndingCRLF  = "crlf"
+	EndingMixed = "mixed"
+	EndingNone  = "none" // no line breaks at all
+)
+
+// LineEndingIssue is a source file whose line endings are mixed or disagree
+// with the repo majority
+type LineEndingIssue struct {
+	Path  string
+	Style string // the file's own style (mixed, lf, crlf)
+	CRLF  int
+	LF    int // bare \n, not preceded by \r
+}
+
+// CountLineEndings counts \r\n pairs and bare \n bytes in data
+func CountLineEndings(data []byte) (crlf, lf int) {
+	total := bytes.Count(data, []byte("\n"))
+	crlf = bytes.Count(data, []byte("\r\n"))
+	return crlf, total - crlf
+}
+
+// LineEndingStyle classifies a file's ending counts
+func LineEndingStyle(crlf, lf int) string {
+	switch {
+	case crlf > 0 && lf > 0:
+		return EndingMixed
+	case crlf > 0:
+		return EndingCRLF
+	case lf > 0:
+		return EndingLF
+	}
+	return EndingNone
+}
+
+// FindLineEndingIssues reads each source file and returns those with mixed
+// endings, plus consistent files whose style differs from the majority among
+// consistent files. The majority style is returned alongside (EndingNone when
+// there are no consistent files); ties go to lf. Unreadable and binary files
+// are skipped.
+func FindLineEndingIssues(root string, files []FileInfo) ([]LineEndingIssue, string) {
+	var scanned []LineEndingIssue
+	counts := make(map[string]int)
+	for _, f := range files {
+		if DetectLanguageAt(root, f.Path) == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, f.Path))
+		if err != nil || isBinaryHead(data[:min(len(data), sniffBytes)]) {
+			continue
+		}
+		crlf, lf := CountLineEndings(data)
+		style := LineEndingStyle(crlf, lf)
+		if style == EndingNone {
+			continue
+		}
+		counts[style]++
+		scanned = append(scanned, LineEndingIssue{Path: f.Path, Style: style, CRLF: crlf, LF: lf})
+	}
+
+	majority := EndingNone
+	if counts[EndingLF] > 0 || counts[EndingCRLF] > 0 {
+		majority = EndingLF
+		if counts[EndingCRLF] > counts[EndingLF] {
+			majority = EndingCRLF
+		}
+	}
+
+	var issues []LineEndingIssue
+	for _, s := range scanned {
+		if s.Style == EndingMixed || s.Style != majority {
+			issues = append(issues, s)
+		}
+	}
+	return issues, majority
+}
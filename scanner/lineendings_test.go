@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCountLineEndings(t *testing.T) {
+	crlf, lf := CountLineEndings([]byte("a\r\nb\nc\r\n"))
+	if crlf != 2 || lf != 1 {
+		t.Errorf("CountLineEndings = (%d, %d), want (2, 1)", crlf, lf)
+	}
+}
+
+func TestFindLineEndingIssues(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.go", "package a\n\nfunc A() {}\n")
+	write("b.go", "package a\n\nfunc B() {}\n")
+	write("mixed.go", "package a\r\n\nfunc M() {}\r\n")
+	write("win.go", "package a\r\n\r\nfunc W() {}\r\n")
+	// Not source: ignored even though it's CRLF
+	write("notes.txt", "one\r\ntwo\n")
+
+	files, err := ScanFiles(root, NewGitIgnoreCache(root), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues, majority := FindLineEndingIssues(root, files)
+	if majority != EndingLF {
+		t.Errorf("majority = %q, want %q", majority, EndingLF)
+	}
+	got := make(map[string]string)
+	for _, i := range issues {
+		got[i.Path] = i.Style
+	}
+	if len(got) != 2 || got["mixed.go"] != EndingMixed || got["win.go"] != EndingCRLF {
+		t.Errorf("issues = %v, want mixed.go (mixed) and win.go (crlf)", got)
+	}
+}
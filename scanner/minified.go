@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Minified-file detection thresholds: a file whose name has ".min." is
+// minified, as is a JS or CSS file (minifiedExts) of at least
+// minifiedMinSize bytes whose lines average MinifiedLineLength characters
+// or more (hand-written code averages well under 100)
+const (
+	MinifiedLineLength = 300
+	minifiedMinSize    = 2048
+	minifiedSample     = 64 * 1024
+)
+
+// minifiedExts are the extensions bundlers minify; other files are judged
+// by name alone, so a scan doesn't read every sizeable file
+var minifiedExts = map[string]bool{".js": true, ".mjs": true, ".cjs": true, ".css": true}
+
+// LooksMinified reports whether the file at path (size bytes) is minified
+// or otherwise machine-compressed: a single 200KB line of JavaScript counts
+// as neither one line of meaningful code nor a real hub. Only JS and CSS
+// files are read, and only their first 64KB.
+func LooksMinified(path string, size int64) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if strings.Contains(base, ".min.") {
+		return true
+	}
+	if size < minifiedMinSize || !minifiedExts[filepath.Ext(base)] {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	buf := make([]byte, minifiedSample)
+	n, _ := io.ReadFull(f, buf)
+	return n >= minifiedMinSize && averageLineLength(buf[:n]) >= MinifiedLineLength
+}
+
+// averageLineLength is len(data) over its line count, a last line without
+// a trailing newline included
+func averageLineLength(data []byte) int {
+	lines := bytes.Count(data, []byte{'\n'})
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		lines++
+	}
+	if lines == 0 {
+		return 0
+	}
+	return len(data) / lines
+}
+
+// findMinified returns the minified files among files, relative to root
+func findMinified(root string, files []FileInfo) map[string]bool {
+	minified := make(map[string]bool)
+	for _, f := range files {
+		if LooksMinified(filepath.Join(root, f.Path), f.Size) {
+			minified[f.Path] = true
+		}
+	}
+	return minified
+}
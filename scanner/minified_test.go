@@ -0,0 +1,47 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLooksMinified(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	oneLine := "var a=" + strings.Repeat("1+", 3000) + "1;"
+	readable := strings.Repeat("function f() { return 1 }\n", 200)
+
+	for _, tt := range []struct {
+		name, content string
+		want          bool
+	}{
+		{"vendor.min.js", "var a=1;\n", true},
+		{"bundle.js", oneLine, true},
+		{"app.js", readable, false},
+		{"short.js", "var a=" + strings.Repeat("1+", 400) + "1;", false}, // too small to judge
+		{"theme.CSS", oneLine, true},
+		{"table.go", oneLine, false}, // only JS and CSS are read
+		{"data.json", oneLine, false},
+	} {
+		path := write(tt.name, tt.content)
+		if got := LooksMinified(path, int64(len(tt.content))); got != tt.want {
+			t.Errorf("LooksMinified(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	fg := &FileGraph{
+		Importers: map[string][]string{"vendor.min.js": {"a.js", "b.js", "c.js"}},
+		Minified:  map[string]bool{"vendor.min.js": true},
+	}
+	if fg.IsHub("vendor.min.js") {
+		t.Error("Expected a minified file not to be a hub")
+	}
+}
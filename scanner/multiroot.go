@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// rootPart is one root of a multi-root project, already scanned
+type rootPart struct {
+	prefix   string // directory name its paths are prefixed with
+	module   string // Go module from its go.mod, if any
+	dir      string // absolute root directory
+	files    []FileInfo
+	analyses []FileAnalysis
+}
+
+// RootPrefixes returns the absolute form of each root and the prefix its
+// files get in a combined graph: the root's path relative to the roots'
+// common parent (see commonParent), so "../../shared/lib" from one root
+// lands on the other root's files just as it does on disk. Roots must be
+// distinct directories and none may contain another.
+func RootPrefixes(roots []string) (abs, prefixes []string, err error) {
+	for _, r := range roots {
+		a, err := filepath.Abs(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if info, err := os.Stat(a); err != nil {
+			return nil, nil, err
+		} else if !info.IsDir() {
+			return nil, nil, fmt.Errorf("%s is not a directory", r)
+		}
+		for i, prev := range abs {
+			if a == prev || strings.HasPrefix(a, prev+string(filepath.Separator)) || strings.HasPrefix(prev, a+string(filepath.Separator)) {
+				return nil, nil, fmt.Errorf("roots %s and %s overlap", roots[i], r)
+			}
+		}
+		abs = append(abs, a)
+	}
+	parent := commonParent(abs)
+	for _, a := range abs {
+		rel, err := filepath.Rel(parent, a)
+		if err != nil {
+			return nil, nil, err
+		}
+		prefixes = append(prefixes, rel)
+	}
+	return abs, prefixes, nil
+}
+
+// BuildMultiRootGraph builds one file graph over several roots that form a
+// single system, such as a frontend and a backend in separate directories,
+// so imports resolve across them. Root is the roots' common parent and each
+// file's path is relative to it (see RootPrefixes), so a relative import
+// like "../../backend/types" resolves as it would on disk. Go imports
+// resolve through each root's own go.mod; Module is left empty since there
+// may be several. It also returns the ast-grep analyses the graph was built
+// from, with the same prefixed paths.
+func BuildMultiRootGraph(roots []string) (*FileGraph, []FileAnalysis, error) {
+	abs, prefixes, err := RootPrefixes(roots)
+	if err != nil {
+		return nil, nil, err
+	}
+	parts := make([]rootPart, len(abs))
+	for i, root := range abs {
+		files, err := ScanFiles(root, NewGitIgnoreCache(root), nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		analyses, err := ScanForDeps(root)
+		if err != nil {
+			return nil, nil, err
+		}
+		parts[i] = rootPart{prefix: prefixes[i], module: detectModule(root), dir: root, files: files, analyses: analyses}
+	}
+	progressStage(StageResolving)
+	fg, analyses := mergeRoots(commonParent(abs), parts)
+	return fg, analyses, nil
+}
+
+// mergeRoots builds the combined graph from scanned roots, returning it with
+// the roots' analyses under their prefixed paths
+func mergeRoots(root string, parts []rootPart) (*FileGraph, []FileAnalysis) {
+	fg := &FileGraph{
+		Root:        root,
+		Imports:     make(map[string][]string),
+		Importers:   make(map[string][]string),
+		PathAliases: make(map[string][]string),
+	}
+
+	idx := newFileIndex()
+	var analyses []FileAnalysis
+	for _, p := range parts {
+		idx.add(p.files, p.module, p.prefix)
+		for _, f := range p.files {
+			fg.Files = append(fg.Files, filepath.Join(p.prefix, f.Path))
+		}
+		for _, a := range p.analyses {
+			a.Path = filepath.Join(p.prefix, a.Path)
+			analyses = append(analyses, a)
+		}
+		// Alias targets point into their own root
+		if p.dir != "" {
+			aliases, baseURL := detectPathAliases(p.dir)
+			for pattern, targets := range aliases {
+				for _, t := range targets {
+					fg.PathAliases[pattern] = append(fg.PathAliases[pattern], filepath.Join(p.prefix, baseURL, t))
+				}
+			}
+		}
+	}
+	fg.Packages = idx.goPkgs
+
+	fg.resolveImports(analyses, idx)
+	fg.Barrels = fg.findBarrels(analyses)
+	return fg, analyses
+}
+
+// commonParent is the deepest directory containing every one of the
+// absolute paths dirs; for a single directory, its parent
+func commonParent(dirs []string) string {
+	parent := filepath.Dir(dirs[0])
+	for _, d := range dirs[1:] {
+		for parent != filepath.Dir(parent) && !strings.HasPrefix(d, parent+string(filepath.Separator)) {
+			parent = filepath.Dir(parent)
+		}
+	}
+	return parent
+}
@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeRootsResolvesAcrossRoots(t *testing.T) {
+	// A frontend and a backend checked out side by side; the generated API
+	// client in web imports the backend's model types
+	web := rootPart{
+		prefix: "web",
+		files:  []FileInfo{{Path: "src/client.ts"}, {Path: "src/app.ts"}},
+		analyses: []FileAnalysis{
+			{Path: "src/client.ts", Language: "typescript", Imports: []string{"../../api/models/user"}},
+			{Path: "src/app.ts", Language: "typescript", Imports: []string{"./client"}},
+		},
+	}
+	api := rootPart{
+		prefix: "api",
+		module: "example.com/api",
+		files:  []FileInfo{{Path: "models/user.ts"}, {Path: "main.go"}, {Path: "store/store.go"}},
+		analyses: []FileAnalysis{
+			{Path: "main.go", Language: "go", Imports: []string{"example.com/api/store"}},
+		},
+	}
+
+	fg, analyses := mergeRoots("/src", []rootPart{web, api})
+
+	if got, want := fg.Imports[filepath.Join("web", "src", "client.ts")], []string{filepath.Join("api", "models", "user.ts")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected web's client to import api's model, got %v", got)
+	}
+	if got, want := fg.Imports[filepath.Join("web", "src", "app.ts")], []string{filepath.Join("web", "src", "client.ts")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected imports within a root to still resolve, got %v", got)
+	}
+	if got, want := fg.Imports[filepath.Join("api", "main.go")], []string{filepath.Join("api", "store", "store.go")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected Go imports to resolve through the root's module, got %v", got)
+	}
+	if len(fg.Files) != 5 {
+		t.Errorf("Expected all 5 files, got %v", fg.Files)
+	}
+	if len(analyses) != 3 || analyses[0].Path != filepath.Join("web", "src", "client.ts") {
+		t.Errorf("Expected the analyses under prefixed paths, got %+v", analyses)
+	}
+}
+
+func TestMergeRootsNestedPrefixes(t *testing.T) {
+	// Two roots named app at different depths below their common parent;
+	// web/app imports shared/lib/app through a path that climbs to it
+	web := rootPart{
+		prefix: filepath.Join("web", "app"),
+		files:  []FileInfo{{Path: "main.ts"}},
+		analyses: []FileAnalysis{
+			{Path: "main.ts", Language: "typescript", Imports: []string{"../../shared/lib/app/x"}},
+		},
+	}
+	lib := rootPart{
+		prefix: filepath.Join("shared", "lib", "app"),
+		files:  []FileInfo{{Path: "x.ts"}},
+	}
+
+	fg, _ := mergeRoots("/src", []rootPart{web, lib})
+	want := []string{filepath.Join("shared", "lib", "app", "x.ts")}
+	if got := fg.Imports[filepath.Join("web", "app", "main.ts")]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the import to resolve across nested roots, got %v", got)
+	}
+}
+
+func TestRootPrefixes(t *testing.T) {
+	base := t.TempDir()
+	for _, d := range []string{"a/app", "b/app", "web"} {
+		if err := os.MkdirAll(filepath.Join(base, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	abs, prefixes, err := RootPrefixes([]string{filepath.Join(base, "a/app"), filepath.Join(base, "b/app"), filepath.Join(base, "web")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join("a", "app"), filepath.Join("b", "app"), "web"}; !reflect.DeepEqual(prefixes, want) {
+		t.Errorf("prefixes = %v, want %v", prefixes, want)
+	}
+	if got := commonParent(abs); got != base {
+		t.Errorf("commonParent = %s, want %s", got, base)
+	}
+	if _, _, err := RootPrefixes([]string{filepath.Join(base, "missing")}); err == nil {
+		t.Error("Expected an error for a missing root")
+	}
+	if _, _, err := RootPrefixes([]string{filepath.Join(base, "a"), filepath.Join(base, "a/app")}); err == nil {
+		t.Error("Expected an error for a root inside another")
+	}
+}
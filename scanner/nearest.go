@@ -0,0 +1,98 @@
+package scanner
+
+import "sort"
+
+// Distances returns every file reachable from path through imports in
+// either direction, mapped to its shortest-path hop count (path itself is
+// left out)
+func (fg *FileGraph) Distances(path string) map[string]int {
+	dist := map[string]int{path: 0}
+	queue := []string{path}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, neighbors := range [][]string{fg.Imports[cur], fg.ImportersOf(cur)} {
+			for _, n := range neighbors {
+				if _, seen := dist[n]; !seen {
+					dist[n] = dist[cur] + 1
+					queue = append(queue, n)
+				}
+			}
+		}
+	}
+	delete(dist, path)
+	return dist
+}
+
+// Nearest returns up to n files closest to path in the import graph, a
+// reading list for a change to it: direct imports and importers first, then
+// files two hops away, and so on. Files at the same distance are ordered by
+// importer count, most first. n <= 0 returns every connected file.
+func (fg *FileGraph) Nearest(path string, n int) []string {
+	dist := fg.Distances(path)
+	files := make([]string, 0, len(dist))
+	for f := range dist {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if dist[a] != dist[b] {
+			return dist[a] < dist[b]
+		}
+		if ia, ib := len(fg.ImportersOf(a)), len(fg.ImportersOf(b)); ia != ib {
+			return ia > ib
+		}
+		return a < b
+	})
+	if n > 0 && len(files) > n {
+		files = files[:n]
+	}
+	return files
+}
+
+// NearestHubDependency returns the closest hub that path depends on, directly
+// or through other files, and how many import hops away it is ("" and 0 when
+// path reaches no hub). Hubs at the same distance go to the one with the most
+// importers.
+func (fg *FileGraph) NearestHubDependency(path string) (string, int) {
+	return fg.nearestHub(path, func(f string) []string { return fg.Imports[f] })
+}
+
+// NearestHubDependent returns the closest hub that depends on path, directly
+// or through other files, and its distance; see NearestHubDependency
+func (fg *FileGraph) NearestHubDependent(path string) (string, int) {
+	return fg.nearestHub(path, fg.ImportersOf)
+}
+
+// nearestHub searches outward from path along edges one hop at a time and
+// returns the best hub at the first distance that has any
+func (fg *FileGraph) nearestHub(path string, edges func(string) []string) (string, int) {
+	seen := map[string]bool{path: true}
+	level := []string{path}
+	for dist := 1; len(level) > 0; dist++ {
+		var next []string
+		for _, cur := range level {
+			for _, n := range edges(cur) {
+				if !seen[n] {
+					seen[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		best := ""
+		for _, f := range next {
+			if !fg.IsHub(f) {
+				continue
+			}
+			n, bestN := len(fg.ImportersOf(f)), len(fg.ImportersOf(best))
+			if best == "" || n > bestN || n == bestN && f < best {
+				best = f
+			}
+		}
+		if best != "" {
+			return best, dist
+		}
+		level = next
+	}
+	return "", 0
+}
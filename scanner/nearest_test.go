@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNearest(t *testing.T) {
+	// api.go imports db.go and log.go; main.go imports api.go; db.go imports
+	// sql.go; cli.go imports log.go
+	fg := &FileGraph{
+		Imports: map[string][]string{
+			"main.go": {"api.go"},
+			"api.go":  {"db.go", "log.go"},
+			"db.go":   {"sql.go"},
+			"cli.go":  {"log.go"},
+		},
+		Importers: map[string][]string{
+			"api.go": {"main.go"},
+			"db.go":  {"api.go"},
+			"log.go": {"api.go", "cli.go"},
+			"sql.go": {"db.go"},
+		},
+	}
+
+	// Direct neighbors first, log.go (2 importers) ahead of db.go and
+	// main.go (1 each), then the two-hop files, sql.go (1 importer) first
+	want := []string{"log.go", "db.go", "main.go", "sql.go", "cli.go"}
+	if got := fg.Nearest("api.go", 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("Nearest(api.go) = %v, want %v", got, want)
+	}
+	if got := fg.Nearest("api.go", 2); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("Nearest(api.go, 2) = %v, want %v", got, want[:2])
+	}
+
+	if d := fg.Distances("main.go"); d["api.go"] != 1 || d["log.go"] != 2 || d["cli.go"] != 3 {
+		t.Errorf("Distances(main.go) = %v", d)
+	}
+	if got := fg.Nearest("unknown.go", 5); len(got) != 0 {
+		t.Errorf("Expected nothing near an unknown file, got %v", got)
+	}
+}
+
+func TestNearestHub(t *testing.T) {
+	// types.go is a hub (3 importers), and so is app.go; handler.go reaches
+	// types.go through store.go, and app.go imports router.go, which imports
+	// handler.go
+	fg := &FileGraph{
+		Imports: map[string][]string{
+			"handler.go": {"store.go"},
+			"store.go":   {"types.go"},
+			"a.go":       {"types.go"},
+			"b.go":       {"types.go"},
+			"router.go":  {"handler.go"},
+			"app.go":     {"router.go"},
+			"x.go":       {"app.go"},
+			"y.go":       {"app.go"},
+			"z.go":       {"app.go"},
+		},
+	}
+	fg.Importers = make(map[string][]string)
+	for from, targets := range fg.Imports {
+		for _, to := range targets {
+			fg.Importers[to] = append(fg.Importers[to], from)
+		}
+	}
+
+	if hub, dist := fg.NearestHubDependency("handler.go"); hub != "types.go" || dist != 2 {
+		t.Errorf("NearestHubDependency(handler.go) = %q, %d, want types.go, 2", hub, dist)
+	}
+	if hub, dist := fg.NearestHubDependent("handler.go"); hub != "app.go" || dist != 2 {
+		t.Errorf("NearestHubDependent(handler.go) = %q, %d, want app.go, 2", hub, dist)
+	}
+	if hub, dist := fg.NearestHubDependency("types.go"); hub != "" || dist != 0 {
+		t.Errorf("Expected no hub below types.go, got %q, %d", hub, dist)
+	}
+}
@@ -0,0 +1,107 @@
+package scanner
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PackageLayout is a project's logical structure: packages as each language
+// defines them, rather than plain directories. Every package maps to its
+// files (relative paths, sorted), and only files directly in the package
+// directory count - subpackages are listed separately.
+type PackageLayout struct {
+	GoModule string              `json:"go_module,omitempty"` // module path from go.mod
+	Go       map[string][]string `json:"go,omitempty"`        // import path (module/dir) -> .go files
+	Python   map[string][]string `json:"python,omitempty"`    // dotted name of a dir with __init__.py -> .py files
+	JS       map[string][]string `json:"js,omitempty"`        // dir with an index.{ts,js,...} entry -> JS/TS files
+}
+
+// PackageLayout groups fg.Files into Go packages (from fg.Packages), Python
+// packages, and JS/TS directory modules
+func (fg *FileGraph) PackageLayout() PackageLayout {
+	layout := PackageLayout{
+		GoModule: fg.Module,
+		Go:       make(map[string][]string),
+		Python:   make(map[string][]string),
+		JS:       make(map[string][]string),
+	}
+	for pkg, files := range fg.Packages {
+		layout.Go[pkg] = sortedCopy(files)
+	}
+
+	// Package directories are marked by an entry file
+	pyPkgs := make(map[string]bool)
+	jsPkgs := make(map[string]bool)
+	for _, f := range fg.Files {
+		dir := filepath.ToSlash(filepath.Dir(f))
+		if filepath.Base(f) == "__init__.py" {
+			pyPkgs[dir] = true
+		}
+		if label := DirIndexLabel(f); label != filepath.ToSlash(f) {
+			jsPkgs[dir] = true
+		}
+	}
+
+	for _, f := range fg.Files {
+		dir := filepath.ToSlash(filepath.Dir(f))
+		switch lang := DetectLanguageAt(fg.Root, f); {
+		case lang == "python" && pyPkgs[dir]:
+			name := strings.ReplaceAll(dir, "/", ".")
+			layout.Python[name] = append(layout.Python[name], f)
+		case (lang == "javascript" || lang == "typescript") && jsPkgs[dir]:
+			layout.JS[dir] = append(layout.JS[dir], f)
+		}
+	}
+	for _, m := range []map[string][]string{layout.Python, layout.JS} {
+		for pkg := range m {
+			sort.Strings(m[pkg])
+		}
+	}
+	return layout
+}
+
+// ImportPath is how code in file's own language refers to it: a Go file's
+// package import path (module + directory), a Python file's dotted module
+// name (a package's __init__.py is the package), or else the slash-separated
+// file path
+func (fg *FileGraph) ImportPath(file string) string {
+	slash := filepath.ToSlash(file)
+	switch DetectLanguageAt(fg.Root, file) {
+	case "go":
+		if fg.Module == "" {
+			break
+		}
+		if dir := path.Dir(slash); dir != "." {
+			return fg.Module + "/" + dir
+		}
+		return fg.Module
+	case "python":
+		mod := strings.TrimSuffix(strings.TrimSuffix(slash, ".py"), "/__init__")
+		if mod != "__init__" {
+			return strings.ReplaceAll(mod, "/", ".")
+		}
+	}
+	return slash
+}
+
+// ImportPaths maps files to their import paths, dropping repeats (Go files
+// in one package share a path) but keeping the first-seen order
+func (fg *FileGraph) ImportPaths(files []string) []string {
+	seen := make(map[string]bool, len(files))
+	var out []string
+	for _, f := range files {
+		if p := fg.ImportPath(f); !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func sortedCopy(s []string) []string {
+	out := append([]string(nil), s...)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPackageLayout(t *testing.T) {
+	paths := []string{
+		"main.go", "cmd/serve.go", "cmd/serve_test.go", "internal/db/db.go", "internal/db/tx.go",
+		"tools/gen/__init__.py", "tools/gen/render.py", "tools/gen/sub/__init__.py", "tools/script.py",
+		"web/src/ui/index.ts", "web/src/ui/Button.tsx", "web/src/app.ts",
+	}
+	var files []FileInfo
+	for _, p := range paths {
+		files = append(files, FileInfo{Path: p})
+	}
+	fg := &FileGraph{
+		Module:   "example.com/app",
+		Files:    paths,
+		Packages: buildFileIndex(files, "example.com/app").goPkgs,
+	}
+
+	layout := fg.PackageLayout()
+
+	if layout.GoModule != "example.com/app" {
+		t.Errorf("GoModule = %q", layout.GoModule)
+	}
+	wantGo := map[string][]string{
+		"example.com/app":             {"main.go"},
+		"example.com/app/cmd":         {"cmd/serve.go", "cmd/serve_test.go"},
+		"example.com/app/internal/db": {"internal/db/db.go", "internal/db/tx.go"},
+	}
+	if !reflect.DeepEqual(layout.Go, wantGo) {
+		t.Errorf("Go packages = %v, want %v", layout.Go, wantGo)
+	}
+
+	// tools/ has no __init__.py, so script.py isn't in a package
+	wantPy := map[string][]string{
+		"tools.gen":     {"tools/gen/__init__.py", "tools/gen/render.py"},
+		"tools.gen.sub": {"tools/gen/sub/__init__.py"},
+	}
+	if !reflect.DeepEqual(layout.Python, wantPy) {
+		t.Errorf("Python packages = %v, want %v", layout.Python, wantPy)
+	}
+
+	wantJS := map[string][]string{"web/src/ui": {"web/src/ui/Button.tsx", "web/src/ui/index.ts"}}
+	if !reflect.DeepEqual(layout.JS, wantJS) {
+		t.Errorf("JS packages = %v, want %v", layout.JS, wantJS)
+	}
+}
+
+func TestImportPath(t *testing.T) {
+	fg := &FileGraph{Module: "codemap"}
+	for file, want := range map[string]string{
+		"scanner/walker.go":     "codemap/scanner",
+		"main.go":               "codemap",
+		"tools/gen/render.py":   "tools.gen.render",
+		"tools/gen/__init__.py": "tools.gen",
+		"web/app.ts":            "web/app.ts",
+	} {
+		if got := fg.ImportPath(file); got != want {
+			t.Errorf("ImportPath(%q) = %q, want %q", file, got, want)
+		}
+	}
+
+	got := fg.ImportPaths([]string{"main.go", "scanner/walker.go", "scanner/deps.go", "render/tree.go"})
+	want := []string{"codemap", "codemap/scanner", "codemap/render"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportPaths = %v, want %v", got, want)
+	}
+}
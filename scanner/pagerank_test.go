@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPageRankCentralNode(t *testing.T) {
+	// config.go has only 2 importers, but both are hubs; util.go has 3 leaf
+	// importers. PageRank should see config.go as more central.
+	fg := &FileGraph{
+		Files: []string{
+			"main.go", "cmd.go", "a.go", "b.go", "c.go", "d.go", "e.go",
+			"server.go", "store.go", "config.go", "util.go",
+		},
+		Imports: map[string][]string{
+			"main.go":   {"server.go", "store.go"},
+			"cmd.go":    {"server.go", "store.go"},
+			"a.go":      {"server.go", "store.go"},
+			"b.go":      {"server.go", "store.go", "util.go"},
+			"c.go":      {"util.go"},
+			"d.go":      {"util.go"},
+			"server.go": {"config.go"},
+			"store.go":  {"config.go"},
+		},
+	}
+
+	ranks := fg.PageRank()
+
+	var total float64
+	for _, r := range ranks {
+		total += r
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("Expected scores to sum to 1, got %f", total)
+	}
+
+	core := fg.CoreFiles()
+	if core[0].Path != "config.go" {
+		t.Errorf("Expected config.go to rank highest, got %s (%v)", core[0].Path, core[:3])
+	}
+	if ranks["config.go"] <= ranks["util.go"] {
+		t.Errorf("Expected config.go (%f) to outrank util.go (%f)", ranks["config.go"], ranks["util.go"])
+	}
+	if ranks["e.go"] >= ranks["util.go"] {
+		t.Errorf("Expected isolated e.go (%f) to rank below util.go (%f)", ranks["e.go"], ranks["util.go"])
+	}
+}
+
+func TestPageRankEmpty(t *testing.T) {
+	fg := &FileGraph{}
+	if ranks := fg.PageRank(); len(ranks) != 0 {
+		t.Errorf("Expected no ranks for empty graph, got %v", ranks)
+	}
+}
@@ -0,0 +1,63 @@
+package scanner
+
+import "sync/atomic"
+
+// Progress stages reported while a scan runs
+const (
+	StageScanning  = "scanning"
+	StageAnalyzing = "analyzing imports"
+	StageResolving = "resolving imports"
+)
+
+// Progress counts the work done by long scans and graph builds so a caller
+// can show it while waiting. Methods are safe for concurrent use.
+type Progress struct {
+	stage    atomic.Value // string
+	scanned  atomic.Int64
+	analyzed atomic.Int64
+}
+
+// activeProgress receives counts from every scan while set (see TrackProgress)
+var activeProgress atomic.Pointer[Progress]
+
+// TrackProgress makes scans report into p until TrackProgress(nil)
+func TrackProgress(p *Progress) {
+	activeProgress.Store(p)
+}
+
+// Stage returns the current step ("" before any scan started)
+func (p *Progress) Stage() string {
+	s, _ := p.stage.Load().(string)
+	return s
+}
+
+// Scanned returns how many files the current walk has visited
+func (p *Progress) Scanned() int64 { return p.scanned.Load() }
+
+// Analyzed returns how many files ast-grep has reported on
+func (p *Progress) Analyzed() int64 { return p.analyzed.Load() }
+
+// progressStage switches the tracked progress, if any, to stage; a new walk
+// restarts the scanned count
+func progressStage(stage string) {
+	if p := activeProgress.Load(); p != nil {
+		if stage == StageScanning {
+			p.scanned.Store(0)
+		}
+		p.stage.Store(stage)
+	}
+}
+
+// progressScanned counts one walked file
+func progressScanned() {
+	if p := activeProgress.Load(); p != nil {
+		p.scanned.Add(1)
+	}
+}
+
+// progressAnalyzed records how many files ast-grep matched
+func progressAnalyzed(n int) {
+	if p := activeProgress.Load(); p != nil {
+		p.analyzed.Store(int64(n))
+	}
+}
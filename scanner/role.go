@@ -0,0 +1,144 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Role describes what a file is for, independent of its language
+type Role string
+
+const (
+	RoleSource    Role = "source"
+	RoleTest      Role = "test"
+	RoleConfig    Role = "config"
+	RoleDocs      Role = "docs"
+	RoleAsset     Role = "asset"
+	RoleGenerated Role = "generated"
+)
+
+// Roles lists every role in display order
+var Roles = []Role{RoleSource, RoleTest, RoleConfig, RoleDocs, RoleAsset, RoleGenerated}
+
+// Asset extensions to exclude from "top large files"
+var assetExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".ico": true, ".webp": true,
+	".ttf": true, ".otf": true, ".woff": true, ".woff2": true, ".eot": true,
+	".mp3": true, ".wav": true, ".mp4": true, ".mov": true,
+	".zip": true, ".tar": true, ".gz": true, ".7z": true, ".rar": true,
+	".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true,
+	".exe": true, ".dll": true, ".so": true, ".dylib": true, ".bin": true,
+	".lock": true, ".resolved": true, ".sum": true,
+	".map": true, ".nib": true, ".xib": true, ".storyboard": true,
+}
+
+// IsAssetExtension returns true if the extension is an asset
+func IsAssetExtension(ext string) bool {
+	return assetExtensions[strings.ToLower(ext)]
+}
+
+// Lockfiles and other tool-written files that are never edited by hand
+var generatedNames = map[string]bool{
+	"go.sum": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"cargo.lock": true, "gemfile.lock": true, "poetry.lock": true, "composer.lock": true,
+	"podfile.lock": true, "package.resolved": true,
+}
+
+// IsGeneratedFile reports whether a path looks machine-generated by name
+// (protobuf output, *_generated.go, minified bundles, lockfiles)
+func IsGeneratedFile(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if generatedNames[base] {
+		return true
+	}
+	for _, suffix := range []string{".pb.go", ".pb.gw.go", "_pb2.py", "_pb2_grpc.py", ".pb.h", ".pb.cc",
+		"_generated.go", ".gen.go", "_gen.go", ".g.dart", ".min.js", ".min.css", ".d.ts"} {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+	return strings.Contains(base, ".generated.")
+}
+
+var configExtensions = map[string]bool{
+	".json": true, ".yaml": true, ".yml": true, ".toml": true, ".ini": true, ".cfg": true,
+	".conf": true, ".env": true, ".properties": true, ".plist": true, ".xcconfig": true,
+	".mod": true, ".gradle": true, ".editorconfig": true,
+}
+
+var configNames = map[string]bool{
+	"makefile": true, "dockerfile": true, "gemfile": true, "podfile": true, "rakefile": true,
+	"procfile": true, "justfile": true, "vagrantfile": true, "jenkinsfile": true,
+	".gitignore": true, ".gitattributes": true, ".dockerignore": true, ".npmrc": true,
+	"requirements.txt": true, "cmakelists.txt": true,
+}
+
+// isConfigFile reports whether a path looks like build or tool configuration
+func isConfigFile(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if configNames[base] || configExtensions[filepath.Ext(base)] {
+		return true
+	}
+	// Dotfile rc configs (.npmrc, .prettierrc) and tool configs written as
+	// code (webpack.config.js, vite.config.ts, jest.config.cjs)
+	if strings.HasPrefix(base, ".") && strings.HasSuffix(base, "rc") {
+		return true
+	}
+	return strings.HasSuffix(strings.TrimSuffix(base, filepath.Ext(base)), ".config")
+}
+
+var docsExtensions = map[string]bool{
+	".md": true, ".mdx": true, ".rst": true, ".txt": true, ".adoc": true,
+}
+
+var docsNames = map[string]bool{
+	"license": true, "licence": true, "readme": true, "changelog": true,
+	"authors": true, "contributing": true, "notice": true, "copying": true,
+}
+
+// isDocsFile reports whether a path looks like documentation
+func isDocsFile(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	return docsExtensions[filepath.Ext(base)] || docsNames[strings.TrimSuffix(base, filepath.Ext(base))]
+}
+
+// isTestDir reports whether any directory in path is a conventional test directory
+func isTestDir(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		switch strings.ToLower(part) {
+		case "test", "tests", "__tests__", "spec", "specs", "testdata", "fixtures":
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyFile returns the role of a file based on its path. Checks run from
+// most to least specific: a generated test fixture is generated, a JSON file
+// under tests/ is a test, requirements.txt is config rather than docs, and anything unrecognized is treated as source.
+func ClassifyFile(path string) Role {
+	switch {
+	case IsGeneratedFile(path):
+		return RoleGenerated
+	case IsTestFile(path):
+		return RoleTest
+	case isTestDir(path) && !isDocsFile(path):
+		return RoleTest
+	case isConfigFile(path):
+		return RoleConfig
+	case isDocsFile(path):
+		return RoleDocs
+	case IsAssetExtension(filepath.Ext(path)):
+		return RoleAsset
+	}
+	return RoleSource
+}
+
+// CountRoles tallies files by role
+func CountRoles(files []FileInfo) map[Role]int {
+	counts := make(map[Role]int)
+	for _, f := range files {
+		counts[ClassifyFile(f.Path)]++
+	}
+	return counts
+}
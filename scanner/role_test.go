@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifyFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want Role
+	}{
+		// source
+		{"main.go", RoleSource},
+		{"scanner/walker.go", RoleSource},
+		{"src/App.tsx", RoleSource},
+		{"styles/site.css", RoleSource},
+		// test
+		{"scanner/walker_test.go", RoleTest},
+		{"src/App.test.tsx", RoleTest},
+		{"tests/test_models.py", RoleTest},
+		{"watch/testdata/sample.json", RoleTest},
+		// config
+		{"go.mod", RoleConfig},
+		{"package.json", RoleConfig},
+		{".github/workflows/ci.yml", RoleConfig},
+		{"Makefile", RoleConfig},
+		{"Dockerfile", RoleConfig},
+		{"webpack.config.js", RoleConfig},
+		{".prettierrc", RoleConfig},
+		{"requirements.txt", RoleConfig},
+		// docs
+		{"README.md", RoleDocs},
+		{"docs/HOOKS.md", RoleDocs},
+		{"LICENSE", RoleDocs},
+		{"CHANGELOG.rst", RoleDocs},
+		// asset
+		{"assets/logo.png", RoleAsset},
+		{"fonts/Inter.woff2", RoleAsset},
+		// generated
+		{"api/service.pb.go", RoleGenerated},
+		{"models/user_generated.go", RoleGenerated},
+		{"dist/app.min.js", RoleGenerated},
+		{"go.sum", RoleGenerated},
+		{"package-lock.json", RoleGenerated},
+		{"proto/user_pb2.py", RoleGenerated},
+	}
+	for _, tt := range tests {
+		if got := ClassifyFile(filepath.FromSlash(tt.path)); got != tt.want {
+			t.Errorf("ClassifyFile(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCountRoles(t *testing.T) {
+	files := []FileInfo{
+		{Path: "main.go"}, {Path: "util.go"}, {Path: "util_test.go"},
+		{Path: "go.mod"}, {Path: "README.md"},
+	}
+	counts := CountRoles(files)
+	if counts[RoleSource] != 2 || counts[RoleTest] != 1 || counts[RoleConfig] != 1 || counts[RoleDocs] != 1 {
+		t.Errorf("Unexpected role counts: %v", counts)
+	}
+}
@@ -0,0 +1,153 @@
+package scanner
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ambiguousExts are the extensions DetectLanguage can't settle on its own.
+// "" covers extensionless scripts, identified by their shebang.
+var ambiguousExts = map[string]bool{
+	".h":  true, // C or C++
+	".m":  true, // Objective-C or MATLAB
+	".ts": true, // TypeScript, or a Qt translation / MPEG transport stream
+	"":    true,
+}
+
+// sniffBytes is how much of a file sniffLanguage reads
+const sniffBytes = 4096
+
+var (
+	// C++-only constructs that turn up early in a header
+	cppHints = regexp.MustCompile(`(?m)^\s*(namespace\s+\w+|template\s*<|class\s+\w+[^;]*\{|(public|private|protected)\s*:|using\s+namespace\b|#include\s*<(iostream|string|vector|memory|map|cstdint|cstddef|algorithm)>)|\bstd::`)
+	// Objective-C directives
+	objcHints = regexp.MustCompile(`(?m)^\s*(#import\b|@interface\b|@implementation\b|@protocol\b|@property\b)`)
+	// MATLAB: % comments and function definitions with no braces in sight
+	matlabHints = regexp.MustCompile(`(?m)^\s*(%|function\s+(\[[^\]]*\]\s*=\s*|\w+\s*=\s*)?\w+\s*(\(|$))`)
+)
+
+// shebangLangs maps shebang interpreters to languages
+var shebangLangs = map[string]string{
+	"sh":     "bash",
+	"bash":   "bash",
+	"zsh":    "bash",
+	"python": "python",
+	"node":   "javascript",
+	"ruby":   "ruby",
+	"php":    "php",
+	"lua":    "lua",
+	"elixir": "elixir",
+}
+
+// sniffLanguage classifies a file with an ambiguous extension from its first
+// sniffBytes. An unreadable file gets the extension's usual language.
+func sniffLanguage(filePath, ext string) string {
+	head, err := readHead(filePath)
+	if err != nil {
+		return extToLang[ext]
+	}
+
+	switch ext {
+	case ".h":
+		if cppHints.Match(head) {
+			return "cpp"
+		}
+		return "c"
+	case ".m":
+		switch {
+		case objcHints.Match(head):
+			return "objc"
+		case matlabHints.Match(head) && !bytes.Contains(head, []byte("{")):
+			return "matlab"
+		}
+		return "objc"
+	case ".ts":
+		trimmed := bytes.TrimSpace(head)
+		if bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<TS")) || bytes.IndexByte(head, 0) >= 0 {
+			return "" // Qt Linguist XML or a video stream
+		}
+		return "typescript"
+	case "":
+		return shebangLangs[shebangInterpreter(head)]
+	}
+	return extToLang[ext]
+}
+
+// shebangInterpreter returns the interpreter named by a "#!" first line,
+// without its path or version: "#!/usr/bin/env -S python3.12 -u" -> "python"
+func shebangInterpreter(head []byte) string {
+	line, _, _ := bytes.Cut(head, []byte("\n"))
+	rest, ok := bytes.CutPrefix(line, []byte("#!"))
+	if !ok {
+		return ""
+	}
+	fields := strings.Fields(string(rest))
+	for i, f := range fields {
+		name := path.Base(f)
+		if name == "env" || (i > 0 && strings.HasPrefix(f, "-")) {
+			continue
+		}
+		return strings.TrimRight(name, "0123456789.")
+	}
+	return ""
+}
+
+// isBinaryHead reports whether head, a file's first bytes, is binary or not
+// UTF-8: it has a NUL byte or an invalid sequence. A rune cut off by the end
+// of head doesn't count.
+func isBinaryHead(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	for i := len(head) - 1; i >= 0 && i >= len(head)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(head[i]) {
+			if !utf8.FullRune(head[i:]) {
+				head = head[:i]
+			}
+			break
+		}
+	}
+	return !utf8.Valid(head)
+}
+
+// LooksBinary reports whether the file at filePath is binary or non-UTF-8
+// text, judged from its first sniffBytes. Content features (TODOs, line
+// endings, line counts) skip such files even with a source extension. An
+// unreadable file reports false and is left to the caller's error handling.
+func LooksBinary(filePath string) bool {
+	head, err := readHead(filePath)
+	return err == nil && isBinaryHead(head)
+}
+
+// BinarySources returns the source files (by language) under root that
+// LooksBinary, so callers can note what their content scan skipped
+func BinarySources(root string, files []FileInfo) []string {
+	var result []string
+	for _, f := range files {
+		if DetectLanguageAt(root, f.Path) != "" && LooksBinary(filepath.Join(root, f.Path)) {
+			result = append(result, f.Path)
+		}
+	}
+	return result
+}
+
+// readHead returns up to sniffBytes from the start of filePath
+func readHead(filePath string) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, sniffBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguageSniffsAmbiguousFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"c/list.h":       "#ifndef LIST_H\n#define LIST_H\n#include <stddef.h>\n\nstruct list { struct list *next; };\nvoid list_free(struct list *l);\n#endif\n",
+		"cpp/widget.h":   "#pragma once\n#include <vector>\n\nnamespace ui {\nclass Widget {\npublic:\n  void draw();\n};\n}\n",
+		"cpp/tmpl.h":     "#pragma once\ntemplate <typename T>\nT clamp(T v, T lo, T hi);\n",
+		"objc/View.m":    "#import \"View.h\"\n\n@implementation View\n@end\n",
+		"matlab/rms.m":   "% root mean square\nfunction y = rms(x)\n  y = sqrt(mean(x.^2));\nend\n",
+		"web/app.ts":     "export const x: number = 1;\n",
+		"i18n/app_de.ts": "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<!DOCTYPE TS>\n<TS version=\"2.1\" language=\"de\">\n</TS>\n",
+		"bin/deploy":     "#!/usr/bin/env bash\nset -e\n",
+		"bin/tool":       "#!/usr/bin/env -S python3.12 -u\nprint('hi')\n",
+		"bin/serve":      "#!/usr/local/bin/node\nconsole.log(1)\n",
+		"LICENSE":        "MIT License\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{
+		"c/list.h":       "c",
+		"cpp/widget.h":   "cpp",
+		"cpp/tmpl.h":     "cpp",
+		"objc/View.m":    "objc",
+		"matlab/rms.m":   "matlab",
+		"web/app.ts":     "typescript",
+		"i18n/app_de.ts": "",
+		"bin/deploy":     "bash",
+		"bin/tool":       "python",
+		"bin/serve":      "javascript",
+		"LICENSE":        "",
+	}
+	for name, lang := range want {
+		if got := DetectLanguageAt(root, name); got != lang {
+			t.Errorf("DetectLanguageAt(%q) = %q, want %q", name, got, lang)
+		}
+		if got := DetectLanguage(filepath.Join(root, name)); got != lang {
+			t.Errorf("DetectLanguage(abs %q) = %q, want %q", name, got, lang)
+		}
+	}
+
+	// Unreadable (here: missing) files fall back to the extension's usual language
+	if got := DetectLanguage("no/such/header.h"); got != "c" {
+		t.Errorf("DetectLanguage(missing .h) = %q, want c", got)
+	}
+	if got := DetectLanguage("main.go"); got != "go" {
+		t.Errorf("DetectLanguage(main.go) = %q, want go", got)
+	}
+}
+
+func TestDetectLanguageCacheSeesEdits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shape.h")
+	if err := os.WriteFile(path, []byte("struct shape { int sides; };\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if got := DetectLanguage(path); got != "c" {
+			t.Fatalf("DetectLanguage = %q, want c", got)
+		}
+	}
+
+	// A rewrite changes size and mtime, so the cached verdict is dropped
+	if err := os.WriteFile(path, []byte("#pragma once\nnamespace geo { class Shape {}; }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := DetectLanguage(path); got != "cpp" {
+		t.Errorf("DetectLanguage after edit = %q, want cpp", got)
+	}
+
+	// A full cache drops an entry to make room rather than growing
+	sniffCache.Lock()
+	saved := sniffCache.entries
+	sniffCache.entries = make(map[string]sniffEntry, sniffCacheLimit)
+	for i := 0; i < sniffCacheLimit; i++ {
+		sniffCache.entries[fmt.Sprintf("/gone/%d.h", i)] = sniffEntry{}
+	}
+	sniffCache.Unlock()
+	defer func() {
+		sniffCache.Lock()
+		sniffCache.entries = saved
+		sniffCache.Unlock()
+	}()
+	if got := DetectLanguage(path); got != "cpp" {
+		t.Errorf("DetectLanguage with a full cache = %q, want cpp", got)
+	}
+	sniffCache.Lock()
+	n, cached := len(sniffCache.entries), sniffCache.entries[path].lang
+	sniffCache.Unlock()
+	if n != sniffCacheLimit || cached != "cpp" {
+		t.Errorf("Expected %d entries including %s, got %d (%q)", sniffCacheLimit, path, n, cached)
+	}
+}
+
+func TestBinarySourcesSkipped(t *testing.T) {
+	root := t.TempDir()
+	files := map[string][]byte{
+		"app.js":     []byte("// TODO: ship it\nexport const x = 1;\n"),
+		"bundle.js":  append([]byte("// TODO: not real\n"), 0x00, 0x01, 0x02, 'x'),
+		"latin1.py":  []byte("# TODO caf\xe9\nx = 1\n"),
+		"unicode.go": []byte("package main // TODO: héllo ✓\n"),
+	}
+	var infos []FileInfo
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(root, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		infos = append(infos, FileInfo{Path: name, Size: int64(len(data))})
+	}
+
+	if !LooksBinary(filepath.Join(root, "bundle.js")) {
+		t.Error("Expected a .js file with NUL bytes to look binary")
+	}
+	if LooksBinary(filepath.Join(root, "unicode.go")) {
+		t.Error("Expected valid UTF-8 to look like text")
+	}
+	// A multi-byte rune cut off by the sniff window is not an encoding error
+	if isBinaryHead([]byte("ok \xe2\x9c")) {
+		t.Error("Expected a truncated trailing rune to look like text")
+	}
+
+	skipped := BinarySources(root, infos)
+	sort.Strings(skipped)
+	if strings.Join(skipped, ",") != "bundle.js,latin1.py" {
+		t.Errorf("BinarySources = %v, want [bundle.js latin1.py]", skipped)
+	}
+
+	var withTodos []string
+	for _, ft := range FindTodos(root, infos) {
+		withTodos = append(withTodos, ft.Path)
+	}
+	sort.Strings(withTodos)
+	if strings.Join(withTodos, ",") != "app.js,unicode.go" {
+		t.Errorf("FindTodos files = %v, want binary files skipped", withTodos)
+	}
+}
@@ -0,0 +1,171 @@
+package scanner
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// suggestExamples caps the matching files listed per suggested directory
+const suggestExamples = 3
+
+// suggestStopwords are description words that say nothing about location
+var suggestStopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "for": true,
+	"to": true, "of": true, "in": true, "on": true, "with": true, "new": true,
+	"add": true, "file": true, "that": true, "this": true, "it": true,
+}
+
+// LocationSuggestion is a directory where files like the described one live
+type LocationSuggestion struct {
+	Dir      string   // slash-separated, "." for the root
+	Matches  int      // files in Dir matching at least one keyword
+	Files    int      // files directly in Dir
+	Density  float64  // keyword hits per file in Dir, 0-1
+	Examples []string // a few of the matching files
+}
+
+// SuggestLocations finds where a file matching description would
+// conventionally go: description words are matched against the words of
+// every path (directory names and file names, split on separators and
+// camelCase, plurals folded), matching files are grouped by directory, and
+// directories are ranked by match density - the share of their files that
+// match, weighted by how many keywords each one hits.
+func SuggestLocations(files []FileInfo, description string) []LocationSuggestion {
+	keywords := suggestKeywords(description)
+	if len(keywords) == 0 {
+		return nil
+	}
+
+	type dirStat struct {
+		files, matches int
+		hits           float64
+		examples       []string
+	}
+	dirs := make(map[string]*dirStat)
+	for _, f := range files {
+		p := filepath.ToSlash(f.Path)
+		dir := path.Dir(p)
+		s := dirs[dir]
+		if s == nil {
+			s = &dirStat{}
+			dirs[dir] = s
+		}
+		s.files++
+
+		words := make(map[string]bool)
+		for _, w := range pathWords(p) {
+			words[w] = true
+		}
+		hit := 0
+		for _, k := range keywords {
+			if words[k] {
+				hit++
+			}
+		}
+		if hit == 0 {
+			continue
+		}
+		s.matches++
+		s.hits += float64(hit) / float64(len(keywords))
+		if len(s.examples) < suggestExamples {
+			s.examples = append(s.examples, p)
+		}
+	}
+
+	var out []LocationSuggestion
+	for dir, s := range dirs {
+		if s.matches == 0 {
+			continue
+		}
+		out = append(out, LocationSuggestion{
+			Dir:      dir,
+			Matches:  s.matches,
+			Files:    s.files,
+			Density:  s.hits / float64(s.files),
+			Examples: s.examples,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Density != out[j].Density {
+			return out[i].Density > out[j].Density
+		}
+		if out[i].Matches != out[j].Matches {
+			return out[i].Matches > out[j].Matches
+		}
+		return out[i].Dir < out[j].Dir
+	})
+	return out
+}
+
+// suggestKeywords splits a description into distinct, stemmed keywords
+func suggestKeywords(description string) []string {
+	seen := make(map[string]bool)
+	var keywords []string
+	for _, w := range splitWords(description) {
+		if suggestStopwords[w] || len(w) < 2 {
+			continue
+		}
+		w = stemWord(w)
+		if !seen[w] {
+			seen[w] = true
+			keywords = append(keywords, w)
+		}
+	}
+	return keywords
+}
+
+// pathWords returns the stemmed words of a slash-separated path, with the
+// file's extensions left out
+func pathWords(p string) []string {
+	base := path.Base(p)
+	if i := strings.Index(base, "."); i > 0 {
+		base = base[:i]
+	}
+	words := splitWords(path.Dir(p) + "/" + base)
+	for i, w := range words {
+		words[i] = stemWord(w)
+	}
+	return words
+}
+
+// splitWords lowercases s and splits it on anything that isn't a letter or
+// digit, and between camelCase humps (userController -> user, controller)
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(cur) > 0 {
+				words = append(words, string(cur))
+				cur = nil
+			}
+			continue
+		}
+		if len(cur) > 0 && i > 0 && unicode.IsLower(runes[i-1]) && unicode.IsUpper(r) {
+			words = append(words, string(cur))
+			cur = nil
+		}
+		cur = append(cur, unicode.ToLower(r))
+	}
+	if len(cur) > 0 {
+		words = append(words, string(cur))
+	}
+	return words
+}
+
+// stemWord folds simple English plurals so "controllers" matches "controller"
+func stemWord(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case len(w) > 4 && (strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes") || strings.HasSuffix(w, "sses") || strings.HasSuffix(w, "xes")):
+		return w[:len(w)-2]
+	case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us"):
+		return w[:len(w)-1]
+	}
+	return w
+}
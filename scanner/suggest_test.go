@@ -0,0 +1,56 @@
+package scanner
+
+import "testing"
+
+func TestSuggestLocations(t *testing.T) {
+	files := []FileInfo{
+		{Path: "src/controllers/user_controller.ts"},
+		{Path: "src/controllers/order_controller.ts"},
+		{Path: "src/controllers/index.ts"},
+		{Path: "src/models/user.ts"},
+		{Path: "src/models/order.ts"},
+		{Path: "src/admin/adminController.ts"},
+		{Path: "src/admin/dashboard.ts"},
+		{Path: "src/admin/settings.ts"},
+		{Path: "README.md"},
+	}
+
+	got := SuggestLocations(files, "controller")
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 suggestions, got %+v", got)
+	}
+	if got[0].Dir != "src/controllers" || got[0].Matches != 3 || got[0].Files != 3 {
+		t.Errorf("Expected src/controllers first with 3/3 matches, got %+v", got[0])
+	}
+	if got[1].Dir != "src/admin" || got[1].Matches != 1 {
+		t.Errorf("Expected src/admin second with 1 match, got %+v", got[1])
+	}
+	if len(got[0].Examples) != suggestExamples {
+		t.Errorf("Expected %d examples, got %v", suggestExamples, got[0].Examples)
+	}
+
+	// Descriptions are split into keywords, dropping filler words; files
+	// hitting more keywords weigh more
+	got = SuggestLocations(files, "a new user model")
+	if len(got) == 0 || got[0].Dir != "src/models" {
+		t.Errorf("Expected src/models first for 'a new user model', got %+v", got)
+	}
+
+	if got := SuggestLocations(files, "the"); got != nil {
+		t.Errorf("Expected no suggestions for stopwords only, got %+v", got)
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	got := splitWords("src/userController.test-Helpers")
+	want := []string{"src", "user", "controller", "test", "helpers"}
+	if len(got) != len(want) {
+		t.Fatalf("splitWords = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("splitWords = %v, want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TestCoverage pairs test files with the source files they likely cover
+type TestCoverage struct {
+	Tests    map[string][]string // source file -> test files covering it
+	Untested []string            // source files with no matching test (sorted)
+}
+
+// IsTestFile reports whether a path looks like a test file by naming convention
+func IsTestFile(path string) bool {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+
+	switch strings.ToLower(ext) {
+	case ".go":
+		return strings.HasSuffix(name, "_test")
+	case ".py":
+		return strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test")
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs":
+		return strings.HasSuffix(name, ".test") || strings.HasSuffix(name, ".spec")
+	case ".rb":
+		return strings.HasSuffix(name, "_spec") || strings.HasSuffix(name, "_test")
+	case ".java", ".kt", ".swift", ".cs", ".scala":
+		return strings.HasSuffix(name, "Test") || strings.HasSuffix(name, "Tests") || strings.HasSuffix(name, "Spec")
+	case ".rs", ".ex", ".exs", ".php":
+		return strings.HasSuffix(name, "_test") || strings.HasSuffix(name, "Test")
+	}
+	return false
+}
+
+// testSourceName returns the source filename a test file is named after
+// (foo_test.go -> foo.go, foo.spec.ts -> foo.ts, test_foo.py -> foo.py)
+func testSourceName(testPath string) string {
+	base := filepath.Base(testPath)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+
+	for _, suffix := range []string{"_test", "_spec", ".test", ".spec", "Tests", "Test", "Spec"} {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return strings.TrimSuffix(name, suffix) + ext
+		}
+	}
+	if strings.HasPrefix(name, "test_") && len(name) > len("test_") {
+		return strings.TrimPrefix(name, "test_") + ext
+	}
+	return ""
+}
+
+// TestMapping pairs each test file in the graph with source files it covers, using
+// naming conventions (same directory first, then a unique basename match anywhere,
+// so tests/ and __tests__/ layouts work) plus the test's own internal imports.
+func TestMapping(fg *FileGraph) *TestCoverage {
+	tc := &TestCoverage{Tests: make(map[string][]string)}
+
+	var sources, tests []string
+	byBase := make(map[string][]string) // source basename -> source paths
+	isSource := make(map[string]bool)
+	for _, f := range fg.Files {
+		if DetectLanguageAt(fg.Root, f) == "" {
+			continue
+		}
+		if IsTestFile(f) {
+			tests = append(tests, f)
+			continue
+		}
+		sources = append(sources, f)
+		isSource[f] = true
+		byBase[filepath.Base(f)] = append(byBase[filepath.Base(f)], f)
+	}
+
+	for _, test := range tests {
+		covered := make(map[string]bool)
+
+		if srcName := testSourceName(test); srcName != "" {
+			sameDir := filepath.Join(filepath.Dir(test), srcName)
+			if isSource[sameDir] {
+				covered[sameDir] = true
+			} else if matches := byBase[srcName]; len(matches) == 1 {
+				covered[matches[0]] = true
+			}
+		}
+
+		for _, imp := range fg.Imports[test] {
+			if isSource[imp] {
+				covered[imp] = true
+			}
+		}
+
+		for src := range covered {
+			tc.Tests[src] = append(tc.Tests[src], test)
+		}
+	}
+
+	for src := range tc.Tests {
+		sort.Strings(tc.Tests[src])
+	}
+	for _, src := range sources {
+		if len(tc.Tests[src]) == 0 {
+			tc.Untested = append(tc.Untested, src)
+		}
+	}
+	sort.Strings(tc.Untested)
+	return tc
+}
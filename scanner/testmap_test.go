@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"scanner/walker_test.go", true},
+		{"scanner/walker.go", false},
+		{"src/utils.test.ts", true},
+		{"src/Button.spec.tsx", true},
+		{"src/utils.ts", false},
+		{"tests/test_models.py", true},
+		{"app/models_test.py", true},
+		{"app/models.py", false},
+		{"spec/user_spec.rb", true},
+		{"src/main/UserServiceTest.java", true},
+		{"src/main/UserService.java", false},
+		{"README.md", false},
+	}
+	for _, tt := range tests {
+		if got := IsTestFile(tt.path); got != tt.want {
+			t.Errorf("IsTestFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTestMappingConventions(t *testing.T) {
+	fg := &FileGraph{
+		Files: []string{
+			// Go: same-directory pairing
+			"scanner/walker.go",
+			"scanner/walker_test.go",
+			"scanner/git.go",
+			// TS: sibling .test/.spec and __tests__ directory
+			"src/utils.ts",
+			"src/utils.test.ts",
+			"src/Button.tsx",
+			"src/__tests__/Button.spec.tsx",
+			// Python: tests/ directory with test_ prefix
+			"app/models.py",
+			"tests/test_models.py",
+			"app/views.py",
+			// Non-code files are ignored
+			"README.md",
+		},
+	}
+	for i, f := range fg.Files {
+		fg.Files[i] = filepath.FromSlash(f)
+	}
+	// test_models.py also exercises views.py through an import
+	fg.Imports = map[string][]string{
+		filepath.FromSlash("tests/test_models.py"): {filepath.FromSlash("app/views.py")},
+	}
+
+	tc := TestMapping(fg)
+
+	expect := map[string]string{
+		"scanner/walker.go": "scanner/walker_test.go",
+		"src/utils.ts":      "src/utils.test.ts",
+		"src/Button.tsx":    "src/__tests__/Button.spec.tsx",
+		"app/models.py":     "tests/test_models.py",
+		"app/views.py":      "tests/test_models.py",
+	}
+	for src, test := range expect {
+		got := tc.Tests[filepath.FromSlash(src)]
+		if len(got) != 1 || got[0] != filepath.FromSlash(test) {
+			t.Errorf("Tests[%s] = %v, want [%s]", src, got, test)
+		}
+	}
+
+	if len(tc.Untested) != 1 || tc.Untested[0] != filepath.FromSlash("scanner/git.go") {
+		t.Errorf("Expected only scanner/git.go untested, got %v", tc.Untested)
+	}
+}
@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TodoMarkers are the debt markers FindTodos looks for
+var TodoMarkers = []string{"TODO", "FIXME", "HACK", "XXX"}
+
+// todoPattern matches a marker as a whole word anywhere in a line - comment
+// syntax differs too much across languages to anchor on it
+var todoPattern = regexp.MustCompile(`\b(TODO|FIXME|HACK|XXX)\b`)
+
+// TodoItem is one marker occurrence
+type TodoItem struct {
+	Line   int    // 1-based
+	Marker string // TODO, FIXME, HACK, or XXX
+	Text   string // rest of the line after the marker, e.g. "handle retries"
+}
+
+// FileTodos is every marker found in one file
+type FileTodos struct {
+	Path  string
+	Items []TodoItem
+}
+
+// FindTodos scans source files for TODO/FIXME/HACK/XXX markers and returns
+// the files that have any, most markers first (ties by path). Only the
+// first marker on a line counts. Unreadable and binary files are skipped.
+func FindTodos(root string, files []FileInfo) []FileTodos {
+	var result []FileTodos
+	for _, f := range files {
+		if DetectLanguageAt(root, f.Path) == "" || LooksBinary(filepath.Join(root, f.Path)) {
+			continue
+		}
+		items := scanTodos(filepath.Join(root, f.Path))
+		if len(items) > 0 {
+			result = append(result, FileTodos{Path: f.Path, Items: items})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Items) != len(result[j].Items) {
+			return len(result[i].Items) > len(result[j].Items)
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// scanTodos returns the markers in one file
+func scanTodos(path string) []TodoItem {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var items []TodoItem
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		loc := todoPattern.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		items = append(items, TodoItem{
+			Line:   n,
+			Marker: line[loc[2]:loc[3]],
+			Text:   todoText(line[loc[1]:]),
+		})
+	}
+	return items
+}
+
+// todoText cleans the text after a marker: an optional (owner) tag, colon,
+// and trailing comment closers are dropped
+func todoText(rest string) string {
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") {
+		if i := strings.Index(rest, ")"); i >= 0 {
+			rest = rest[i+1:]
+		}
+	}
+	rest = strings.TrimLeft(rest, ":- \t")
+	rest = strings.TrimSuffix(rest, "*/")
+	rest = strings.TrimSuffix(rest, "-->")
+	return strings.TrimSpace(rest)
+}
+
+// TodoCounts totals the markers across files, keyed by marker
+func TodoCounts(todos []FileTodos) map[string]int {
+	counts := make(map[string]int)
+	for _, ft := range todos {
+		for _, item := range ft.Items {
+			counts[item.Marker]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindTodos(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.go", "package a\n\n// TODO: handle retries\nfunc A() {} // FIXME(bob) leaks\n/* HACK */\n")
+	write("b.py", "# XXX - revisit\nx = 'TODOS are not markers'\ny = 1  # TODO later\n")
+	write("clean.go", "package a\n")
+	// Not a source file: ignored
+	write("notes.txt", "TODO buy milk\n")
+
+	files, err := ScanFiles(root, NewGitIgnoreCache(root), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	todos := FindTodos(root, files)
+
+	if len(todos) != 2 || todos[0].Path != "a.go" || todos[1].Path != "b.py" {
+		t.Fatalf("FindTodos files = %+v, want a.go (3) then b.py (2)", todos)
+	}
+	wantA := []TodoItem{
+		{Line: 3, Marker: "TODO", Text: "handle retries"},
+		{Line: 4, Marker: "FIXME", Text: "leaks"},
+		{Line: 5, Marker: "HACK", Text: ""},
+	}
+	if !reflect.DeepEqual(todos[0].Items, wantA) {
+		t.Errorf("a.go items = %+v, want %+v", todos[0].Items, wantA)
+	}
+
+	counts := TodoCounts(todos)
+	want := map[string]int{"TODO": 2, "FIXME": 1, "HACK": 1, "XXX": 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("TodoCounts = %v, want %v", counts, want)
+	}
+}
@@ -3,37 +3,55 @@ package scanner
 import (
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // FileInfo represents a single file in the codebase.
 type FileInfo struct {
-	Path    string `json:"path"`
-	Size    int64  `json:"size"`
-	Ext     string `json:"ext"`
-	IsNew   bool   `json:"is_new,omitempty"`
-	Added   int    `json:"added,omitempty"`
-	Removed int    `json:"removed,omitempty"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	Ext     string    `json:"ext"`
+	ModTime time.Time `json:"mod_time,omitzero"` // last modification, from the scan
+	IsNew   bool      `json:"is_new,omitempty"`
+	Added   int       `json:"added,omitempty"`
+	Removed int       `json:"removed,omitempty"`
 }
 
 // Project represents the root of the codebase for tree/skyline mode.
 type Project struct {
-	Root    string       `json:"root"`
-	Mode    string       `json:"mode"`
-	Animate bool         `json:"animate"`
-	Files   []FileInfo   `json:"files"`
-	DiffRef string       `json:"diff_ref,omitempty"`
-	Impact  []ImpactInfo `json:"impact,omitempty"`
-	Depth   int          `json:"depth,omitempty"`   // Max tree depth (0 = unlimited)
-	Only    []string     `json:"only,omitempty"`    // Extension filter (e.g., ["swift", "go"])
-	Exclude []string     `json:"exclude,omitempty"` // Exclusion patterns (e.g., [".xcassets", "Fonts"])
+	Root     string       `json:"root"`
+	Mode     string       `json:"mode"`
+	Animate  bool         `json:"animate"`
+	Files    []FileInfo   `json:"files"`
+	DiffRef  string       `json:"diff_ref,omitempty"`
+	Impact   []ImpactInfo `json:"impact,omitempty"`
+	Depth    int          `json:"depth,omitempty"`     // Max tree depth (0 = unlimited)
+	Only     []string     `json:"only,omitempty"`      // Extension filter (e.g., ["swift", "go"])
+	Exclude  []string     `json:"exclude,omitempty"`   // Exclusion patterns (e.g., [".xcassets", "Fonts"])
+	MinSize  int64        `json:"min_size,omitempty"`  // Files smaller than this (bytes) are hidden
+	MaxSize  int64        `json:"max_size,omitempty"`  // Files larger than this (bytes) are hidden
+	TopLangs int          `json:"top_langs,omitempty"` // Skyline: show N largest languages, roll the rest into "other"
+	Width    int          `json:"-"`                   // Output width (0 = detect from terminal / COLUMNS)
+	Collapse []string     `json:"collapse,omitempty"`  // Directory names shown as a single summary line
+	Focus    string       `json:"focus,omitempty"`     // Subdirectory the files are scoped to ("" = whole project)
+	Detailed bool         `json:"detailed,omitempty"`  // Skyline: show file counts on buildings when width allows
+	Bars     bool         `json:"bars,omitempty"`      // Tree: one file per line with a bar scaled to the largest file
+	BarsAll  bool         `json:"bars_all,omitempty"`  // With Bars: give assets bars too and count them in the scale
 }
 
 // FileAnalysis holds extracted info about a single file for deps mode.
+//
+// Functions follows one policy in every language so counts are comparable:
+// named functions and methods declared at file, type, or module level are
+// counted; anonymous functions, closures, lambdas, and functions declared
+// inside another function's body are not. Constructors are not counted, and
+// names are deduplicated (overloads count once).
 type FileAnalysis struct {
 	Path      string   `json:"path"`
 	Language  string   `json:"language"`
 	Functions []string `json:"functions"`
 	Imports   []string `json:"imports"`
+	Exports   []string `json:"exports,omitempty"` // JS/TS exported names ("default" for a default export, "* from ./x" for export *), Python __all__
 }
 
 // DepsProject is the JSON output for --deps mode.
@@ -43,6 +61,12 @@ type DepsProject struct {
 	Files        []FileAnalysis      `json:"files"`
 	ExternalDeps map[string][]string `json:"external_deps"`
 	DiffRef      string              `json:"diff_ref,omitempty"`
+	Width        int                 `json:"-"`                     // Output width (0 = detect from terminal / COLUMNS)
+	ChainDepth   int                 `json:"chain_depth,omitempty"` // Expand internal dependency chains up to N hops (0 = default view)
+	Focus        string              `json:"focus,omitempty"`       // Subdirectory the files are scoped to; edges leaving it stay visible
+	Graph        *FileGraph          `json:"-"`                     // Prebuilt file graph to render from (nil = build from Root)
+	Changed      map[string]bool     `json:"-"`                     // With --diff: the changed files; others shown are their neighbors, and only edges touching a changed file are drawn
+	DedupeHubs   bool                `json:"-"`                     // Count a barrel and the module it re-exports as one hub (FileGraph.Barrels)
 }
 
 // extToLang maps file extensions to language names
@@ -77,9 +101,25 @@ var extToLang = map[string]string{
 	".sol":   "solidity",
 }
 
-// DetectLanguage returns the language name for a file path
+// DetectLanguage returns the language name for a file path. Extensions that
+// several languages share (see sniffLanguage) are settled by peeking at the
+// file's first lines when filePath can be opened as given; otherwise the
+// extension's usual language is returned.
 func DetectLanguage(filePath string) string {
 	ext := strings.ToLower(filepath.Ext(filePath))
+	if ambiguousExts[ext] {
+		return cachedSniff(filePath, ext)
+	}
+	return extToLang[ext]
+}
+
+// DetectLanguageAt is DetectLanguage for a path relative to root, so
+// ambiguous files are sniffed regardless of the working directory
+func DetectLanguageAt(root, relPath string) string {
+	ext := strings.ToLower(filepath.Ext(relPath))
+	if ambiguousExts[ext] {
+		return cachedSniff(filepath.Join(root, relPath), ext)
+	}
 	return extToLang[ext]
 }
 
@@ -103,6 +143,8 @@ var LangDisplay = map[string]string{
 	"scala":      "Scala",
 	"elixir":     "Elixir",
 	"solidity":   "Solidity",
+	"objc":       "Objective-C",
+	"matlab":     "MATLAB",
 }
 
 // dedupe removes duplicate strings from a slice
@@ -0,0 +1,178 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UnusedDep is a declared dependency no scanned source file imports
+type UnusedDep struct {
+	ExternalDep
+	LowConfidence bool   // may well be used without an import (tooling, plugins, indirect)
+	Reason        string // why confidence is low
+}
+
+// pythonImportNames maps distributions whose import name differs from the
+// package name (beyond case and -/_) to that import name
+var pythonImportNames = map[string]string{
+	"beautifulsoup4": "bs4", "pillow": "PIL", "pyyaml": "yaml", "scikit_learn": "sklearn",
+	"python_dateutil": "dateutil", "opencv_python": "cv2", "protobuf": "google",
+	"pyjwt": "jwt", "python_dotenv": "dotenv", "attrs": "attr",
+}
+
+// toolingPrefixes are dependencies usually used from config files, CLIs, or
+// plugin loaders rather than imported by source
+var toolingPrefixes = map[string][]string{
+	"javascript": {"@types/", "@babel/", "babel-", "eslint", "@eslint/", "prettier", "typescript", "ts-node", "tsx",
+		"jest", "vitest", "@vitejs/", "vite", "webpack", "rollup", "esbuild", "postcss", "autoprefixer",
+		"tailwindcss", "nodemon", "husky", "lint-staged", "@typescript-eslint/", "concurrently", "rimraf"},
+	"python": {"pytest", "black", "flake8", "mypy", "pylint", "ruff", "isort", "coverage", "tox",
+		"gunicorn", "uvicorn", "setuptools", "wheel", "pip", "twine", "pre_commit", "types_"},
+}
+
+// FindUnusedDeps reports dependencies declared in root's manifests (see
+// ReadExternalDepSources) that no file in analyses imports. Matching is by
+// package: Go modules by import path prefix, JS packages by name (a
+// "@types/x" counts as used when x is), Python distributions by normalized
+// name or a known import name. Results the import graph can't settle are
+// marked LowConfidence: tooling that runs from config, go.mod "// indirect"
+// requirements, and languages with no scanned sources at all.
+func FindUnusedDeps(root string, analyses []FileAnalysis) []UnusedDep {
+	used := make(map[string]map[string]bool) // manifest language -> used package keys
+	var goImports []string
+	for _, a := range analyses {
+		lang := manifestLanguage(a.Language)
+		if used[lang] == nil {
+			used[lang] = make(map[string]bool)
+		}
+		for _, imp := range a.Imports {
+			imp = strings.Trim(imp, "\"'` ")
+			if imp == "" {
+				continue
+			}
+			if lang == "go" {
+				goImports = append(goImports, imp)
+				continue
+			}
+			if pkg := externalPackage(imp, a.Language); pkg != "" {
+				used[lang][depKey(lang, pkg)] = true
+			}
+		}
+	}
+	indirect := goIndirectDeps(root)
+
+	var unused []UnusedDep
+	for _, dep := range ReadExternalDepSources(root) {
+		if depUsed(dep, used[dep.Language], goImports) {
+			continue
+		}
+		u := UnusedDep{ExternalDep: dep}
+		switch {
+		case used[dep.Language] == nil:
+			u.LowConfidence, u.Reason = true, "no "+dep.Language+" sources were scanned"
+		case indirect[dep.Name]:
+			u.LowConfidence, u.Reason = true, "marked // indirect in go.mod"
+		case isTooling(dep):
+			u.LowConfidence, u.Reason = true, "tooling, usually run from config or the CLI"
+		}
+		unused = append(unused, u)
+	}
+	sort.SliceStable(unused, func(i, j int) bool {
+		return !unused[i].LowConfidence && unused[j].LowConfidence
+	})
+	return unused
+}
+
+// manifestLanguage maps a source language to the manifest language its
+// dependencies are declared under
+func manifestLanguage(lang string) string {
+	if lang == "typescript" {
+		return "javascript"
+	}
+	return lang
+}
+
+// depKey normalizes a package name for comparison within a language
+func depKey(lang, name string) string {
+	switch lang {
+	case "python":
+		return strings.ReplaceAll(strings.ToLower(name), "-", "_")
+	case "swift":
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// depUsed reports whether any scanned import uses dep
+func depUsed(dep ExternalDep, used map[string]bool, goImports []string) bool {
+	switch dep.Language {
+	case "go":
+		for _, imp := range goImports {
+			if imp == dep.Name || strings.HasPrefix(imp, dep.Name+"/") {
+				return true
+			}
+		}
+		return false
+	case "javascript":
+		if typed, ok := strings.CutPrefix(dep.Name, "@types/"); ok {
+			// @types/node, @types/scope__pkg
+			if used[typed] || used["@"+strings.Replace(typed, "__", "/", 1)] || typed == "node" {
+				return true
+			}
+		}
+	case "python":
+		key := depKey("python", dep.Name)
+		if alt, ok := pythonImportNames[key]; ok && used[depKey("python", alt)] {
+			return true
+		}
+	}
+	return used[depKey(dep.Language, dep.Name)]
+}
+
+// isTooling reports whether dep is a known config/CLI-driven tool
+func isTooling(dep ExternalDep) bool {
+	name := depKey(dep.Language, dep.Name)
+	for _, prefix := range toolingPrefixes[dep.Language] {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// goIndirectDeps returns the modules every go.mod under root marks
+// "// indirect": they're needed by dependencies, not imported directly
+func goIndirectDeps(root string) map[string]bool {
+	indirect := make(map[string]bool)
+	filepath.Walk(root, func(path string, info os.FileInfo, _ error) error {
+		if info == nil {
+			return nil
+		}
+		if info.IsDir() {
+			if IgnoredDirs[info.Name()] || info.Name() == CodemapDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() != "go.mod" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if !strings.Contains(line, "// indirect") {
+				continue
+			}
+			fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "require"))
+			if len(fields) > 0 {
+				indirect[fields[0]] = true
+			}
+		}
+		return nil
+	})
+	return indirect
+}
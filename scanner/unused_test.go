@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindUnusedDeps(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/app\n\ngo 1.22\n\nrequire (\n\tgithub.com/spf13/cobra v1.8.0\n\tgithub.com/pkg/errors v0.9.1\n\tgolang.org/x/sys v0.20.0 // indirect\n)\n")
+	write("web/package.json", "{\n  \"dependencies\": {\n    \"react\": \"^18.0.0\",\n    \"lodash\": \"^4.17.21\"\n  },\n  \"devDependencies\": {\n    \"@types/react\": \"^18.0.0\",\n    \"eslint\": \"^9.0.0\"\n  }\n}\n")
+	write("requirements.txt", "PyYAML==6.0\nrequests>=2.0\n")
+
+	analyses := []FileAnalysis{
+		{Path: "cmd/root.go", Language: "go", Imports: []string{"fmt", "github.com/spf13/cobra"}},
+		{Path: "web/app.tsx", Language: "typescript", Imports: []string{"react", "./util"}},
+		{Path: "tools/load.py", Language: "python", Imports: []string{"yaml"}},
+	}
+
+	got := make(map[string]UnusedDep)
+	for _, u := range FindUnusedDeps(root, analyses) {
+		got[u.Name] = u
+	}
+
+	// Declared, never imported, nothing to excuse it
+	for _, name := range []string{"github.com/pkg/errors", "lodash", "requests"} {
+		u, ok := got[name]
+		if !ok || u.LowConfidence {
+			t.Errorf("Expected %s to be flagged with confidence, got %+v (found=%v)", name, u, ok)
+		}
+	}
+	if got["lodash"].Sources[0] != "web/package.json" {
+		t.Errorf("lodash sources = %v, want web/package.json", got["lodash"].Sources)
+	}
+	// Possibly used without an import
+	for _, name := range []string{"golang.org/x/sys", "eslint"} {
+		if u, ok := got[name]; !ok || !u.LowConfidence || u.Reason == "" {
+			t.Errorf("Expected %s to be flagged low-confidence, got %+v", name, u)
+		}
+	}
+	// Used: directly, via @types, or under a different import name
+	for _, name := range []string{"github.com/spf13/cobra", "react", "@types/react", "PyYAML"} {
+		if _, ok := got[name]; ok {
+			t.Errorf("%s is used but was flagged", name)
+		}
+	}
+	if len(got) != 5 {
+		t.Errorf("Expected 5 flagged deps, got %v", got)
+	}
+}
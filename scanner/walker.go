@@ -5,47 +5,153 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	ignore "github.com/sabhiram/go-gitignore"
 )
 
+// DefaultIgnoreFiles are the per-directory ignore files honored by NewGitIgnoreCache
+// unless ignore_files in the project config lists others. Later files take
+// precedence over earlier ones in the same directory, matching ripgrep's
+// ordering (.rgignore > .ignore > .gitignore).
+var DefaultIgnoreFiles = []string{".gitignore", ".ignore", ".rgignore"}
+
+// ExcludeEnv names the environment variable holding extra ignore patterns,
+// for CI and sandboxes where writing an ignore file is awkward
+const ExcludeEnv = "CODEMAP_EXCLUDE"
+
+// ScanWorkersEnv names the environment variable capping how many goroutines
+// ScanFiles walks with; 1 walks sequentially, unset or 0 means GOMAXPROCS
+const ScanWorkersEnv = "CODEMAP_SCAN_WORKERS"
+
+// ScanWorkers returns the CODEMAP_SCAN_WORKERS setting, 0 when unset or
+// not a non-negative integer
+func ScanWorkers() int {
+	n, err := strconv.Atoi(strings.TrimSpace(os.Getenv(ScanWorkersEnv)))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// EnvExcludePatterns returns the gitignore-style patterns in CODEMAP_EXCLUDE,
+// separated by newlines or commas
+func EnvExcludePatterns() []string {
+	var patterns []string
+	for _, p := range strings.FieldsFunc(os.Getenv(ExcludeEnv), func(r rune) bool { return r == '\n' || r == ',' }) {
+		if p = strings.TrimSpace(p); p != "" && !strings.HasPrefix(p, "#") {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// RootMarkers are files or directories whose presence marks a project root
+// (used by --auto-root)
+var RootMarkers = []string{".git", "go.mod", "package.json", "Cargo.toml", "pyproject.toml", "setup.py", "Gemfile", "pom.xml", "build.gradle", "Package.swift"}
+
+// FindProjectRoot walks up from start to the nearest directory containing one of
+// the given markers. Returns the absolute directory and true, or start and false
+// if no ancestor has a marker.
+func FindProjectRoot(start string, markers []string) (string, bool) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return start, false
+	}
+	for {
+		for _, marker := range markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return start, false
+		}
+		dir = parent
+	}
+}
+
 // GitIgnoreCache manages nested .gitignore files throughout a project.
 // It lazily loads gitignore files as directories are visited and checks
-// paths against all applicable rules from root to leaf.
+// paths against all applicable rules from root to leaf. It is safe for
+// concurrent use, as ScanFilesParallel needs.
 type GitIgnoreCache struct {
-	root     string
-	cache    map[string]*ignore.GitIgnore // abs dir path -> compiled gitignore (only dirs WITH gitignores)
-	patterns map[string][]string          // abs dir path -> raw pattern lines
-	visited  map[string]struct{}          // tracks visited dirs to avoid re-checking for .gitignore
+	mu          sync.RWMutex // guards cache, patterns and visited
+	root        string
+	ignoreFiles []string                     // ignore filenames loaded per directory, in precedence order
+	cache       map[string]*ignore.GitIgnore // abs dir path -> compiled gitignore (only dirs WITH gitignores)
+	patterns    map[string][]string          // abs dir path -> raw pattern lines
+	visited     map[string]struct{}          // tracks visited dirs to avoid re-checking for .gitignore
+	env         *ignore.GitIgnore            // CODEMAP_EXCLUDE patterns, relative to root (nil if unset)
 }
 
-// NewGitIgnoreCache creates a cache that supports nested .gitignore files.
+// NewGitIgnoreCache creates a cache that supports nested .gitignore files,
+// plus ripgrep-style .ignore and .rgignore files (see DefaultIgnoreFiles),
+// or the ignore_files set in root's ConfigFile in their place.
 // root should be the project root directory.
 func NewGitIgnoreCache(root string) *GitIgnoreCache {
+	files := DefaultIgnoreFiles
+	if cfg, _ := LoadConfig(root); cfg.IgnoreFiles != nil {
+		files = cfg.IgnoreFiles
+	}
+	return NewGitIgnoreCacheWithFiles(root, files)
+}
+
+// NewGitIgnoreCacheWithFiles creates a cache that honors the given ignore filenames
+// in each directory. Files listed later override rules from files listed earlier.
+func NewGitIgnoreCacheWithFiles(root string, ignoreFiles []string) *GitIgnoreCache {
 	absRoot, _ := filepath.Abs(root)
 	c := &GitIgnoreCache{
-		root:     absRoot,
-		cache:    make(map[string]*ignore.GitIgnore),
-		patterns: make(map[string][]string),
-		visited:  make(map[string]struct{}),
+		root:        absRoot,
+		ignoreFiles: ignoreFiles,
+		cache:       make(map[string]*ignore.GitIgnore),
+		patterns:    make(map[string][]string),
+		visited:     make(map[string]struct{}),
+	}
+	if env := EnvExcludePatterns(); len(env) > 0 {
+		c.env = ignore.CompileIgnoreLines(env...)
 	}
 	c.tryLoadGitignore(absRoot)
 	return c
 }
 
-// tryLoadGitignore attempts to load a .gitignore from dir if not already visited.
-// Only adds to cache if a valid .gitignore exists.
+// tryLoadGitignore attempts to load ignore files from dir if not already visited.
+// Only adds to cache if at least one ignore file with patterns exists.
 func (c *GitIgnoreCache) tryLoadGitignore(dir string) {
-	if _, seen := c.visited[dir]; seen {
+	c.mu.Lock()
+	_, seen := c.visited[dir]
+	c.visited[dir] = struct{}{}
+	c.mu.Unlock()
+	if seen {
 		return
 	}
-	c.visited[dir] = struct{}{}
 
-	gitignorePath := filepath.Join(dir, ".gitignore")
-	f, err := os.Open(gitignorePath)
+	// Read outside the lock so parallel walkers don't queue on file I/O;
+	// a directory's children are only checked after this returns
+	var lines []string
+	for _, name := range c.ignoreFiles {
+		lines = append(lines, readIgnoreFile(filepath.Join(dir, name))...)
+	}
+
+	if len(lines) > 0 {
+		compiled := ignore.CompileIgnoreLines(lines...)
+		c.mu.Lock()
+		c.patterns[dir] = lines
+		c.cache[dir] = compiled
+		c.mu.Unlock()
+	}
+}
+
+// readIgnoreFile returns the non-empty, non-comment pattern lines of an ignore file
+func readIgnoreFile(path string) []string {
+	f, err := os.Open(path)
 	if err != nil {
-		return
+		return nil
 	}
 	defer f.Close()
 
@@ -57,17 +163,30 @@ func (c *GitIgnoreCache) tryLoadGitignore(dir string) {
 			lines = append(lines, line)
 		}
 	}
+	return lines
+}
 
-	if len(lines) > 0 {
-		c.patterns[dir] = lines
-		c.cache[dir] = ignore.CompileIgnoreLines(lines...)
+// EnvExcluded reports whether a path matches the CODEMAP_EXCLUDE patterns
+// alone, for file lists that don't come from a walk (see ScanTrackedFiles)
+func (c *GitIgnoreCache) EnvExcluded(absPath string) bool {
+	if c.env == nil {
+		return false
 	}
+	rel, err := filepath.Rel(c.root, absPath)
+	return err == nil && rel != "." && c.env.MatchesPath(rel)
 }
 
-// ShouldIgnore checks if a path should be ignored based on all applicable .gitignore files.
+// ShouldIgnore checks if a path should be ignored based on all applicable ignore files.
 // Git evaluates rules from root to leaf, with later rules overriding earlier ones.
+// CODEMAP_EXCLUDE patterns are checked on top, so ignore files can't re-include
+// what they exclude.
 func (c *GitIgnoreCache) ShouldIgnore(absPath string) bool {
+	if c.EnvExcluded(absPath) {
+		return true
+	}
+	c.mu.RLock()
 	if len(c.cache) == 0 {
+		c.mu.RUnlock()
 		return false
 	}
 
@@ -88,6 +207,7 @@ func (c *GitIgnoreCache) ShouldIgnore(absPath string) bool {
 			allPatterns = append(allPatterns, patterns...)
 		}
 	}
+	c.mu.RUnlock()
 
 	if len(allPatterns) == 0 {
 		return false
@@ -98,6 +218,17 @@ func (c *GitIgnoreCache) ShouldIgnore(absPath string) bool {
 	return combined.MatchesPath(relPath)
 }
 
+// CodemapDir is codemap's own scratch directory (daemon state, event log).
+// It is always excluded from scans, graphs and watches, regardless of
+// IgnoredDirs or hidden-file settings.
+const CodemapDir = ".codemap"
+
+// IsCodemapPath reports whether a root-relative path is inside CodemapDir
+func IsCodemapPath(relPath string) bool {
+	first, _, _ := strings.Cut(filepath.ToSlash(relPath), "/")
+	return first == CodemapDir
+}
+
 // IgnoredDirs are directories to skip during scanning
 var IgnoredDirs = map[string]bool{
 	".git":           true,
@@ -193,6 +324,25 @@ func shouldIncludeFile(relPath string, ext string, only []string, exclude []stri
 	return true
 }
 
+// FilterBySize keeps files whose size is within [minSize, maxSize].
+// A bound of 0 disables that side of the filter.
+func FilterBySize(files []FileInfo, minSize, maxSize int64) []FileInfo {
+	if minSize <= 0 && maxSize <= 0 {
+		return files
+	}
+	var result []FileInfo
+	for _, f := range files {
+		if minSize > 0 && f.Size < minSize {
+			continue
+		}
+		if maxSize > 0 && f.Size > maxSize {
+			continue
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
 // LoadGitignore loads .gitignore from root if it exists
 // Deprecated: Use NewGitIgnoreCache for nested gitignore support
 func LoadGitignore(root string) *ignore.GitIgnore {
@@ -207,76 +357,277 @@ func LoadGitignore(root string) *ignore.GitIgnore {
 	return nil
 }
 
-// ScanFiles walks the directory tree and returns all files.
-// Supports nested .gitignore files via GitIgnoreCache.
+// ScanFiles walks the directory tree and returns all files, collecting what
+// WalkFiles visits. Supports nested .gitignore files via GitIgnoreCache.
+// Subtrees are walked in parallel (see ScanFilesParallel) unless
+// CODEMAP_SCAN_WORKERS is 1; the result is the same either way.
 // only: list of extensions to include (empty = all)
 // exclude: list of patterns to exclude
 func ScanFiles(root string, cache *GitIgnoreCache, only []string, exclude []string) ([]FileInfo, error) {
+	if workers := ScanWorkers(); workers != 1 {
+		return ScanFilesParallel(root, cache, only, exclude, workers)
+	}
 	var files []FileInfo
+	err := walkFiles(root, cache, only, exclude, func(f FileInfo) error {
+		files = append(files, f)
+		return nil
+	})
+	return files, err
+}
+
+// ScanFilesStream walks the directory tree in the background and sends each file
+// on the returned channel as it is discovered, so callers can render incrementally
+// on huge repos. The file channel is closed when the walk finishes; the error
+// channel then receives the walk result (nil on success) and is closed.
+func ScanFilesStream(root string, cache *GitIgnoreCache, only []string, exclude []string) (<-chan FileInfo, <-chan error) {
+	files := make(chan FileInfo, 256)
+	errc := make(chan error, 1)
+	go func() {
+		err := walkFiles(root, cache, only, exclude, func(f FileInfo) error {
+			files <- f
+			return nil
+		})
+		close(files)
+		errc <- err
+		close(errc)
+	}()
+	return files, errc
+}
+
+// WalkFiles calls fn for every file under root that passes the ignore rules,
+// in lexical walk order, without collecting them: embedders can process a
+// huge repo incrementally in bounded memory. It visits exactly the files
+// ScanFiles(root, cache, nil, nil) returns. A non-nil error from fn stops the
+// walk and is returned.
+func WalkFiles(root string, cache *GitIgnoreCache, fn func(FileInfo) error) error {
+	return walkFiles(root, cache, nil, nil, fn)
+}
+
+// walkFiles walks the directory tree and calls fn for every file that passes
+// the ignore rules and only/exclude filters, in lexical walk order.
+func walkFiles(root string, cache *GitIgnoreCache, only []string, exclude []string, fn func(FileInfo) error) error {
 	absRoot, _ := filepath.Abs(root)
+	rules := walkRules{absRoot: absRoot, cache: cache, only: only, exclude: exclude}
+	progressStage(StageScanning)
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		name := info.Name()
+		// Compute absolute path once for gitignore checks and relative path calculation
+		absPath, _ := filepath.Abs(path)
 
-		// Fast path: skip hardcoded ignored dirs/files
-		if IgnoredDirs[name] {
-			if info.IsDir() {
+		if info.IsDir() {
+			if rules.skipDir(absPath, info.Name()) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
+		if f, ok := rules.file(absPath, info); ok {
+			progressScanned()
+			return fn(f)
+		}
+		return nil
+	})
+}
 
-		// Compute absolute path once for gitignore checks and relative path calculation
-		absPath, _ := filepath.Abs(path)
+// walkRules are the ignore rules and only/exclude filters of one walk,
+// shared by walkFiles and ScanFilesParallel so both skip exactly the same
+// paths
+type walkRules struct {
+	absRoot string
+	cache   *GitIgnoreCache
+	only    []string
+	exclude []string
+}
 
-		// For directories: load any .gitignore, then check if dir itself should be skipped
-		if info.IsDir() {
-			if cache != nil {
-				cache.tryLoadGitignore(absPath)
-				if cache.ShouldIgnore(absPath) {
-					return filepath.SkipDir
-				}
-			}
-			// Check if directory matches any exclude pattern
-			relPath, _ := filepath.Rel(absRoot, absPath)
-			if relPath != "." {
-				for _, pattern := range exclude {
-					pattern = strings.TrimSpace(pattern)
-					if pattern != "" && matchesPattern(relPath, pattern) {
-						return filepath.SkipDir
-					}
-				}
+// skipDir loads any ignore files in the directory at absPath, then reports
+// whether the walk should skip it
+func (r walkRules) skipDir(absPath, name string) bool {
+	// Never scan codemap's own scratch space; fast path for hardcoded ignored dirs
+	if name == CodemapDir || IgnoredDirs[name] {
+		return true
+	}
+	if r.cache != nil {
+		r.cache.tryLoadGitignore(absPath)
+		if r.cache.ShouldIgnore(absPath) {
+			return true
+		}
+	}
+	// Check if directory matches any exclude pattern
+	relPath, _ := filepath.Rel(r.absRoot, absPath)
+	if relPath != "." {
+		for _, pattern := range r.exclude {
+			pattern = strings.TrimSpace(pattern)
+			if pattern != "" && matchesPattern(relPath, pattern) {
+				return true
 			}
-			return nil
 		}
+	}
+	return false
+}
 
-		// For files: check gitignore
-		if cache != nil && cache.ShouldIgnore(absPath) {
-			return nil
+// file returns the FileInfo for the file at absPath and whether it passes
+// the rules
+func (r walkRules) file(absPath string, info os.FileInfo) (FileInfo, bool) {
+	if IgnoredDirs[info.Name()] {
+		return FileInfo{}, false
+	}
+	if r.cache != nil && r.cache.ShouldIgnore(absPath) {
+		return FileInfo{}, false
+	}
+
+	relPath, _ := filepath.Rel(r.absRoot, absPath)
+	ext := filepath.Ext(absPath)
+
+	// Apply user filters (--only and --exclude)
+	if !shouldIncludeFile(relPath, ext, r.only, r.exclude) {
+		return FileInfo{}, false
+	}
+	return FileInfo{
+		Path:    relPath,
+		Size:    info.Size(),
+		Ext:     ext,
+		ModTime: info.ModTime(),
+	}, true
+}
+
+// ScanFilesParallel returns the same files as a sequential walk, in the
+// same order, but walks subtrees on up to workers goroutines at once (GOMAXPROCS when
+// workers <= 0). On large monorepos the walk is bound by directory reads and
+// stats, which run concurrently here. The first error stops the walk and is
+// returned.
+func ScanFilesParallel(root string, cache *GitIgnoreCache, only []string, exclude []string, workers int) ([]FileInfo, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	progressStage(StageScanning)
+
+	info, err := os.Lstat(absRoot)
+	if err != nil {
+		return nil, err
+	}
+	w := &parallelWalk{
+		rules: walkRules{absRoot: absRoot, cache: cache, only: only, exclude: exclude},
+		sem:   make(chan struct{}, workers-1), // the calling goroutine is a worker too
+	}
+	if !info.IsDir() {
+		if f, ok := w.rules.file(absRoot, info); ok {
+			progressScanned()
+			w.files = append(w.files, f)
 		}
+		return w.files, nil
+	}
+	if w.rules.skipDir(absRoot, info.Name()) {
+		return nil, nil
+	}
 
-		relPath, _ := filepath.Rel(absRoot, absPath)
-		ext := filepath.Ext(path)
+	w.wg.Add(1)
+	w.walkDir(absRoot)
+	w.wg.Wait()
+	if w.err != nil {
+		return nil, w.err
+	}
+	sort.Slice(w.files, func(i, j int) bool { return walkOrderLess(w.files[i].Path, w.files[j].Path) })
+	return w.files, nil
+}
 
-		// Apply user filters (--only and --exclude)
-		if !shouldIncludeFile(relPath, ext, only, exclude) {
-			return nil
+// parallelWalk is the shared state of one ScanFilesParallel walk
+type parallelWalk struct {
+	rules walkRules
+	sem   chan struct{} // one token per extra goroutine walking a subtree
+	wg    sync.WaitGroup
+
+	mu    sync.Mutex // guards files and err
+	files []FileInfo
+	err   error
+}
+
+// walkDir collects the files in dir and walks its subdirectories, handing
+// each to a new goroutine while a worker slot is free and walking it inline
+// otherwise, so the goroutine count stays bounded without a queue that
+// could deadlock
+func (w *parallelWalk) walkDir(dir string) {
+	defer w.wg.Done()
+	if w.failed() {
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.fail(err)
+		return
+	}
+	var files []FileInfo
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			w.fail(err)
+			return
+		}
+		if info.IsDir() {
+			if w.rules.skipDir(path, info.Name()) {
+				continue
+			}
+			w.wg.Add(1)
+			select {
+			case w.sem <- struct{}{}:
+				go func() {
+					defer func() { <-w.sem }()
+					w.walkDir(path)
+				}()
+			default:
+				w.walkDir(path)
+			}
+			continue
 		}
+		if f, ok := w.rules.file(path, info); ok {
+			progressScanned()
+			files = append(files, f)
+		}
+	}
+	w.addFiles(files)
+}
 
-		files = append(files, FileInfo{
-			Path: relPath,
-			Size: info.Size(),
-			Ext:  ext,
-		})
+func (w *parallelWalk) addFiles(files []FileInfo) {
+	w.mu.Lock()
+	w.files = append(w.files, files...)
+	w.mu.Unlock()
+}
 
-		return nil
-	})
+func (w *parallelWalk) fail(err error) {
+	w.mu.Lock()
+	if w.err == nil {
+		w.err = err
+	}
+	w.mu.Unlock()
+}
 
-	return files, err
+func (w *parallelWalk) failed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err != nil
+}
+
+// walkOrderLess orders relative paths as filepath.Walk visits them: by
+// path element, so "a/b.go" comes before "a.go" ('/' sorts after '.'
+// byte-wise, but "a" sorts before "a.go")
+func walkOrderLess(a, b string) bool {
+	for a != "" && b != "" {
+		ea, ra, _ := strings.Cut(a, string(filepath.Separator))
+		eb, rb, _ := strings.Cut(b, string(filepath.Separator))
+		if ea != eb {
+			return ea < eb
+		}
+		a, b = ra, rb
+	}
+	return a == "" && b != ""
 }
 
 // ScanForDeps uses ast-grep for batched dependency analysis.
@@ -291,5 +642,8 @@ func ScanForDeps(root string) ([]FileAnalysis, error) {
 		return nil, fmt.Errorf("ast-grep not found in PATH (tried 'sg' and 'ast-grep')")
 	}
 
-	return scanner.ScanDirectory(root)
+	progressStage(StageAnalyzing)
+	analyses, err := scanner.ScanDirectory(root)
+	progressAnalyzed(len(analyses))
+	return analyses, err
 }
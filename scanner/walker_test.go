@@ -1,9 +1,15 @@
 package scanner
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestIgnoredDirs(t *testing.T) {
@@ -137,6 +143,40 @@ func TestScanFilesExtensions(t *testing.T) {
 	}
 }
 
+func TestScanFilesModTime(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2024, 5, 17, 8, 30, 0, 0, time.UTC)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ScanFiles(tmpDir, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || !result[0].ModTime.Equal(mtime) {
+		t.Fatalf("Expected main.go with mtime %v, got %+v", mtime, result)
+	}
+
+	data, err := json.Marshal(result[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"mod_time":"2024-05-17T08:30:00Z"`) {
+		t.Errorf("Expected mod_time in JSON, got %s", data)
+	}
+
+	// Files built without a scan (e.g. from a graph) leave it out
+	data, _ = json.Marshal(FileInfo{Path: "a.go", Size: 1, Ext: ".go"})
+	if strings.Contains(string(data), "mod_time") {
+		t.Errorf("Expected a zero mod_time to be omitted, got %s", data)
+	}
+}
+
 func TestFilterToChanged(t *testing.T) {
 	files := []FileInfo{
 		{Path: "main.go", Size: 100},
@@ -564,3 +604,373 @@ func TestNestedGitignoreDirectoryIgnore(t *testing.T) {
 		t.Errorf("Expected 3 files, got %d: %v", len(files), foundPaths)
 	}
 }
+
+func TestRipgrepIgnoreFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// .gitignore excludes logs, .ignore excludes a path git still tracks
+	files := map[string]string{
+		".gitignore":        "*.log\n",
+		".ignore":           "fixtures/\n",
+		"main.go":           "package main",
+		"debug.log":         "debug",
+		"fixtures/big.json": "{}",
+		"sub/.rgignore":     "*.snap\n",
+		"sub/app.go":        "package sub",
+		"sub/output.snap":   "snapshot",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scan := func(cache *GitIgnoreCache) map[string]bool {
+		result, err := ScanFiles(tmpDir, cache, nil, nil)
+		if err != nil {
+			t.Fatalf("ScanFiles failed: %v", err)
+		}
+		found := make(map[string]bool)
+		for _, f := range result {
+			found[f.Path] = true
+		}
+		return found
+	}
+
+	found := scan(NewGitIgnoreCache(tmpDir))
+	if !found["main.go"] || !found[filepath.Join("sub", "app.go")] {
+		t.Errorf("Expected source files to be included, got %v", found)
+	}
+	if found["debug.log"] {
+		t.Error("Should ignore debug.log (matched by .gitignore)")
+	}
+	if found[filepath.Join("fixtures", "big.json")] {
+		t.Error("Should ignore fixtures/ (matched by .ignore)")
+	}
+	if found[filepath.Join("sub", "output.snap")] {
+		t.Error("Should ignore sub/output.snap (matched by nested .rgignore)")
+	}
+
+	// Restricting to .gitignore only brings .ignore'd paths back
+	found = scan(NewGitIgnoreCacheWithFiles(tmpDir, []string{".gitignore"}))
+	if !found[filepath.Join("fixtures", "big.json")] {
+		t.Error("fixtures/big.json should be included when .ignore is not honored")
+	}
+	if found["debug.log"] {
+		t.Error("Should still ignore debug.log via .gitignore")
+	}
+
+	// ignore_files in the config does the same for every NewGitIgnoreCache
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".codemap"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ConfigFile), []byte(`ignore_files = [".gitignore", ".codemapignore"]`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".codemapignore"), []byte("*.go\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	found = scan(NewGitIgnoreCache(tmpDir))
+	if !found[filepath.Join("fixtures", "big.json")] || !found[filepath.Join("sub", "output.snap")] {
+		t.Errorf("Expected .ignore and .rgignore to be dropped by ignore_files, got %v", found)
+	}
+	if found["main.go"] || found["debug.log"] {
+		t.Errorf("Expected the configured .codemapignore and .gitignore to apply, got %v", found)
+	}
+}
+
+func TestEnvExcludePatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".gitignore":          "!gen/keep.go\n",
+		"main.go":             "package main",
+		"gen/api.go":          "package gen",
+		"gen/keep.go":         "package gen",
+		"testdata/big.json":   "{}",
+		"docs/guide.md":       "# guide",
+		"internal/db/db.go":   "package db",
+		"internal/db/fix.sql": "select 1;",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Newlines and commas both separate patterns
+	t.Setenv(ExcludeEnv, "gen/\ntestdata/, *.sql\n# comment\n")
+	if got := EnvExcludePatterns(); len(got) != 3 {
+		t.Errorf("EnvExcludePatterns = %q, want 3 patterns", got)
+	}
+
+	result, err := ScanFiles(tmpDir, NewGitIgnoreCache(tmpDir), nil, []string{"docs"})
+	if err != nil {
+		t.Fatalf("ScanFiles failed: %v", err)
+	}
+	var got []string
+	for _, f := range result {
+		got = append(got, filepath.ToSlash(f.Path))
+	}
+	sort.Strings(got)
+	// gen/keep.go's .gitignore re-include doesn't override the env; --exclude
+	// still applies on top
+	want := []string{".gitignore", "internal/db/db.go", "main.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scanned %v, want %v", got, want)
+	}
+}
+
+func TestScanFilesStreamMatchesBatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"main.go", "a/one.go", "a/sub/two.py", "b/three.ts", "b/debug.log", ".gitignore"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := "x"
+		if name == ".gitignore" {
+			content = "*.log\n"
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	batch, err := ScanFiles(tmpDir, NewGitIgnoreCache(tmpDir), nil, nil)
+	if err != nil {
+		t.Fatalf("ScanFiles failed: %v", err)
+	}
+
+	stream, errc := ScanFilesStream(tmpDir, NewGitIgnoreCache(tmpDir), nil, nil)
+	var streamed []FileInfo
+	for f := range stream {
+		streamed = append(streamed, f)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("ScanFilesStream failed: %v", err)
+	}
+
+	if len(streamed) != len(batch) {
+		t.Fatalf("Streamed %d files, batch returned %d", len(streamed), len(batch))
+	}
+	for i := range batch {
+		if streamed[i] != batch[i] {
+			t.Errorf("File %d differs: streamed %+v, batch %+v", i, streamed[i], batch[i])
+		}
+	}
+}
+
+func TestWalkFilesMatchesScanFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"main.go", "a/one.go", "a/sub/two.py", "b/three.ts", "b/debug.log", "node_modules/x/index.js", ".gitignore"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := "x"
+		if name == ".gitignore" {
+			content = "*.log\n"
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	batch, err := ScanFiles(tmpDir, NewGitIgnoreCache(tmpDir), nil, nil)
+	if err != nil {
+		t.Fatalf("ScanFiles failed: %v", err)
+	}
+
+	var walked []FileInfo
+	err = WalkFiles(tmpDir, NewGitIgnoreCache(tmpDir), func(f FileInfo) error {
+		walked = append(walked, f)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkFiles failed: %v", err)
+	}
+	if !reflect.DeepEqual(walked, batch) {
+		t.Errorf("WalkFiles visited %+v, ScanFiles returned %+v", walked, batch)
+	}
+
+	// An error from the callback stops the walk
+	stop := errors.New("stop")
+	visits := 0
+	err = WalkFiles(tmpDir, NewGitIgnoreCache(tmpDir), func(f FileInfo) error {
+		visits++
+		return stop
+	})
+	if err != stop || visits != 1 {
+		t.Errorf("Expected the walk to stop after the first callback error, got %v after %d visits", err, visits)
+	}
+}
+
+func TestScanFilesParallelMatchesScanFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".gitignore":                "*.log\n",
+		"a.go":                      "x", // sorts after a/ in walk order
+		"a/one.go":                  "x",
+		"a/sub/two.py":              "x",
+		"a/debug.log":               "x",
+		"b/.gitignore":              "dist/\n*.tmp\n!keep.log\n",
+		"b/three.ts":                "x",
+		"b/keep.log":                "x",
+		"b/temp.tmp":                "x",
+		"b/dist/bundle.js":          "x",
+		"b/lib/deep/er/helper.ts":   "x",
+		"docs/guide.md":             "x",
+		"node_modules/x/index.js":   "x",
+		".codemap/state.json":       "{}",
+		"c/d/e/f/g/h/i/j/k/leaf.go": "x",
+	}
+	for i := 0; i < 20; i++ {
+		files[filepath.Join("many", string(rune('a'+i)), "f.go")] = "x"
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv(ScanWorkersEnv, "1")
+	want, err := ScanFiles(tmpDir, NewGitIgnoreCache(tmpDir), nil, []string{"docs"})
+	if err != nil {
+		t.Fatalf("ScanFiles failed: %v", err)
+	}
+	t.Setenv(ScanWorkersEnv, "")
+	if got, err := ScanFiles(tmpDir, NewGitIgnoreCache(tmpDir), nil, []string{"docs"}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Parallel ScanFiles returned %+v (%v), sequential %+v", got, err, want)
+	}
+	for _, workers := range []int{0, 1, 4} {
+		got, err := ScanFilesParallel(tmpDir, NewGitIgnoreCache(tmpDir), nil, []string{"docs"}, workers)
+		if err != nil {
+			t.Fatalf("ScanFilesParallel(%d workers) failed: %v", workers, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ScanFilesParallel(%d workers) returned %+v, ScanFiles returned %+v", workers, got, want)
+		}
+	}
+
+	if _, err := ScanFilesParallel(filepath.Join(tmpDir, "missing"), nil, nil, nil, 2); err == nil {
+		t.Error("Expected an error for a missing root")
+	}
+}
+
+func TestFilterBySize(t *testing.T) {
+	files := []FileInfo{
+		{Path: "empty.go", Size: 0},
+		{Path: "tiny.go", Size: 12},
+		{Path: "normal.go", Size: 2048},
+		{Path: "huge.json", Size: 5 << 20},
+	}
+
+	paths := func(fs []FileInfo) []string {
+		var result []string
+		for _, f := range fs {
+			result = append(result, f.Path)
+		}
+		return result
+	}
+
+	if got := FilterBySize(files, 0, 0); len(got) != len(files) {
+		t.Errorf("No bounds should keep all files, got %v", paths(got))
+	}
+
+	got := paths(FilterBySize(files, 100, 0))
+	if len(got) != 2 || got[0] != "normal.go" || got[1] != "huge.json" {
+		t.Errorf("--min-size 100: expected [normal.go huge.json], got %v", got)
+	}
+
+	got = paths(FilterBySize(files, 0, 4096))
+	if len(got) != 3 || got[2] != "normal.go" {
+		t.Errorf("--max-size 4096: expected [empty.go tiny.go normal.go], got %v", got)
+	}
+
+	got = paths(FilterBySize(files, 1, 4096))
+	if len(got) != 2 || got[0] != "tiny.go" || got[1] != "normal.go" {
+		t.Errorf("Both bounds: expected [tiny.go normal.go], got %v", got)
+	}
+}
+
+func TestFindProjectRoot(t *testing.T) {
+	tmpDir := t.TempDir()
+	project := filepath.Join(tmpDir, "project")
+	nested := filepath.Join(project, "internal", "pkg", "deep")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "go.mod"), []byte("module example.com/project\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := FindProjectRoot(nested, []string{"go.mod"})
+	if !ok || got != project {
+		t.Errorf("FindProjectRoot(nested) = %q, %v; want %q, true", got, ok, project)
+	}
+
+	// The marker-bearing directory itself is its own root
+	if got, ok := FindProjectRoot(project, []string{"go.mod"}); !ok || got != project {
+		t.Errorf("FindProjectRoot(project) = %q, %v; want %q, true", got, ok, project)
+	}
+
+	// No marker anywhere up the tree: start is returned unchanged
+	if got, ok := FindProjectRoot(nested, []string{"no-such-marker-file"}); ok || got != nested {
+		t.Errorf("FindProjectRoot without marker = %q, %v; want %q, false", got, ok, nested)
+	}
+}
+
+func TestScanFilesExcludesCodemapDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.go":                 "package main\n",
+		".hidden.go":              "package main\n", // hidden files are scanned...
+		".codemap/state.json":     "{}",             // ...but never codemap's own state
+		".codemap/events.log":     "",
+		"sub/.codemap/state.json": "{}",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Even with IgnoredDirs cleared, .codemap stays excluded
+	saved := IgnoredDirs
+	IgnoredDirs = map[string]bool{}
+	defer func() { IgnoredDirs = saved }()
+
+	result, err := ScanFiles(tmpDir, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("ScanFiles failed: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, f := range result {
+		found[filepath.ToSlash(f.Path)] = true
+		if strings.Contains(filepath.ToSlash(f.Path), ".codemap/") {
+			t.Errorf("Expected .codemap contents to be excluded, got %s", f.Path)
+		}
+	}
+	if !found["main.go"] || !found[".hidden.go"] {
+		t.Errorf("Expected main.go and .hidden.go in results, got %v", found)
+	}
+
+	if !IsCodemapPath(filepath.Join(".codemap", "state.json")) || IsCodemapPath("src/codemap.go") {
+		t.Error("IsCodemapPath misclassified a path")
+	}
+}
@@ -0,0 +1,159 @@
+package watch
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FileActivity aggregates the events recorded for a single file
+type FileActivity struct {
+	Path     string    `json:"path"`
+	Edits    int       `json:"edits"`
+	Added    int       `json:"added"`
+	Removed  int       `json:"removed"`
+	NetDelta int       `json:"net_delta"`
+	LastEdit time.Time `json:"last_edit"`
+	IsHub    bool      `json:"is_hub,omitempty"`
+	Dirty    bool      `json:"dirty,omitempty"`
+}
+
+// DirActivity aggregates events for all files in a directory
+type DirActivity struct {
+	Dir     string `json:"dir"`
+	Files   int    `json:"files"`
+	Edits   int    `json:"edits"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+// ActivitySummary is the per-file, per-directory and hub breakdown of a set of events
+type ActivitySummary struct {
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Events   int            `json:"events"`
+	Added    int            `json:"added"`
+	Removed  int            `json:"removed"`
+	Files    []FileActivity `json:"files"`     // sorted by edit count (hot files first)
+	Dirs     []DirActivity  `json:"dirs"`      // sorted by edit count
+	HubEdits []FileActivity `json:"hub_edits"` // files in Files that are hubs
+}
+
+// Summarize aggregates events into an ActivitySummary. Edits count CREATE/WRITE
+// events; line additions and removals include every event's delta. hubs marks
+// additional hub files beyond those flagged on the events themselves.
+func Summarize(events []Event, hubs map[string]bool) ActivitySummary {
+	summary := ActivitySummary{Events: len(events)}
+	byFile := make(map[string]*FileActivity)
+
+	for _, e := range events {
+		if summary.Start.IsZero() || e.Time.Before(summary.Start) {
+			summary.Start = e.Time
+		}
+		if e.Time.After(summary.End) {
+			summary.End = e.Time
+		}
+
+		fa, ok := byFile[e.Path]
+		if !ok {
+			fa = &FileActivity{Path: e.Path}
+			byFile[e.Path] = fa
+		}
+		if e.Op == "WRITE" || e.Op == "CREATE" {
+			fa.Edits++
+		}
+		if e.Delta > 0 {
+			fa.Added += e.Delta
+		} else {
+			fa.Removed -= e.Delta
+		}
+		fa.NetDelta += e.Delta
+		if e.Time.After(fa.LastEdit) {
+			fa.LastEdit = e.Time
+		}
+		if e.IsHub || hubs[e.Path] {
+			fa.IsHub = true
+		}
+		if e.Dirty {
+			fa.Dirty = true
+		}
+	}
+
+	byDir := make(map[string]*DirActivity)
+	for _, fa := range byFile {
+		summary.Files = append(summary.Files, *fa)
+		summary.Added += fa.Added
+		summary.Removed += fa.Removed
+
+		dir := filepath.Dir(fa.Path)
+		da, ok := byDir[dir]
+		if !ok {
+			da = &DirActivity{Dir: dir}
+			byDir[dir] = da
+		}
+		da.Files++
+		da.Edits += fa.Edits
+		da.Added += fa.Added
+		da.Removed += fa.Removed
+	}
+
+	sort.Slice(summary.Files, func(i, j int) bool {
+		if summary.Files[i].Edits != summary.Files[j].Edits {
+			return summary.Files[i].Edits > summary.Files[j].Edits
+		}
+		return summary.Files[i].Path < summary.Files[j].Path
+	})
+	for _, fa := range summary.Files {
+		if fa.IsHub {
+			summary.HubEdits = append(summary.HubEdits, fa)
+		}
+	}
+
+	for _, da := range byDir {
+		summary.Dirs = append(summary.Dirs, *da)
+	}
+	sort.Slice(summary.Dirs, func(i, j int) bool {
+		if summary.Dirs[i].Edits != summary.Dirs[j].Edits {
+			return summary.Dirs[i].Edits > summary.Dirs[j].Edits
+		}
+		return summary.Dirs[i].Dir < summary.Dirs[j].Dir
+	})
+
+	return summary
+}
+
+// SortByImpact returns a copy of events ordered by impact: hub edits first,
+// then by size of the line delta (largest first). Ties keep chronological order.
+func SortByImpact(events []Event) []Event {
+	sorted := make([]Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].IsHub != sorted[j].IsHub {
+			return sorted[i].IsHub
+		}
+		return absInt(sorted[i].Delta) > absInt(sorted[j].Delta)
+	})
+	return sorted
+}
+
+// HighestImpact returns up to n of the most impactful events, skipping
+// no-op edits (non-hub events that didn't change line count)
+func HighestImpact(events []Event, n int) []Event {
+	var result []Event
+	for _, e := range SortByImpact(events) {
+		if len(result) >= n {
+			break
+		}
+		if e.IsHub || e.Delta != 0 {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -19,9 +19,28 @@ type Daemon struct {
 	graph    *Graph
 	watcher  *fsnotify.Watcher
 	gitCache *scanner.GitIgnoreCache
-	eventLog string // path to event log file
+	eventLog string          // path to event log file
+	ignore   []string        // --watch-ignore globs: never watched, events dropped
+	only     map[string]bool // --only files: if set, events for other paths are dropped
 	verbose  bool
-	done     chan struct{}
+
+	timeFormat    TimeFormat    // event time layouts for the log and verbose output
+	relatedWindow time.Duration // how far back connected edits count as RelatedHot
+	snapshotKeep  int           // commit snapshots to retain (0 = snapshots off)
+	contentHash   bool          // drop WRITEs whose content hash didn't change
+	countMinified bool          // include_minified: count minified files' lines like any other
+	lastHead      string        // last seen HEAD commit (pollHead goroutine only)
+	started       time.Time     // when Start was called, for uptime
+	done          chan struct{}
+
+	watchStats WatchStats           // directories watched vs skipped by addWatchDirs
+	noPoll     bool                 // don't poll the skipped directories
+	polled     []string             // skipped subtrees polled instead (absolute)
+	pollStamps map[string]fileStamp // pollSkipped goroutine only
+
+	// Guarded by graph.mu
+	dirtyPending []int // indexes into graph.Events awaiting a dirty check
+	logged       int   // number of graph.Events already written to eventLog
 }
 
 // NewDaemon creates a new watch daemon for the given root
@@ -36,7 +55,14 @@ func NewDaemon(root string, verbose bool) (*Daemon, error) {
 		return nil, fmt.Errorf("failed to create watcher: %w", err)
 	}
 
+	timeFormat, err := LoadTimeFormat(absRoot)
+	if err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("time format: %w", err)
+	}
+
 	gitCache := scanner.NewGitIgnoreCache(root)
+	cfg, _ := scanner.LoadConfig(absRoot) // a bad config is reported by LoadTimeFormat above
 
 	// Check if git repo (fast, one-time)
 	isGitRepo := false
@@ -45,12 +71,15 @@ func NewDaemon(root string, verbose bool) (*Daemon, error) {
 	}
 
 	d := &Daemon{
-		root:     absRoot,
-		watcher:  watcher,
-		gitCache: gitCache,
-		verbose:  verbose,
-		done:     make(chan struct{}),
-		eventLog: filepath.Join(absRoot, ".codemap", "events.log"),
+		root:          absRoot,
+		watcher:       watcher,
+		gitCache:      gitCache,
+		verbose:       verbose,
+		timeFormat:    timeFormat,
+		done:          make(chan struct{}),
+		eventLog:      filepath.Join(absRoot, ".codemap", "events.log"),
+		relatedWindow: DefaultRelatedWindow,
+		countMinified: cfg.IncludeMinified,
 		graph: &Graph{
 			Root:      absRoot,
 			Files:     make(map[string]*scanner.FileInfo),
@@ -64,8 +93,101 @@ func NewDaemon(root string, verbose bool) (*Daemon, error) {
 	return d, nil
 }
 
+// DefaultRelatedWindow is how recently a connected file must have been edited
+// to show up in an event's RelatedHot list
+const DefaultRelatedWindow = 5 * time.Minute
+
+// SetRelatedWindow sets how far back connected edits count as RelatedHot
+// (non-positive restores the default). Must be called before Start.
+func (d *Daemon) SetRelatedWindow(window time.Duration) {
+	if window <= 0 {
+		window = DefaultRelatedWindow
+	}
+	d.relatedWindow = window
+}
+
+// SetContentHash turns on hashing file contents on every write, so a save
+// that leaves the content unchanged produces no event. Off by default since
+// it reads each written file in full. Must be called before Start.
+func (d *Daemon) SetContentHash(on bool) {
+	d.contentHash = on
+}
+
+// SetIgnorePatterns sets globs for paths the daemon should neither watch nor
+// report events for. Unlike .gitignore these paths still appear in scans.
+// Must be called before Start.
+func (d *Daemon) SetIgnorePatterns(patterns []string) {
+	d.ignore = patterns
+}
+
+// SetOnlyFiles restricts event reporting to the given files (relative to the
+// root, or absolute). Directories are still watched as usual; events for any
+// other path are dropped. Must be called before Start.
+func (d *Daemon) SetOnlyFiles(files []string) error {
+	rels, err := ResolveOnlyFiles(d.root, files)
+	if err != nil {
+		return err
+	}
+	d.only = nil
+	if len(rels) > 0 {
+		d.only = make(map[string]bool)
+		for _, rel := range rels {
+			d.only[rel] = true
+		}
+	}
+	return nil
+}
+
+// ResolveOnlyFiles checks that every --only file exists under root and
+// returns their root-relative paths
+func ResolveOnlyFiles(root string, files []string) ([]string, error) {
+	var rels []string
+	for _, f := range files {
+		abs := f
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(root, f)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, fmt.Errorf("--only %s: %w", f, err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("--only %s: is a directory", f)
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("--only %s: outside %s", f, root)
+		}
+		rels = append(rels, rel)
+	}
+	return rels, nil
+}
+
+// isTracked reports whether events for a root-relative path are reported
+// under --only (always true when no file set was given)
+func (d *Daemon) isTracked(relPath string) bool {
+	return len(d.only) == 0 || d.only[relPath]
+}
+
+// isIgnored reports whether a root-relative path is inside .codemap or matches
+// a --watch-ignore glob (see scanner.MatchGlob), so ".cache", "tmp/*",
+// "**/gen" and "*.log" all work as expected.
+func (d *Daemon) isIgnored(relPath string) bool {
+	if scanner.IsCodemapPath(relPath) {
+		return true // our own state/event writes must never feed back as events
+	}
+	for _, pattern := range d.ignore {
+		if scanner.MatchGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
 // Start begins watching and returns immediately
 func (d *Daemon) Start() error {
+	d.started = time.Now()
+
 	// Ensure .codemap directory exists
 	codemapDir := filepath.Join(d.root, ".codemap")
 	if err := os.MkdirAll(codemapDir, 0755); err != nil {
@@ -85,12 +207,28 @@ func (d *Daemon) Start() error {
 		return fmt.Errorf("failed to add watch dirs: %w", err)
 	}
 
+	if warning := WatchLimitWarning(d.watchStats); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
 	// Write initial state for hooks to read immediately
 	d.writeState()
 
 	// Start event loop
 	go d.eventLoop()
 
+	// Poll whatever the watcher couldn't take
+	if d.watchStats.Polling {
+		d.pollOnce()
+		go d.pollSkipped()
+	}
+
+	// Snapshot structure on every new commit (opt-in)
+	if d.snapshotKeep > 0 && d.graph.IsGitRepo {
+		d.lastHead = gitHead(d.root)
+		go d.pollHead()
+	}
+
 	return nil
 }
 
@@ -121,6 +259,17 @@ func (d *Daemon) GetEvents(limit int) []Event {
 	return result
 }
 
+// StartedAt returns when the daemon started watching
+func (d *Daemon) StartedAt() time.Time {
+	return d.started
+}
+
+// skipLines reports whether a file's line count stays out of line stats:
+// a minified file's handful of huge lines says nothing about the code
+func (d *Daemon) skipLines(path string, size int64) bool {
+	return !d.countMinified && scanner.LooksMinified(path, size)
+}
+
 // FileCount returns current tracked file count
 func (d *Daemon) FileCount() int {
 	d.graph.mu.RLock()
@@ -149,9 +298,15 @@ func (d *Daemon) fullScan() error {
 		f := &files[i]
 		d.graph.Files[f.Path] = f
 		// Cache line count for delta calculations (fast: ~1ms per file)
-		if lines := countLines(filepath.Join(d.root, f.Path)); lines > 0 {
+		path := filepath.Join(d.root, f.Path)
+		if scanner.LooksBinary(path) || d.skipLines(path, f.Size) {
+			d.graph.State[f.Path] = &FileState{Size: f.Size, LinesUnknown: true}
+		} else if lines := countLines(path); lines > 0 {
 			d.graph.State[f.Path] = &FileState{Lines: lines, Size: f.Size}
 		}
+		if st := d.graph.State[f.Path]; st != nil && d.contentHash {
+			st.Hash = scanner.HashFile(path)
+		}
 	}
 	d.graph.LastScan = time.Now()
 	d.graph.mu.Unlock()
@@ -186,7 +341,7 @@ func (d *Daemon) computeDeps() {
 	for path := range d.graph.Files {
 		ctx := &DepContext{
 			Imports:   fg.Imports[path],
-			Importers: fg.Importers[path],
+			Importers: fg.ImportersOf(path),
 		}
 		d.graph.DepCtx[path] = ctx
 	}
@@ -199,21 +354,58 @@ func (d *Daemon) computeDeps() {
 	}
 }
 
-// addWatchDirs recursively adds directories to the watcher
+// addWatchDirs recursively adds directories to the watcher. Once the OS
+// watch limit is hit it stops trying: the rest of the tree is recorded as
+// skipped (and polled, unless disabled) rather than failing one Add per
+// directory.
 func (d *Daemon) addWatchDirs() error {
-	return filepath.Walk(d.root, func(path string, info os.FileInfo, err error) error {
+	d.watchStats = WatchStats{}
+	d.polled = nil
+	err := filepath.Walk(d.root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // skip errors
 		}
 
-		// Skip hidden directories and common ignores
+		// Skip hidden directories, common ignores and --watch-ignore paths
 		name := info.Name()
 		if info.IsDir() {
 			if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" {
 				return filepath.SkipDir
 			}
-			return d.watcher.Add(path)
+			if rel, err := filepath.Rel(d.root, path); err == nil && rel != "." && d.isIgnored(rel) {
+				return filepath.SkipDir
+			}
+			if n := len(d.polled); n > 0 && strings.HasPrefix(path, d.polled[n-1]+string(filepath.Separator)) {
+				// Inside an unwatched subtree: counted, and polled with it
+				d.watchStats.Skipped++
+				return nil
+			}
+			if !d.watchStats.LimitHit {
+				err := addWatch(d.watcher, path)
+				if err == nil {
+					d.watchStats.Watched++
+					return nil
+				}
+				if isWatchLimit(err) {
+					d.watchStats.LimitHit = true
+				} else if d.verbose {
+					fmt.Printf("[watch] Can't watch %s: %v\n", path, err)
+				}
+			}
+			// Unwatched: the whole subtree falls back to polling. The walk
+			// still descends so every directory in it is counted.
+			d.watchStats.Skipped++
+			d.polled = append(d.polled, path)
+			return nil
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	if d.watchStats.Watched == 0 && d.watchStats.Skipped > 0 && d.noPoll {
+		return fmt.Errorf("no directories could be watched")
+	}
+	d.watchStats.Polling = d.watchStats.Skipped > 0 && !d.noPoll
+	return nil
 }
@@ -0,0 +1,76 @@
+package watch
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// flushEvents resolves pending dirty checks with a single `git status` call,
+// then logs every event recorded so far that hasn't been written yet, in order.
+// Called from the event loop once per debounce window so rapid edits don't
+// spawn a git process each.
+func (d *Daemon) flushEvents() {
+	d.graph.mu.Lock()
+	cutoff := len(d.graph.Events)
+	if d.logged == cutoff {
+		d.graph.mu.Unlock()
+		return
+	}
+	pending := d.dirtyPending
+	d.dirtyPending = nil
+	d.graph.mu.Unlock()
+
+	// Run git outside the lock so readers aren't blocked on the subprocess
+	var dirty map[string]bool
+	if len(pending) > 0 {
+		dirty = gitDirtyFiles(d.root)
+	}
+
+	d.graph.mu.Lock()
+	for _, i := range pending {
+		d.graph.Events[i].Dirty = dirty[d.graph.Events[i].Path]
+	}
+	toLog := make([]Event, cutoff-d.logged)
+	copy(toLog, d.graph.Events[d.logged:cutoff])
+	d.logged = cutoff
+	d.graph.mu.Unlock()
+
+	for _, e := range toLog {
+		d.logEvent(e)
+		d.printEvent(e)
+	}
+
+	// Update state file for hooks to read
+	d.writeState()
+}
+
+// gitDirtyFiles returns the set of files under root with uncommitted changes
+// (modified, staged, or untracked), keyed by OS-native relative path
+func gitDirtyFiles(root string) map[string]bool {
+	cmd := exec.Command("git", "status", "--porcelain", "-z", "--untracked-files=all")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return parsePorcelainZ(string(out))
+}
+
+// parsePorcelainZ parses `git status --porcelain -z` output into a set of paths.
+// Entries are "XY path"; renames and copies carry the original path as an extra entry.
+func parsePorcelainZ(out string) map[string]bool {
+	dirty := make(map[string]bool)
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		dirty[filepath.FromSlash(entry[3:])] = true
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++ // skip the original path
+		}
+	}
+	return dirty
+}
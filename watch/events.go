@@ -1,12 +1,15 @@
 package watch
 
 import (
-	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -21,11 +24,19 @@ func (d *Daemon) eventLoop() {
 	debounce := make(map[string]time.Time)
 	debounceWindow := 100 * time.Millisecond
 
+	// Pending events are logged (and their dirty state resolved) once per window
+	flush := time.NewTicker(debounceWindow)
+	defer flush.Stop()
+
 	for {
 		select {
 		case <-d.done:
+			d.flushEvents()
 			return
 
+		case <-flush.C:
+			d.flushEvents()
+
 		case event, ok := <-d.watcher.Events:
 			if !ok {
 				return
@@ -47,6 +58,11 @@ func (d *Daemon) eventLoop() {
 				}
 			}
 
+			// Drop events from --watch-ignore paths and, with --only, untracked files
+			if rel, err := filepath.Rel(d.root, event.Name); err == nil && (d.isIgnored(rel) || !d.isTracked(rel)) {
+				continue
+			}
+
 			// Debounce rapid events on same file
 			if last, exists := debounce[event.Name]; exists {
 				if time.Since(last) < debounceWindow {
@@ -105,37 +121,76 @@ func (d *Daemon) handleEvent(fsEvent fsnotify.Event) {
 		Time:     time.Now(),
 		Op:       op,
 		Path:     relPath,
-		Language: scanner.DetectLanguage(relPath),
+		Language: scanner.DetectLanguageAt(d.root, relPath),
 	}
 
 	// Update graph and calculate deltas
 	d.graph.mu.Lock()
 	switch op {
 	case "CREATE", "WRITE":
-		info, err := os.Stat(fsEvent.Name)
-		if err != nil {
+		info, err := statFile(fsEvent.Name)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Gone before we got to it: the REMOVE/RENAME that follows records it
 			d.graph.mu.Unlock()
 			return
 		}
+		if err != nil {
+			// Exists but can't be read right now (permissions, locks): keep
+			// the op and path so the timeline has no gap, without counts
+			d.warnUnreadable(&event, err)
+			break
+		}
 
 		// If a new directory was created, add it to the watcher
 		if info.IsDir() {
 			name := filepath.Base(fsEvent.Name)
 			// Skip hidden directories and common ignores
-			if !strings.HasPrefix(name, ".") && name != "node_modules" && name != "vendor" {
+			if !strings.HasPrefix(name, ".") && name != "node_modules" && name != "vendor" && !d.isIgnored(relPath) {
 				d.watcher.Add(fsEvent.Name)
 			}
 			d.graph.mu.Unlock()
 			return
 		}
 
+		// With content hashing on, a save that didn't change a byte is noise
+		var hash string
+		if d.contentHash {
+			hash = scanner.HashFile(fsEvent.Name)
+			if prev, exists := d.graph.State[relPath]; exists && op == "WRITE" && hash != "" && prev.Hash == hash {
+				d.graph.mu.Unlock()
+				return
+			}
+		}
+
+		// Binary, non-UTF-8, or minified content has no meaningful line
+		// count: keep the size, forget any cached lines
+		binary := scanner.LooksBinary(fsEvent.Name)
+		if binary || d.skipLines(fsEvent.Name, info.Size()) {
+			event.Binary = binary
+			event.Minified = !binary
+			if prev, exists := d.graph.State[relPath]; exists {
+				event.SizeDelta = info.Size() - prev.Size
+			} else {
+				event.SizeDelta = info.Size()
+			}
+			d.graph.State[relPath] = &FileState{Size: info.Size(), LinesUnknown: true, Hash: hash}
+			d.graph.Files[relPath] = &scanner.FileInfo{Path: relPath, Size: info.Size(), Ext: filepath.Ext(relPath), ModTime: info.ModTime()}
+			break
+		}
+
 		// Count new lines
-		newLines := countLines(fsEvent.Name)
+		newLines, err := lineCount(fsEvent.Name)
+		if err != nil {
+			d.warnUnreadable(&event, err)
+			break
+		}
 		event.Lines = newLines
 
 		// Calculate deltas from cached state
 		if prev, exists := d.graph.State[relPath]; exists {
-			event.Delta = newLines - prev.Lines
+			if !prev.LinesUnknown {
+				event.Delta = newLines - prev.Lines
+			}
 			event.SizeDelta = info.Size() - prev.Size
 		} else {
 			event.Delta = newLines // new file, all lines are added
@@ -143,13 +198,14 @@ func (d *Daemon) handleEvent(fsEvent fsnotify.Event) {
 		}
 
 		// Update cached state
-		d.graph.State[relPath] = &FileState{Lines: newLines, Size: info.Size()}
+		d.graph.State[relPath] = &FileState{Lines: newLines, Size: info.Size(), Hash: hash}
 
 		// Update file info
 		d.graph.Files[relPath] = &scanner.FileInfo{
-			Path: relPath,
-			Size: info.Size(),
-			Ext:  filepath.Ext(relPath),
+			Path:    relPath,
+			Size:    info.Size(),
+			Ext:     filepath.Ext(relPath),
+			ModTime: info.ModTime(),
 		}
 
 	case "REMOVE", "RENAME":
@@ -163,47 +219,74 @@ func (d *Daemon) handleEvent(fsEvent fsnotify.Event) {
 		delete(d.graph.State, relPath)
 	}
 
-	// Check if file is dirty (uncommitted) - only if git repo
-	if d.graph.IsGitRepo && (op == "CREATE" || op == "WRITE") {
-		event.Dirty = isFileDirty(d.root, relPath)
-	}
-
 	// Enrich with structural context from file graph (if available)
 	if d.graph.HasDeps && d.graph.FileGraph != nil {
 		fg := d.graph.FileGraph
 		event.Imports = len(fg.Imports[relPath])
-		event.Importers = len(fg.Importers[relPath])
+		event.Importers = len(fg.ImportersOf(relPath))
 		event.IsHub = fg.IsHub(relPath)
 
-		// Find related hot files - connected files also edited recently (last 5 min)
-		event.RelatedHot = d.findRelatedHot(relPath, 5*time.Minute)
+		// Find related hot files - connected files also edited within the window
+		event.RelatedHot = d.findRelatedHot(relPath, d.relatedWindow)
 	}
 
 	d.graph.Events = append(d.graph.Events, event)
+
+	// Dirty state (uncommitted) is resolved in batches by flushEvents - only if git repo
+	if d.graph.IsGitRepo && (op == "CREATE" || op == "WRITE") {
+		d.dirtyPending = append(d.dirtyPending, len(d.graph.Events)-1)
+	}
+	flushNow := len(d.dirtyPending) == 0
 	d.graph.mu.Unlock()
 
-	// Log event
-	d.logEvent(event)
+	// Nothing waiting on git: log right away
+	if flushNow {
+		d.flushEvents()
+	}
+}
+
+// statFile is os.Stat, swappable in tests to simulate unreadable files
+var statFile = os.Stat
 
+// warnUnreadable marks an event whose file exists but couldn't be read,
+// warning about it in verbose mode
+func (d *Daemon) warnUnreadable(event *Event, err error) {
+	event.Unreadable = true
 	if d.verbose {
-		deltaStr := ""
-		if event.Delta != 0 {
-			deltaStr = fmt.Sprintf(" (%+d lines)", event.Delta)
-		}
-		dirtyStr := ""
-		if event.Dirty {
-			dirtyStr = " [dirty]"
-		}
-		hubStr := ""
-		if event.IsHub {
-			hubStr = fmt.Sprintf(" [HUB:%d importers]", event.Importers)
-		}
-		hotStr := ""
-		if len(event.RelatedHot) > 0 {
-			hotStr = fmt.Sprintf(" [related:%d]", len(event.RelatedHot))
-		}
-		fmt.Printf("[watch] %s %s %s%s%s%s%s\n", event.Time.Format("15:04:05"), op, relPath, deltaStr, dirtyStr, hubStr, hotStr)
+		fmt.Printf("[watch] Warning: %s %s unreadable, recorded without line counts: %v\n", event.Op, event.Path, err)
+	}
+}
+
+// printEvent prints an event line in verbose mode
+func (d *Daemon) printEvent(event Event) {
+	if !d.verbose {
+		return
+	}
+	deltaStr := ""
+	if event.Delta != 0 {
+		deltaStr = fmt.Sprintf(" (%+d lines)", event.Delta)
+	}
+	dirtyStr := ""
+	if event.Dirty {
+		dirtyStr = " [dirty]"
 	}
+	hubStr := ""
+	if event.IsHub {
+		hubStr = fmt.Sprintf(" [HUB:%d importers]", event.Importers)
+	}
+	hotStr := ""
+	if len(event.RelatedHot) > 0 {
+		hotStr = fmt.Sprintf(" [related:%d]", len(event.RelatedHot))
+	}
+	unreadableStr := ""
+	if event.Unreadable {
+		unreadableStr = " [unreadable]"
+	} else if event.Binary {
+		unreadableStr = " [binary]"
+	} else if event.Minified {
+		unreadableStr = " [minified]"
+	}
+	fmt.Printf("[watch] %s %s %s%s%s%s%s%s\n", d.timeFormat.FormatClock(event.Time), event.Op, event.Path, deltaStr, dirtyStr, unreadableStr, hubStr, hotStr)
 }
 
 // findRelatedHot finds connected files that were also recently edited
@@ -244,6 +327,7 @@ func (d *Daemon) findRelatedHot(path string, window time.Duration) []string {
 			hot = append(hot, file)
 		}
 	}
+	sort.Strings(hot)
 
 	return hot
 }
@@ -256,7 +340,7 @@ func (d *Daemon) logEvent(e Event) {
 	}
 	defer f.Close()
 
-	// Format: timestamp | OP | path | lines | delta | dirty
+	// Format: timestamp | OP | path | lines | delta | flags (dirty, unreadable, binary)
 	deltaStr := ""
 	if e.Delta > 0 {
 		deltaStr = fmt.Sprintf("+%d", e.Delta)
@@ -264,23 +348,29 @@ func (d *Daemon) logEvent(e Event) {
 		deltaStr = fmt.Sprintf("%d", e.Delta)
 	}
 
-	dirtyStr := ""
+	var flags []string
 	if e.Dirty {
-		dirtyStr = "dirty"
+		flags = append(flags, "dirty")
+	}
+	if e.Unreadable {
+		flags = append(flags, "unreadable")
+	}
+	if e.Binary {
+		flags = append(flags, "binary")
+	}
+	if e.Minified {
+		flags = append(flags, "minified")
 	}
 
 	line := fmt.Sprintf("%s | %-6s | %-40s | %4d | %6s | %s\n",
-		e.Time.Format("2006-01-02 15:04:05"),
+		d.timeFormat.FormatLog(e.Time),
 		e.Op,
 		e.Path,
 		e.Lines,
 		deltaStr,
-		dirtyStr,
+		strings.Join(flags, ","),
 	)
 	f.WriteString(line)
-
-	// Update state file for hooks to read
-	d.writeState()
 }
 
 // writeState persists current state for hooks to read
@@ -298,13 +388,29 @@ func (d *Daemon) writeState() {
 		events = events[len(events)-50:]
 	}
 
+	netLines := 0
+	for _, e := range d.graph.Events {
+		netLines += e.Delta
+	}
+
+	sizes := make(map[string]int64, len(d.graph.Files))
+	for path, f := range d.graph.Files {
+		sizes[path] = f.Size
+	}
+
 	state := State{
 		UpdatedAt:    time.Now(),
 		FileCount:    len(d.graph.Files),
 		Hubs:         d.graph.FileGraph.HubFiles(),
+		Minified:     sortedKeys(d.graph.FileGraph.Minified),
 		Importers:    d.graph.FileGraph.Importers,
 		Imports:      d.graph.FileGraph.Imports,
 		RecentEvents: events,
+		StartedAt:    d.started,
+		EventsTotal:  len(d.graph.Events),
+		NetLines:     netLines,
+		Sizes:        sizes,
+		Watch:        d.watchStats,
 	}
 
 	data, err := json.MarshalIndent(state, "", "  ")
@@ -313,29 +419,57 @@ func (d *Daemon) writeState() {
 	}
 
 	stateFile := filepath.Join(d.root, ".codemap", "state.json")
-	os.WriteFile(stateFile, data, 0644)
+	scanner.WriteFileAtomic(stateFile, data)
+}
+
+// sortedKeys returns the keys of set in order, or nil for an empty set
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
-// countLines counts lines in a file efficiently (no full read into memory)
+// countLines counts lines in a file efficiently (no full read into memory),
+// returning 0 if it can't be read or is binary
 func countLines(path string) int {
+	if scanner.LooksBinary(path) {
+		return 0
+	}
+	count, _ := lineCount(path)
+	return count
+}
+
+// lineCount is countLines with the open/read error
+func lineCount(path string) (int, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return 0
+		return 0, err
 	}
 	defer f.Close()
 
+	// Count newlines in fixed-size chunks, so no line is too long to count;
+	// a final line without a trailing newline counts too
 	count := 0
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
+	buf := make([]byte, 32*1024)
+	var last byte = '\n'
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			count += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+	}
+	if last != '\n' {
 		count++
 	}
-	return count
-}
-
-// isFileDirty checks if a file has uncommitted changes (fast git check)
-func isFileDirty(root, relPath string) bool {
-	cmd := exec.Command("git", "diff", "--quiet", "--", relPath)
-	cmd.Dir = root
-	err := cmd.Run()
-	return err != nil // non-zero exit = dirty
+	return count, nil
 }
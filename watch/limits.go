@@ -0,0 +1,141 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// addWatch registers one directory with the watcher (swapped out in tests to
+// simulate hitting the OS watch limit)
+var addWatch = func(w *fsnotify.Watcher, path string) error {
+	return w.Add(path)
+}
+
+// pollInterval is how often unwatched directories are rescanned
+const pollInterval = 2 * time.Second
+
+// WatchStats counts the directories addWatchDirs could and couldn't watch
+type WatchStats struct {
+	Watched  int  `json:"watched"`
+	Skipped  int  `json:"skipped"`
+	LimitHit bool `json:"limit_hit"` // the OS watch limit stopped further adds
+	Polling  bool `json:"polling"`   // skipped directories are polled instead
+}
+
+// isWatchLimit reports whether err means the OS ran out of watches
+// (inotify's max_user_watches gives ENOSPC, too many instances or open
+// files gives EMFILE)
+func isWatchLimit(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
+
+// WatchLimitWarning explains skipped directories and how to fix it, or ""
+// when every directory is watched
+func WatchLimitWarning(stats WatchStats) string {
+	if stats.Skipped == 0 {
+		return ""
+	}
+	var b strings.Builder
+	if stats.LimitHit {
+		fmt.Fprintf(&b, "⚠️  OS file watch limit reached: watching %d directories, %d not watched\n", stats.Watched, stats.Skipped)
+		b.WriteString("   Raise it with: sudo sysctl fs.inotify.max_user_watches=524288\n")
+		b.WriteString("   (persist in /etc/sysctl.conf; on macOS raise `ulimit -n`)\n")
+	} else {
+		fmt.Fprintf(&b, "⚠️  %d directories could not be watched (%d watched)\n", stats.Skipped, stats.Watched)
+	}
+	if stats.Polling {
+		fmt.Fprintf(&b, "   Polling them every %v instead", pollInterval)
+	} else {
+		b.WriteString("   Edits under them won't show up in watch activity")
+	}
+	return b.String()
+}
+
+// WatchStats returns how many directories are watched, skipped, and polled
+func (d *Daemon) WatchStats() WatchStats {
+	return d.watchStats
+}
+
+// SetPollFallback turns polling of directories the watcher couldn't add on or
+// off (on by default). Must be called before Start.
+func (d *Daemon) SetPollFallback(on bool) {
+	d.noPoll = !on
+}
+
+// fileStamp is what polling compares to spot a change
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// pollSkipped rescans unwatched directories until the daemon stops
+func (d *Daemon) pollSkipped() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			d.pollOnce()
+		}
+	}
+}
+
+// pollOnce compares the source files under the skipped directories with the
+// last pass and feeds the differences through handleEvent as if the watcher
+// had reported them. The first pass only records stamps.
+func (d *Daemon) pollOnce() {
+	seen := make(map[string]fileStamp)
+	for _, dir := range d.polled {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			rel, relErr := filepath.Rel(d.root, path)
+			if info.IsDir() {
+				if path != dir && (strings.HasPrefix(info.Name(), ".") || info.Name() == "node_modules" || info.Name() == "vendor") {
+					return filepath.SkipDir
+				}
+				if relErr == nil && d.isIgnored(rel) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.isSourceFile(path) || relErr != nil || d.isIgnored(rel) || !d.isTracked(rel) {
+				return nil
+			}
+			seen[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+	}
+
+	first := d.pollStamps == nil
+	prev := d.pollStamps
+	d.pollStamps = seen
+	if first {
+		return
+	}
+
+	for path, stamp := range seen {
+		old, ok := prev[path]
+		switch {
+		case !ok:
+			d.handleEvent(fsnotify.Event{Name: path, Op: fsnotify.Create})
+		case !old.modTime.Equal(stamp.modTime) || old.size != stamp.size:
+			d.handleEvent(fsnotify.Event{Name: path, Op: fsnotify.Write})
+		}
+	}
+	for path := range prev {
+		if _, ok := seen[path]; !ok {
+			d.handleEvent(fsnotify.Event{Name: path, Op: fsnotify.Remove})
+		}
+	}
+}
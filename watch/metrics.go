@@ -0,0 +1,43 @@
+package watch
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// metric is one sample in the Prometheus text exposition format
+type metric struct {
+	name  string
+	kind  string // counter or gauge
+	help  string
+	value float64
+}
+
+// WriteMetrics writes daemon state in the Prometheus text exposition format.
+// A nil state (daemon not running, or state stale) reports only
+// codemap_watch_up 0 so scrapers can alert on it.
+func WriteMetrics(w io.Writer, state *State, now time.Time) error {
+	metrics := []metric{{"codemap_watch_up", "gauge", "Whether the watch daemon is running with fresh state.", 0}}
+	if state != nil {
+		metrics[0].value = 1
+		uptime := 0.0
+		if !state.StartedAt.IsZero() {
+			uptime = now.Sub(state.StartedAt).Seconds()
+		}
+		metrics = append(metrics,
+			metric{"codemap_watch_events_total", "counter", "File events recorded since the daemon started.", float64(state.EventsTotal)},
+			metric{"codemap_watch_files_tracked", "gauge", "Files in the daemon's current scan.", float64(state.FileCount)},
+			metric{"codemap_watch_hubs", "gauge", "Files imported by at least hub_threshold (default 3) other files.", float64(len(state.Hubs))},
+			metric{"codemap_watch_net_lines", "gauge", "Net line delta across all events since the daemon started.", float64(state.NetLines)},
+			metric{"codemap_watch_uptime_seconds", "gauge", "Seconds since the daemon started.", uptime},
+		)
+	}
+
+	for _, m := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", m.name, m.help, m.name, m.kind, m.name, m.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package watch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBurstGap is how long the event stream must be idle before the
+// next event starts a new edit burst (a new trace). A burst is not a
+// session: sessions are daemon runs (see ExportSession), and one run may
+// hold many bursts.
+const DefaultBurstGap = 30 * time.Minute
+
+// Span is one OpenTelemetry-style span: an edit burst's root span, or one
+// event within it
+type Span struct {
+	TraceID      string         // 32 hex digits, shared by a burst's spans
+	SpanID       string         // 16 hex digits
+	ParentSpanID string         // the burst span's ID; "" for the burst span itself
+	Name         string         // "edit burst", or the event op ("WRITE", ...)
+	Start, End   time.Time      // events are instants: Start == End
+	Attributes   map[string]any // string, int, int64, or bool values
+}
+
+// SpanExporter sends spans somewhere: a file, a collector, a test recorder
+type SpanExporter interface {
+	ExportSpans(spans []Span) error
+}
+
+// SplitBursts splits chronological events into edit bursts wherever the
+// stream was idle for longer than gap
+func SplitBursts(events []Event, gap time.Duration) [][]Event {
+	var bursts [][]Event
+	for i, e := range events {
+		if i == 0 || e.Time.Sub(events[i-1].Time) > gap {
+			bursts = append(bursts, nil)
+		}
+		bursts[len(bursts)-1] = append(bursts[len(bursts)-1], e)
+	}
+	return bursts
+}
+
+// EventSpans turns events into spans: each edit burst (see SplitBursts)
+// becomes a trace with a root span covering it, and each event a child span
+// carrying its path, language, line and size deltas, and hub status. IDs are
+// derived from root and the event times, so re-exporting the same log gives
+// the same IDs and a collector can deduplicate.
+func EventSpans(root string, events []Event, gap time.Duration) []Span {
+	var spans []Span
+	for _, burst := range SplitBursts(events, gap) {
+		first, last := burst[0].Time, burst[len(burst)-1].Time
+		traceID := spanHash(32, root, first.Format(time.RFC3339Nano))
+		burstID := spanHash(16, traceID, "burst")
+
+		files := make(map[string]bool)
+		delta, hubEdits := 0, 0
+		for _, e := range burst {
+			files[e.Path] = true
+			delta += e.Delta
+			if e.IsHub {
+				hubEdits++
+			}
+		}
+		spans = append(spans, Span{
+			TraceID: traceID,
+			SpanID:  burstID,
+			Name:    "edit burst",
+			Start:   first,
+			End:     last,
+			Attributes: map[string]any{
+				"codemap.root":        root,
+				"codemap.events":      len(burst),
+				"codemap.files":       len(files),
+				"codemap.delta":       delta,
+				"codemap.hub_edits":   hubEdits,
+				"codemap.duration_ms": last.Sub(first).Milliseconds(),
+			},
+		})
+
+		for i, e := range burst {
+			attrs := map[string]any{
+				"code.filepath":  e.Path,
+				"codemap.op":     e.Op,
+				"codemap.delta":  e.Delta,
+				"codemap.is_hub": e.IsHub,
+			}
+			if e.Language != "" {
+				attrs["codemap.language"] = e.Language
+			}
+			if e.SizeDelta != 0 {
+				attrs["codemap.size_delta"] = e.SizeDelta
+			}
+			if e.Importers > 0 {
+				attrs["codemap.importers"] = e.Importers
+			}
+			if e.Lines > 0 {
+				attrs["codemap.lines"] = e.Lines
+			}
+			spans = append(spans, Span{
+				TraceID:      traceID,
+				SpanID:       spanHash(16, traceID, strconv.Itoa(i), e.Path),
+				ParentSpanID: burstID,
+				Name:         e.Op,
+				Start:        e.Time,
+				End:          e.Time,
+				Attributes:   attrs,
+			})
+		}
+	}
+	return spans
+}
+
+// spanHash is the first n hex digits of the SHA-256 of parts
+func spanHash(n int, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])[:n]
+}
+
+// OTLPJSON encodes spans as an OTLP/JSON ExportTraceServiceRequest, the body
+// an OTLP/HTTP collector accepts at /v1/traces
+func OTLPJSON(spans []Span) ([]byte, error) {
+	type value map[string]any
+	type keyValue struct {
+		Key   string `json:"key"`
+		Value value  `json:"value"`
+	}
+	attrList := func(attrs map[string]any) []keyValue {
+		keys := make([]string, 0, len(attrs))
+		for k := range attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		list := make([]keyValue, 0, len(keys))
+		for _, k := range keys {
+			var v value
+			switch a := attrs[k].(type) {
+			case bool:
+				v = value{"boolValue": a}
+			case int:
+				v = value{"intValue": strconv.Itoa(a)} // int64 is a string in proto3 JSON
+			case int64:
+				v = value{"intValue": strconv.FormatInt(a, 10)}
+			default:
+				v = value{"stringValue": fmt.Sprint(a)}
+			}
+			list = append(list, keyValue{k, v})
+		}
+		return list
+	}
+
+	otlpSpans := make([]map[string]any, len(spans))
+	for i, s := range spans {
+		span := map[string]any{
+			"traceId":           s.TraceID,
+			"spanId":            s.SpanID,
+			"name":              s.Name,
+			"kind":              1, // SPAN_KIND_INTERNAL
+			"startTimeUnixNano": strconv.FormatInt(s.Start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(s.End.UnixNano(), 10),
+			"attributes":        attrList(s.Attributes),
+		}
+		if s.ParentSpanID != "" {
+			span["parentSpanId"] = s.ParentSpanID
+		}
+		otlpSpans[i] = span
+	}
+	return json.Marshal(map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": map[string]any{
+				"attributes": attrList(map[string]any{"service.name": "codemap"}),
+			},
+			"scopeSpans": []any{map[string]any{
+				"scope": map[string]any{"name": "codemap/watch"},
+				"spans": otlpSpans,
+			}},
+		}},
+	})
+}
+
+// FileSpanExporter writes spans as one OTLP/JSON document to Path, or to
+// stdout when Path is ""
+type FileSpanExporter struct {
+	Path string
+}
+
+func (x FileSpanExporter) ExportSpans(spans []Span) error {
+	data, err := OTLPJSON(spans)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if x.Path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(x.Path, data, 0644)
+}
+
+// OTLPHTTPExporter posts spans to an OTLP/HTTP collector in JSON. An
+// Endpoint without a path gets the standard /v1/traces.
+type OTLPHTTPExporter struct {
+	Endpoint string
+	Client   *http.Client // nil for a client with a 10s timeout
+}
+
+func (x OTLPHTTPExporter) ExportSpans(spans []Span) error {
+	data, err := OTLPJSON(spans)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(x.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid OTLP endpoint: %w", err)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	client := x.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Post(u.String(), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("OTLP collector at %s returned %s: %s", u, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
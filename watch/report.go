@@ -0,0 +1,87 @@
+package watch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// MarkdownReport renders an activity summary as a Markdown standup report
+func MarkdownReport(title string, s ActivitySummary) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## %s\n\n", title))
+
+	if len(s.Files) == 0 {
+		sb.WriteString("No file activity recorded.\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("**Summary:** %s\n\n", StandupLine(s)))
+
+	sb.WriteString("### Hot files\n\n")
+	sb.WriteString("| File | Edits | Lines |\n")
+	sb.WriteString("|------|------:|------:|\n")
+	for i, f := range s.Files {
+		if i >= 10 {
+			sb.WriteString(fmt.Sprintf("| ... and %d more | | |\n", len(s.Files)-10))
+			break
+		}
+		hub := ""
+		if f.IsHub {
+			hub = " ⚠️ hub"
+		}
+		sb.WriteString(fmt.Sprintf("| `%s`%s | %d | +%d/-%d |\n", f.Path, hub, f.Edits, f.Added, f.Removed))
+	}
+
+	sb.WriteString("\n### By directory\n\n")
+	sb.WriteString("| Directory | Files | Edits | Lines |\n")
+	sb.WriteString("|-----------|------:|------:|------:|\n")
+	for _, d := range s.Dirs {
+		sb.WriteString(fmt.Sprintf("| `%s` | %d | %d | +%d/-%d |\n", dirLabel(d.Dir), d.Files, d.Edits, d.Added, d.Removed))
+	}
+
+	sb.WriteString("\n### Hub edits\n\n")
+	if len(s.HubEdits) == 0 {
+		sb.WriteString("None.\n")
+	} else {
+		for _, h := range s.HubEdits {
+			sb.WriteString(fmt.Sprintf("- `%s` (%d edits, %+d lines)\n", h.Path, h.Edits, h.NetDelta))
+		}
+	}
+
+	return sb.String()
+}
+
+// StandupLine returns a one-sentence summary, e.g.
+// "touched 12 files (+340/-120), main work in api/ and models/, 2 hub edits (auth.go, db.go)"
+func StandupLine(s ActivitySummary) string {
+	line := fmt.Sprintf("touched %d files (+%d/-%d)", len(s.Files), s.Added, s.Removed)
+
+	var dirs []string
+	for i, d := range s.Dirs {
+		if i >= 2 {
+			break
+		}
+		dirs = append(dirs, dirLabel(d.Dir))
+	}
+	if len(dirs) > 0 {
+		line += ", main work in " + strings.Join(dirs, " and ")
+	}
+
+	if len(s.HubEdits) > 0 {
+		var names []string
+		for _, h := range s.HubEdits {
+			names = append(names, filepath.Base(h.Path))
+		}
+		line += fmt.Sprintf(", %d hub edits (%s)", len(s.HubEdits), strings.Join(names, ", "))
+	}
+	return line
+}
+
+// dirLabel formats a directory for display ("." becomes the project root)
+func dirLabel(dir string) string {
+	if dir == "." || dir == "" {
+		return "./"
+	}
+	return filepath.ToSlash(dir) + "/"
+}
@@ -0,0 +1,126 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// SessionSchemaVersion is the version of the SessionExport document; bump
+// it when fields change meaning or go away
+const SessionSchemaVersion = 1
+
+// sessionHotFiles is how many of the most edited files SessionExport.HotFiles keeps
+const sessionHotFiles = 10
+
+// SessionExport is everything recorded about one watch session, for archiving
+// or analytics: metadata, the full event list, and its aggregates
+type SessionExport struct {
+	Version  int            `json:"version"`
+	Root     string         `json:"root"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Running  bool           `json:"running"` // the daemon was still up at export time
+	Totals   SessionTotals  `json:"totals"`
+	Events   []Event        `json:"events"`    // chronological
+	Files    []FileActivity `json:"files"`     // per-file aggregates, most edited first
+	HotFiles []FileActivity `json:"hot_files"` // the most edited files
+	HubEdits []FileActivity `json:"hub_edits"` // edited files that are hubs
+	Dirs     []DirActivity  `json:"dirs"`      // per-directory breakdown, most edited first
+}
+
+// SessionTotals sums a session's events
+type SessionTotals struct {
+	Events  int `json:"events"`
+	Files   int `json:"files"`
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// NewSessionExport builds the export for events between start and end.
+// Events outside the window are dropped; hubs marks hub files on top of
+// those flagged on the events themselves.
+func NewSessionExport(root string, events []Event, start, end time.Time, running bool, hubs map[string]bool) SessionExport {
+	var session []Event
+	for _, e := range events {
+		if e.Time.Before(start) || (!end.IsZero() && e.Time.After(end)) {
+			continue
+		}
+		if hubs[e.Path] {
+			e.IsHub = true
+		}
+		session = append(session, e)
+	}
+	if session == nil {
+		session = []Event{}
+	}
+
+	summary := Summarize(session, hubs)
+	export := SessionExport{
+		Version: SessionSchemaVersion,
+		Root:    root,
+		Start:   start,
+		End:     end,
+		Running: running,
+		Totals: SessionTotals{
+			Events:  summary.Events,
+			Files:   len(summary.Files),
+			Added:   summary.Added,
+			Removed: summary.Removed,
+		},
+		Events:   session,
+		Files:    nonNil(summary.Files),
+		HotFiles: nonNil(summary.Files[:min(len(summary.Files), sessionHotFiles)]),
+		HubEdits: nonNil(summary.HubEdits),
+		Dirs:     summary.Dirs,
+	}
+	if export.Dirs == nil {
+		export.Dirs = []DirActivity{}
+	}
+	return export
+}
+
+// ExportSession exports the current session, or the last one if the daemon
+// has stopped: the logged events since the daemon's last start, up to now
+// or to its last state write. Without any daemon state every logged event
+// counts.
+func ExportSession(root string, now time.Time) (SessionExport, error) {
+	events, err := ReadEventLog(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return SessionExport{}, errors.New("no watch activity recorded (.codemap/events.log not found)")
+	} else if err != nil {
+		return SessionExport{}, fmt.Errorf("reading events.log: %w", err)
+	}
+
+	running := IsRunning(root)
+	var start, end time.Time
+	hubs := make(map[string]bool)
+	if state := ReadLastState(root); state != nil {
+		// The log has whole-second timestamps
+		start = state.StartedAt.Truncate(time.Second)
+		if !running {
+			end = state.UpdatedAt
+			if n := len(events); n > 0 && events[n-1].Time.After(end) {
+				end = events[n-1].Time
+			}
+		}
+		for _, h := range state.Hubs {
+			hubs[h] = true
+		}
+	} else if len(events) > 0 {
+		start = events[0].Time
+	}
+	if end.IsZero() {
+		end = now
+	}
+	return NewSessionExport(root, events, start, end, running, hubs), nil
+}
+
+// nonNil keeps empty lists as [] rather than null in the JSON
+func nonNil(files []FileActivity) []FileActivity {
+	if files == nil {
+		return []FileActivity{}
+	}
+	return files
+}
@@ -0,0 +1,259 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"codemap/scanner"
+)
+
+// Snapshot is the project structure recorded when HEAD moves to a new commit,
+// or on request as the working-tree baseline (see SaveWorkingSnapshot)
+type Snapshot struct {
+	Commit      string              `json:"commit"`
+	Time        time.Time           `json:"time"`
+	FileCount   int                 `json:"file_count"`
+	Fingerprint string              `json:"fingerprint,omitempty"` // scanner.Fingerprint hash, working snapshots only
+	Sizes       map[string]int64    `json:"sizes,omitempty"`       // file -> size in bytes
+	Hubs        []string            `json:"hubs"`
+	Imports     map[string][]string `json:"imports"`   // file -> files it imports
+	Importers   map[string][]string `json:"importers"` // file -> files that import it
+}
+
+// WorkingSnapshot is the name of the working-tree baseline snapshot. It lives
+// beside the commit snapshots but is never pruned.
+const WorkingSnapshot = "working"
+
+// headPollInterval is how often the daemon checks for new commits
+const headPollInterval = 2 * time.Second
+
+// SetSnapshotLimit enables writing a structure snapshot to
+// .codemap/snapshots/<sha>.json whenever HEAD moves, keeping at most keep
+// snapshots (oldest removed first). 0 disables. Must be called before Start.
+func (d *Daemon) SetSnapshotLimit(keep int) {
+	d.snapshotKeep = keep
+}
+
+// snapshotDir returns the directory holding commit snapshots
+func snapshotDir(root string) string {
+	return filepath.Join(root, ".codemap", "snapshots")
+}
+
+// gitHead returns the current HEAD commit, or "" if unavailable
+func gitHead(root string) string {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// pollHead checks for new commits until the daemon stops
+func (d *Daemon) pollHead() {
+	ticker := time.NewTicker(headPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			d.checkHead()
+		}
+	}
+}
+
+// checkHead snapshots the structure if HEAD moved since the last check
+// (lastHead is seeded at Start, so only commits made while watching count)
+func (d *Daemon) checkHead() {
+	head := gitHead(d.root)
+	if head == "" || head == d.lastHead {
+		return
+	}
+	d.lastHead = head
+
+	// A commit can add or remove imports (e.g. after a pull), so rebuild first
+	d.computeDeps()
+	if err := d.writeSnapshot(head); err != nil && d.verbose {
+		fmt.Printf("[watch] Snapshot failed: %v\n", err)
+	}
+}
+
+// writeSnapshot records the current graph under the given commit and prunes old snapshots
+func (d *Daemon) writeSnapshot(commit string) error {
+	d.graph.mu.RLock()
+	snap := Snapshot{
+		Commit:    commit,
+		Time:      time.Now(),
+		FileCount: len(d.graph.Files),
+		Sizes:     make(map[string]int64, len(d.graph.Files)),
+	}
+	for path, f := range d.graph.Files {
+		snap.Sizes[path] = f.Size
+	}
+	if fg := d.graph.FileGraph; fg != nil {
+		snap.Hubs = fg.HubFiles()
+		snap.Imports = fg.Imports
+		snap.Importers = fg.Importers
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	d.graph.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	dir := snapshotDir(d.root)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, commit+".json"), data, 0644); err != nil {
+		return err
+	}
+	pruneSnapshots(dir, d.snapshotKeep)
+	return nil
+}
+
+// pruneSnapshots removes the oldest snapshots beyond keep
+func pruneSnapshots(dir string, keep int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil || keep <= 0 {
+		return
+	}
+
+	type snapFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []snapFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") || e.Name() == WorkingSnapshot+".json" {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			files = append(files, snapFile{filepath.Join(dir, e.Name()), info.ModTime()})
+		}
+	}
+	if len(files) <= keep {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files[:len(files)-keep] {
+		os.Remove(f.path)
+	}
+}
+
+// ReadSnapshot loads the snapshot recorded for a commit
+func ReadSnapshot(root, commit string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(snapshotDir(root), commit+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// SaveWorkingSnapshot records the current file list and sizes under root as
+// the working-tree baseline, so later changes can be found without git. fg
+// supplies hubs and imports; it may be nil when no graph could be built.
+func SaveWorkingSnapshot(root string, fg *scanner.FileGraph) (*Snapshot, error) {
+	fp, err := scanner.Fingerprint(root)
+	if err != nil {
+		return nil, err
+	}
+	files, err := scanner.ScanFiles(root, scanner.NewGitIgnoreCache(root), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := Snapshot{
+		Time:        time.Now(),
+		FileCount:   len(files),
+		Fingerprint: fp.Hash,
+		Sizes:       make(map[string]int64, len(files)),
+	}
+	for _, f := range files {
+		snap.Sizes[filepath.ToSlash(f.Path)] = f.Size
+	}
+	if fg != nil {
+		snap.Hubs = fg.HubFiles()
+		snap.Imports = fg.Imports
+		snap.Importers = fg.Importers
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	dir := snapshotDir(root)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := scanner.WriteFileAtomic(filepath.Join(dir, WorkingSnapshot+".json"), data); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// ResizedFile is a file whose size differs from its snapshot
+type ResizedFile struct {
+	Path    string
+	OldSize int64
+	NewSize int64
+}
+
+// SnapshotDiff is what changed in the file list since a snapshot. Same-size
+// edits don't show up, as with scanner.Fingerprint.
+type SnapshotDiff struct {
+	Added   []string
+	Removed []string
+	Resized []ResizedFile
+}
+
+// Empty reports whether nothing changed
+func (sd SnapshotDiff) Empty() bool {
+	return len(sd.Added) == 0 && len(sd.Removed) == 0 && len(sd.Resized) == 0
+}
+
+// DiffSnapshot compares the scanned files against a snapshot's sizes
+func DiffSnapshot(snap *Snapshot, files []scanner.FileInfo) SnapshotDiff {
+	return DiffSizes(snap.Sizes, files)
+}
+
+// DiffSizes compares the scanned files against a recorded path -> size listing
+func DiffSizes(sizes map[string]int64, files []scanner.FileInfo) SnapshotDiff {
+	var sd SnapshotDiff
+	seen := make(map[string]bool, len(files))
+	for _, f := range files {
+		path := filepath.ToSlash(f.Path)
+		seen[path] = true
+		old, ok := sizes[path]
+		switch {
+		case !ok:
+			sd.Added = append(sd.Added, path)
+		case old != f.Size:
+			sd.Resized = append(sd.Resized, ResizedFile{Path: path, OldSize: old, NewSize: f.Size})
+		}
+	}
+	for path := range sizes {
+		if !seen[path] {
+			sd.Removed = append(sd.Removed, path)
+		}
+	}
+	sort.Strings(sd.Added)
+	sort.Strings(sd.Removed)
+	sort.Slice(sd.Resized, func(i, j int) bool { return sd.Resized[i].Path < sd.Resized[j].Path })
+	return sd
+}
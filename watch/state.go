@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
+
+	"codemap/scanner"
 )
 
 // ReadState reads the daemon state from disk (for hooks to use)
@@ -31,6 +35,95 @@ func ReadState(root string) *State {
 	return &state
 }
 
+// Graph returns the dependency edges recorded in s as a FileGraph under
+// root, with the project config applied as BuildFileGraph would, so hub and
+// importer checks against a daemon's state match a fresh graph's
+func (s *State) Graph(root string) *scanner.FileGraph {
+	fg := &scanner.FileGraph{Root: root, Imports: s.Imports, Importers: s.Importers}
+	cfg, _ := scanner.LoadConfig(root)
+	fg.ApplyConfig(cfg)
+	if len(s.Minified) > 0 {
+		fg.Minified = make(map[string]bool, len(s.Minified))
+		for _, f := range s.Minified {
+			fg.Minified[f] = true
+		}
+	}
+	return fg
+}
+
+// ReadLastState loads state.json however old it is - the last state a
+// daemon left behind - or nil if there is none
+func ReadLastState(root string) *State {
+	data, err := os.ReadFile(filepath.Join(root, ".codemap", "state.json"))
+	if err != nil {
+		return nil
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	return &state
+}
+
+// ReadEventLog parses .codemap/events.log into events.
+// Lines that don't match the log format are skipped.
+func ReadEventLog(root string) ([]Event, error) {
+	data, err := os.ReadFile(filepath.Join(root, ".codemap", "events.log"))
+	if err != nil {
+		return nil, err
+	}
+	tf, err := LoadTimeFormat(root)
+	if err != nil {
+		return nil, fmt.Errorf("time format: %w", err)
+	}
+	return parseEventLog(root, string(data), tf), nil
+}
+
+// ParseEventLog parses event log lines written by logEvent:
+// timestamp | OP | path | lines | delta | flags (comma-separated: dirty, unreadable)
+// with timestamps in the default layout
+func ParseEventLog(data string) []Event {
+	return parseEventLog("", data, DefaultTimeFormat())
+}
+
+// parseEventLog is ParseEventLog for root's log written with tf; ambiguous
+// extensions are sniffed under root ("" for the working directory)
+func parseEventLog(root, data string, tf TimeFormat) []Event {
+	var events []Event
+	for _, line := range strings.Split(data, "\n") {
+		parts := strings.Split(line, "|")
+		if len(parts) < 6 {
+			continue
+		}
+		t, err := tf.ParseLog(strings.TrimSpace(parts[0]))
+		if err != nil {
+			continue
+		}
+		e := Event{
+			Time:     t,
+			Op:       strings.TrimSpace(parts[1]),
+			Path:     strings.TrimSpace(parts[2]),
+			Language: scanner.DetectLanguageAt(root, strings.TrimSpace(parts[2])),
+		}
+		for _, flag := range strings.Split(strings.TrimSpace(parts[5]), ",") {
+			switch flag {
+			case "dirty":
+				e.Dirty = true
+			case "unreadable":
+				e.Unreadable = true
+			case "binary":
+				e.Binary = true
+			case "minified":
+				e.Minified = true
+			}
+		}
+		e.Lines, _ = strconv.Atoi(strings.TrimSpace(parts[3]))
+		e.Delta, _ = strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(parts[4]), "+"))
+		events = append(events, e)
+	}
+	return events
+}
+
 // WritePID writes the daemon PID to .codemap/watch.pid
 func WritePID(root string) error {
 	pidFile := filepath.Join(root, ".codemap", "watch.pid")
@@ -0,0 +1,68 @@
+package watch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StatusLine formats a one-line snapshot of a running daemon, e.g.
+//
+//	watching (pid 4242, up 1h12m) · 318 files · 57 events · last: api/server.go 3m ago · net +142 lines
+//
+// A nil state means the daemon is up but hasn't written fresh state yet.
+func StatusLine(state *State, pid int, now time.Time) string {
+	head := fmt.Sprintf("watching (pid %d", pid)
+	if state != nil && !state.StartedAt.IsZero() {
+		head += ", up " + shortDuration(now.Sub(state.StartedAt))
+	}
+	head += ")"
+	if state == nil {
+		return head + " · no fresh state yet"
+	}
+
+	parts := []string{
+		head,
+		fmt.Sprintf("%d files", state.FileCount),
+		fmt.Sprintf("%d events", state.EventsTotal),
+	}
+	if n := len(state.RecentEvents); n > 0 {
+		last := state.RecentEvents[n-1]
+		parts = append(parts, fmt.Sprintf("last: %s %s ago", last.Path, shortDuration(now.Sub(last.Time))))
+	} else {
+		parts = append(parts, "no edits yet")
+	}
+	parts = append(parts, fmt.Sprintf("net %+d lines", state.NetLines))
+	if w := state.Watch; w.Skipped > 0 {
+		mode := "unwatched"
+		if w.Polling {
+			mode = "polled"
+		}
+		parts = append(parts, fmt.Sprintf("%d dirs %s", w.Skipped, mode))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// shortDuration renders d at most two units deep: 45s, 12m, 1h12m, 3d4h
+func shortDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		h := int(d.Hours())
+		if m := int(d.Minutes()) % 60; m > 0 {
+			return fmt.Sprintf("%dh%dm", h, m)
+		}
+		return fmt.Sprintf("%dh", h)
+	}
+	days := int(d.Hours()) / 24
+	if h := int(d.Hours()) % 24; h > 0 {
+		return fmt.Sprintf("%dd%dh", days, h)
+	}
+	return fmt.Sprintf("%dd", days)
+}
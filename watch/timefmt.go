@@ -0,0 +1,112 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"codemap/scanner"
+)
+
+// Default event time layouts
+const (
+	DefaultClockFormat = "15:04:05"
+	DefaultLogFormat   = "2006-01-02 15:04:05"
+)
+
+// TimeFormat controls how event times are rendered: Clock for activity,
+// summaries, and verbose output; Log for .codemap/events.log. The zero
+// value renders like DefaultTimeFormat.
+type TimeFormat struct {
+	Clock    string
+	Log      string
+	Location *time.Location
+}
+
+// DefaultTimeFormat is the built-in formatting in local time
+func DefaultTimeFormat() TimeFormat {
+	return TimeFormat{Clock: DefaultClockFormat, Log: DefaultLogFormat, Location: time.Local}
+}
+
+// LoadTimeFormat reads time_format, log_time_format, and timezone from
+// root's .codemap/config.toml, overridden by the CODEMAP_TIME_FORMAT,
+// CODEMAP_LOG_TIME_FORMAT, and CODEMAP_TZ environment variables. Layouts
+// use Go's reference time (Mon Jan 2 15:04:05 MST 2006). A log layout may
+// not contain "|", the log's field separator, and must record the full date
+// and time to the second, so logged events parse back to when they happened.
+func LoadTimeFormat(root string) (TimeFormat, error) {
+	tf := DefaultTimeFormat()
+	cfg, err := scanner.LoadConfig(root)
+	if err != nil {
+		return tf, err
+	}
+	clock := firstSet(os.Getenv("CODEMAP_TIME_FORMAT"), cfg.TimeFormat)
+	log := firstSet(os.Getenv("CODEMAP_LOG_TIME_FORMAT"), cfg.LogTimeFormat)
+	zone := firstSet(os.Getenv("CODEMAP_TZ"), cfg.Timezone)
+
+	if clock != "" {
+		tf.Clock = clock
+	}
+	if log != "" {
+		if strings.Contains(log, "|") {
+			return tf, fmt.Errorf("log time format %q may not contain |", log)
+		}
+		tf.Log = log
+	}
+	if zone != "" {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return tf, fmt.Errorf("timezone %q: %w", zone, err)
+		}
+		tf.Location = loc
+	}
+	if log != "" {
+		now := time.Now().In(tf.location()).Truncate(time.Second)
+		if back, err := time.ParseInLocation(log, now.Format(log), tf.location()); err != nil || !back.Equal(now) {
+			return tf, fmt.Errorf("log time format %q must record the date and time to the second", log)
+		}
+	}
+	return tf, nil
+}
+
+// FormatClock renders t for activity and summaries
+func (tf TimeFormat) FormatClock(t time.Time) string {
+	return t.In(tf.location()).Format(firstSet(tf.Clock, DefaultClockFormat))
+}
+
+// FormatLog renders t for the event log
+func (tf TimeFormat) FormatLog(t time.Time) string {
+	return t.In(tf.location()).Format(tf.logLayout())
+}
+
+// ParseLog parses an event log timestamp, accepting the default layout too
+// so a log that predates a format change still reads
+func (tf TimeFormat) ParseLog(s string) (time.Time, error) {
+	t, err := time.ParseInLocation(tf.logLayout(), s, tf.location())
+	if err != nil && tf.logLayout() != DefaultLogFormat {
+		return time.ParseInLocation(DefaultLogFormat, s, tf.location())
+	}
+	return t, err
+}
+
+func (tf TimeFormat) logLayout() string {
+	return firstSet(tf.Log, DefaultLogFormat)
+}
+
+func (tf TimeFormat) location() *time.Location {
+	if tf.Location == nil {
+		return time.Local
+	}
+	return tf.Location
+}
+
+// firstSet returns the first non-empty value
+func firstSet(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
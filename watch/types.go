@@ -9,25 +9,30 @@ import (
 
 // Event represents a file change event with timestamp and structural context
 type Event struct {
-	Time      time.Time `json:"time"`
-	Op        string    `json:"op"`             // CREATE, WRITE, REMOVE, RENAME
-	Path      string    `json:"path"`           // relative path
-	Language  string    `json:"lang,omitempty"` // go, py, js, etc.
-	Lines     int       `json:"lines,omitempty"`
-	Delta     int       `json:"delta,omitempty"` // line count change (+/-)
-	SizeDelta int64     `json:"size_delta,omitempty"`
-	Dirty     bool      `json:"dirty,omitempty"` // uncommitted changes
+	Time       time.Time `json:"time"`
+	Op         string    `json:"op"`             // CREATE, WRITE, REMOVE, RENAME
+	Path       string    `json:"path"`           // relative path
+	Language   string    `json:"lang,omitempty"` // go, py, js, etc.
+	Lines      int       `json:"lines,omitempty"`
+	Delta      int       `json:"delta,omitempty"` // line count change (+/-)
+	SizeDelta  int64     `json:"size_delta,omitempty"`
+	Dirty      bool      `json:"dirty,omitempty"`      // uncommitted changes
+	Unreadable bool      `json:"unreadable,omitempty"` // file exists but couldn't be read: line/size fields unknown
+	Binary     bool      `json:"binary,omitempty"`     // binary or non-UTF-8 content: line fields unknown
+	Minified   bool      `json:"minified,omitempty"`   // minified content: line fields left out of line stats
 	// Structural context from deps
 	Importers  int      `json:"importers,omitempty"`   // how many files import this
 	Imports    int      `json:"imports,omitempty"`     // how many files this imports
-	IsHub      bool     `json:"is_hub,omitempty"`      // importers >= 3
+	IsHub      bool     `json:"is_hub,omitempty"`      // see scanner.FileGraph.IsHub
 	RelatedHot []string `json:"related_hot,omitempty"` // connected files also edited recently
 }
 
 // FileState tracks lightweight per-file state for delta calculations
 type FileState struct {
-	Lines int
-	Size  int64
+	Lines        int
+	Size         int64
+	LinesUnknown bool   // binary, non-UTF-8, or minified: Lines isn't counted
+	Hash         string // content hash, only with SetContentHash
 }
 
 // DepContext holds pre-computed dependency context for a file
@@ -55,7 +60,13 @@ type State struct {
 	UpdatedAt    time.Time           `json:"updated_at"`
 	FileCount    int                 `json:"file_count"`
 	Hubs         []string            `json:"hubs"`
-	Importers    map[string][]string `json:"importers"`     // file -> files that import it
-	Imports      map[string][]string `json:"imports"`       // file -> files it imports
-	RecentEvents []Event             `json:"recent_events"` // last 50 events for timeline
+	Minified     []string            `json:"minified,omitempty"` // files never counted as hubs, see scanner.LooksMinified
+	Importers    map[string][]string `json:"importers"`          // file -> files that import it
+	Imports      map[string][]string `json:"imports"`            // file -> files it imports
+	RecentEvents []Event             `json:"recent_events"`      // last 50 events for timeline
+	StartedAt    time.Time           `json:"started_at"`
+	EventsTotal  int                 `json:"events_total"`    // all events since StartedAt
+	NetLines     int                 `json:"net_lines"`       // sum of line deltas since StartedAt
+	Sizes        map[string]int64    `json:"sizes,omitempty"` // tracked file -> size, to diff against the next session
+	Watch        WatchStats          `json:"watch"`           // directories watched vs skipped at startup
 }
@@ -1,10 +1,24 @@
 package watch
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
+
+	"codemap/scanner"
+
+	"github.com/fsnotify/fsnotify"
 )
 
 // TestDaemonStartStop tests basic daemon lifecycle
@@ -362,6 +376,7 @@ func TestCountLines(t *testing.T) {
 		{"single line with newline", "hello\n", 1},
 		{"multiple lines", "line1\nline2\nline3", 3},
 		{"multiple lines with trailing newline", "line1\nline2\nline3\n", 3},
+		{"line longer than 64KB", strings.Repeat("x", 100*1024) + "\nend", 2},
 	}
 
 	for _, tt := range tests {
@@ -371,10 +386,1109 @@ func TestCountLines(t *testing.T) {
 				t.Fatalf("Failed to create test file: %v", err)
 			}
 
-			count := countLines(testFile)
-			if count != tt.expected {
-				t.Errorf("countLines(%q) = %d, want %d", tt.content, count, tt.expected)
+			count, err := lineCount(testFile)
+			if err != nil || count != tt.expected {
+				t.Errorf("lineCount(%s) = %d, %v, want %d", tt.name, count, err, tt.expected)
 			}
 		})
 	}
 }
+
+// TestMarkdownReport tests the standup report built from a fixed event set
+func TestMarkdownReport(t *testing.T) {
+	base := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: base, Op: "WRITE", Path: "api/auth.go", Delta: 20, IsHub: true},
+		{Time: base.Add(time.Minute), Op: "WRITE", Path: "api/auth.go", Delta: -5, IsHub: true},
+		{Time: base.Add(2 * time.Minute), Op: "CREATE", Path: "api/routes.go", Delta: 40},
+		{Time: base.Add(3 * time.Minute), Op: "WRITE", Path: "models/user.go", Delta: -10},
+		{Time: base.Add(4 * time.Minute), Op: "WRITE", Path: "db.go", Delta: 3},
+	}
+	hubs := map[string]bool{"db.go": true}
+
+	summary := Summarize(events, hubs)
+	if len(summary.Files) != 4 {
+		t.Errorf("Expected 4 files, got %d", len(summary.Files))
+	}
+	if summary.Added != 63 || summary.Removed != 15 {
+		t.Errorf("Expected +63/-15, got +%d/-%d", summary.Added, summary.Removed)
+	}
+	if len(summary.HubEdits) != 2 {
+		t.Errorf("Expected 2 hub edits, got %d", len(summary.HubEdits))
+	}
+
+	report := MarkdownReport("Standup", summary)
+	for _, want := range []string{
+		"## Standup",
+		"touched 4 files (+63/-15)",
+		"main work in api/",
+		"2 hub edits (auth.go, db.go)",
+		"### Hot files",
+		"### By directory",
+		"### Hub edits",
+		"| `api/` | 2 | 3 | +60/-5 |",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+// TestParseEventLog tests reading events back from the log format
+func TestParseEventLog(t *testing.T) {
+	log := "2025-01-15 09:00:00 | WRITE  | api/auth.go                              |   42 |    +20 | dirty\n" +
+		"garbage line\n" +
+		"2025-01-15 09:01:00 | REMOVE | old.go                                   |    0 |    -12 | \n"
+
+	events := ParseEventLog(log)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].Op != "WRITE" || events[0].Path != "api/auth.go" || events[0].Lines != 42 || events[0].Delta != 20 || !events[0].Dirty {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+	if events[1].Op != "REMOVE" || events[1].Delta != -12 || events[1].Dirty {
+		t.Errorf("Unexpected second event: %+v", events[1])
+	}
+}
+
+// TestReadEventLogSniffsUnderRoot verifies ambiguous extensions are sniffed
+// in the watched root, not the working directory
+func TestReadEventLogSniffsUnderRoot(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, ".codemap"), 0755)
+	os.MkdirAll(filepath.Join(root, "inc"), 0755)
+	if err := os.WriteFile(filepath.Join(root, "inc", "widget.h"), []byte("namespace ui {\nclass Widget {};\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	log := "2025-01-15 09:00:00 | WRITE  | inc/widget.h | 3 | +3 | \n"
+	if err := os.WriteFile(filepath.Join(root, ".codemap", "events.log"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := ReadEventLog(root)
+	if err != nil || len(events) != 1 || events[0].Language != "cpp" {
+		t.Errorf("Expected the C++ header to be detected under the root, got %+v, %v", events, err)
+	}
+}
+
+// TestBatchedDirtyState verifies a single git status resolves dirty flags for all pending events
+func TestBatchedDirtyState(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git unavailable: %v (%s)", err, out)
+		}
+	}
+	writeFile := func(name, content string) {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile("clean.go", "package main\n")
+	writeFile("pkg/edited.go", "package pkg\n")
+	git("init", "-q")
+	git("add", ".")
+	git("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qm", "init")
+
+	writeFile("pkg/edited.go", "package pkg\n\nfunc F() {}\n")
+	writeFile("new.go", "package main\n")
+
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+	os.MkdirAll(filepath.Join(tmpDir, ".codemap"), 0755)
+
+	now := time.Now()
+	for _, path := range []string{"clean.go", filepath.Join("pkg", "edited.go"), "new.go"} {
+		daemon.graph.Events = append(daemon.graph.Events, Event{Time: now, Op: "WRITE", Path: path})
+		daemon.dirtyPending = append(daemon.dirtyPending, len(daemon.graph.Events)-1)
+	}
+
+	daemon.flushEvents()
+
+	want := map[string]bool{
+		"clean.go":                        false,
+		filepath.Join("pkg", "edited.go"): true,
+		"new.go":                          true,
+	}
+	for _, e := range daemon.GetEvents(0) {
+		if e.Dirty != want[e.Path] {
+			t.Errorf("%s: Dirty = %v, want %v", e.Path, e.Dirty, want[e.Path])
+		}
+	}
+	if len(daemon.dirtyPending) != 0 {
+		t.Errorf("Expected pending dirty checks to be cleared, got %d", len(daemon.dirtyPending))
+	}
+
+	logged, err := ReadEventLog(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadEventLog failed: %v", err)
+	}
+	if len(logged) != 3 {
+		t.Errorf("Expected 3 logged events after flush, got %d", len(logged))
+	}
+}
+
+// TestWatchIgnore tests that events from --watch-ignore paths are dropped
+func TestWatchIgnore(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	genDir := filepath.Join(tmpDir, "gen")
+	if err := os.MkdirAll(genDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	ignoredFile := filepath.Join(genDir, "out.go")
+	keptFile := filepath.Join(tmpDir, "main.go")
+	os.WriteFile(ignoredFile, []byte("package gen\n"), 0644)
+	os.WriteFile(keptFile, []byte("package main\n"), 0644)
+
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	daemon.SetIgnorePatterns([]string{"gen"})
+
+	for path, want := range map[string]bool{
+		"gen":                               true,
+		filepath.Join("gen", "out.go"):      true,
+		filepath.Join("src", "gen", "a.go"): true,
+		"main.go":                           false,
+		"generate.go":                       false,
+	} {
+		if got := daemon.isIgnored(path); got != want {
+			t.Errorf("isIgnored(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	if err := daemon.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer daemon.Stop()
+
+	time.Sleep(300 * time.Millisecond)
+
+	os.WriteFile(ignoredFile, []byte("package gen\n\nvar X = 1\n"), 0644)
+	os.WriteFile(keptFile, []byte("package main\n\nfunc main() {}\n"), 0644)
+
+	time.Sleep(500 * time.Millisecond)
+
+	events := daemon.GetEvents(0)
+	if len(events) == 0 {
+		t.Skip("fsnotify may not work reliably in temp directories on this platform")
+	}
+	for _, e := range events {
+		if e.Path == filepath.Join("gen", "out.go") {
+			t.Errorf("Expected events from ignored path to be dropped, got %s %s", e.Op, e.Path)
+		}
+	}
+}
+
+// TestSortByImpact tests that hub edits float to the top, then larger deltas
+func TestSortByImpact(t *testing.T) {
+	base := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: base, Op: "WRITE", Path: "small.go", Delta: 2},
+		{Time: base.Add(time.Minute), Op: "WRITE", Path: "big.go", Delta: -40},
+		{Time: base.Add(2 * time.Minute), Op: "WRITE", Path: "hub.go", Delta: 1, IsHub: true},
+		{Time: base.Add(3 * time.Minute), Op: "WRITE", Path: "noop.go"},
+		{Time: base.Add(4 * time.Minute), Op: "WRITE", Path: "hub2.go", Delta: 10, IsHub: true},
+	}
+
+	sorted := SortByImpact(events)
+	want := []string{"hub2.go", "hub.go", "big.go", "small.go", "noop.go"}
+	for i, path := range want {
+		if sorted[i].Path != path {
+			t.Errorf("position %d: got %s, want %s", i, sorted[i].Path, path)
+		}
+	}
+
+	// Input order is untouched (chronological stays the default)
+	if events[0].Path != "small.go" {
+		t.Error("SortByImpact should not reorder its input")
+	}
+
+	top := HighestImpact(events, 10)
+	if len(top) != 4 {
+		t.Errorf("Expected no-op edit to be skipped, got %d events", len(top))
+	}
+}
+
+// TestRelatedHot tests that editing two connected files within the window populates RelatedHot
+func TestRelatedHot(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"config.go", "server.go", "other.go"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte("package main\n"), 0644)
+	}
+
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+	daemon.SetRelatedWindow(time.Minute)
+	daemon.graph.HasDeps = true
+	daemon.graph.FileGraph = &scanner.FileGraph{
+		Imports:   map[string][]string{"server.go": {"config.go"}},
+		Importers: map[string][]string{"config.go": {"server.go"}},
+	}
+
+	write := func(name string) Event {
+		daemon.handleEvent(fsnotify.Event{Name: filepath.Join(tmpDir, name), Op: fsnotify.Write})
+		events := daemon.GetEvents(1)
+		return events[len(events)-1]
+	}
+
+	if e := write("config.go"); len(e.RelatedHot) != 0 {
+		t.Errorf("First edit should have no related files, got %v", e.RelatedHot)
+	}
+	if e := write("other.go"); len(e.RelatedHot) != 0 {
+		t.Errorf("Unconnected file should have no related files, got %v", e.RelatedHot)
+	}
+	if e := write("server.go"); len(e.RelatedHot) != 1 || e.RelatedHot[0] != "config.go" {
+		t.Errorf("Expected server.go to be related to config.go, got %v", e.RelatedHot)
+	}
+
+	// Edits older than the window no longer count
+	daemon.graph.mu.Lock()
+	for i := range daemon.graph.Events {
+		daemon.graph.Events[i].Time = daemon.graph.Events[i].Time.Add(-2 * time.Minute)
+	}
+	daemon.graph.mu.Unlock()
+	if e := write("server.go"); len(e.RelatedHot) != 0 {
+		t.Errorf("Expected edits outside the window to be ignored, got %v", e.RelatedHot)
+	}
+}
+
+// TestCodemapDirNeverWatched tests that codemap's own scratch files never produce events
+func TestCodemapDirNeverWatched(t *testing.T) {
+	daemon, err := NewDaemon(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+
+	for _, path := range []string{".codemap", filepath.Join(".codemap", "state.json"), filepath.Join(".codemap", "events.log")} {
+		if !daemon.isIgnored(path) {
+			t.Errorf("Expected %s to be ignored", path)
+		}
+	}
+	if daemon.isIgnored("main.go") {
+		t.Error("main.go should not be ignored")
+	}
+}
+
+// TestCommitSnapshot tests that a new commit produces a snapshot and old ones are pruned
+func TestCommitSnapshot(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = tmpDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git unavailable: %v (%s)", err, out)
+		}
+	}
+	commit := func(name string) string {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte("package main\n"), 0644)
+		git("add", ".")
+		git("commit", "-qm", "add "+name)
+		return gitHead(tmpDir)
+	}
+
+	git("init", "-q")
+	commit("main.go")
+
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+	daemon.SetSnapshotLimit(2)
+	daemon.graph.FileGraph = &scanner.FileGraph{
+		Imports:   map[string][]string{"a.go": {"main.go"}},
+		Importers: map[string][]string{"main.go": {"a.go"}},
+	}
+	daemon.lastHead = gitHead(tmpDir)
+
+	// No new commit: nothing written
+	daemon.checkHead()
+	if _, err := os.Stat(snapshotDir(tmpDir)); !os.IsNotExist(err) {
+		t.Fatal("Expected no snapshot before any new commit")
+	}
+
+	sha := commit("a.go")
+	daemon.checkHead()
+
+	snap, err := ReadSnapshot(tmpDir, sha)
+	if err != nil {
+		t.Fatalf("Expected snapshot for %s: %v", sha, err)
+	}
+	if snap.Commit != sha {
+		t.Errorf("Snapshot commit = %s, want %s", snap.Commit, sha)
+	}
+
+	// Retention: only the newest 2 survive
+	for _, name := range []string{"b.go", "c.go"} {
+		commit(name)
+		daemon.checkHead()
+		time.Sleep(10 * time.Millisecond) // distinct mtimes for pruning order
+	}
+	entries, _ := os.ReadDir(snapshotDir(tmpDir))
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 retained snapshots, got %d", len(entries))
+	}
+	if _, err := ReadSnapshot(tmpDir, sha); err == nil {
+		t.Error("Expected the oldest snapshot to be pruned")
+	}
+}
+
+// TestAtomicStateWrite tests that hooks reading state.json never see a partial document
+func TestAtomicStateWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+
+	// A large graph makes each write take long enough to race against
+	importers := make(map[string][]string)
+	for i := 0; i < 2000; i++ {
+		importers[fmt.Sprintf("pkg/file%04d.go", i)] = []string{"a.go", "b.go", "c.go"}
+	}
+	daemon.graph.FileGraph = &scanner.FileGraph{Importers: importers}
+
+	os.MkdirAll(filepath.Join(tmpDir, ".codemap"), 0755)
+	stateFile := filepath.Join(tmpDir, ".codemap", "state.json")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			daemon.writeState()
+		}
+	}()
+
+	reads := 0
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		data, err := os.ReadFile(stateFile)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("read state: %v", err)
+		}
+		var state State
+		if err := json.Unmarshal(data, &state); err != nil {
+			t.Fatalf("Observed a partial state.json (%d bytes): %v", len(data), err)
+		}
+		reads++
+	}
+	if reads == 0 {
+		t.Error("Expected to read state.json at least once")
+	}
+
+	// No temp files left behind
+	entries, _ := os.ReadDir(filepath.Join(tmpDir, ".codemap"))
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("Leftover temp file %s", e.Name())
+		}
+	}
+}
+
+// TestWatchOnly tests that --only restricts recorded events to the given files
+func TestWatchOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte("package main\n"), 0644)
+	}
+
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	if err := daemon.SetOnlyFiles([]string{"missing.go"}); err == nil {
+		t.Error("Expected a missing --only file to be rejected")
+	}
+	if err := daemon.SetOnlyFiles([]string{"a.go", filepath.Join(tmpDir, "b.go")}); err != nil {
+		t.Fatalf("SetOnlyFiles failed: %v", err)
+	}
+
+	if err := daemon.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer daemon.Stop()
+
+	time.Sleep(300 * time.Millisecond)
+
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte("package main\n\nfunc f() {}\n"), 0644)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	events := daemon.GetEvents(0)
+	if len(events) == 0 {
+		t.Skip("fsnotify may not work reliably in temp directories on this platform")
+	}
+	seen := make(map[string]bool)
+	for _, e := range events {
+		seen[e.Path] = true
+	}
+	if seen["c.go"] {
+		t.Error("Expected events for c.go to be dropped")
+	}
+	if !seen["a.go"] || !seen["b.go"] {
+		t.Errorf("Expected events for a.go and b.go, got %v", seen)
+	}
+}
+
+// promSample matches a sample line of the Prometheus text format (no labels)
+var promSample = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*) ([-+]?(?:[0-9]*\.?[0-9]+(?:[eE][-+]?[0-9]+)?|NaN|[-+]Inf))$`)
+
+func TestWriteMetrics(t *testing.T) {
+	now := time.Now()
+	state := &State{
+		UpdatedAt:   now,
+		FileCount:   42,
+		Hubs:        []string{"types.go", "util.go"},
+		StartedAt:   now.Add(-90 * time.Second),
+		EventsTotal: 7,
+		NetLines:    -12,
+	}
+
+	var sb strings.Builder
+	if err := WriteMetrics(&sb, state, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every sample must be preceded by HELP and TYPE lines for its name
+	values := make(map[string]string)
+	typed := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n") {
+		if strings.HasPrefix(line, "# HELP ") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "# TYPE "); ok {
+			name, kind, _ := strings.Cut(rest, " ")
+			if kind != "counter" && kind != "gauge" {
+				t.Errorf("bad TYPE line %q", line)
+			}
+			typed[name] = kind
+			continue
+		}
+		m := promSample.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("invalid sample line %q", line)
+		}
+		if typed[m[1]] == "" {
+			t.Errorf("sample %s has no TYPE line before it", m[1])
+		}
+		values[m[1]] = m[2]
+	}
+
+	want := map[string]string{
+		"codemap_watch_up":             "1",
+		"codemap_watch_events_total":   "7",
+		"codemap_watch_files_tracked":  "42",
+		"codemap_watch_hubs":           "2",
+		"codemap_watch_net_lines":      "-12",
+		"codemap_watch_uptime_seconds": "90",
+	}
+	for name, v := range want {
+		if values[name] != v {
+			t.Errorf("%s = %q, want %q", name, values[name], v)
+		}
+	}
+	if typed["codemap_watch_events_total"] != "counter" {
+		t.Errorf("events_total should be a counter, got %q", typed["codemap_watch_events_total"])
+	}
+
+	// No state: only the up gauge, reporting 0
+	sb.Reset()
+	WriteMetrics(&sb, nil, now)
+	if !strings.HasSuffix(sb.String(), "codemap_watch_up 0\n") || strings.Contains(sb.String(), "events_total") {
+		t.Errorf("nil state output = %q", sb.String())
+	}
+}
+
+// TestUnreadableFileStillRecorded tests that a stat failure on an existing
+// file records a minimal event instead of dropping it, while a file that's
+// gone is left to its REMOVE event
+func TestUnreadableFileStillRecorded(t *testing.T) {
+	tmpDir := t.TempDir()
+	locked := filepath.Join(tmpDir, "locked.go")
+	os.WriteFile(locked, []byte("package main\n\nfunc f() {}\n"), 0644)
+
+	daemon, err := NewDaemon(tmpDir, true)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+	daemon.graph.State["locked.go"] = &FileState{Lines: 3, Size: 26}
+
+	statFile = func(name string) (os.FileInfo, error) {
+		if name == locked {
+			return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrPermission}
+		}
+		return os.Stat(name)
+	}
+	defer func() { statFile = os.Stat }()
+
+	daemon.handleEvent(fsnotify.Event{Name: locked, Op: fsnotify.Write})
+	events := daemon.GetEvents(0)
+	if len(events) != 1 {
+		t.Fatalf("Expected the unreadable write to be recorded, got %d events", len(events))
+	}
+	e := events[0]
+	if e.Op != "WRITE" || e.Path != "locked.go" || !e.Unreadable || e.Delta != 0 {
+		t.Errorf("Expected minimal unreadable WRITE event for locked.go, got %+v", e)
+	}
+	if st := daemon.graph.State["locked.go"]; st == nil || st.Lines != 3 {
+		t.Errorf("Cached state should be kept for the next readable event, got %+v", st)
+	}
+
+	// Gone, not unreadable: dropped here, the REMOVE that follows records it
+	daemon.handleEvent(fsnotify.Event{Name: filepath.Join(tmpDir, "gone.go"), Op: fsnotify.Write})
+	if n := len(daemon.GetEvents(0)); n != 1 {
+		t.Errorf("Expected no event for a vanished file, got %d events", n)
+	}
+
+	// The flag survives the event log round trip
+	line := fmt.Sprintf("%s | WRITE  | locked.go | 0 | | unreadable\n", time.Now().Format("2006-01-02 15:04:05"))
+	if parsed := ParseEventLog(line); len(parsed) != 1 || !parsed[0].Unreadable || parsed[0].Dirty {
+		t.Errorf("ParseEventLog unreadable flag: %+v", parsed)
+	}
+}
+
+// TestBinaryFileLinesUnknown tests that a source-extension file with binary
+// content is recorded without line counts
+func TestBinaryFileLinesUnknown(t *testing.T) {
+	tmpDir := t.TempDir()
+	bundle := filepath.Join(tmpDir, "bundle.js")
+	os.WriteFile(bundle, []byte("var a = 1;\n"), 0644)
+
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+	daemon.graph.State["bundle.js"] = &FileState{Lines: 1, Size: 11}
+
+	os.WriteFile(bundle, []byte("var a = 1;\n\x00\x00\x01\n\n"), 0644)
+	daemon.handleEvent(fsnotify.Event{Name: bundle, Op: fsnotify.Write})
+
+	events := daemon.GetEvents(0)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if !e.Binary || e.Lines != 0 || e.Delta != 0 || e.SizeDelta != 5 {
+		t.Errorf("Expected a binary WRITE with size delta 5 and no line counts, got %+v", e)
+	}
+	if st := daemon.graph.State["bundle.js"]; st == nil || !st.LinesUnknown {
+		t.Errorf("Expected cached lines to be marked unknown, got %+v", st)
+	}
+	if n := countLines(bundle); n != 0 {
+		t.Errorf("countLines(binary) = %d, want 0", n)
+	}
+
+	line := fmt.Sprintf("%s | WRITE  | bundle.js | 0 | | binary\n", time.Now().Format("2006-01-02 15:04:05"))
+	if parsed := ParseEventLog(line); len(parsed) != 1 || !parsed[0].Binary {
+		t.Errorf("ParseEventLog binary flag: %+v", parsed)
+	}
+}
+
+func TestStatusLine(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	state := &State{
+		UpdatedAt:   now,
+		FileCount:   318,
+		StartedAt:   now.Add(-(72 * time.Minute)),
+		EventsTotal: 57,
+		NetLines:    142,
+		RecentEvents: []Event{
+			{Time: now.Add(-time.Hour), Op: "WRITE", Path: "main.go"},
+			{Time: now.Add(-3 * time.Minute), Op: "WRITE", Path: "api/server.go"},
+		},
+	}
+
+	want := "watching (pid 4242, up 1h12m) · 318 files · 57 events · last: api/server.go 3m ago · net +142 lines"
+	if got := StatusLine(state, 4242, now); got != want {
+		t.Errorf("StatusLine =\n  %q\nwant\n  %q", got, want)
+	}
+
+	state.RecentEvents = nil
+	state.NetLines = -5
+	if got := StatusLine(state, 4242, now); !strings.Contains(got, "no edits yet · net -5 lines") {
+		t.Errorf("Expected an idle status, got %q", got)
+	}
+	if got := StatusLine(nil, 4242, now); got != "watching (pid 4242) · no fresh state yet" {
+		t.Errorf("StatusLine(nil) = %q", got)
+	}
+
+	for d, want := range map[time.Duration]string{
+		45 * time.Second:       "45s",
+		12 * time.Minute:       "12m",
+		2 * time.Hour:          "2h",
+		(3*24 + 4) * time.Hour: "3d4h",
+		-time.Second:           "0s",
+	} {
+		if got := shortDuration(d); got != want {
+			t.Errorf("shortDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestCustomTimeFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, ".codemap"), 0755)
+	config := "time_format = \"3:04PM\"\nlog_time_format = \"2006-01-02T15:04:05Z07:00\"\ntimezone = \"UTC\"\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, scanner.ConfigFile), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+
+	at := time.Date(2026, 5, 4, 18, 30, 15, 0, time.FixedZone("CEST", 2*3600))
+	if got := daemon.timeFormat.FormatClock(at); got != "4:30PM" {
+		t.Errorf("FormatClock = %q, want 4:30PM (UTC)", got)
+	}
+
+	daemon.logEvent(Event{Time: at, Op: "WRITE", Path: "main.go", Lines: 10, Delta: 2})
+	data, err := os.ReadFile(daemon.eventLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "2026-05-04T16:30:15Z | WRITE") {
+		t.Errorf("event log line = %q, want the custom UTC layout", data)
+	}
+	events, err := ReadEventLog(tmpDir)
+	if err != nil || len(events) != 1 || !events[0].Time.Equal(at) {
+		t.Errorf("ReadEventLog with custom layout = %+v, %v", events, err)
+	}
+
+	// Environment overrides the config; lines in the old layout still parse
+	t.Setenv("CODEMAP_TIME_FORMAT", "15h04")
+	tf, err := LoadTimeFormat(tmpDir)
+	if err != nil || tf.FormatClock(at) != "16h30" {
+		t.Errorf("CODEMAP_TIME_FORMAT not applied: %q, %v", tf.FormatClock(at), err)
+	}
+	if _, err := tf.ParseLog("2026-05-04 16:30:15"); err != nil {
+		t.Errorf("default-layout log line should still parse: %v", err)
+	}
+
+	// A log layout that loses the date (or seconds) would misdate every event
+	for _, layout := range []string{"15:04:05", "2006-01-02 15:04"} {
+		t.Setenv("CODEMAP_LOG_TIME_FORMAT", layout)
+		if _, err := LoadTimeFormat(tmpDir); err == nil {
+			t.Errorf("Expected log layout %q to be rejected", layout)
+		}
+	}
+	if _, err := ReadEventLog(tmpDir); err == nil || !strings.Contains(err.Error(), "time format") {
+		t.Errorf("Expected ReadEventLog to report the config error, got %v", err)
+	}
+	t.Setenv("CODEMAP_LOG_TIME_FORMAT", "")
+
+	t.Setenv("CODEMAP_TZ", "Not/AZone")
+	if _, err := LoadTimeFormat(tmpDir); err == nil {
+		t.Error("Expected an unknown timezone to be rejected")
+	}
+	if got := (TimeFormat{}).FormatClock(at); got != at.In(time.Local).Format(DefaultClockFormat) {
+		t.Errorf("zero TimeFormat should render the default, got %q", got)
+	}
+}
+
+// TestWorkingSnapshotDiff tests comparing a mutated tree against a saved working snapshot
+func TestWorkingSnapshotDiff(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n")
+	write("util.go", "package main\n")
+	write("old.go", "package main\n")
+
+	fg := &scanner.FileGraph{
+		Importers: map[string][]string{"old.go": {"main.go"}},
+	}
+	if _, err := SaveWorkingSnapshot(tmpDir, fg); err != nil {
+		t.Fatalf("SaveWorkingSnapshot failed: %v", err)
+	}
+
+	write("new.go", "package main\n")
+	write("util.go", "package main\n\nfunc helper() {}\n")
+	os.Remove(filepath.Join(tmpDir, "old.go"))
+
+	snap, err := ReadSnapshot(tmpDir, WorkingSnapshot)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+	if got := snap.Importers["old.go"]; len(got) != 1 {
+		t.Errorf("Snapshot importers of old.go = %v, want [main.go]", got)
+	}
+
+	files, err := scanner.ScanFiles(tmpDir, scanner.NewGitIgnoreCache(tmpDir), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := DiffSnapshot(snap, files)
+	if !reflect.DeepEqual(diff.Added, []string{"new.go"}) {
+		t.Errorf("Added = %v, want [new.go]", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"old.go"}) {
+		t.Errorf("Removed = %v, want [old.go]", diff.Removed)
+	}
+	if len(diff.Resized) != 1 || diff.Resized[0].Path != "util.go" || diff.Resized[0].NewSize <= diff.Resized[0].OldSize {
+		t.Errorf("Resized = %+v, want util.go grown", diff.Resized)
+	}
+
+	// Commit snapshot pruning leaves the working baseline alone
+	pruneSnapshots(snapshotDir(tmpDir), 1)
+	os.WriteFile(filepath.Join(snapshotDir(tmpDir), "abc.json"), []byte("{}"), 0644)
+	pruneSnapshots(snapshotDir(tmpDir), 1)
+	if _, err := ReadSnapshot(tmpDir, WorkingSnapshot); err != nil {
+		t.Errorf("Working snapshot was pruned: %v", err)
+	}
+}
+
+// TestWatchLimitFallsBackToPolling tests that hitting the OS watch limit
+// stops further adds, reports the skipped directories, and polls them
+func TestWatchLimitFallsBackToPolling(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"other", "pkg/deep"} {
+		os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0644)
+	deep := filepath.Join(tmpDir, "pkg", "deep", "c.go")
+	os.WriteFile(deep, []byte("package deep\n"), 0644)
+
+	attempts := 0
+	addWatch = func(w *fsnotify.Watcher, path string) error {
+		attempts++
+		if attempts > 1 {
+			return fmt.Errorf("add %s: %w", path, syscall.ENOSPC)
+		}
+		return w.Add(path)
+	}
+	defer func() {
+		addWatch = func(w *fsnotify.Watcher, path string) error { return w.Add(path) }
+	}()
+
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+
+	if err := daemon.addWatchDirs(); err != nil {
+		t.Fatalf("addWatchDirs should survive the watch limit, got %v", err)
+	}
+	stats := daemon.WatchStats()
+	// pkg, pkg/deep, and other are all unwatched; pkg and other are polled
+	want := WatchStats{Watched: 1, Skipped: 3, LimitHit: true, Polling: true}
+	if stats != want {
+		t.Errorf("WatchStats = %+v, want %+v", stats, want)
+	}
+	if len(daemon.polled) != 2 {
+		t.Errorf("Expected the 2 unwatched subtree roots to be polled, got %v", daemon.polled)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected no adds after the limit error, got %d attempts", attempts)
+	}
+	warning := WatchLimitWarning(stats)
+	if !strings.Contains(warning, "fs.inotify.max_user_watches") || !strings.Contains(warning, "Polling") {
+		t.Errorf("Expected sysctl guidance and a polling note, got:\n%s", warning)
+	}
+
+	// Edits under skipped directories come through the poller
+	daemon.pollOnce()
+	os.WriteFile(deep, []byte("package deep\n\nfunc C() {}\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "other", "e.go"), []byte("package other\n"), 0644)
+	daemon.pollOnce()
+
+	got := make(map[string]string)
+	for _, e := range daemon.GetEvents(0) {
+		got[e.Path] = e.Op
+	}
+	if got[filepath.Join("pkg", "deep", "c.go")] != "WRITE" || got[filepath.Join("other", "e.go")] != "CREATE" || len(got) != 2 {
+		t.Errorf("Expected polled WRITE pkg/deep/c.go and CREATE other/e.go, got %v", got)
+	}
+
+	// With polling off, the skipped directories are only reported
+	daemon.SetPollFallback(false)
+	attempts = 0
+	daemon.watcher.Remove(tmpDir)
+	daemon.addWatchDirs()
+	if stats := daemon.WatchStats(); stats.Polling || stats.Skipped != 3 {
+		t.Errorf("Expected 3 skipped dirs and no polling, got %+v", stats)
+	}
+	if warning := WatchLimitWarning(daemon.WatchStats()); !strings.Contains(warning, "won't show up") {
+		t.Errorf("Expected a no-polling warning, got:\n%s", warning)
+	}
+	if WatchLimitWarning(WatchStats{Watched: 3}) != "" {
+		t.Error("Expected no warning when every directory is watched")
+	}
+}
+
+// TestContentHashDropsNoopSave tests that with content hashing on, a save
+// with identical content produces no event while a real edit still does
+func TestContentHashDropsNoopSave(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "main.go")
+	content := []byte("package main\n\nfunc main() {}\n")
+	os.WriteFile(file, content, 0644)
+
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+	daemon.SetContentHash(true)
+	if err := daemon.fullScan(); err != nil {
+		t.Fatal(err)
+	}
+	if st := daemon.graph.State["main.go"]; st == nil || st.Hash == "" {
+		t.Fatalf("Expected the initial scan to record a content hash, got %+v", st)
+	}
+
+	os.WriteFile(file, content, 0644)
+	daemon.handleEvent(fsnotify.Event{Name: file, Op: fsnotify.Write})
+	if n := len(daemon.GetEvents(0)); n != 0 {
+		t.Fatalf("Expected no event for a no-op save, got %d", n)
+	}
+
+	os.WriteFile(file, []byte("package main\n\nfunc main() { run() }\n"), 0644)
+	daemon.handleEvent(fsnotify.Event{Name: file, Op: fsnotify.Write})
+	if n := len(daemon.GetEvents(0)); n != 1 {
+		t.Fatalf("Expected a same-line-count edit to be recorded, got %d events", n)
+	}
+
+	// Off (the default): the same no-op save is recorded
+	daemon.SetContentHash(false)
+	daemon.handleEvent(fsnotify.Event{Name: file, Op: fsnotify.Write})
+	if n := len(daemon.GetEvents(0)); n != 2 {
+		t.Errorf("Expected the no-op save to be recorded without hashing, got %d events", n)
+	}
+}
+
+// TestSessionExportJSON tests that a session with known events exports to
+// the expected versioned JSON document
+func TestSessionExportJSON(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return start.Add(time.Duration(min) * time.Minute) }
+	events := []Event{
+		{Time: at(-30), Op: "WRITE", Path: "old.go", Delta: 4}, // previous session
+		{Time: at(1), Op: "WRITE", Path: "api/server.go", Delta: 10},
+		{Time: at(2), Op: "WRITE", Path: "api/server.go", Delta: -3},
+		{Time: at(3), Op: "CREATE", Path: "db/pool.go", Delta: 20},
+		{Time: at(4), Op: "REMOVE", Path: "db/old.go", Delta: -8},
+	}
+	export := NewSessionExport("/src/shop", events, start, at(60), false, map[string]bool{"api/server.go": true})
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	json.Unmarshal(data, &got)
+
+	want := `{
+		"version": 1,
+		"root": "/src/shop",
+		"start": "2026-03-02T09:00:00Z",
+		"end": "2026-03-02T10:00:00Z",
+		"running": false,
+		"totals": {"events": 4, "files": 3, "added": 30, "removed": 11},
+		"events": [
+			{"time": "2026-03-02T09:01:00Z", "op": "WRITE", "path": "api/server.go", "delta": 10, "is_hub": true},
+			{"time": "2026-03-02T09:02:00Z", "op": "WRITE", "path": "api/server.go", "delta": -3, "is_hub": true},
+			{"time": "2026-03-02T09:03:00Z", "op": "CREATE", "path": "db/pool.go", "delta": 20},
+			{"time": "2026-03-02T09:04:00Z", "op": "REMOVE", "path": "db/old.go", "delta": -8}
+		],
+		"files": [
+			{"path": "api/server.go", "edits": 2, "added": 10, "removed": 3, "net_delta": 7, "last_edit": "2026-03-02T09:02:00Z", "is_hub": true},
+			{"path": "db/pool.go", "edits": 1, "added": 20, "removed": 0, "net_delta": 20, "last_edit": "2026-03-02T09:03:00Z"},
+			{"path": "db/old.go", "edits": 0, "added": 0, "removed": 8, "net_delta": -8, "last_edit": "2026-03-02T09:04:00Z"}
+		],
+		"hot_files": [
+			{"path": "api/server.go", "edits": 2, "added": 10, "removed": 3, "net_delta": 7, "last_edit": "2026-03-02T09:02:00Z", "is_hub": true},
+			{"path": "db/pool.go", "edits": 1, "added": 20, "removed": 0, "net_delta": 20, "last_edit": "2026-03-02T09:03:00Z"},
+			{"path": "db/old.go", "edits": 0, "added": 0, "removed": 8, "net_delta": -8, "last_edit": "2026-03-02T09:04:00Z"}
+		],
+		"hub_edits": [
+			{"path": "api/server.go", "edits": 2, "added": 10, "removed": 3, "net_delta": 7, "last_edit": "2026-03-02T09:02:00Z", "is_hub": true}
+		],
+		"dirs": [
+			{"dir": "api", "files": 1, "edits": 2, "added": 10, "removed": 3},
+			{"dir": "db", "files": 2, "edits": 1, "added": 20, "removed": 8}
+		]
+	}`
+	var expected map[string]any
+	if err := json.Unmarshal([]byte(want), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Session export mismatch:\ngot  %s", data)
+	}
+
+	// The document round-trips
+	var back SessionExport
+	if err := json.Unmarshal(data, &back); err != nil || back.Version != SessionSchemaVersion || len(back.Events) != 4 {
+		t.Errorf("Round trip failed: %v %+v", err, back.Totals)
+	}
+}
+
+func TestExportSessionErrors(t *testing.T) {
+	root := t.TempDir()
+	if _, err := ExportSession(root, time.Now()); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a not-found error without an event log, got %v", err)
+	}
+
+	// Any other read failure is reported as itself
+	if err := os.MkdirAll(filepath.Join(root, ".codemap", "events.log"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExportSession(root, time.Now()); err == nil || strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected the real read error, got %v", err)
+	}
+}
+
+// TestEventSpans tests that events map to edit bursts (traces) split at idle
+// gaps, with the expected span attributes, and that the OTLP exporter posts
+// them to /v1/traces
+func TestEventSpans(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: start, Op: "WRITE", Path: "api/server.go", Language: "go", Delta: 10, SizeDelta: 240, Importers: 5, IsHub: true},
+		{Time: start.Add(5 * time.Minute), Op: "CREATE", Path: "db/pool.go", Language: "go", Lines: 20, Delta: 20},
+		{Time: start.Add(2 * time.Hour), Op: "REMOVE", Path: "db/old.go", Delta: -8},
+	}
+
+	spans := EventSpans("/src/shop", events, DefaultBurstGap)
+	if len(spans) != 5 {
+		t.Fatalf("Expected 2 burst spans + 3 event spans, got %d", len(spans))
+	}
+	burst, write, create := spans[0], spans[1], spans[2]
+	if burst.Name != "edit burst" || burst.ParentSpanID != "" || !burst.End.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("Unexpected burst span: %+v", burst)
+	}
+	if burst.Attributes["codemap.events"] != 2 || burst.Attributes["codemap.delta"] != 30 || burst.Attributes["codemap.hub_edits"] != 1 {
+		t.Errorf("Unexpected burst attributes: %v", burst.Attributes)
+	}
+	wantWrite := map[string]any{
+		"code.filepath":      "api/server.go",
+		"codemap.op":         "WRITE",
+		"codemap.language":   "go",
+		"codemap.delta":      10,
+		"codemap.size_delta": int64(240),
+		"codemap.importers":  5,
+		"codemap.is_hub":     true,
+	}
+	if !reflect.DeepEqual(write.Attributes, wantWrite) {
+		t.Errorf("WRITE attributes = %v, want %v", write.Attributes, wantWrite)
+	}
+	if write.TraceID != burst.TraceID || write.ParentSpanID != burst.SpanID || create.Attributes["codemap.lines"] != 20 {
+		t.Errorf("Event spans should be children of their burst: %+v %+v", write, create)
+	}
+	if spans[3].TraceID == burst.TraceID || spans[4].ParentSpanID != spans[3].SpanID {
+		t.Error("An event after an idle gap should start a new trace")
+	}
+	if again := EventSpans("/src/shop", events, DefaultBurstGap); again[1].SpanID != write.SpanID {
+		t.Error("Span IDs should be stable across exports")
+	}
+
+	var gotPath string
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	var exporter SpanExporter = OTLPHTTPExporter{Endpoint: srv.URL}
+	if err := exporter.ExportSpans(spans); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/v1/traces" {
+		t.Errorf("Posted to %q, want /v1/traces", gotPath)
+	}
+	for _, want := range []string{
+		`"traceId":"` + burst.TraceID + `"`,
+		`{"key":"codemap.delta","value":{"intValue":"10"}}`,
+		`{"key":"codemap.is_hub","value":{"boolValue":true}}`,
+		`{"key":"service.name","value":{"stringValue":"codemap"}}`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %s in the OTLP body:\n%s", want, body)
+		}
+	}
+}
+
+// TestMinifiedFileLinesExcluded tests that a .min.js bundle stays out of
+// line stats unless include_minified is set
+func TestMinifiedFileLinesExcluded(t *testing.T) {
+	tmpDir := t.TempDir()
+	bundle := filepath.Join(tmpDir, "app.min.js")
+	os.WriteFile(bundle, []byte("var a=1;var b=2;\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "main.js"), []byte("var a = 1;\nvar b = 2;\n"), 0644)
+
+	daemon, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer daemon.watcher.Close()
+	if err := daemon.fullScan(); err != nil {
+		t.Fatal(err)
+	}
+	if st := daemon.graph.State["app.min.js"]; st == nil || !st.LinesUnknown {
+		t.Errorf("Expected the minified file's lines to be left uncounted, got %+v", st)
+	}
+	if st := daemon.graph.State["main.js"]; st == nil || st.Lines != 2 {
+		t.Errorf("Expected main.js to be counted as 2 lines, got %+v", st)
+	}
+
+	os.WriteFile(bundle, []byte("var a=1;var b=2;\nvar c=3;\n"), 0644)
+	daemon.handleEvent(fsnotify.Event{Name: bundle, Op: fsnotify.Write})
+	events := daemon.GetEvents(0)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if e := events[0]; !e.Minified || e.Binary || e.Lines != 0 || e.Delta != 0 || e.SizeDelta != 9 {
+		t.Errorf("Expected a minified WRITE with only a size delta, got %+v", e)
+	}
+
+	// include_minified counts it like any other file
+	os.MkdirAll(filepath.Join(tmpDir, ".codemap"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, scanner.ConfigFile), []byte("include_minified = true\n"), 0644)
+	counting, err := NewDaemon(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	defer counting.watcher.Close()
+	if err := counting.fullScan(); err != nil {
+		t.Fatal(err)
+	}
+	if st := counting.graph.State["app.min.js"]; st == nil || st.Lines != 2 {
+		t.Errorf("Expected include_minified to count the bundle's 2 lines, got %+v", st)
+	}
+}